@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -44,6 +45,16 @@ var (
 		"list feeds ",
 		"is shareing ",
 
+		// quotas
+
+		"set quota ",
+		"quota ",
+
+		// backup
+
+		"backup ",
+		"restore ",
+
 		// tcp
 
 		"tcp connect ",
@@ -73,8 +84,15 @@ var (
 
 		"root info ",
 		"root tree ",
+		"root get ",
+		"root json ",
+		"root publish ",
 		"last root ",
 
+		"root pin ",
+		"root unpin ",
+		"root pins ",
+
 		// stat
 
 		"stat ",
@@ -265,6 +283,12 @@ func (c *client) mapping() map[string]func(in []string) (err error) {
 		"list feeds":       c.listFeeds,
 		"is shareing":      c.isShareing,
 
+		"set quota": c.setQuota,
+		"quota":     c.quota,
+
+		"backup":  c.backup,
+		"restore": c.restore,
+
 		"tcp connect":     c.tcpConnect,
 		"tcp disconnect":  c.tcpDisconnet,
 		"tcp subsribe":    c.tcpSubscribe,
@@ -282,8 +306,16 @@ func (c *client) mapping() map[string]func(in []string) (err error) {
 
 		"root info": c.rootInfo,
 		"root tree": c.rootTree,
+		"root get":  c.rootGet,
+		"root json": c.rootJSON,
 		"last root": c.lastRoot,
 
+		"root publish": c.rootPublish,
+
+		"root pin":   c.rootPin,
+		"root unpin": c.rootUnpin,
+		"root pins":  c.rootPins,
+
 		"stat": c.stat,
 
 		"help": c.help,
@@ -429,6 +461,94 @@ func (c *client) argsRoot(in []string) (rs node.RootSelector, err error) {
 
 }
 
+func (c *client) argsRootPath(in []string) (rp node.RootPath, err error) {
+
+	const expected = "expected public key, nonce, seq number and path"
+
+	switch len(in) {
+	case 0, 1, 2, 3:
+		err = errors.New("missing arguments: " + expected)
+	case 4:
+		if rp.RootSelector, err = c.argsRoot(in[:3]); err != nil {
+			return
+		}
+		rp.Path = in[3]
+	default:
+		err = errors.New("too many arguments: " + expected)
+	}
+
+	return
+
+}
+
+func (c *client) argsRootJSON(in []string) (rj node.RootJSON, err error) {
+
+	const expected = "expected public key, nonce, seq number " +
+		"and optional depth and path"
+
+	rj.Depth = -1 // expand all by default
+
+	switch len(in) {
+	case 0, 1, 2:
+		err = errors.New("missing arguments: " + expected)
+	case 3, 4, 5:
+		if rj.RootSelector, err = c.argsRoot(in[:3]); err != nil {
+			return
+		}
+		if len(in) > 3 {
+			if rj.Depth, err = strconv.Atoi(in[3]); err != nil {
+				return
+			}
+		}
+		if len(in) > 4 {
+			rj.Path = in[4]
+		}
+	default:
+		err = errors.New("too many arguments: " + expected)
+	}
+
+	return
+
+}
+
+func (c *client) argsRootPublish(in []string) (rp node.RootPublish,
+	err error) {
+
+	const expected = "expected path to secret key file and path to JSON file"
+
+	switch len(in) {
+	case 0, 1:
+		err = errors.New("missing arguments: " + expected)
+	case 2:
+		if rp.SecKey, err = readSecKeyFile(in[0]); err != nil {
+			return
+		}
+		rp.JSON, err = ioutil.ReadFile(in[1])
+	default:
+		err = errors.New("too many arguments: " + expected)
+	}
+
+	return
+
+}
+
+// read hex-encoded secret key from given file, the
+// key is not passed by arguments to keep it out of
+// history and list of processes
+func readSecKeyFile(name string) (sk cipher.SecKey, err error) {
+
+	var p []byte
+	if p, err = ioutil.ReadFile(name); err != nil {
+		return
+	}
+
+	if sk, err = cipher.SecKeyFromHex(strings.TrimSpace(string(p))); err != nil {
+		return sk, fmt.Errorf("invalid secret key file %q: %v", name, err)
+	}
+
+	return
+}
+
 func (c *client) argsNo(in []string) (err error) {
 	if len(in) != 0 {
 		err = errors.New("unexpected arguments, expected nothing")
@@ -701,6 +821,48 @@ func (c *client) rootTree(in []string) (err error) {
 	return
 }
 
+func (c *client) rootGet(in []string) (err error) {
+	var rp node.RootPath
+	if rp, err = c.argsRootPath(in); err != nil {
+		return
+	}
+	var rv *node.RootValue
+	rv, err = c.r.Root().Get(rp.Feed, rp.Nonce, rp.Seq, rp.Path)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(out, "  schema:", rv.Schema)
+	fmt.Fprintln(out, " ", rv.Tree)
+	return
+}
+
+func (c *client) rootJSON(in []string) (err error) {
+	var rj node.RootJSON
+	if rj, err = c.argsRootJSON(in); err != nil {
+		return
+	}
+	var js []byte
+	js, err = c.r.Root().JSON(rj.Feed, rj.Nonce, rj.Seq, rj.Path, rj.Depth)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(out, string(js))
+	return
+}
+
+func (c *client) rootPublish(in []string) (err error) {
+	var rp node.RootPublish
+	if rp, err = c.argsRootPublish(in); err != nil {
+		return
+	}
+	var z *registry.Root
+	if z, err = c.r.Root().Publish(rp.SecKey, rp.JSON); err != nil {
+		return
+	}
+	c.printRoot(z)
+	return
+}
+
 func (c *client) lastRoot(in []string) (err error) {
 	var pk cipher.PubKey
 	if pk, err = c.argsFeed(in); err != nil {
@@ -714,6 +876,146 @@ func (c *client) lastRoot(in []string) (err error) {
 	return
 }
 
+func (c *client) rootPin(in []string) (err error) {
+	var sl node.RootSelector
+	if sl, err = c.argsRoot(in); err != nil {
+		return
+	}
+	return c.r.Root().Pin(sl.Feed, sl.Nonce, sl.Seq)
+}
+
+func (c *client) rootUnpin(in []string) (err error) {
+	var sl node.RootSelector
+	if sl, err = c.argsRoot(in); err != nil {
+		return
+	}
+	return c.r.Root().Unpin(sl.Feed, sl.Nonce, sl.Seq)
+}
+
+func (c *client) rootPins(in []string) (err error) {
+	var pk cipher.PubKey
+	if pk, err = c.argsFeed(in); err != nil {
+		return
+	}
+	var pins []skyobject.Pinned
+	if pins, err = c.r.Root().Pins(pk); err != nil {
+		return
+	}
+	if len(pins) == 0 {
+		fmt.Fprintln(out, "  no pinned Root objects")
+		return
+	}
+	for _, p := range pins {
+		fmt.Fprintf(out, "  - nonce: %d, seq: %d\n", p.Nonce, p.Seq)
+	}
+	return
+}
+
+//
+// quotas
+//
+
+func (c *client) argsQuota(in []string) (fq node.FeedQuota, err error) {
+
+	const expected = "expected public key, max Root objects, " +
+		"max objects and max volume"
+
+	switch len(in) {
+	case 0, 1, 2, 3:
+		err = errors.New("missing arguments: " + expected)
+	case 4:
+		if fq.Feed, err = pubKeyFromHex(in[0]); err != nil {
+			return
+		}
+		if fq.Quota.Roots, err = strconv.ParseUint(in[1], 10, 64); err != nil {
+			return
+		}
+		if fq.Quota.Objects, err = strconv.ParseUint(in[2], 10, 64); err != nil {
+			return
+		}
+		fq.Quota.Volume, err = strconv.ParseUint(in[3], 10, 64)
+	default:
+		err = errors.New("too many arguments: " + expected)
+	}
+
+	return
+
+}
+
+func (c *client) setQuota(in []string) (err error) {
+	var fq node.FeedQuota
+	if fq, err = c.argsQuota(in); err != nil {
+		return
+	}
+	return c.r.Node().SetQuota(fq.Feed, fq.Quota)
+}
+
+// zero is "no limit"
+func limit(u uint64) string {
+	if u == 0 {
+		return "no limit"
+	}
+	return strconv.FormatUint(u, 10)
+}
+
+func printUsage(u skyobject.Usage, q skyobject.Quota) {
+	fmt.Fprintln(out, "    Root objects:", u.Roots, "of", limit(q.Roots))
+	fmt.Fprintln(out, "    objects:     ", u.Objects, "of", limit(q.Objects))
+	fmt.Fprintln(out, "    volume:      ", u.Volume, "of", limit(q.Volume))
+}
+
+func (c *client) quota(in []string) (err error) {
+	var pk cipher.PubKey
+	if pk, err = c.argsFeed(in); err != nil {
+		return
+	}
+	var q skyobject.Quota
+	if q, err = c.r.Node().Quota(pk); err != nil {
+		return
+	}
+	var u skyobject.Usage
+	if u, err = c.r.Node().Usage(pk); err != nil {
+		return
+	}
+	printUsage(u, q)
+	return
+}
+
+//
+// backup
+//
+
+func (c *client) backup(in []string) (err error) {
+	switch len(in) {
+	case 0:
+		return errors.New("missing path to backup file")
+	case 1:
+	default:
+		return errTooManyArguments
+	}
+	if err = c.r.Node().Backup(in[0]); err != nil {
+		return
+	}
+	fmt.Fprintln(out, "  saved to", in[0])
+	return
+}
+
+func (c *client) restore(in []string) (err error) {
+	const expected = "expected backup, CXDS and IdxDB paths"
+	switch len(in) {
+	case 0, 1, 2:
+		return errors.New("missing arguments: " + expected)
+	case 3:
+	default:
+		return errors.New("too many arguments: " + expected)
+	}
+	if err = c.r.Node().Restore(in[0], in[1], in[2]); err != nil {
+		return
+	}
+	fmt.Fprintln(out, "  restored, restart the node with the files to use them")
+	return
+}
+
 //
 // stat
 //
@@ -772,6 +1074,8 @@ func (c *client) stat(in []string) (err error) {
 	for pk, fs := range s.Feeds {
 		fmt.Fprintln(out, " ", pk.Hex())
 
+		printUsage(fs.Usage, fs.Quota)
+
 		if len(fs.Heads) == 0 {
 			fmt.Fprintln(out, "    no heads")
 			continue
@@ -810,6 +1114,18 @@ func (c *client) help(in []string) (err error) {
   list feeds
     show all feeds the node share
 
+  set quota <public key> <roots> <objects> <volume>
+    set limits of given feed, use zero for no limit
+  quota <public key>
+    show limits and usage of given feed
+
+  backup <path>
+    save backup of DB of the node to given file
+    on host of the node; the node keeps running
+  restore <backup> <cxds path> <idxdb path>
+    restore given backup to given DB files on host
+    of the node, the files must not exist
+
   tcp connect <address>
     connect to tcp address
   tcp disconnect <connection address>
@@ -845,9 +1161,37 @@ func (c *client) help(in []string) (err error) {
   root tree <public key> <nonce> <seq>
     print tree of selected Root
 
+  root get <public key> <nonce> <seq> <path>
+    print value of selected Root by path, for example
+    Refs[0].Members[3].Name or Refs[0].Posts[-1]
+
+  root json <public key> <nonce> <seq> [depth] [path]
+    print selected Root or its value by path as JSON,
+    the depth is depth of references to expand, by
+    default it's -1 that means expand all, use 0 to
+    print references as hashes
+
+  root publish <path to secret key file> <path to JSON file>
+    create Root from JSON and publish it, the JSON
+    is like output of the 'root json' command, the
+    Nonce of the JSON is head of the Root (random if
+    it's zero), the Reg is RegistryRef (Registry of
+    last Root of the head, if missing), the Refs are
+    Dynamic references with objects by hash or inline;
+    the key file contains hex-encoded secret key of
+    the feed, the key is sent to the node, thus use
+    the command with local node only
+
   last root <public key>
     show info about last Root of given feed
 
+  root pin <public key> <nonce> <seq>
+    protect selected Root from removing
+  root unpin <public key> <nonce> <seq>
+    unpin selected Root
+  root pins <public key>
+    list pinned Root objects of given feed
+
 
   stat
     show statistic of node
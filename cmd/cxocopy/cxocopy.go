@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/crypt"
+	"github.com/skycoin/cxo/data/cxds"
+	"github.com/skycoin/cxo/data/idxdb"
+	"github.com/skycoin/cxo/skyobject"
+)
+
+func main() {
+
+	var (
+		src, dst       string
+		srcKey, dstKey string
+		compression    bool
+		srcDB, dstDB   *data.DB
+		err            error
+	)
+
+	flag.StringVar(&src,
+		"src",
+		"",
+		"path to source DB without extensions")
+	flag.StringVar(&dst,
+		"dst",
+		"",
+		"path to destination DB without extensions, the DB must not exist")
+	flag.StringVar(&srcKey,
+		"src-key-file",
+		"",
+		"path to file with key of encrypted source DB")
+	flag.StringVar(&dstKey,
+		"dst-key-file",
+		"",
+		"path to file with key to encrypt destination DB")
+	flag.BoolVar(&compression,
+		"compression",
+		false,
+		"compress objects of not encrypted destination DB")
+
+	flag.Parse()
+
+	log.SetFlags(0)
+
+	if src == "" || dst == "" {
+		log.Fatal("missing DB path, use -src and -dst")
+	}
+
+	for _, name := range []string{src + ".cxds", src + ".idx"} {
+		if _, err = os.Stat(name); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{dst + ".cxds", dst + ".idx"} {
+		if _, err = os.Stat(name); err == nil {
+			log.Fatalf("destination %s already exists", name)
+		}
+	}
+
+	if srcDB, err = openDB(src, srcKey, -1); err != nil {
+		log.Fatal(err)
+	}
+	defer srcDB.Close()
+
+	var minSize = -1 // no compression
+	if compression == true && dstKey == "" {
+		minSize = skyobject.CompressMinSize
+	}
+
+	if dstDB, err = openDB(dst, dstKey, minSize); err != nil {
+		log.Fatal(err)
+	}
+
+	if err = data.Copy(dstDB, srcDB); err != nil {
+		dstDB.Close()
+		os.Remove(dst + ".cxds") // clean up
+		os.Remove(dst + ".idx")
+		log.Fatal(err)
+	}
+
+	if err = dstDB.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+}
+
+// open boltdb based DB by given path without extensions,
+// the DB is encrypted if given key file is not empty
+func openDB(path, keyFile string, minSize int) (db *data.DB, err error) {
+
+	var (
+		cx  data.CXDS
+		idx data.IdxDB
+	)
+
+	if cx, err = cxds.NewDriveCXDSCompressed(path+".cxds", minSize); err != nil {
+		return
+	}
+
+	if idx, err = idxdb.NewDriveIdxDB(path + ".idx"); err != nil {
+		cx.Close()
+		return
+	}
+
+	db = data.NewDB(cx, idx)
+
+	if keyFile == "" {
+		return
+	}
+
+	var key crypt.Key
+	if key, err = crypt.LoadKeyFile(keyFile); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	var edb *data.DB
+	if edb, err = crypt.NewDB(db, key); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return edb, nil
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/skycoin/net/skycoin-messenger/factory"
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// defaults
+const (
+	Address  string        = ":8008"           // listening address
+	DBFile   string        = "cxodiscovery.db" // DB file
+	TTL      time.Duration = 10 * time.Minute  // stale nodes TTL
+	Interval time.Duration = time.Minute       // expiration interval
+	Rate     float64       = 5                 // requests per second
+	Burst    int           = 20                // requests at once
+)
+
+// A Config represents configurations of the discovery server
+type Config struct {
+	Address string // listening address
+	DBFile  string // path to DB file
+	SecKey  string // hex-encoded secret key, random if empty
+	Stats   string // address of HTTP stats server, disabled if empty
+
+	TTL      time.Duration // stale nodes TTL
+	Interval time.Duration // expiration interval
+
+	Rate  float64 // requests per second per node, zero to disable
+	Burst int     // max requests at once per node
+
+	Debug bool // show debug logs
+}
+
+// NewConfig returns Config with defaults
+func NewConfig() (c *Config) {
+	c = new(Config)
+	c.Address = Address
+	c.DBFile = DBFile
+	c.TTL = TTL
+	c.Interval = Interval
+	c.Rate = Rate
+	c.Burst = Burst
+	return
+}
+
+// FromFlags obtains values from command-line flags.
+// Call this method before flag.Parse
+func (c *Config) FromFlags() {
+	flag.StringVar(&c.Address,
+		"a",
+		c.Address,
+		"listening address")
+	flag.StringVar(&c.DBFile,
+		"db",
+		c.DBFile,
+		"path to DB file")
+	flag.StringVar(&c.SecKey,
+		"sk",
+		c.SecKey,
+		"hex-encoded secret key of the server, random if empty")
+	flag.StringVar(&c.Stats,
+		"stats",
+		c.Stats,
+		"listening address of HTTP stats, e.g. 127.0.0.1:8009")
+	flag.DurationVar(&c.TTL,
+		"ttl",
+		c.TTL,
+		"remove disconnected nodes not updated for this time")
+	flag.DurationVar(&c.Interval,
+		"interval",
+		c.Interval,
+		"interval of expiration of stale nodes")
+	flag.Float64Var(&c.Rate,
+		"rate",
+		c.Rate,
+		"requests per second per node, zero to disable limits")
+	flag.IntVar(&c.Burst,
+		"burst",
+		c.Burst,
+		"max requests at once per node")
+	flag.BoolVar(&c.Debug,
+		"debug",
+		c.Debug,
+		"show debug logs")
+}
+
+// Validate the Config
+func (c *Config) Validate() (err error) {
+
+	switch {
+	case c.TTL <= 0:
+		err = errors.New("non-positive TTL")
+	case c.Interval <= 0:
+		err = errors.New("non-positive expiration interval")
+	case c.Rate < 0:
+		err = errors.New("negative rate")
+	case c.Rate > 0 && c.Burst < 1:
+		err = errors.New("burst less than 1")
+	}
+
+	return
+}
+
+func main() {
+
+	var c = NewConfig()
+
+	c.FromFlags()
+	flag.Parse()
+
+	var (
+		d   *discoveryServer
+		err error
+	)
+
+	if err = c.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	if d, err = newDiscoveryServer(c); err != nil {
+		log.Fatal(err)
+	}
+	defer d.Close()
+
+	log.Printf("listen on %s, DB %s", c.Address, c.DBFile)
+
+	waitInterrupt() // wait for SIGINT
+}
+
+func waitInterrupt() {
+	var sig = make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	<-sig
+}
+
+// A discoveryServer is MessengerFactory
+// that keeps its data in store
+type discoveryServer struct {
+	conf *Config
+
+	m *factory.MessengerFactory
+	s *store
+	l *limiter
+	c counters
+
+	h *http.Server // stats or nil
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+func newDiscoveryServer(c *Config) (d *discoveryServer, err error) {
+
+	if err = c.Validate(); err != nil {
+		return
+	}
+
+	d = new(discoveryServer)
+	d.conf = c
+	d.l = newLimiter(c.Rate, c.Burst)
+	d.c.start = time.Now()
+	d.quit = make(chan struct{})
+	d.done = make(chan struct{})
+
+	var sc *factory.SeedConfig
+
+	if c.SecKey == "" {
+		sc, err = factory.NewSeedConfig() // random seed every start
+	} else {
+		var sk cipher.SecKey
+		if sk, err = cipher.SecKeyFromHex(c.SecKey); err != nil {
+			return
+		}
+		sc, err = factory.SecKeyToSeedConfig(sk)
+	}
+
+	if err != nil {
+		return
+	}
+
+	if d.s, err = newStore(c.DBFile); err != nil {
+		return
+	}
+
+	d.m = factory.NewMessengerFactory()
+
+	if c.Debug == true {
+		d.m.SetLoggerLevel(factory.DebugLevel)
+	} else {
+		d.m.SetLoggerLevel(factory.ErrorLevel)
+	}
+
+	if err = d.m.SetDefaultSeedConfig(sc); err != nil {
+		d.s.Close()
+		return
+	}
+
+	d.m.RegisterService = d.registerService
+	d.m.UnRegisterService = d.unregisterService
+	d.m.FindByAttributes = d.findByAttributes
+	d.m.FindByAttributesAndPaging = d.findByAttributesAndPaging
+	d.m.FindServiceAddresses = d.findServiceAddresses
+
+	// remove nodes left after previous run, if they are stale
+	d.expire()
+
+	if err = d.m.Listen(c.Address); err != nil {
+		d.s.Close()
+		return
+	}
+
+	if c.Stats != "" {
+		d.h = &http.Server{Addr: c.Stats, Handler: d}
+		go d.serveStats()
+	}
+
+	go d.expireLoop()
+	return
+}
+
+func (d *discoveryServer) debugf(format string, args ...interface{}) {
+	if d.conf.Debug == true {
+		log.Printf("[DBG] "+format, args...)
+	}
+}
+
+func (d *discoveryServer) errorf(format string, args ...interface{}) {
+	d.c.add(&d.c.errors, 1)
+	log.Printf("[ERR] "+format, args...)
+}
+
+func (d *discoveryServer) serveStats() {
+	if err := d.h.ListenAndServe(); err != http.ErrServerClosed {
+		log.Print("[ERR] stats server: ", err)
+	}
+}
+
+// keep only nodes that are connected now
+// in the list of alive nodes, since nodes
+// can disconnect without unregistering
+func (d *discoveryServer) connected() {
+
+	var keys = make(map[cipher.PubKey]struct{})
+
+	d.m.ForEachConn(func(c *factory.Connection) {
+		keys[c.GetKey()] = struct{}{}
+	})
+
+	d.s.KeepAlive(keys)
+}
+
+func (d *discoveryServer) expire() {
+
+	d.connected()
+
+	var expired, err = d.s.Expire(d.conf.TTL)
+
+	if err != nil {
+		d.errorf("expiring stale nodes: %v", err)
+		return
+	}
+
+	if expired > 0 {
+		d.c.add(&d.c.expired, uint64(expired))
+		d.debugf("%d stale nodes removed", expired)
+	}
+
+}
+
+func (d *discoveryServer) expireLoop() {
+	defer close(d.done)
+
+	var tk = time.NewTicker(d.conf.Interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			d.expire()
+			d.l.Clean()
+		case <-d.quit:
+			return
+		}
+	}
+}
+
+//
+// callbacks of the MessengerFactory
+//
+
+func (d *discoveryServer) registerService(
+	pk cipher.PubKey,
+	ns *factory.NodeServices,
+) (
+	err error,
+) {
+
+	if d.l.Allow(pk) == false {
+		d.c.add(&d.c.rateLimited, 1)
+		d.debugf("rate limit exceeded: %s", pk.Hex())
+		return ErrRateLimit
+	}
+
+	if err = d.s.Register(pk, ns); err != nil {
+		d.errorf("registering %s: %v", pk.Hex(), err)
+		return
+	}
+
+	d.c.add(&d.c.registrations, 1)
+	d.debugf("register %s (%s), %d services", pk.Hex(), ns.ServiceAddress,
+		len(ns.Services))
+	return
+}
+
+func (d *discoveryServer) unregisterService(pk cipher.PubKey) (err error) {
+
+	if err = d.s.Unregister(pk); err != nil {
+		d.errorf("unregistering %s: %v", pk.Hex(), err)
+		return
+	}
+
+	d.c.add(&d.c.unregistrations, 1)
+	d.debugf("unregister %s", pk.Hex())
+	return
+}
+
+func (d *discoveryServer) findByAttributes(
+	attrs ...string,
+) *factory.AttrNodesInfo {
+
+	return d.findByAttributesAndPaging(0, 0, attrs...)
+}
+
+func (d *discoveryServer) findByAttributesAndPaging(
+	page, limit int,
+	attrs ...string,
+) (
+	result *factory.AttrNodesInfo,
+) {
+
+	d.c.add(&d.c.queries, 1)
+
+	var err error
+	if result, err = d.s.ByAttributes(page, limit, attrs...); err != nil {
+		d.errorf("finding by attributes: %v", err)
+	}
+
+	return
+}
+
+// the exclude is key of requesting node
+func (d *discoveryServer) findServiceAddresses(
+	keys []cipher.PubKey,
+	exclude cipher.PubKey,
+) (
+	result []*factory.ServiceInfo,
+) {
+
+	if d.l.Allow(exclude) == false {
+		d.c.add(&d.c.rateLimited, 1)
+		d.debugf("rate limit exceeded: %s", exclude.Hex())
+		return
+	}
+
+	d.c.add(&d.c.queries, 1)
+
+	var err error
+	if result, err = d.s.ServiceAddresses(keys, exclude); err != nil {
+		d.errorf("finding service addresses: %v", err)
+	}
+
+	return
+}
+
+// Close the discovery server
+func (d *discoveryServer) Close() (err error) {
+
+	close(d.quit)
+	<-d.done
+
+	if d.h != nil {
+		d.h.Close()
+	}
+
+	if err = d.m.Close(); err != nil {
+		log.Print("[ERR] closing MessengerFactory: ", err)
+	}
+
+	return d.s.Close()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestConfig_Validate(t *testing.T) {
+
+	if err := NewConfig().Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, change := range []func(c *Config){
+		func(c *Config) { c.TTL = 0 },
+		func(c *Config) { c.Interval = 0 },
+		func(c *Config) { c.Interval = -1 },
+		func(c *Config) { c.Rate = -1 },
+		func(c *Config) { c.Burst = 0 },
+	} {
+		var c = NewConfig()
+		change(c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("missing error for %#v", c)
+		}
+	}
+
+	// burst is not used if limits disabled
+	var c = NewConfig()
+	c.Rate, c.Burst = 0, 0
+	if err := c.Validate(); err != nil {
+		t.Error(err)
+	}
+
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// ErrRateLimit occurs if a node performs
+// too many requests
+var ErrRateLimit = errors.New("rate limit exceeded")
+
+// a token bucket
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// A limiter limits number of requests per second
+// of every node using token bucket algorithm
+type limiter struct {
+	rate  float64 // tokens per second
+	burst float64 // max tokens
+
+	mx sync.Mutex
+	bs map[cipher.PubKey]*bucket
+}
+
+// newLimiter creates limiter. If given rate is zero,
+// then the limiter allows everything
+func newLimiter(rate float64, burst int) (l *limiter) {
+	l = new(limiter)
+	l.rate = rate
+	l.burst = float64(burst)
+	if l.burst < 1 {
+		l.burst = 1
+	}
+	l.bs = make(map[cipher.PubKey]*bucket)
+	return
+}
+
+// Allow reports true if node with given key can perform a request
+func (l *limiter) Allow(pk cipher.PubKey) bool {
+
+	if l.rate <= 0 {
+		return true
+	}
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	var (
+		now   = time.Now()
+		b, ok = l.bs[pk]
+	)
+
+	if ok == false {
+		b = &bucket{tokens: l.burst, last: now}
+		l.bs[pk] = b
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * l.rate
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// Clean removes buckets that are full already
+func (l *limiter) Clean() {
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	var now = time.Now()
+
+	for pk, b := range l.bs {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.bs, pk)
+		}
+	}
+
+}
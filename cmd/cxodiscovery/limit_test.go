@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+
+	var (
+		l     = newLimiter(10, 3)
+		pk, _ = cipher.GenerateKeyPair()
+		ok, _ = cipher.GenerateKeyPair()
+	)
+
+	for i := 0; i < 3; i++ {
+		if l.Allow(pk) == false {
+			t.Fatal("burst is not allowed:", i)
+		}
+	}
+
+	if l.Allow(pk) == true {
+		t.Error("allowed over burst")
+	}
+
+	// other node has its own bucket
+	if l.Allow(ok) == false {
+		t.Error("other node is not allowed")
+	}
+
+	time.Sleep(150 * time.Millisecond) // 1.5 tokens
+
+	if l.Allow(pk) == false {
+		t.Error("not allowed after refill")
+	}
+
+	if l.Allow(pk) == true {
+		t.Error("allowed over refilled tokens")
+	}
+
+}
+
+func TestLimiter_disabled(t *testing.T) {
+
+	var (
+		l     = newLimiter(0, 1)
+		pk, _ = cipher.GenerateKeyPair()
+	)
+
+	for i := 0; i < 100; i++ {
+		if l.Allow(pk) == false {
+			t.Fatal("disabled limiter doesn't allow")
+		}
+	}
+
+	if len(l.bs) != 0 {
+		t.Error("disabled limiter keeps buckets")
+	}
+
+}
+
+func TestLimiter_Clean(t *testing.T) {
+
+	var (
+		l     = newLimiter(100, 2)
+		pk, _ = cipher.GenerateKeyPair()
+	)
+
+	l.Allow(pk)
+	l.Allow(pk)
+
+	l.Clean()
+
+	if len(l.bs) != 1 {
+		t.Fatal("empty bucket removed")
+	}
+
+	time.Sleep(50 * time.Millisecond) // full
+
+	l.Clean()
+
+	if len(l.bs) != 0 {
+		t.Error("full bucket is not removed")
+	}
+
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Stats of the discovery server
+type Stats struct {
+	Uptime string `json:"uptime"`
+
+	Nodes    int `json:"nodes"`    // registered nodes
+	Services int `json:"services"` // known services (feeds)
+
+	Registrations   uint64 `json:"registrations"`
+	Unregistrations uint64 `json:"unregistrations"`
+	Queries         uint64 `json:"queries"`
+	Expired         uint64 `json:"expired"`
+	RateLimited     uint64 `json:"rate_limited"`
+	Errors          uint64 `json:"errors"`
+}
+
+// counters of the discovery server
+type counters struct {
+	start time.Time
+
+	registrations   uint64
+	unregistrations uint64
+	queries         uint64
+	expired         uint64
+	rateLimited     uint64
+	errors          uint64
+}
+
+func (c *counters) add(counter *uint64, n uint64) {
+	atomic.AddUint64(counter, n)
+}
+
+// Stats returns statistic of the discovery server
+func (d *discoveryServer) Stats() (s *Stats, err error) {
+
+	s = new(Stats)
+
+	if s.Nodes, s.Services, err = d.s.Counts(); err != nil {
+		return
+	}
+
+	var c = &d.c
+
+	s.Uptime = time.Since(c.start).Truncate(time.Second).String()
+
+	s.Registrations = atomic.LoadUint64(&c.registrations)
+	s.Unregistrations = atomic.LoadUint64(&c.unregistrations)
+	s.Queries = atomic.LoadUint64(&c.queries)
+	s.Expired = atomic.LoadUint64(&c.expired)
+	s.RateLimited = atomic.LoadUint64(&c.rateLimited)
+	s.Errors = atomic.LoadUint64(&c.errors)
+
+	return
+}
+
+// ServeHTTP implements http.Handler interface
+// and sends the Stats as JSON object
+func (d *discoveryServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+
+	var s, err = d.Stats()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var enc = json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(s)
+}
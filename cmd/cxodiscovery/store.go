@@ -0,0 +1,548 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/skycoin/net/skycoin-messenger/factory"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+var (
+	nodesBucket    = []byte("n") // node key -> encoded node
+	servicesBucket = []byte("s") // service key -> bucket of node keys
+)
+
+// ErrNotFound occurs if requested node not found
+var ErrNotFound = errors.New("not found")
+
+// a service of a node
+type service struct {
+	Key               cipher.PubKey
+	Attributes        []string
+	Address           string
+	HideFromDiscovery bool
+	AllowNodes        []string
+	Version           string
+}
+
+// a registered node
+type node struct {
+	Address  string // service address
+	Location string
+	Version  []string
+	Services []service
+	Created  int64 // unix nano
+	Updated  int64 // unix nano
+}
+
+// allowed reports true if the service can
+// be shown to node with given key
+func (s *service) allowed(pk cipher.PubKey) bool {
+
+	if s.HideFromDiscovery == true {
+		return false
+	}
+
+	if len(s.AllowNodes) == 0 {
+		return true
+	}
+
+	var hex = pk.Hex()
+
+	for _, an := range s.AllowNodes {
+		if an == hex {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A store keeps registered nodes and their
+// services in a bolt DB. The store keeps
+// list of nodes connected to the discovery
+// server (alive) to remove stale nodes that
+// are not connected, but not unregistered
+type store struct {
+	b *bolt.DB
+
+	mx    sync.Mutex
+	alive map[cipher.PubKey]struct{} // registered and connected
+}
+
+// newStore opens or creates DB file
+func newStore(fileName string) (s *store, err error) {
+
+	var b *bolt.DB
+
+	b, err = bolt.Open(fileName, 0644, &bolt.Options{
+		Timeout: time.Millisecond * 500,
+	})
+
+	if err != nil {
+		return
+	}
+
+	err = b.Update(func(tx *bolt.Tx) (err error) {
+		if _, err = tx.CreateBucketIfNotExists(nodesBucket); err != nil {
+			return
+		}
+		_, err = tx.CreateBucketIfNotExists(servicesBucket)
+		return
+	})
+
+	if err != nil {
+		b.Close()
+		return
+	}
+
+	s = new(store)
+	s.b = b
+	s.alive = make(map[cipher.PubKey]struct{})
+	return
+}
+
+func (s *store) setAlive(pk cipher.PubKey, alive bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if alive == true {
+		s.alive[pk] = struct{}{}
+		return
+	}
+	delete(s.alive, pk)
+}
+
+// KeepAlive removes nodes that are not in
+// given set from the list of alive nodes
+func (s *store) KeepAlive(connected map[cipher.PubKey]struct{}) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	for pk := range s.alive {
+		if _, ok := connected[pk]; ok == false {
+			delete(s.alive, pk)
+		}
+	}
+}
+
+func (s *store) isAlive(pk cipher.PubKey) (ok bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	_, ok = s.alive[pk]
+	return
+}
+
+func pubKey(k []byte) (pk cipher.PubKey) {
+	copy(pk[:], k)
+	return
+}
+
+func getNode(nb *bolt.Bucket, pk cipher.PubKey) (n *node, err error) {
+
+	var val = nb.Get(pk[:])
+
+	if val == nil {
+		return nil, ErrNotFound
+	}
+
+	n = new(node)
+	_, err = encoder.DeserializeRaw(val, n)
+	return
+}
+
+// remove services of given node from the services bucket
+func delServices(sb *bolt.Bucket, pk cipher.PubKey, n *node) (err error) {
+
+	for _, svc := range n.Services {
+
+		var nks = sb.Bucket(svc.Key[:])
+
+		if nks == nil {
+			continue
+		}
+
+		if err = nks.Delete(pk[:]); err != nil {
+			return
+		}
+
+		if k, _ := nks.Cursor().First(); k == nil {
+			if err = sb.DeleteBucket(svc.Key[:]); err != nil {
+				return
+			}
+		}
+
+	}
+
+	return
+}
+
+// delNode removes node with given key
+func delNode(tx *bolt.Tx, pk cipher.PubKey) (err error) {
+
+	var (
+		nb = tx.Bucket(nodesBucket)
+		n  *node
+	)
+
+	if n, err = getNode(nb, pk); err != nil {
+		if err == ErrNotFound {
+			err = nil
+		}
+		return
+	}
+
+	if err = delServices(tx.Bucket(servicesBucket), pk, n); err != nil {
+		return
+	}
+
+	return nb.Delete(pk[:])
+}
+
+// Register node with given key and services
+func (s *store) Register(pk cipher.PubKey, ns *factory.NodeServices) error {
+
+	var now = time.Now().UnixNano()
+
+	err := s.b.Update(func(tx *bolt.Tx) (err error) {
+
+		var (
+			nb = tx.Bucket(nodesBucket)
+			sb = tx.Bucket(servicesBucket)
+
+			n   *node
+			nks *bolt.Bucket
+		)
+
+		switch n, err = getNode(nb, pk); err {
+		case nil:
+			if err = delServices(sb, pk, n); err != nil {
+				return
+			}
+		case ErrNotFound:
+			n = &node{Created: now}
+		default:
+			return
+		}
+
+		n.Address = ns.ServiceAddress
+		n.Location = ns.Location
+		n.Version = ns.Version
+		n.Updated = now
+		n.Services = n.Services[:0]
+
+		for _, svc := range ns.Services {
+
+			if svc == nil {
+				continue
+			}
+
+			n.Services = append(n.Services, service{
+				Key:               svc.Key,
+				Attributes:        svc.Attributes,
+				Address:           svc.Address,
+				HideFromDiscovery: svc.HideFromDiscovery,
+				AllowNodes:        svc.AllowNodes,
+				Version:           svc.Version,
+			})
+
+			nks, err = sb.CreateBucketIfNotExists(svc.Key[:])
+			if err != nil {
+				return
+			}
+
+			if err = nks.Put(pk[:], []byte{}); err != nil {
+				return
+			}
+
+		}
+
+		return nb.Put(pk[:], encoder.Serialize(n))
+	})
+
+	if err == nil {
+		s.setAlive(pk, true)
+	}
+
+	return err
+}
+
+// Unregister node with given key
+func (s *store) Unregister(pk cipher.PubKey) (err error) {
+
+	s.setAlive(pk, false)
+
+	return s.b.Update(func(tx *bolt.Tx) error {
+		return delNode(tx, pk)
+	})
+}
+
+// ServiceAddresses finds nodes that provide services with given
+// keys excluding node with the exclude key
+func (s *store) ServiceAddresses(
+	keys []cipher.PubKey,
+	exclude cipher.PubKey,
+) (
+	result []*factory.ServiceInfo,
+	err error,
+) {
+
+	result = make([]*factory.ServiceInfo, 0, len(keys))
+
+	err = s.b.View(func(tx *bolt.Tx) (err error) {
+
+		var (
+			nb = tx.Bucket(nodesBucket)
+			sb = tx.Bucket(servicesBucket)
+		)
+
+		for _, sk := range keys {
+
+			var nks = sb.Bucket(sk[:])
+
+			if nks == nil {
+				continue
+			}
+
+			var si = &factory.ServiceInfo{PubKey: sk}
+
+			err = nks.ForEach(func(k, _ []byte) (err error) {
+
+				var (
+					pk = pubKey(k)
+					n  *node
+				)
+
+				if pk == exclude {
+					return
+				}
+
+				if n, err = getNode(nb, pk); err != nil {
+					if err == ErrNotFound {
+						err = nil // inconsistent index, skip
+					}
+					return
+				}
+
+				for i := range n.Services {
+					var svc = &n.Services[i]
+					if svc.Key != sk || svc.allowed(exclude) == false {
+						continue
+					}
+					si.Nodes = append(si.Nodes, &factory.NodeInfo{
+						PubKey:  pk,
+						Address: n.Address,
+					})
+					break
+				}
+
+				return
+			})
+
+			if err != nil {
+				return
+			}
+
+			if len(si.Nodes) > 0 {
+				result = append(result, si)
+			}
+
+		}
+
+		return
+	})
+
+	return
+}
+
+// ByAttributes finds nodes that provide services with any of given
+// attributes. The page starts from 1. If the limit is zero, then all
+// nodes returned. The result is ordered by node key
+func (s *store) ByAttributes(
+	page, limit int,
+	attrs ...string,
+) (
+	result *factory.AttrNodesInfo,
+	err error,
+) {
+
+	var want = make(map[string]struct{}, len(attrs))
+
+	for _, attr := range attrs {
+		want[attr] = struct{}{}
+	}
+
+	var all []*factory.AttrNodeInfo
+
+	err = s.b.View(func(tx *bolt.Tx) error {
+
+		// bolt iterates keys in byte-sorted order
+		return tx.Bucket(nodesBucket).ForEach(func(k, v []byte) (err error) {
+
+			var n node
+
+			if _, err = encoder.DeserializeRaw(v, &n); err != nil {
+				return
+			}
+
+			var ani *factory.AttrNodeInfo
+
+			for i := range n.Services {
+
+				var svc = &n.Services[i]
+
+				if svc.HideFromDiscovery == true ||
+					hasAttribute(svc.Attributes, want) == false {
+
+					continue
+				}
+
+				if ani == nil {
+					ani = &factory.AttrNodeInfo{
+						Node:     pubKey(k),
+						Location: n.Location,
+						Version:  n.Version,
+					}
+				}
+
+				ani.Apps = append(ani.Apps, svc.Key)
+				ani.AppInfos = append(ani.AppInfos, &factory.AttrAppInfo{
+					Key:     svc.Key,
+					Version: svc.Version,
+				})
+
+			}
+
+			if ani != nil {
+				all = append(all, ani)
+			}
+
+			return
+		})
+
+	})
+
+	if err != nil {
+		return
+	}
+
+	result = &factory.AttrNodesInfo{Count: int64(len(all))}
+
+	if limit <= 0 {
+		result.Nodes = all
+		return
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	var from, to = (page - 1) * limit, page * limit
+
+	if from > len(all) {
+		from = len(all)
+	}
+
+	if to > len(all) {
+		to = len(all)
+	}
+
+	result.Nodes = all[from:to]
+	return
+}
+
+func hasAttribute(attrs []string, want map[string]struct{}) bool {
+	for _, attr := range attrs {
+		if _, ok := want[attr]; ok == true {
+			return true
+		}
+	}
+	return false
+}
+
+// Expire removes nodes that are not connected to the
+// discovery server and not updated for given ttl. It
+// also refreshes update time of connected nodes. It
+// returns number of removed nodes
+func (s *store) Expire(ttl time.Duration) (expired int, err error) {
+
+	var (
+		now  = time.Now()
+		dead = now.Add(-ttl).UnixNano()
+	)
+
+	err = s.b.Update(func(tx *bolt.Tx) (err error) {
+
+		var (
+			nb = tx.Bucket(nodesBucket)
+
+			stale []cipher.PubKey
+			fresh = make(map[cipher.PubKey]*node)
+		)
+
+		err = nb.ForEach(func(k, v []byte) (err error) {
+
+			var (
+				pk = pubKey(k)
+				n  = new(node)
+			)
+
+			if _, err = encoder.DeserializeRaw(v, n); err != nil {
+				return
+			}
+
+			if s.isAlive(pk) == true {
+				fresh[pk] = n
+			} else if n.Updated < dead {
+				stale = append(stale, pk)
+			}
+
+			return
+		})
+
+		if err != nil {
+			return
+		}
+
+		// can't modify the bucket inside ForEach
+
+		for pk, n := range fresh {
+			n.Updated = now.UnixNano()
+			if err = nb.Put(pk[:], encoder.Serialize(n)); err != nil {
+				return
+			}
+		}
+
+		for _, pk := range stale {
+			if err = delNode(tx, pk); err != nil {
+				return
+			}
+		}
+
+		expired = len(stale)
+		return
+	})
+
+	return
+}
+
+// Counts returns number of registered nodes and services
+func (s *store) Counts() (nodes, services int, err error) {
+	err = s.b.View(func(tx *bolt.Tx) (_ error) {
+		nodes = tx.Bucket(nodesBucket).Stats().KeyN
+		services = tx.Bucket(servicesBucket).Stats().BucketN - 1
+		return
+	})
+	return
+}
+
+// Close the store
+func (s *store) Close() error {
+	return s.b.Close()
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skycoin/net/skycoin-messenger/factory"
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func getTestStore(t *testing.T) (s *store, clean func()) {
+
+	var dir, err = ioutil.TempDir("", "cxodiscovery")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s, err = newStore(filepath.Join(dir, "test.db")); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return s, func() {
+		s.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func getTestNodeServices(
+	address string,
+	svcs ...*factory.Service,
+) *factory.NodeServices {
+
+	return &factory.NodeServices{
+		ServiceAddress: address,
+		Location:       "here",
+		Version:        []string{"1"},
+		Services:       svcs,
+	}
+}
+
+func getTestService(attrs ...string) *factory.Service {
+	var pk, _ = cipher.GenerateKeyPair()
+	return &factory.Service{Key: pk, Attributes: attrs}
+}
+
+func TestStore_Register(t *testing.T) {
+
+	var s, clean = getTestStore(t)
+	defer clean()
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+		svc   = getTestService("cxo")
+	)
+
+	if err := s.Register(pk, getTestNodeServices("1.2.3.4:5", svc)); err != nil {
+		t.Fatal(err)
+	}
+
+	if nodes, services, err := s.Counts(); err != nil {
+		t.Fatal(err)
+	} else if nodes != 1 || services != 1 {
+		t.Errorf("wrong counts %d, %d", nodes, services)
+	}
+
+	if s.isAlive(pk) == false {
+		t.Error("registered node is not alive")
+	}
+
+	// update removes old services
+
+	var other = getTestService("cxo")
+
+	if err := s.Register(pk, getTestNodeServices("1.2.3.4:6", other)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, services, err := s.Counts(); err != nil {
+		t.Fatal(err)
+	} else if services != 1 {
+		t.Error("wrong number of services:", services)
+	}
+
+	var requester, _ = cipher.GenerateKeyPair()
+
+	var si, err = s.ServiceAddresses(
+		[]cipher.PubKey{svc.Key, other.Key},
+		requester,
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(si) != 1 {
+		t.Fatal("wrong number of services found:", len(si))
+	}
+
+	if si[0].PubKey != other.Key {
+		t.Error("wrong service found")
+	} else if len(si[0].Nodes) != 1 {
+		t.Error("wrong number of nodes:", len(si[0].Nodes))
+	} else if si[0].Nodes[0].PubKey != pk ||
+		si[0].Nodes[0].Address != "1.2.3.4:6" {
+
+		t.Error("wrong node info:", si[0].Nodes[0].PubKey.Hex(),
+			si[0].Nodes[0].Address)
+	}
+
+	// the node doesn't see itself
+
+	if si, err = s.ServiceAddresses([]cipher.PubKey{other.Key}, pk); err != nil {
+		t.Fatal(err)
+	} else if len(si) != 0 {
+		t.Error("node found itself")
+	}
+
+}
+
+func TestStore_Unregister(t *testing.T) {
+
+	var s, clean = getTestStore(t)
+	defer clean()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	err := s.Register(pk, getTestNodeServices("1.2.3.4:5", getTestService()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.Unregister(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	if nodes, services, err := s.Counts(); err != nil {
+		t.Fatal(err)
+	} else if nodes != 0 || services != 0 {
+		t.Errorf("wrong counts %d, %d", nodes, services)
+	}
+
+	if s.isAlive(pk) == true {
+		t.Error("unregistered node is alive")
+	}
+
+	// not registered
+	if err = s.Unregister(pk); err != nil {
+		t.Error(err)
+	}
+
+}
+
+func TestStore_ServiceAddresses(t *testing.T) {
+
+	var s, clean = getTestStore(t)
+	defer clean()
+
+	var (
+		pk, _        = cipher.GenerateKeyPair()
+		requester, _ = cipher.GenerateKeyPair()
+
+		hidden  = getTestService()
+		allowed = getTestService()
+		denied  = getTestService()
+	)
+
+	hidden.HideFromDiscovery = true
+	allowed.AllowNodes = []string{requester.Hex()}
+	denied.AllowNodes = []string{pk.Hex()}
+
+	err := s.Register(pk, getTestNodeServices("1.2.3.4:5", hidden, allowed,
+		denied))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var si []*factory.ServiceInfo
+
+	si, err = s.ServiceAddresses(
+		[]cipher.PubKey{hidden.Key, allowed.Key, denied.Key},
+		requester,
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(si) != 1 {
+		t.Fatal("wrong number of services found:", len(si))
+	}
+
+	if si[0].PubKey != allowed.Key {
+		t.Error("wrong service found")
+	}
+
+}
+
+func TestStore_ByAttributes(t *testing.T) {
+
+	var s, clean = getTestStore(t)
+	defer clean()
+
+	var keys []cipher.PubKey
+
+	for i := 0; i < 5; i++ {
+
+		var pk, _ = cipher.GenerateKeyPair()
+		keys = append(keys, pk)
+
+		var attr = "odd"
+		if i%2 == 0 {
+			attr = "even"
+		}
+
+		err := s.Register(pk, getTestNodeServices("1.2.3.4:5",
+			getTestService(attr), getTestService("any")))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+	}
+
+	for _, tc := range []struct {
+		page, limit int
+		attrs       []string
+		nodes       int
+		count       int64
+	}{
+		{0, 0, []string{"even"}, 3, 3},
+		{0, 0, []string{"odd"}, 2, 2},
+		{0, 0, []string{"odd", "even"}, 5, 5},
+		{0, 0, []string{"none"}, 0, 0},
+		{1, 2, []string{"any"}, 2, 5},
+		{3, 2, []string{"any"}, 1, 5},
+		{4, 2, []string{"any"}, 0, 5},
+	} {
+
+		var ani, err = s.ByAttributes(tc.page, tc.limit, tc.attrs...)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ani.Count != tc.count {
+			t.Errorf("%v: wrong count %d, want %d", tc.attrs, ani.Count,
+				tc.count)
+		}
+
+		if len(ani.Nodes) != tc.nodes {
+			t.Errorf("%v (%d, %d): wrong number of nodes %d, want %d",
+				tc.attrs, tc.page, tc.limit, len(ani.Nodes), tc.nodes)
+		}
+
+	}
+
+	// one app per node found by "any"
+
+	var ani, err = s.ByAttributes(0, 0, "any")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range ani.Nodes {
+		if len(n.Apps) != 1 || len(n.AppInfos) != 1 {
+			t.Error("wrong number of apps:", len(n.Apps), len(n.AppInfos))
+		}
+	}
+
+}
+
+func TestStore_Expire(t *testing.T) {
+
+	var s, clean = getTestStore(t)
+	defer clean()
+
+	var (
+		connected, _    = cipher.GenerateKeyPair()
+		disconnected, _ = cipher.GenerateKeyPair()
+	)
+
+	for _, pk := range []cipher.PubKey{connected, disconnected} {
+		err := s.Register(pk, getTestNodeServices("1.2.3.4:5",
+			getTestService()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// both alive
+	if expired, err := s.Expire(0); err != nil {
+		t.Fatal(err)
+	} else if expired != 0 {
+		t.Error("alive nodes expired:", expired)
+	}
+
+	// disconnect one of the nodes without unregistering
+	s.KeepAlive(map[cipher.PubKey]struct{}{connected: {}})
+
+	if s.isAlive(disconnected) == true {
+		t.Error("disconnected node is alive")
+	}
+
+	if s.isAlive(connected) == false {
+		t.Error("connected node is not alive")
+	}
+
+	// not stale yet
+	if expired, err := s.Expire(time.Hour); err != nil {
+		t.Fatal(err)
+	} else if expired != 0 {
+		t.Error("fresh node expired:", expired)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if expired, err := s.Expire(time.Millisecond); err != nil {
+		t.Fatal(err)
+	} else if expired != 1 {
+		t.Error("wrong number of expired nodes:", expired)
+	}
+
+	if nodes, _, err := s.Counts(); err != nil {
+		t.Fatal(err)
+	} else if nodes != 1 {
+		t.Error("wrong number of nodes:", nodes)
+	}
+
+	var si, err = s.ServiceAddresses(nil, cipher.PubKey{})
+
+	if err != nil {
+		t.Fatal(err)
+	} else if len(si) != 0 {
+		t.Error("unexpected services")
+	}
+
+}
+
+func TestStore_reopen(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxodiscovery")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	var (
+		file  = filepath.Join(dir, "test.db")
+		pk, _ = cipher.GenerateKeyPair()
+		s     *store
+	)
+
+	if s, err = newStore(file); err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Register(pk, getTestNodeServices("1.2.3.4:5", getTestService()))
+
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s, err = newStore(file); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if s.isAlive(pk) == true {
+		t.Error("node is alive after reopening")
+	}
+
+	if nodes, _, err := s.Counts(); err != nil {
+		t.Fatal(err)
+	} else if nodes != 1 {
+		t.Error("wrong number of nodes:", nodes)
+	}
+
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/skycoin/cxo/skyobject"
+)
+
+func main() {
+
+	var (
+		conf = skyobject.NewConfig()
+
+		repair  bool
+		verbose bool
+	)
+
+	flag.StringVar(&conf.DataDir,
+		"data-dir",
+		conf.DataDir,
+		"directory with db.cxds and db.idx")
+	flag.StringVar(&conf.DBPath,
+		"db-path",
+		conf.DBPath,
+		"path to DB without extensions, overrides the data-dir")
+	flag.StringVar(&conf.KeyFile,
+		"key-file",
+		conf.KeyFile,
+		"path to file with key of encrypted DB")
+	flag.BoolVar(&repair,
+		"repair",
+		false,
+		"repair references counters")
+	flag.BoolVar(&verbose,
+		"v",
+		false,
+		"show all found problems")
+
+	flag.Parse()
+
+	log.SetFlags(0)
+
+	if conf.DBPath == "" && conf.DataDir == "" {
+		log.Fatal("missing DB path, use -data-dir or -db-path")
+	}
+
+	conf.RetentionInterval = 0 // don't remove anything
+
+	var c, err = skyobject.NewContainer(conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var rep *skyobject.FsckReport
+	rep, err = c.Fsck(repair)
+
+	if rep != nil {
+		printReport(rep, verbose)
+	}
+
+	if cerr := c.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if rep.IsClean() == false && rep.Repaired == 0 {
+		os.Exit(1)
+	}
+}
+
+func printReport(rep *skyobject.FsckReport, verbose bool) {
+
+	fmt.Println("objects:      ", rep.Objects)
+	fmt.Println("Root objects: ", rep.Roots)
+	fmt.Println("corrupted:    ", len(rep.Corrupted))
+	fmt.Println("missing:      ", len(rep.Missing))
+	fmt.Println("orphans:      ", len(rep.Orphans))
+	fmt.Println("wrong rc:     ", len(rep.Mismatches))
+	fmt.Println("broken Roots: ", len(rep.Broken))
+	fmt.Println("repaired:     ", rep.Repaired)
+
+	if verbose == false {
+		return
+	}
+
+	for _, key := range rep.Corrupted {
+		fmt.Println("  corrupted", key.Hex())
+	}
+	for _, key := range rep.Missing {
+		fmt.Println("  missing", key.Hex())
+	}
+	for _, rm := range rep.Orphans {
+		fmt.Println("  orphan", rm.Key.Hex(), "rc", rm.RC)
+	}
+	for _, rm := range rep.Mismatches {
+		fmt.Println("  wrong rc", rm.Key.Hex(), rm.RC, "expected", rm.Expected)
+	}
+	for _, br := range rep.Broken {
+		fmt.Println("  " + br.Error())
+	}
+}
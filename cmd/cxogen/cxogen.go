@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+	"github.com/skycoin/cxo/skyobject/registry/gogen"
+)
+
+func main() {
+
+	var (
+		conf = skyobject.NewConfig()
+		gen  = gogen.NewConfig()
+
+		reg    string
+		feed   string
+		file   string
+		output string
+	)
+
+	flag.StringVar(&conf.DataDir,
+		"data-dir",
+		conf.DataDir,
+		"directory with db.cxds and db.idx")
+	flag.StringVar(&conf.DBPath,
+		"db-path",
+		conf.DBPath,
+		"path to DB without extensions, overrides the data-dir")
+	flag.StringVar(&conf.KeyFile,
+		"key-file",
+		conf.KeyFile,
+		"path to file with key of encrypted DB")
+	flag.StringVar(&reg,
+		"reg",
+		"",
+		"hex-encoded RegistryRef of Registry to get from DB")
+	flag.StringVar(&feed,
+		"feed",
+		"",
+		"public key of feed to get Registry of last Root from DB")
+	flag.StringVar(&file,
+		"file",
+		"",
+		"path to file with encoded Registry, instead of DB")
+	flag.StringVar(&gen.Package,
+		"pkg",
+		gen.Package,
+		"name of package of generated code")
+	flag.StringVar(&gen.Registry,
+		"var",
+		gen.Registry,
+		"name of Registry variable, blank to omit")
+	flag.BoolVar(&gen.Methods,
+		"methods",
+		false,
+		"generate static Encode and Decode methods")
+	flag.StringVar(&output,
+		"o",
+		"",
+		"output file, default is stdout")
+
+	flag.Parse()
+
+	log.SetFlags(0)
+
+	var (
+		r   *registry.Registry
+		err error
+	)
+
+	switch {
+	case file != "":
+		r, err = registryFromFile(file)
+	case reg != "" || feed != "":
+		r, err = registryFromDB(conf, reg, feed)
+	default:
+		log.Fatal("missing Registry, use -reg, -feed or -file")
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var src []byte
+	if src, err = gogen.Generate(r, gen); err != nil {
+		log.Fatal(err)
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = ioutil.WriteFile(output, src, 0644)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func registryFromFile(file string) (r *registry.Registry, err error) {
+
+	var val []byte
+	if val, err = ioutil.ReadFile(file); err != nil {
+		return
+	}
+
+	return registry.DecodeRegistry(val)
+}
+
+func registryFromDB(
+	conf *skyobject.Config,
+	reg string,
+	feed string,
+) (
+	r *registry.Registry,
+	err error,
+) {
+
+	if conf.DBPath == "" && conf.DataDir == "" {
+		log.Fatal("missing DB path, use -data-dir or -db-path")
+	}
+
+	conf.RetentionInterval = 0 // don't remove anything
+
+	var c *skyobject.Container
+	if c, err = skyobject.NewContainer(conf); err != nil {
+		return
+	}
+
+	defer func() {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	var rr registry.RegistryRef
+
+	if reg != "" {
+
+		var hash cipher.SHA256
+		if hash, err = cipher.SHA256FromHex(reg); err != nil {
+			return
+		}
+
+		rr = registry.RegistryRef(hash)
+
+	} else if rr, err = lastRegistryRef(c, feed); err != nil {
+		return
+	}
+
+	return c.Registry(rr)
+}
+
+// RegistryRef of the newest Root of given feed
+func lastRegistryRef(
+	c *skyobject.Container,
+	feed string,
+) (
+	rr registry.RegistryRef,
+	err error,
+) {
+
+	var pk cipher.PubKey
+	if pk, err = cipher.PubKeyFromHex(feed); err != nil {
+		return
+	}
+
+	var heads []uint64
+	if heads, err = c.Heads(pk); err != nil {
+		return
+	}
+
+	var last *registry.Root
+
+	for _, nonce := range heads {
+
+		var r *registry.Root
+		if r, err = c.LastRoot(pk, nonce); err != nil {
+			continue // empty head
+		}
+
+		if last == nil || last.Time < r.Time {
+			last = r
+		}
+
+	}
+
+	if last == nil {
+		return rr, errors.New("the feed has no Root objects")
+	}
+
+	return last.Reg, nil
+}
@@ -28,7 +28,32 @@ import (
 //
 // If a feed contains more then one head, then the method
 // keeps last n-th Root objects of every head.
+//
+// The method keeps pinned Root objects (see Pin method
+// of the skyobject.Container). Use ForceRemoveRootObjects
+// to remove them too.
+//
+// See also Retention and Prune method of the
+// skyobject.Container, that removes old Root
+// objects automatically
 func RemoveRootObjects(c *skyobject.Container, keepLast int) (err error) {
+	return removeRootObjects(c, keepLast, c.DelRoot)
+}
+
+// ForceRemoveRootObjects is the same as the
+// RemoveRootObjects, but it removes pinned
+// Root objects too
+func ForceRemoveRootObjects(c *skyobject.Container, keepLast int) (err error) {
+	return removeRootObjects(c, keepLast, c.ForceDelRoot)
+}
+
+func removeRootObjects(
+	c *skyobject.Container,
+	keepLast int,
+	delRoot func(pk cipher.PubKey, nonce, seq uint64) error,
+) (
+	err error,
+) {
 
 	for _, pk := range c.Feeds() {
 
@@ -53,7 +78,11 @@ func RemoveRootObjects(c *skyobject.Container, keepLast int) (err error) {
 
 			for ; goDown > 0; goDown-- {
 
-				if err = c.DelRoot(pk, nonce, goDown); err != nil {
+				if err = delRoot(pk, nonce, goDown); err != nil {
+					if err == skyobject.ErrPinnedRoot {
+						err = nil // keep pinned
+						continue
+					}
 					if err == data.ErrNotFound {
 						err = nil // clear error
 						continue HeadLoop
@@ -64,8 +93,8 @@ func RemoveRootObjects(c *skyobject.Container, keepLast int) (err error) {
 			}
 
 			// seq = 0 (goDown == 0)
-			if err = c.DelRoot(pk, nonce, 0); err != nil {
-				if err == data.ErrNotFound {
+			if err = delRoot(pk, nonce, 0); err != nil {
+				if err == data.ErrNotFound || err == skyobject.ErrPinnedRoot {
 					err = nil // clear error
 					continue HeadLoop
 				}
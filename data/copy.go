@@ -0,0 +1,184 @@
+package data
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// Copy copies all objects, feeds, heads and Root
+// objects from given src DB to given dst DB. The DBs
+// can use different CXDS and IdxDB implementations.
+// For example, use the Copy to move a DB from memory
+// to drive
+//
+//     var dst = data.NewDB(dstCXDS, dstIdxDB)
+//
+//     if err = data.Copy(dst, src); err != nil {
+//         // [...]
+//     }
+//
+// See CopyCXDS and CopyIdxDB for details. The DBs
+// must not be used by others during the Copy
+func Copy(dst, src *DB) (err error) {
+	if err = CopyCXDS(dst.CXDS(), src.CXDS()); err != nil {
+		return
+	}
+	return CopyIdxDB(dst.IdxDB(), src.IdxDB())
+}
+
+// limits of a batch of the CopyCXDS
+const (
+	copyBatchLen    = 1024             // objects
+	copyBatchVolume = 16 * 1024 * 1024 // bytes
+)
+
+// CopyCXDS copies all objects from given src CXDS to
+// given dst CXDS keeping rc of the objects (including
+// objects with zero rc). If an object already exists
+// in the dst, then its rc will be replaced. The
+// CopyCXDS copies objects by batches and doesn't
+// load all objects to memory. If the dst implements
+// the Putter, then every batch is put in one
+// transaction
+func CopyCXDS(dst, src CXDS) (err error) {
+
+	var (
+		batch []Object
+		vol   int
+	)
+
+	err = src.Iterate(func(key cipher.SHA256, rc uint32, val []byte) (err error) {
+
+		// the val is valid during the iteration only
+		var cp = make([]byte, len(val))
+		copy(cp, val)
+
+		batch = append(batch, Object{key, cp, rc})
+		vol += len(cp)
+
+		if len(batch) < copyBatchLen && vol < copyBatchVolume {
+			return
+		}
+
+		err = PutObjects(dst, batch)
+		batch, vol = batch[:0], 0
+		return
+	})
+
+	if err != nil || len(batch) == 0 {
+		return
+	}
+
+	return PutObjects(dst, batch)
+}
+
+// PutObjects puts given objects to given CXDS. If the
+// CXDS implements the Putter, then the PutObjects
+// uses it. Otherwise, the objects are put one by one
+func PutObjects(ds CXDS, objs []Object) (err error) {
+
+	if pr, ok := ds.(Putter); ok == true {
+		return pr.Put(objs)
+	}
+
+	for _, obj := range objs {
+		if err = putObject(ds, obj); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// put object replacing its rc
+func putObject(ds CXDS, obj Object) (err error) {
+
+	var drc uint32
+
+	switch _, drc, err = ds.Get(obj.Key, 0); err {
+	case nil:
+		if drc != obj.RC {
+			_, err = ds.Inc(obj.Key, int(obj.RC)-int(drc))
+		}
+		return
+	case ErrNotFound:
+	default:
+		return
+	}
+
+	if _, err = ds.Set(obj.Key, obj.Val, 1); err != nil || obj.RC == 1 {
+		return
+	}
+
+	_, err = ds.Inc(obj.Key, int(obj.RC)-1)
+	return
+}
+
+// CopyIdxDB copies all feeds with meta information,
+// heads and Root objects from given src IdxDB to given
+// dst IdxDB. The Root objects are copied as is (see
+// Put method of the Roots), with the same Create and
+// Access fields. The CopyIdxDB uses a transaction of
+// the dst per feed
+func CopyIdxDB(dst, src IdxDB) (err error) {
+
+	return src.Tx(func(sfs Feeds) (err error) {
+
+		return sfs.Iterate(func(pk cipher.PubKey) (err error) {
+
+			return dst.Tx(func(dfs Feeds) (err error) {
+				return copyFeed(dfs, sfs, pk)
+			})
+
+		})
+
+	})
+
+}
+
+func copyFeed(dfs, sfs Feeds, pk cipher.PubKey) (err error) {
+
+	if err = dfs.Add(pk); err != nil {
+		return
+	}
+
+	err = sfs.IterateMeta(pk, func(key, val []byte) (err error) {
+
+		var cp = make([]byte, len(val))
+		copy(cp, val)
+
+		return dfs.SetMeta(pk, key, cp)
+	})
+
+	if err != nil {
+		return
+	}
+
+	var shs, dhs Heads
+
+	if shs, err = sfs.Heads(pk); err != nil {
+		return
+	}
+
+	if dhs, err = dfs.Heads(pk); err != nil {
+		return
+	}
+
+	return shs.Iterate(func(nonce uint64) (err error) {
+
+		var srs, drs Roots
+
+		if srs, err = shs.Roots(nonce); err != nil {
+			return
+		}
+
+		if drs, err = dhs.Add(nonce); err != nil {
+			return
+		}
+
+		return srs.Ascend(func(r *Root) error {
+			return drs.Put(r)
+		})
+
+	})
+
+}
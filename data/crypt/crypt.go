@@ -0,0 +1,270 @@
+// Package crypt implements encryption at rest for
+// any data.CXDS and data.IdxDB. The package wraps a
+// CXDS and an IdxDB encrypting values of objects,
+// meta information of feeds and Root objects using
+// AES-256-GCM. Keys (hashes of objects, public keys
+// of feeds, nonces of heads and seq numbers of Root
+// objects) are not encrypted. For example
+//
+//     var key, err = crypt.LoadKeyFile("cxo.key")
+//     if err != nil {
+//         // [...]
+//     }
+//
+//     var db *data.DB
+//     if db, err = crypt.NewDB(data.NewDB(cx, idx), key); err != nil {
+//         // [...]
+//     }
+//
+// To change a key copy a DB using the data.Copy
+//
+//     var src, dst *data.DB
+//
+//     if src, err = crypt.NewDB(oldDB, oldKey); err != nil {
+//         // [...]
+//     }
+//
+//     if dst, err = crypt.NewDB(newDB, newKey); err != nil {
+//         // [...]
+//     }
+//
+//     if err = data.Copy(dst, src); err != nil {
+//         // [...]
+//     }
+//
+// See also skyobject.RotateKey
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// Version of encrypted values
+const Version byte = 1
+
+// common errors
+var (
+	// ErrDecrypt occurs if a value can't be decrypted,
+	// e.g. if the value has been encrypted by another
+	// key, if the value is corrupted or if the value is
+	// not encrypted at all
+	ErrDecrypt = errors.New("can't decrypt, wrong key or corrupted value")
+	// ErrWrongKey occurs if a DB is encrypted by another
+	// key (see NewDB)
+	ErrWrongKey = errors.New("wrong key of encrypted DB")
+	// ErrNoMetadata occurs if an IdxDB doesn't implement
+	// the data.Metadata, but the Metadata required
+	ErrNoMetadata = errors.New("the IdxDB doesn't implement data.Metadata")
+	// ErrPlaintextDB occurs if a DB that is not empty and
+	// not encrypted is opened with a key or a passphrase.
+	// Use skyobject.RotateKey to encrypt such DB
+	ErrPlaintextDB = errors.New("the DB is not encrypted, " +
+		"use skyobject.RotateKey to encrypt it")
+)
+
+// keys of meta information of a DB (see data.Metadata)
+var (
+	saltMetaKey  = []byte("crypt.salt")  // salt of passphrase
+	checkMetaKey = []byte("crypt.check") // encrypted checkValue
+)
+
+// encrypted check value is kept in a DB to
+// detect wrong key
+var checkValue = []byte("skycoin/cxo/data/crypt")
+
+// A sealer encrypts and decrypts values
+type sealer struct {
+	aead cipher.AEAD
+}
+
+func newSealer(key Key) (s *sealer) {
+
+	var (
+		block cipher.Block
+		err   error
+	)
+
+	if block, err = aes.NewCipher(key[:]); err != nil {
+		panic(err) // never happens, the key is 32 bytes long
+	}
+
+	s = new(sealer)
+
+	if s.aead, err = cipher.NewGCM(block); err != nil {
+		panic(err) // never happens
+	}
+
+	return
+}
+
+// seal given value, the ad is additional data that
+// used to authenticate the value, but not stored;
+// the result is [version][nonce][encrypted value]
+func (s *sealer) seal(val, ad []byte) (sealed []byte) {
+
+	var ns = s.aead.NonceSize()
+
+	sealed = make([]byte, 1+ns, 1+ns+len(val)+s.aead.Overhead())
+	sealed[0] = Version
+
+	if _, err := rand.Read(sealed[1:]); err != nil {
+		panic(err) // crypto/rand is broken
+	}
+
+	return s.aead.Seal(sealed, sealed[1:], val, ad)
+}
+
+// open sealed value, the result is new slice
+func (s *sealer) open(sealed, ad []byte) (val []byte, err error) {
+
+	var ns = s.aead.NonceSize()
+
+	if len(sealed) < 1+ns+s.aead.Overhead() {
+		return nil, ErrDecrypt
+	}
+
+	if sealed[0] != Version {
+		return nil, fmt.Errorf("unknown version of encrypted value: %d",
+			sealed[0])
+	}
+
+	if val, err = s.aead.Open(nil, sealed[1:1+ns], sealed[1+ns:], ad); err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return
+}
+
+// NewDB wraps CXDS and IdxDB of given DB. See
+// NewCXDS and NewIdxDB for details. If the IdxDB
+// implements data.Metadata, then the NewDB keeps
+// encrypted check value in the IdxDB and returns
+// ErrWrongKey if the value can't be decrypted by
+// given key. The NewDB returns ErrPlaintextDB if
+// the DB doesn't have the check value, but it's
+// not empty
+func NewDB(db *data.DB, key Key) (edb *data.DB, err error) {
+
+	if err = checkKey(db, newSealer(key)); err != nil {
+		return
+	}
+
+	edb = data.NewDB(NewCXDS(db.CXDS(), key), NewIdxDB(db.IdxDB(), key))
+	return
+}
+
+// NewDBPassphrase is like the NewDB, but it derives
+// key from given passphrase (see KeyFromPassphrase).
+// Random salt is generated for new DB and kept in
+// the IdxDB. The IdxDB must implement data.Metadata,
+// otherwise the ErrNoMetadata returned
+func NewDBPassphrase(
+	db *data.DB, //        : DB to wrap
+	passphrase string, //  : passphrase
+) (
+	edb *data.DB, //       : encrypted DB
+	err error, //          : an error
+) {
+
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+
+	var md, ok = db.IdxDB().(data.Metadata)
+
+	if ok == false {
+		return nil, ErrNoMetadata
+	}
+
+	var salt []byte
+
+	switch salt, err = md.GetMeta(saltMetaKey); err {
+	case nil:
+	case data.ErrNotFound:
+		// encrypted by a key, not by a passphrase
+		if _, err = md.GetMeta(checkMetaKey); err == nil {
+			return nil, ErrWrongKey
+		} else if err != data.ErrNotFound {
+			return
+		}
+		if err = checkBlank(db); err != nil {
+			return // don't keep the salt
+		}
+		salt = NewSalt()
+		if err = md.SetMeta(saltMetaKey, salt); err != nil {
+			return
+		}
+	default:
+		return
+	}
+
+	var key Key
+	if key, err = KeyFromPassphrase(passphrase, salt); err != nil {
+		return
+	}
+
+	return NewDB(db, key)
+}
+
+// check key using encrypted check value of IdxDB
+// of given DB, the check value will be created if
+// it doesn't exist and the DB is empty; the
+// checkKey does nothing if the IdxDB doesn't
+// implement data.Metadata
+func checkKey(db *data.DB, s *sealer) (err error) {
+
+	var md, ok = db.IdxDB().(data.Metadata)
+
+	if ok == false {
+		return
+	}
+
+	var sealed []byte
+
+	switch sealed, err = md.GetMeta(checkMetaKey); err {
+	case nil:
+	case data.ErrNotFound:
+		if err = checkBlank(db); err != nil {
+			return
+		}
+		return md.SetMeta(checkMetaKey, s.seal(checkValue, checkMetaKey))
+	default:
+		return
+	}
+
+	var val []byte
+	if val, err = s.open(sealed, checkMetaKey); err != nil {
+		return ErrWrongKey
+	}
+
+	if bytes.Equal(val, checkValue) == false {
+		return ErrWrongKey
+	}
+
+	return
+}
+
+// checkBlank returns ErrPlaintextDB if given
+// DB has objects or feeds; it's used for DB
+// without the check value that can't be
+// encrypted in place
+func checkBlank(db *data.DB) (err error) {
+
+	if all, _ := db.CXDS().Amount(); all > 0 {
+		return ErrPlaintextDB
+	}
+
+	return db.IdxDB().Tx(func(fs data.Feeds) (_ error) {
+		if fs.Len() > 0 {
+			return ErrPlaintextDB
+		}
+		return
+	})
+}
@@ -0,0 +1,394 @@
+package crypt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds"
+	"github.com/skycoin/cxo/data/idxdb"
+	"github.com/skycoin/cxo/data/tests"
+)
+
+func testCXDS() (ds, raw data.CXDS) {
+	raw = cxds.NewMemoryCXDS()
+	return NewCXDS(raw, NewKey()), raw
+}
+
+func testIdxDB() data.IdxDB {
+	return NewIdxDB(idxdb.NewMemeoryDB(), NewKey())
+}
+
+func TestNewCXDS(t *testing.T) {
+	// NewCXDS(ds data.CXDS, key Key) data.CXDS
+
+	t.Run("get", func(t *testing.T) {
+		ds, _ := testCXDS()
+		defer ds.Close()
+		tests.CXDSGet(t, ds)
+	})
+
+	t.Run("set", func(t *testing.T) {
+		ds, _ := testCXDS()
+		defer ds.Close()
+		tests.CXDSSet(t, ds)
+	})
+
+	t.Run("inc", func(t *testing.T) {
+		ds, _ := testCXDS()
+		defer ds.Close()
+		tests.CXDSInc(t, ds)
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		ds, raw := testCXDS()
+		defer ds.Close()
+
+		var (
+			val = []byte("secret value")
+			key = cipher.SumSHA256(val)
+		)
+
+		if _, err := ds.Set(key, val, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, _, err := raw.Get(key, 0); err != nil {
+			t.Fatal(err)
+		} else if bytes.Contains(got, val) == true {
+			t.Error("not encrypted")
+		}
+
+		// wrong key
+		var wds = NewCXDS(raw, NewKey())
+
+		if _, _, err := wds.Get(key, 0); err != ErrDecrypt {
+			t.Error("wrong error:", err)
+		}
+
+		if err := wds.Iterate(func(cipher.SHA256, uint32, []byte) error {
+			return nil
+		}); err != ErrDecrypt {
+			t.Error("wrong error:", err)
+		}
+	})
+
+}
+
+func TestNewIdxDB(t *testing.T) {
+	// NewIdxDB(idx data.IdxDB, key Key) data.IdxDB
+
+	for _, tc := range []struct {
+		name string
+		test func(*testing.T, data.IdxDB)
+	}{
+		{"feeds meta", tests.FeedsMeta},
+		{"roots ascend", tests.RootsAscend},
+		{"roots descend", tests.RootsDescend},
+		{"roots set", tests.RootsSet},
+		{"roots put", tests.RootsPut},
+		{"roots get", tests.RootsGet},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			idx := testIdxDB()
+			defer idx.Close()
+			tc.test(t, idx)
+		})
+	}
+
+	t.Run("wrong key", func(t *testing.T) {
+
+		var (
+			raw    = idxdb.NewMemeoryDB()
+			idx    = NewIdxDB(raw, NewKey())
+			pk, sk = cipher.GenerateKeyPair()
+			r      = &data.Root{Time: 1, Hash: cipher.SumSHA256([]byte("r"))}
+		)
+
+		var err error
+		if r.Sig, err = cipher.SignHash(r.Hash, sk); err != nil {
+			t.Fatal(err)
+		}
+
+		err = idx.Tx(func(fs data.Feeds) (err error) {
+			if err = fs.Add(pk); err != nil {
+				return
+			}
+			var hs data.Heads
+			if hs, err = fs.Heads(pk); err != nil {
+				return
+			}
+			var rs data.Roots
+			if rs, err = hs.Add(0); err != nil {
+				return
+			}
+			return rs.Set(r)
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var get = func(idx data.IdxDB) (gr *data.Root, err error) {
+			err = idx.Tx(func(fs data.Feeds) (err error) {
+				var hs data.Heads
+				if hs, err = fs.Heads(pk); err != nil {
+					return
+				}
+				var rs data.Roots
+				if rs, err = hs.Roots(0); err != nil {
+					return
+				}
+				gr, err = rs.Get(0)
+				return
+			})
+			return
+		}
+
+		if er, err := get(raw); err != nil {
+			t.Fatal(err)
+		} else if er.Sig == r.Sig || er.Time == r.Time {
+			t.Error("not encrypted")
+		}
+
+		if _, err := get(NewIdxDB(raw, NewKey())); err != ErrDecrypt {
+			t.Error("wrong error:", err)
+		}
+
+		if gr, err := get(idx); err != nil {
+			t.Fatal(err)
+		} else if gr.Time != r.Time || gr.Sig != r.Sig || gr.Hash != r.Hash {
+			t.Error("wrong Root")
+		}
+
+	})
+
+}
+
+func TestKeyFromPassphrase(t *testing.T) {
+	// KeyFromPassphrase(passphrase string, salt []byte) (k Key, err error)
+
+	var salt = NewSalt()
+
+	if _, err := KeyFromPassphrase("", salt); err != ErrEmptyPassphrase {
+		t.Error("wrong error:", err)
+	}
+
+	if _, err := KeyFromPassphrase("secret", nil); err != ErrEmptySalt {
+		t.Error("wrong error:", err)
+	}
+
+	var a, b, c, d Key
+	var err error
+
+	if a, err = KeyFromPassphrase("secret", salt); err != nil {
+		t.Fatal(err)
+	}
+	if b, err = KeyFromPassphrase("secret", salt); err != nil {
+		t.Fatal(err)
+	}
+	if c, err = KeyFromPassphrase("another secret", salt); err != nil {
+		t.Fatal(err)
+	}
+	if d, err = KeyFromPassphrase("secret", NewSalt()); err != nil {
+		t.Fatal(err)
+	}
+
+	if a != b {
+		t.Error("different keys of the same passphrase")
+	}
+	if a == c {
+		t.Error("the same keys of different passphrases")
+	}
+	if a == d {
+		t.Error("the same keys of different salts")
+	}
+}
+
+func TestNewDB(t *testing.T) {
+	// NewDB(db *data.DB, key Key) (edb *data.DB, err error)
+
+	var (
+		db  = data.NewDB(cxds.NewMemoryCXDS(), idxdb.NewMemeoryDB())
+		key = NewKey()
+		err error
+	)
+
+	defer db.Close()
+
+	if _, err = NewDB(db, key); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = NewDB(db, key); err != nil {
+		t.Error(err)
+	}
+
+	if _, err = NewDB(db, NewKey()); err != ErrWrongKey {
+		t.Error("wrong error:", err)
+	}
+
+	if _, err = NewDBPassphrase(db, "secret"); err != ErrWrongKey {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestNewDB_notEncrypted(t *testing.T) {
+
+	var val = []byte("value")
+
+	// objects
+	var db = data.NewDB(cxds.NewMemoryCXDS(), idxdb.NewMemeoryDB())
+	defer db.Close()
+
+	if _, err := db.CXDS().Set(cipher.SumSHA256(val), val, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewDB(db, NewKey()); err != ErrPlaintextDB {
+		t.Error("wrong error:", err)
+	}
+
+	// feeds
+	var fdb = data.NewDB(cxds.NewMemoryCXDS(), idxdb.NewMemeoryDB())
+	defer fdb.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	if err := fdb.IdxDB().Tx(func(fs data.Feeds) error {
+		return fs.Add(pk)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewDBPassphrase(fdb, "secret"); err != ErrPlaintextDB {
+		t.Error("wrong error:", err)
+	}
+
+	var md = fdb.IdxDB().(data.Metadata)
+
+	for _, key := range [][]byte{saltMetaKey, checkMetaKey} {
+		if _, err := md.GetMeta(key); err != data.ErrNotFound {
+			t.Errorf("%s: wrong error: %v", key, err)
+		}
+	}
+
+}
+
+func TestNewDBPassphrase(t *testing.T) {
+	// NewDBPassphrase(db *data.DB, passphrase string) (*data.DB, error)
+
+	var (
+		db  = data.NewDB(cxds.NewMemoryCXDS(), idxdb.NewMemeoryDB())
+		an  = data.NewDB(cxds.NewMemoryCXDS(), idxdb.NewMemeoryDB())
+		err error
+	)
+
+	defer db.Close()
+	defer an.Close()
+
+	if _, err = NewDBPassphrase(db, ""); err != ErrEmptyPassphrase {
+		t.Error("wrong error:", err)
+	}
+
+	var val = []byte("value")
+
+	var edb *data.DB
+	if edb, err = NewDBPassphrase(db, "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = edb.CXDS().Set(cipher.SumSHA256(val), val, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if edb, err = NewDBPassphrase(db, "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _, err := edb.CXDS().Get(cipher.SumSHA256(val), 0); err != nil {
+		t.Error(err)
+	} else if bytes.Equal(got, val) == false {
+		t.Error("wrong value")
+	}
+
+	if _, err = NewDBPassphrase(db, "wrong"); err != ErrWrongKey {
+		t.Error("wrong error:", err)
+	}
+
+	// salt is random per DB
+
+	if _, err = NewDBPassphrase(an, "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	var salt1, salt2 []byte
+
+	if salt1, err = db.IdxDB().(data.Metadata).GetMeta(saltMetaKey); err != nil {
+		t.Fatal(err)
+	}
+	if salt2, err = an.IdxDB().(data.Metadata).GetMeta(saltMetaKey); err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(salt1, salt2) == true {
+		t.Error("the same salt")
+	}
+
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	// LoadKeyFile(name string) (k Key, err error)
+
+	var dir, err = ioutil.TempDir("", "cxo-crypt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		key  = NewKey()
+		name = filepath.Join(dir, "cxo.key")
+		got  Key
+	)
+
+	// hex
+
+	if err = SaveKeyFile(name, key); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err = LoadKeyFile(name); err != nil {
+		t.Fatal(err)
+	} else if got != key {
+		t.Error("wrong key")
+	}
+
+	// raw
+
+	if err = ioutil.WriteFile(name, key[:], 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err = LoadKeyFile(name); err != nil {
+		t.Fatal(err)
+	} else if got != key {
+		t.Error("wrong key")
+	}
+
+	// invalid
+
+	if err = ioutil.WriteFile(name, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = LoadKeyFile(name); err != ErrInvalidKeyFile {
+		t.Error("wrong error:", err)
+	}
+}
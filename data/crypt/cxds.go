@@ -0,0 +1,153 @@
+package crypt
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+type cryptCXDS struct {
+	ds data.CXDS
+	s  *sealer
+}
+
+// a CXDS that supports snapshots
+type snapshotCXDS struct {
+	*cryptCXDS
+}
+
+// NewCXDS wraps given CXDS. The result encrypts
+// values of objects. The keys (hashes) and rc of
+// objects are not encrypted. A value is bound to
+// its key, thus it's impossible to swap values.
+// The result implements data.Snapshotter if given
+// CXDS implements it. Statistic (see Volume) of
+// the result is statistic of encrypted values.
+// Given CXDS should be empty or should contain
+// objects encrypted by the same key
+func NewCXDS(ds data.CXDS, key Key) data.CXDS {
+
+	var c = &cryptCXDS{ds: ds, s: newSealer(key)}
+
+	if _, ok := ds.(data.Snapshotter); ok == true {
+		return &snapshotCXDS{c}
+	}
+
+	return c
+}
+
+func (c *cryptCXDS) Get(
+	key cipher.SHA256,
+	inc int,
+) (
+	val []byte,
+	rc uint32,
+	err error,
+) {
+
+	if val, rc, err = c.ds.Get(key, inc); err != nil {
+		return
+	}
+
+	if val, err = c.s.open(val, key[:]); err != nil {
+		return nil, 0, err
+	}
+
+	return
+}
+
+func (c *cryptCXDS) Set(
+	key cipher.SHA256,
+	val []byte,
+	inc int,
+) (
+	rc uint32,
+	err error,
+) {
+
+	if len(val) == 0 {
+		return c.ds.Set(key, val, inc) // pass through
+	}
+
+	return c.ds.Set(key, c.s.seal(val, key[:]), inc)
+}
+
+// Put encrypts values of given objects and puts
+// them to underlying CXDS (see data.PutObjects)
+func (c *cryptCXDS) Put(objs []data.Object) (err error) {
+
+	var sealed = make([]data.Object, 0, len(objs))
+
+	for _, obj := range objs {
+		if len(obj.Val) != 0 {
+			obj.Val = c.s.seal(obj.Val, obj.Key[:])
+		}
+		sealed = append(sealed, obj)
+	}
+
+	return data.PutObjects(c.ds, sealed)
+}
+
+func (c *cryptCXDS) Inc(key cipher.SHA256, inc int) (rc uint32, err error) {
+	return c.ds.Inc(key, inc)
+}
+
+func (c *cryptCXDS) Iterate(iterateFunc data.IterateObjectsFunc) (err error) {
+
+	return c.ds.Iterate(func(key cipher.SHA256, rc uint32, val []byte) error {
+
+		var err error
+		if val, err = c.s.open(val, key[:]); err != nil {
+			return err
+		}
+
+		return iterateFunc(key, rc, val)
+	})
+
+}
+
+func (c *cryptCXDS) IterateDel(
+	iterateFunc data.IterateObjectsDelFunc,
+) (
+	err error,
+) {
+
+	return c.ds.IterateDel(func(
+		key cipher.SHA256,
+		rc uint32,
+		val []byte,
+	) (
+		del bool,
+		err error,
+	) {
+
+		if val, err = c.s.open(val, key[:]); err != nil {
+			return
+		}
+
+		return iterateFunc(key, rc, val)
+	})
+
+}
+
+func (c *cryptCXDS) Del(key cipher.SHA256) (err error) {
+	return c.ds.Del(key)
+}
+
+func (c *cryptCXDS) Amount() (all, used int) {
+	return c.ds.Amount()
+}
+
+func (c *cryptCXDS) Volume() (all, used int) {
+	return c.ds.Volume()
+}
+
+func (c *cryptCXDS) Close() (err error) {
+	return c.ds.Close()
+}
+
+// Snapshot of underlying CXDS, the Snapshot
+// contains encrypted values
+func (s *snapshotCXDS) Snapshot() (data.Snapshot, error) {
+	return s.ds.(data.Snapshotter).Snapshot()
+}
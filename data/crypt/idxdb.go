@@ -0,0 +1,339 @@
+package crypt
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// sealedTime is Time of encrypted data.Root
+const sealedTime int64 = -1
+
+// ErrNotEncrypted occurs if a Root is not encrypted
+var ErrNotEncrypted = errors.New("Root is not encrypted")
+
+type cryptIdxDB struct {
+	idx data.IdxDB
+	s   *sealer
+}
+
+// an IdxDB that supports snapshots
+type snapshotIdxDB struct {
+	*cryptIdxDB
+}
+
+// NewIdxDB wraps given IdxDB. The result encrypts
+// values of meta information of feeds, and fields
+// Time, Sig and Sigs of Root objects. Public keys
+// of feeds, keys of meta information, nonces of
+// heads and fields Seq, Prev, Hash, Create and
+// Access of Root objects are not encrypted. The
+// result implements data.Snapshotter if given
+// IdxDB implements it. Given IdxDB should be
+// empty or should contain feeds and Root objects
+// encrypted by the same key
+func NewIdxDB(idx data.IdxDB, key Key) data.IdxDB {
+
+	var c = &cryptIdxDB{idx: idx, s: newSealer(key)}
+
+	if _, ok := idx.(data.Snapshotter); ok == true {
+		return &snapshotIdxDB{c}
+	}
+
+	return c
+}
+
+func (c *cryptIdxDB) Tx(txFunc func(data.Feeds) error) (err error) {
+	return c.idx.Tx(func(fs data.Feeds) error {
+		return txFunc(&cryptFeeds{fs: fs, s: c.s})
+	})
+}
+
+func (c *cryptIdxDB) Close() (err error) {
+	return c.idx.Close()
+}
+
+// Snapshot of underlying IdxDB, the Snapshot
+// contains encrypted values
+func (s *snapshotIdxDB) Snapshot() (data.Snapshot, error) {
+	return s.idx.(data.Snapshotter).Snapshot()
+}
+
+type cryptFeeds struct {
+	fs data.Feeds
+	s  *sealer
+}
+
+// additional data of meta information
+func metaAD(pk cipher.PubKey, key []byte) (ad []byte) {
+	ad = make([]byte, 0, len(pk)+len(key))
+	ad = append(ad, pk[:]...)
+	return append(ad, key...)
+}
+
+func (c *cryptFeeds) Add(pk cipher.PubKey) (err error) {
+	return c.fs.Add(pk)
+}
+
+func (c *cryptFeeds) Del(pk cipher.PubKey) (err error) {
+	return c.fs.Del(pk)
+}
+
+func (c *cryptFeeds) Iterate(iterateFunc data.IterateFeedsFunc) (err error) {
+	return c.fs.Iterate(iterateFunc)
+}
+
+func (c *cryptFeeds) Has(pk cipher.PubKey) (ok bool, err error) {
+	return c.fs.Has(pk)
+}
+
+func (c *cryptFeeds) Heads(pk cipher.PubKey) (hs data.Heads, err error) {
+
+	if hs, err = c.fs.Heads(pk); err != nil {
+		return
+	}
+
+	return &cryptHeads{hs: hs, pk: pk, s: c.s}, nil
+}
+
+func (c *cryptFeeds) Len() (length int) {
+	return c.fs.Len()
+}
+
+func (c *cryptFeeds) GetMeta(
+	pk cipher.PubKey,
+	key []byte,
+) (
+	val []byte,
+	err error,
+) {
+
+	if val, err = c.fs.GetMeta(pk, key); err != nil {
+		return
+	}
+
+	return c.s.open(val, metaAD(pk, key))
+}
+
+func (c *cryptFeeds) SetMeta(pk cipher.PubKey, key, val []byte) (err error) {
+
+	if val == nil {
+		return c.fs.SetMeta(pk, key, nil) // delete
+	}
+
+	return c.fs.SetMeta(pk, key, c.s.seal(val, metaAD(pk, key)))
+}
+
+func (c *cryptFeeds) IterateMeta(
+	pk cipher.PubKey,
+	iterateFunc data.IterateMetaFunc,
+) (
+	err error,
+) {
+
+	return c.fs.IterateMeta(pk, func(key, val []byte) (err error) {
+
+		if val, err = c.s.open(val, metaAD(pk, key)); err != nil {
+			return
+		}
+
+		return iterateFunc(key, val)
+	})
+
+}
+
+type cryptHeads struct {
+	hs data.Heads
+	pk cipher.PubKey
+	s  *sealer
+}
+
+func (c *cryptHeads) roots(nonce uint64, rs data.Roots) data.Roots {
+	return &cryptRoots{rs: rs, pk: c.pk, nonce: nonce, s: c.s}
+}
+
+func (c *cryptHeads) Roots(nonce uint64) (rs data.Roots, err error) {
+
+	if rs, err = c.hs.Roots(nonce); err != nil {
+		return
+	}
+
+	return c.roots(nonce, rs), nil
+}
+
+func (c *cryptHeads) Add(nonce uint64) (rs data.Roots, err error) {
+
+	if rs, err = c.hs.Add(nonce); err != nil {
+		return
+	}
+
+	return c.roots(nonce, rs), nil
+}
+
+func (c *cryptHeads) Del(nonce uint64) (err error) {
+	return c.hs.Del(nonce)
+}
+
+func (c *cryptHeads) Has(nonce uint64) (ok bool, err error) {
+	return c.hs.Has(nonce)
+}
+
+func (c *cryptHeads) Iterate(iterateFunc data.IterateHeadsFunc) (err error) {
+	return c.hs.Iterate(iterateFunc)
+}
+
+func (c *cryptHeads) Len() (length int) {
+	return c.hs.Len()
+}
+
+type cryptRoots struct {
+	rs    data.Roots
+	pk    cipher.PubKey
+	nonce uint64
+	s     *sealer
+}
+
+// encrypted fields of data.Root
+type sealedRoot struct {
+	Time int64
+	Sig  cipher.Sig
+	Sigs []byte
+}
+
+// additional data of a Root
+func (c *cryptRoots) ad(r *data.Root) (ad []byte) {
+	ad = make([]byte, len(c.pk)+8+8+len(r.Hash))
+	copy(ad, c.pk[:])
+	binary.BigEndian.PutUint64(ad[len(c.pk):], c.nonce)
+	binary.BigEndian.PutUint64(ad[len(c.pk)+8:], r.Seq)
+	copy(ad[len(c.pk)+16:], r.Hash[:])
+	return
+}
+
+// encrypt given Root
+func (c *cryptRoots) seal(r *data.Root) (er *data.Root) {
+
+	var sr = sealedRoot{
+		Time: r.Time,
+		Sig:  r.Sig,
+		Sigs: r.Sigs,
+	}
+
+	er = new(data.Root)
+
+	er.Create = r.Create
+	er.Access = r.Access
+	er.Time = sealedTime
+	er.Seq = r.Seq
+	er.Prev = r.Prev
+	er.Hash = r.Hash
+	er.Sigs = c.s.seal(encoder.Serialize(&sr), c.ad(r))
+
+	return
+}
+
+// decrypt given Root
+func (c *cryptRoots) open(er *data.Root) (r *data.Root, err error) {
+
+	if er.Time != sealedTime {
+		return nil, ErrNotEncrypted
+	}
+
+	var p []byte
+	if p, err = c.s.open(er.Sigs, c.ad(er)); err != nil {
+		return
+	}
+
+	var sr sealedRoot
+	if _, err = encoder.DeserializeRaw(p, &sr); err != nil {
+		return
+	}
+
+	r = new(data.Root)
+
+	r.Create = er.Create
+	r.Access = er.Access
+	r.Time = sr.Time
+	r.Seq = er.Seq
+	r.Prev = er.Prev
+	r.Hash = er.Hash
+	r.Sig = sr.Sig
+	r.Sigs = sr.Sigs
+
+	return
+}
+
+func (c *cryptRoots) iterate(
+	iterateFunc data.IterateRootsFunc,
+) data.IterateRootsFunc {
+
+	return func(er *data.Root) (err error) {
+
+		var r *data.Root
+		if r, err = c.open(er); err != nil {
+			return
+		}
+
+		return iterateFunc(r)
+	}
+
+}
+
+func (c *cryptRoots) Ascend(iterateFunc data.IterateRootsFunc) (err error) {
+	return c.rs.Ascend(c.iterate(iterateFunc))
+}
+
+func (c *cryptRoots) Descend(iterateFunc data.IterateRootsFunc) (err error) {
+	return c.rs.Descend(c.iterate(iterateFunc))
+}
+
+func (c *cryptRoots) Set(r *data.Root) (err error) {
+
+	if err = r.Validate(); err != nil {
+		return
+	}
+
+	var er = c.seal(r)
+
+	if err = c.rs.Set(er); err != nil {
+		return
+	}
+
+	r.Create, r.Access = er.Create, er.Access // "reply"
+	return
+}
+
+func (c *cryptRoots) Put(r *data.Root) (err error) {
+
+	if err = r.Validate(); err != nil {
+		return
+	}
+
+	return c.rs.Put(c.seal(r))
+}
+
+func (c *cryptRoots) Del(seq uint64) (err error) {
+	return c.rs.Del(seq)
+}
+
+func (c *cryptRoots) Get(seq uint64) (r *data.Root, err error) {
+
+	var er *data.Root
+	if er, err = c.rs.Get(seq); err != nil {
+		return
+	}
+
+	return c.open(er)
+}
+
+func (c *cryptRoots) Has(seq uint64) (ok bool, err error) {
+	return c.rs.Has(seq)
+}
+
+func (c *cryptRoots) Len() (length int) {
+	return c.rs.Len()
+}
@@ -0,0 +1,111 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// key related constants
+const (
+	KeySize    int = 32 // AES-256
+	SaltSize   int = 32 // random salt of passphrase
+	Iterations int = 1 << 16
+)
+
+// key related errors
+var (
+	ErrEmptyPassphrase = errors.New("empty passphrase")
+	ErrInvalidKeyFile  = errors.New("invalid key file")
+	ErrEmptySalt       = errors.New("empty salt")
+)
+
+// A Key represents AES-256 key
+type Key [KeySize]byte
+
+// NewKey generates random Key
+func NewKey() (k Key) {
+	if _, err := rand.Read(k[:]); err != nil {
+		panic(err) // crypto/rand is broken
+	}
+	return
+}
+
+// Hex encoded Key
+func (k Key) Hex() string {
+	return hex.EncodeToString(k[:])
+}
+
+// NewSalt generates random salt for
+// the KeyFromPassphrase
+func NewSalt() (salt []byte) {
+	salt = make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		panic(err) // crypto/rand is broken
+	}
+	return
+}
+
+// KeyFromPassphrase derives Key from given passphrase
+// and salt using PBKDF2 with HMAC-SHA256. The salt
+// should be random (see NewSalt) and should be kept
+// with encrypted data. The NewDBPassphrase keeps the
+// salt in a DB
+func KeyFromPassphrase(passphrase string, salt []byte) (k Key, err error) {
+
+	if passphrase == "" {
+		err = ErrEmptyPassphrase
+		return
+	}
+
+	if len(salt) == 0 {
+		err = ErrEmptySalt
+		return
+	}
+
+	copy(k[:], pbkdf2.Key([]byte(passphrase), salt, Iterations, KeySize,
+		sha256.New))
+	return
+}
+
+// LoadKeyFile loads Key from file with given name.
+// The file should contain hex encoded Key (see Hex
+// method of the Key) or raw 32 bytes. Leading and
+// trailing white spaces of hex encoded Key are ignored
+func LoadKeyFile(name string) (k Key, err error) {
+
+	var p []byte
+	if p, err = ioutil.ReadFile(name); err != nil {
+		return
+	}
+
+	if len(p) == KeySize {
+		copy(k[:], p)
+		return
+	}
+
+	p = bytes.TrimSpace(p)
+
+	if len(p) != hex.EncodedLen(KeySize) {
+		err = ErrInvalidKeyFile
+		return
+	}
+
+	if _, err = hex.Decode(k[:], p); err != nil {
+		err = ErrInvalidKeyFile
+	}
+
+	return
+}
+
+// SaveKeyFile saves hex encoded Key to file
+// with given name. The file can be loaded
+// by the LoadKeyFile
+func SaveKeyFile(name string, k Key) (err error) {
+	return ioutil.WriteFile(name, []byte(k.Hex()+"\n"), 0600)
+}
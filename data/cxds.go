@@ -87,3 +87,21 @@ type CXDS interface {
 	// Close the CXDS
 	Close() (err error)
 }
+
+// An Object is value with its key and rc. The Object
+// used to put many objects at once (see Putter)
+type Object struct {
+	Key cipher.SHA256 // key (hash) of the value
+	Val []byte        // the value
+	RC  uint32        // references counter
+}
+
+// A Putter is a CXDS that can put many objects in
+// one transaction. The Put creates objects or replaces
+// rc of existing objects. The rc can be zero. The
+// CopyCXDS uses the Putter if dst CXDS implements it.
+// The boltdb based CXDS (see data/cxds package)
+// implements the Putter
+type Putter interface {
+	Put(objs []Object) (err error)
+}
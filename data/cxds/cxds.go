@@ -17,7 +17,12 @@ import (
 )
 
 // Version of the CXDS API and data representation
-const Version int = 2 // previous is 1
+const Version int = 3 // previous is 2
+
+// the version 2 has no compressed objects, and
+// a DB of the version migrates to the Version
+// when it's opened (see NewDriveCXDSCompressed)
+const rawVersion int = 2
 
 // comon errors
 var (
@@ -30,20 +35,40 @@ var (
 	ErrMissingVersion = errors.New("missing version in meta")
 	ErrOldVersion     = errors.New("db file of old version")      // cxodbfix
 	ErrNewVersion     = errors.New("db file newer then this CXO") // go get
+
+	ErrUnknownCodec = errors.New("unknown codec of stored object")
+)
+
+// the highest bit of the rc of a stored object
+// means that the object is encoded (compressed),
+// and the first byte of the stored value is codec;
+// thus, objects stored raw and compressed objects
+// can be mixed in a DB
+const encodedBit uint32 = 1 << 31
+
+// codecs of stored objects
+const (
+	deflateCodec byte = 1 // DEFLATE
 )
 
 //
 func getRefsCount(val []byte) (rc uint32) {
-	rc = binary.BigEndian.Uint32(val)
+	rc = binary.BigEndian.Uint32(val) &^ encodedBit
 	return
 }
 
-//
+// set rc keeping the encodedBit
 func setRefsCount(val []byte, rc uint32) {
-	binary.BigEndian.PutUint32(val, rc)
+	var eb = binary.BigEndian.Uint32(val) & encodedBit
+	binary.BigEndian.PutUint32(val, rc|eb)
 	return
 }
 
+// is stored object encoded
+func isEncoded(val []byte) bool {
+	return binary.BigEndian.Uint32(val)&encodedBit != 0
+}
+
 func getHash(val []byte) (key cipher.SHA256) {
 	return cipher.SumSHA256(val)
 }
@@ -56,7 +81,7 @@ func versionBytes() []byte {
 
 func encodeUint32(u uint32) (ub []byte) {
 	ub = make([]byte, 4)
-	binary.BigEndian.PutUint32(ub, uint32(Version))
+	binary.BigEndian.PutUint32(ub, u)
 	return
 }
 
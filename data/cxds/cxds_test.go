@@ -1,9 +1,14 @@
 package cxds
 
 import (
+	"bytes"
 	"os"
 	"testing"
 
+	"github.com/boltdb/bolt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
 	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/data/tests"
 )
@@ -31,6 +36,174 @@ func TestNewDriveCXDS(t *testing.T) {
 	defer ds.Close()
 }
 
+func testDriveDSCompressed(t *testing.T) (ds data.CXDS) {
+	var err error
+	if ds, err = NewDriveCXDSCompressed(testFileName, 0); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestNewDriveCXDSCompressed(t *testing.T) {
+	// NewDriveCXDSCompressed(fileName string, minSize int) (data.CXDS, error)
+
+	defer os.Remove(testFileName)
+
+	var (
+		ds    = testDriveDSCompressed(t)
+		val   = bytes.Repeat([]byte("compress me "), 100)
+		key   = cipher.SumSHA256(val)
+		small = []byte("x")
+		skey  = cipher.SumSHA256(small)
+	)
+
+	for k, v := range map[cipher.SHA256][]byte{key: val, skey: small} {
+		if _, err := ds.Set(k, v, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, vol := ds.Volume(); vol >= len(val) {
+		t.Error("not compressed", vol)
+	}
+
+	if err := ds.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// read mixed objects without compression
+
+	ds = testDriveDS(t)
+	defer ds.Close()
+
+	var raw = []byte("raw value")
+
+	if _, err := ds.Set(cipher.SumSHA256(raw), raw, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, rc, err := ds.Get(key, 1); err != nil {
+		t.Fatal(err)
+	} else if rc != 2 {
+		t.Error("wrong rc", rc)
+	} else if bytes.Equal(got, val) == false {
+		t.Error("wrong value")
+	}
+
+	var n int
+	err := ds.Iterate(func(k cipher.SHA256, _ uint32, v []byte) (_ error) {
+		if cipher.SumSHA256(v) != k {
+			t.Error("wrong value of", k.Hex()[:7])
+		}
+		n++
+		return
+	})
+
+	if err != nil {
+		t.Error(err)
+	} else if n != 3 {
+		t.Error("wrong number of objects", n)
+	}
+}
+
+// set version of DB file and put
+// given raw stored value (if any)
+func testSetVersion(t *testing.T, vers int, key cipher.SHA256, got []byte) {
+
+	b, err := bolt.Open(testFileName, 0644, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	err = b.Update(func(tx *bolt.Tx) (err error) {
+		if got != nil {
+			err = tx.Bucket(objsBucket).Put(key[:], got)
+			if err != nil {
+				return
+			}
+		}
+		return tx.Bucket(metaBucket).Put(versionKey,
+			encodeUint32(uint32(vers)))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewDriveCXDS_migrate(t *testing.T) {
+
+	defer os.Remove(testFileName)
+
+	var (
+		ds  = testDriveDS(t)
+		val = []byte("value")
+		key = cipher.SumSHA256(val)
+	)
+
+	if _, err := ds.Set(key, val, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ds.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// version 2 -> Version
+
+	testSetVersion(t, rawVersion, key, nil)
+
+	ds = testDriveDS(t)
+
+	if got, rc, err := ds.Get(key, 0); err != nil {
+		t.Fatal(err)
+	} else if rc != 2 {
+		t.Error("wrong rc", rc)
+	} else if bytes.Equal(got, val) == false {
+		t.Error("wrong value")
+	}
+
+	if err := ds.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	testSetVersion(t, Version, cipher.SHA256{}, nil) // the same
+
+	ds = testDriveDS(t)
+
+	if err := ds.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// rc of version 2 overlaps the encodedBit
+
+	var big = make([]byte, 4+len(val))
+	setRefsCount(big, encodedBit)
+	copy(big[4:], val)
+
+	testSetVersion(t, rawVersion, key, big)
+
+	if _, err := NewDriveCXDS(testFileName); err != ErrOldVersion {
+		t.Error("wrong error", err)
+	}
+
+	// version 1
+
+	testSetVersion(t, 1, cipher.SHA256{}, nil)
+
+	if _, err := NewDriveCXDS(testFileName); err != ErrOldVersion {
+		t.Error("wrong error", err)
+	}
+
+	// future version
+
+	testSetVersion(t, Version+1, cipher.SHA256{}, nil)
+
+	if _, err := NewDriveCXDS(testFileName); err != ErrNewVersion {
+		t.Error("wrong error", err)
+	}
+}
+
 func TestNewMemoryCXDS(t *testing.T) {
 	// NewMemoryCXDS() (ds *MemoryCXDS, err error)
 
@@ -51,6 +224,13 @@ func TestCXDS_Get(t *testing.T) {
 		defer ds.Close()
 		tests.CXDSGet(t, ds)
 	})
+
+	t.Run("drive compressed", func(t *testing.T) {
+		ds := testDriveDSCompressed(t)
+		defer os.Remove(testFileName)
+		defer ds.Close()
+		tests.CXDSGet(t, ds)
+	})
 }
 
 func TestCXDS_Set(t *testing.T) {
@@ -66,6 +246,13 @@ func TestCXDS_Set(t *testing.T) {
 		defer ds.Close()
 		tests.CXDSSet(t, ds)
 	})
+
+	t.Run("drive compressed", func(t *testing.T) {
+		ds := testDriveDSCompressed(t)
+		defer os.Remove(testFileName)
+		defer ds.Close()
+		tests.CXDSSet(t, ds)
+	})
 }
 
 func TestCXDS_Inc(t *testing.T) {
@@ -81,6 +268,13 @@ func TestCXDS_Inc(t *testing.T) {
 		defer ds.Close()
 		tests.CXDSInc(t, ds)
 	})
+
+	t.Run("drive compressed", func(t *testing.T) {
+		ds := testDriveDSCompressed(t)
+		defer os.Remove(testFileName)
+		defer ds.Close()
+		tests.CXDSInc(t, ds)
+	})
 }
 
 func TestCXDS_Close(t *testing.T) {
@@ -97,3 +291,34 @@ func TestCXDS_Close(t *testing.T) {
 		tests.CXDSClose(t, ds)
 	})
 }
+
+func TestCopyCXDS(t *testing.T) {
+	// data.CopyCXDS(dst, src data.CXDS) (err error)
+
+	t.Run("memory to drive", func(t *testing.T) {
+		ds := testDriveDS(t)
+		defer os.Remove(testFileName)
+		defer ds.Close()
+		tests.CopyCXDS(t, ds, NewMemoryCXDS())
+	})
+
+	t.Run("drive to memory", func(t *testing.T) {
+		ds := testDriveDS(t)
+		defer os.Remove(testFileName)
+		defer ds.Close()
+		tests.CopyCXDS(t, NewMemoryCXDS(), ds)
+	})
+
+	t.Run("drive to drive", func(t *testing.T) {
+		ds := testDriveDS(t)
+		defer os.Remove(testFileName)
+		defer ds.Close()
+		dst, err := NewDriveCXDSCompressed(testFileName+".dst", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(testFileName + ".dst")
+		defer dst.Close()
+		tests.CopyCXDS(t, dst, ds)
+	})
+}
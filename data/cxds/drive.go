@@ -1,8 +1,11 @@
 package cxds
 
 import (
+	"bytes"
+	"compress/flate"
 	"encoding/binary"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"sync"
 	"time"
@@ -36,6 +39,8 @@ type driveCXDS struct {
 	volumeAll  int // volume of all objects
 	volumeUsed int // volume of used objects
 
+	minSize int // compress objects not shorter, -1 disables
+
 	b *bolt.DB
 }
 
@@ -44,6 +49,27 @@ type driveCXDS struct {
 // database is boltdb (github.com/boltdb/bolt).
 // E.g. this stores data on disk
 func NewDriveCXDS(fileName string) (ds data.CXDS, err error) {
+	return NewDriveCXDSCompressed(fileName, -1)
+}
+
+// NewDriveCXDSCompressed is the same as the NewDriveCXDS,
+// but the CXDS compresses (DEFLATE) new objects that are
+// not shorter then given minSize. An object is stored
+// compressed only if it's shorter then raw one. Negative
+// minSize disables the compression. The CXDS reads raw
+// and compressed objects regardless the minSize. Thus,
+// the compression can be turned on and off for existing
+// DB. The compression is transparent: values and keys
+// (hashes) of objects are not affected. Volume of
+// objects (see Volume method) is volume of stored
+// values (compressed or not)
+func NewDriveCXDSCompressed(
+	fileName string, // : path to DB file
+	minSize int, //     : min size of objects to compress
+) (
+	ds data.CXDS, //    : the CXDS
+	err error, //       : an error
+) {
 
 	var created bool // true if the file does not exist
 
@@ -110,6 +136,10 @@ func NewDriveCXDS(fileName string) (ds data.CXDS, err error) {
 
 			switch vers := int(binary.BigEndian.Uint32(vb)); {
 			case vers == Version: // ok
+			case vers == rawVersion:
+				if err = migrateRaw(tx, info); err != nil {
+					return
+				}
 			case vers < Version:
 				return ErrOldVersion
 			case vers > Version:
@@ -127,7 +157,7 @@ func NewDriveCXDS(fileName string) (ds data.CXDS, err error) {
 		return
 	}
 
-	var dr = &driveCXDS{b: b} // wrap
+	var dr = &driveCXDS{b: b, minSize: minSize} // wrap
 
 	// stat
 
@@ -145,6 +175,29 @@ func NewDriveCXDS(fileName string) (ds data.CXDS, err error) {
 	return
 }
 
+// migrate DB of the rawVersion to the Version; all
+// objects of the rawVersion are raw, and the migration
+// checks that rc of all the objects doesn't overlap
+// the encodedBit
+func migrateRaw(tx *bolt.Tx, info *bolt.Bucket) (err error) {
+
+	var o = tx.Bucket(objsBucket)
+
+	if o != nil {
+		err = o.ForEach(func(_, got []byte) (_ error) {
+			if len(got) < 4 || isEncoded(got) == true {
+				return ErrOldVersion // can't migrate
+			}
+			return
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	return info.Put(versionKey, versionBytes())
+}
+
 func (d *driveCXDS) loadStat() (err error) {
 
 	d.mx.Lock()
@@ -262,7 +315,7 @@ func (d *driveCXDS) av(rc, nrc uint32, vol int) {
 func (d *driveCXDS) incr(
 	o *bolt.Bucket, // : objects
 	key []byte, //     : key[:]
-	val []byte, //     : value without leading rc (4 bytes)
+	got []byte, //     : stored value with leading rc (4 bytes)
 	rc uint32, //      : existing rc
 	inc int, //        : change the rc
 ) (
@@ -285,13 +338,70 @@ func (d *driveCXDS) incr(
 		nrc = rc + uint32(inc) // increase the rc
 	}
 
-	var repl = make([]byte, 4, 4+len(val))
+	var repl = make([]byte, len(got))
+	copy(repl, got)
 	setRefsCount(repl, nrc)
-	repl = append(repl, val...)
 	err = o.Put(key[:], repl)
 
 	if rc != nrc {
-		d.av(rc, nrc, len(val))
+		d.av(rc, nrc, len(got)-4)
+	}
+
+	return
+}
+
+// encode given value to store, the encoded
+// value starts with rc (4 bytes), that is zero
+func (d *driveCXDS) encode(val []byte) (enc []byte) {
+
+	enc = make([]byte, 4, 4+len(val))
+
+	if d.minSize < 0 || len(val) < d.minSize {
+		return append(enc, val...) // raw
+	}
+
+	var buf bytes.Buffer
+
+	buf.Write(enc)
+	buf.WriteByte(deflateCodec)
+
+	var fw, err = flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		panic(err) // never happens (valid level)
+	}
+
+	fw.Write(val) // writing to bytes.Buffer never fails
+	fw.Close()
+
+	if buf.Len() >= len(enc)+len(val) {
+		return append(enc, val...) // not shorter
+	}
+
+	enc = buf.Bytes()
+	binary.BigEndian.PutUint32(enc, encodedBit)
+	return
+}
+
+// decode stored value (with leading rc),
+// the result is a copy
+func decode(got []byte) (val []byte, err error) {
+
+	if isEncoded(got) == false {
+		return copySlice(got[4:]), nil // raw
+	}
+
+	if len(got) < 5 {
+		return nil, ErrWrongValueLength
+	}
+
+	switch got[4] {
+	case deflateCodec:
+		var fr = flate.NewReader(bytes.NewReader(got[5:]))
+		if val, err = ioutil.ReadAll(fr); err == nil {
+			err = fr.Close()
+		}
+	default:
+		err = ErrUnknownCodec
 	}
 
 	return
@@ -320,10 +430,12 @@ func (d *driveCXDS) Get(
 		}
 
 		rc = getRefsCount(got)
-		val = make([]byte, len(got)-4)
-		copy(val, got[4:])
 
-		rc, err = d.incr(o, key[:], val, rc, inc)
+		if val, err = decode(got); err != nil {
+			return
+		}
+
+		rc, err = d.incr(o, key[:], got, rc, inc)
 		return
 	}
 
@@ -377,13 +489,61 @@ func (d *driveCXDS) Set(
 		if len(got) == 0 {
 
 			// created
-			d.addAll(len(val))
+			var enc = d.encode(val)
+
+			d.addAll(len(enc) - 4)
 
-			rc, err = d.incr(o, key[:], val, 0, 1)
+			rc, err = d.incr(o, key[:], enc, 0, inc)
 			return
 		}
 
-		rc, err = d.incr(o, key[:], got[4:], getRefsCount(got), inc)
+		rc, err = d.incr(o, key[:], got, getRefsCount(got), inc)
+		return
+	})
+
+	return
+}
+
+// Put objects in one transaction
+// replacing rc of existing objects
+func (d *driveCXDS) Put(objs []data.Object) (err error) {
+
+	for _, obj := range objs {
+		if len(obj.Val) == 0 {
+			return ErrEmptyValue
+		}
+	}
+
+	err = d.b.Update(func(tx *bolt.Tx) (err error) {
+
+		var o = tx.Bucket(objsBucket)
+
+		for _, obj := range objs {
+
+			var got = o.Get(obj.Key[:])
+
+			if len(got) != 0 {
+				var rc = getRefsCount(got)
+				_, err = d.incr(o, obj.Key[:], got, rc, int(obj.RC)-int(rc))
+				if err != nil {
+					return
+				}
+				continue
+			}
+
+			// created
+			var enc = d.encode(obj.Val)
+
+			setRefsCount(enc, obj.RC)
+
+			if err = o.Put(obj.Key[:], enc); err != nil {
+				return
+			}
+
+			d.addAll(len(enc) - 4)
+			d.av(0, obj.RC, len(enc)-4)
+		}
+
 		return
 	})
 
@@ -416,7 +576,7 @@ func (d *driveCXDS) Inc(
 			return // done
 		}
 
-		rc, err = d.incr(o, key[:], got[4:], rc, inc)
+		rc, err = d.incr(o, key[:], got, rc, inc)
 		return
 	}
 
@@ -479,6 +639,7 @@ func (d *driveCXDS) Iterate(iterateFunc data.IterateObjectsFunc) (err error) {
 
 		var (
 			key cipher.SHA256
+			val []byte
 			c   = tx.Bucket(objsBucket).Cursor()
 		)
 
@@ -486,7 +647,15 @@ func (d *driveCXDS) Iterate(iterateFunc data.IterateObjectsFunc) (err error) {
 
 			copy(key[:], k)
 
-			if err = iterateFunc(key, getRefsCount(v), v[4:]); err != nil {
+			if isEncoded(v) == true {
+				if val, err = decode(v); err != nil {
+					return
+				}
+			} else {
+				val = v[4:] // avoid copying
+			}
+
+			if err = iterateFunc(key, getRefsCount(v), val); err != nil {
 				if err == data.ErrStopIteration {
 					err = nil
 				}
@@ -514,6 +683,7 @@ func (d *driveCXDS) IterateDel(
 		var (
 			key cipher.SHA256
 			rc  uint32
+			val []byte
 			c   = tx.Bucket(objsBucket).Cursor()
 			del bool
 		)
@@ -527,7 +697,15 @@ func (d *driveCXDS) IterateDel(
 
 			rc = getRefsCount(v)
 
-			if del, err = iterateFunc(key, rc, v[4:]); err != nil {
+			if isEncoded(v) == true {
+				if val, err = decode(v); err != nil {
+					return
+				}
+			} else {
+				val = v[4:] // avoid copying
+			}
+
+			if del, err = iterateFunc(key, rc, val); err != nil {
 				if err == data.ErrStopIteration {
 					err = nil
 				}
@@ -584,3 +762,29 @@ func copySlice(in []byte) (got []byte) {
 	copy(got, in)
 	return
 }
+
+// Snapshot of the CXDS (see data.Snapshotter).
+// The Snapshot saves statistic of the CXDS first
+func (d *driveCXDS) Snapshot() (s data.Snapshot, err error) {
+
+	if err = d.saveStat(); err != nil {
+		return
+	}
+
+	var tx *bolt.Tx
+	if tx, err = d.b.Begin(false); err != nil {
+		return
+	}
+
+	return boltSnapshot{tx}, nil
+}
+
+// a read-only transaction is a snapshot
+type boltSnapshot struct {
+	*bolt.Tx
+}
+
+// Close the snapshot
+func (b boltSnapshot) Close() (err error) {
+	return b.Rollback()
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"bytes"
 	"errors"
 
 	"github.com/skycoin/skycoin/src/cipher"
@@ -67,6 +68,21 @@ type Root struct {
 
 	Hash cipher.SHA256 // hash of the Root
 	Sig  cipher.Sig    // signature of the Root
+
+	// Sigs is encoded signature container of Root
+	// (see EncodeSigs method of registry.Root), e.g.
+	// signatures of Root of a multisig feed, the Sig
+	// is blank then
+	Sigs []byte
+}
+
+// Root without the Sigs field, for Roots
+// saved before multisig feeds
+type legacyRoot struct {
+	Create, Access, Time int64
+	Seq                  uint64
+	Prev, Hash           cipher.SHA256
+	Sig                  cipher.Sig
 }
 
 // Validate the Root
@@ -83,7 +99,7 @@ func (r *Root) Validate() (err error) {
 		return errors.New("(idxdb.Root.Validate) empty Hash")
 	}
 
-	if r.Sig == (cipher.Sig{}) {
+	if r.Sig == (cipher.Sig{}) && len(r.Sigs) == 0 {
 		return errors.New("(idxdb.Root.Validate) empty Sig")
 	}
 	if r.Time == 0 {
@@ -92,6 +108,19 @@ func (r *Root) Validate() (err error) {
 	return
 }
 
+// Equal returns true if given Root is
+// equal to this one
+func (r *Root) Equal(x *Root) bool {
+	return r.Create == x.Create &&
+		r.Access == x.Access &&
+		r.Time == x.Time &&
+		r.Seq == x.Seq &&
+		r.Prev == x.Prev &&
+		r.Hash == x.Hash &&
+		r.Sig == x.Sig &&
+		bytes.Equal(r.Sigs, x.Sigs)
+}
+
 // Encode the Root
 func (r *Root) Encode() (p []byte) {
 	return encoder.Serialize(r)
@@ -101,5 +130,14 @@ func (r *Root) Encode() (p []byte) {
 func (r *Root) Decode(p []byte) error {
 	_, err := encoder.DeserializeRaw(p, r)
 
+	if err != nil {
+		var lr legacyRoot
+		if _, lerr := encoder.DeserializeRaw(p, &lr); lerr == nil {
+			*r = Root{lr.Create, lr.Access, lr.Time, lr.Seq, lr.Prev,
+				lr.Hash, lr.Sig, nil}
+			err = nil
+		}
+	}
+
 	return err
 }
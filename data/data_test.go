@@ -46,7 +46,7 @@ func TestRoot_Decode(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if *x != *r {
+	if x.Equal(r) == false {
 		t.Error("wrong")
 	}
 
@@ -56,6 +56,36 @@ func TestRoot_Decode(t *testing.T) {
 
 }
 
+func TestRoot_Decode_legacy(t *testing.T) {
+
+	r := testRoot("ha-ha")
+
+	// encoded before the Sigs field
+	p := encoder.Serialize(legacyRoot{r.Create, r.Access, r.Time, r.Seq,
+		r.Prev, r.Hash, r.Sig})
+
+	x := new(Root)
+	if err := x.Decode(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Equal(r) == false {
+		t.Error("wrong")
+	}
+
+	// multisig
+	r.Sigs = []byte("signatures")
+
+	if err := x.Decode(r.Encode()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Equal(r) == false {
+		t.Error("wrong")
+	}
+
+}
+
 func TestRoot_Validate(t *testing.T) {
 
 	r := testRoot("seed")
@@ -8,6 +8,12 @@ import (
 // iterating over all feeds IdxDB contains
 type IterateFeedsFunc func(cipher.PubKey) error
 
+// An IterateMetaFunc used to iterate over meta
+// information of a feed. The key and the val are
+// valid during the iteration only and must not
+// be modified
+type IterateMetaFunc func(key, val []byte) (err error)
+
 // A Feeds represents bucket of feeds
 type Feeds interface {
 	// Add feed. Adding a feed twice or
@@ -34,6 +40,24 @@ type Feeds interface {
 
 	// Len is number of feeds stroed
 	Len() (length int)
+
+	// GetMeta returns meta information of a feed
+	// by given key. It returns ErrNoSuchFeed if the
+	// feed doesn't exist and ErrNotFound if there
+	// is not meta information with given key
+	GetMeta(pk cipher.PubKey, key []byte) (val []byte, err error)
+	// SetMeta sets meta information of a feed. Use
+	// nil value to delete. The SetMeta returns
+	// ErrNoSuchFeed if the feed doesn't exist.
+	// Meta information of a feed deleted with
+	// the feed
+	SetMeta(pk cipher.PubKey, key, val []byte) (err error)
+	// IterateMeta iterates over all meta information
+	// of a feed. It returns ErrNoSuchFeed if the feed
+	// doesn't exist. Use ErrStopIteration to stop the
+	// iteration. The meta information can't be changed
+	// inside the IterateMeta
+	IterateMeta(pk cipher.PubKey, iterateFunc IterateMetaFunc) (err error)
 }
 
 // An IterateHeadsFunc used to iterate over
@@ -95,6 +119,11 @@ type Roots interface {
 	// will be changed to saved. But Access field
 	// of saved Root will be changed to now
 	Set(r *Root) (err error)
+	// Put saves given Root as is, including Create
+	// and Access fields. If a Root with the same seq
+	// already exists, then the Put replaces it. The
+	// Put used to copy Root objects between IdxDBs
+	Put(r *Root) (err error)
 
 	// Del Root by seq number. The Del never returns
 	// ErrNotFound if Root doesn't exist
@@ -121,3 +150,15 @@ type IdxDB interface {
 	Tx(func(Feeds) error) error // transaction
 	Close() error               // close the IdxDB
 }
+
+// A Metadata is an IdxDB that keeps meta information
+// of the DB, that is not related to feeds. For example,
+// the data/crypt package keeps salt and check value of
+// key of encrypted DB. The GetMeta returns ErrNotFound
+// if meta information with given key doesn't exist. The
+// IdxDB implementations of the data/idxdb package
+// implement the Metadata
+type Metadata interface {
+	GetMeta(key []byte) (val []byte, err error) // get
+	SetMeta(key, val []byte) (err error)        // set or replace
+}
@@ -14,7 +14,9 @@ import (
 
 var (
 	feedsBucket = []byte("f")       // feeds
+	feedsMeta   = []byte("x")       // meta information of feeds
 	metaBucket  = []byte("m")       // meta information
+	dbMeta      = []byte("d")       // meta information of the DB
 	versionKey  = []byte("version") // encoded version in the meta bucket
 )
 
@@ -83,7 +85,15 @@ func NewDriveIdxDB(fileName string) (idx data.IdxDB, err error) {
 
 		}
 
-		_, err = tx.CreateBucketIfNotExists(feedsBucket)
+		if _, err = tx.CreateBucketIfNotExists(feedsBucket); err != nil {
+			return
+		}
+
+		if _, err = tx.CreateBucketIfNotExists(feedsMeta); err != nil {
+			return
+		}
+
+		_, err = tx.CreateBucketIfNotExists(dbMeta)
 		return
 	})
 
@@ -99,7 +109,33 @@ func NewDriveIdxDB(fileName string) (idx data.IdxDB, err error) {
 // Tx performs ACID-transaction
 func (d *driveDB) Tx(txFunc func(feeds data.Feeds) (err error)) (err error) {
 	return d.b.Update(func(tx *bolt.Tx) (err error) {
-		return txFunc(&driveFeeds{tx.Bucket(feedsBucket)})
+		return txFunc(&driveFeeds{
+			bk:   tx.Bucket(feedsBucket),
+			meta: tx.Bucket(feedsMeta),
+		})
+	})
+}
+
+// GetMeta returns meta information of the DB
+// (see data.Metadata)
+func (d *driveDB) GetMeta(key []byte) (val []byte, err error) {
+	err = d.b.View(func(tx *bolt.Tx) (_ error) {
+		var got = tx.Bucket(dbMeta).Get(key)
+		if got == nil {
+			return data.ErrNotFound
+		}
+		val = make([]byte, len(got))
+		copy(val, got)
+		return
+	})
+	return
+}
+
+// SetMeta sets meta information of the DB
+// (see data.Metadata)
+func (d *driveDB) SetMeta(key, val []byte) (err error) {
+	return d.b.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(dbMeta).Put(key, val)
 	})
 }
 
@@ -109,7 +145,8 @@ func (d *driveDB) Close() (err error) {
 }
 
 type driveFeeds struct {
-	bk *bolt.Bucket
+	bk   *bolt.Bucket
+	meta *bolt.Bucket
 }
 
 // Add feed or does nothing if its already exists
@@ -129,6 +166,12 @@ func (d *driveFeeds) Del(pk cipher.PubKey) (err error) {
 
 	}
 
+	if d.meta.Bucket(pk[:]) != nil {
+		if err = d.meta.DeleteBucket(pk[:]); err != nil {
+			return
+		}
+	}
+
 	return d.bk.DeleteBucket(pk[:])
 }
 
@@ -182,6 +225,81 @@ func (d *driveFeeds) Len() (length int) {
 	return d.bk.Stats().BucketN - 1
 }
 
+// GetMeta returns meta information of a feed
+func (d *driveFeeds) GetMeta(pk cipher.PubKey, key []byte) (
+	val []byte,
+	err error,
+) {
+
+	if d.bk.Bucket(pk[:]) == nil {
+		return nil, data.ErrNoSuchFeed
+	}
+
+	var mb *bolt.Bucket
+	if mb = d.meta.Bucket(pk[:]); mb == nil {
+		return nil, data.ErrNotFound
+	}
+
+	var got []byte
+	if got = mb.Get(key); got == nil {
+		return nil, data.ErrNotFound
+	}
+
+	val = make([]byte, len(got)) // the got is valid during the Tx only
+	copy(val, got)
+	return
+}
+
+// SetMeta sets or deletes meta information of a feed
+func (d *driveFeeds) SetMeta(pk cipher.PubKey, key, val []byte) (err error) {
+
+	if d.bk.Bucket(pk[:]) == nil {
+		return data.ErrNoSuchFeed
+	}
+
+	if val == nil {
+		if mb := d.meta.Bucket(pk[:]); mb != nil {
+			err = mb.Delete(key)
+		}
+		return
+	}
+
+	var mb *bolt.Bucket
+	if mb, err = d.meta.CreateBucketIfNotExists(pk[:]); err != nil {
+		return
+	}
+
+	return mb.Put(key, val)
+}
+
+// IterateMeta iterates over meta information of a feed
+func (d *driveFeeds) IterateMeta(
+	pk cipher.PubKey,
+	iterateFunc data.IterateMetaFunc,
+) (
+	err error,
+) {
+
+	if d.bk.Bucket(pk[:]) == nil {
+		return data.ErrNoSuchFeed
+	}
+
+	var mb *bolt.Bucket
+	if mb = d.meta.Bucket(pk[:]); mb == nil {
+		return // no meta information
+	}
+
+	err = mb.ForEach(func(key, val []byte) error {
+		return iterateFunc(key, val)
+	})
+
+	if err == data.ErrStopIteration {
+		err = nil
+	}
+
+	return
+}
+
 type driveHeads struct {
 	bk *bolt.Bucket
 }
@@ -356,6 +474,16 @@ func (d *driveRoots) Set(r *data.Root) (err error) {
 	return d.bk.Put(seqb, nr.Encode())
 }
 
+// Put Root object as is
+func (d *driveRoots) Put(r *data.Root) (err error) {
+
+	if err = r.Validate(); err != nil {
+		return
+	}
+
+	return d.bk.Put(utob(r.Seq), r.Encode())
+}
+
 // Del deletes Root object by seq
 func (d *driveRoots) Del(seq uint64) (err error) {
 	return d.bk.Delete(utob(seq))
@@ -409,3 +537,24 @@ func utob(u uint64) (p []byte) {
 	binary.BigEndian.PutUint64(p, u)
 	return
 }
+
+// Snapshot of the IdxDB (see data.Snapshotter)
+func (d *driveDB) Snapshot() (s data.Snapshot, err error) {
+
+	var tx *bolt.Tx
+	if tx, err = d.b.Begin(false); err != nil {
+		return
+	}
+
+	return boltSnapshot{tx}, nil
+}
+
+// a read-only transaction is a snapshot
+type boltSnapshot struct {
+	*bolt.Tx
+}
+
+// Close the snapshot
+func (b boltSnapshot) Close() (err error) {
+	return b.Rollback()
+}
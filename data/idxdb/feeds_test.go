@@ -76,3 +76,16 @@ func TestFeeds_Heads(t *testing.T) {
 	})
 
 }
+
+func TestFeeds_Meta(t *testing.T) {
+	// GetMeta(cipher.PubKey, []byte) ([]byte, error)
+	// SetMeta(cipher.PubKey, []byte, []byte) error
+
+	t.Run("drive", func(t *testing.T) {
+		idx := testNewDriveIdxDB(t)
+		defer os.Remove(testFileName)
+		defer idx.Close()
+		tests.FeedsMeta(t, idx)
+	})
+
+}
@@ -41,3 +41,66 @@ func TestIdxDB_Close(t *testing.T) {
 	})
 
 }
+
+func TestCopyIdxDB(t *testing.T) {
+	// data.CopyIdxDB(dst, src data.IdxDB) (err error)
+
+	t.Run("memory to drive", func(t *testing.T) {
+		idx := testNewDriveIdxDB(t)
+		defer os.Remove(testFileName)
+		defer idx.Close()
+
+		src := NewMemeoryDB()
+		defer src.Close()
+
+		tests.CopyIdxDB(t, idx, src)
+	})
+
+}
+
+func TestIdxDB_Metadata(t *testing.T) {
+	// GetMeta(key []byte) ([]byte, error)
+	// SetMeta(key, val []byte) error
+
+	defer os.Remove(testFileName)
+
+	var (
+		idx      = testNewDriveIdxDB(t)
+		key, val = []byte("key"), []byte("value")
+	)
+
+	var md, ok = idx.(data.Metadata)
+
+	if ok == false {
+		t.Fatal("doesn't implement data.Metadata")
+	}
+
+	if _, err := md.GetMeta(key); err != data.ErrNotFound {
+		t.Error("wrong error:", err)
+	}
+
+	if err := md.SetMeta(key, val); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	idx = testNewDriveIdxDB(t)
+	defer idx.Close()
+
+	if got, err := idx.(data.Metadata).GetMeta(key); err != nil {
+		t.Error(err)
+	} else if string(got) != string(val) {
+		t.Errorf("wrong value %q", got)
+	}
+
+	var mem = NewMemeoryDB()
+	defer mem.Close()
+
+	if _, ok = mem.(data.Metadata); ok == false {
+		t.Error("memory DB doesn't implement data.Metadata")
+	}
+
+}
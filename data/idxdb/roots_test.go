@@ -52,6 +52,21 @@ func TestRoots_Set(t *testing.T) {
 
 }
 
+func TestRoots_Put(t *testing.T) {
+	// Put(*Root) error
+
+	// TODO (kostyarin): memeory
+
+	t.Run("drive", func(t *testing.T) {
+		idx := testNewDriveIdxDB(t)
+		defer os.Remove(testFileName)
+		defer idx.Close()
+
+		tests.RootsPut(t, idx)
+	})
+
+}
+
 func TestRoots_Del(t *testing.T) {
 	// Del(uint64) error
 
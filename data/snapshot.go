@@ -0,0 +1,30 @@
+package data
+
+import (
+	"io"
+)
+
+// A Snapshot represents consistent read-only state of
+// a CXDS or an IdxDB. The Snapshot doesn't block the
+// CXDS or the IdxDB. The Snapshot must be closed
+// after use
+type Snapshot interface {
+	// Size of the Snapshot in bytes, e.g. number of
+	// bytes the WriteTo method writes
+	Size() (size int64)
+	// WriteTo writes the Snapshot to given writer.
+	// The Snapshot is a valid database file that
+	// can be opened by the CXDS or the IdxDB
+	WriteTo(w io.Writer) (n int64, err error)
+	// Close the Snapshot releasing resources
+	Close() (err error)
+}
+
+// A Snapshotter is a CXDS or an IdxDB that can make
+// its Snapshot. The Snapshotter used to make backups
+// of running databases. The boltdb based CXDS and IdxDB
+// (see data/cxds and data/idxdb packages) implement the
+// Snapshotter, but in-memory implementations don't
+type Snapshotter interface {
+	Snapshot() (s Snapshot, err error)
+}
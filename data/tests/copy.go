@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// CopyCXDS is test case for data.CopyCXDS,
+// the dst must be empty
+func CopyCXDS(t *testing.T, dst, src data.CXDS) {
+
+	var (
+		k1, v1 = testKeyValue("one")
+		k2, v2 = testKeyValue("two")
+		k3, v3 = testKeyValue("three")
+	)
+
+	for _, kv := range []struct {
+		key cipher.SHA256
+		val []byte
+		rc  int
+	}{
+		{k1, v1, 1},
+		{k2, v2, 2},
+		{k3, v3, 1},
+	} {
+		if _, err := src.Set(kv.key, kv.val, kv.rc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := src.Inc(k3, -1); err != nil { // zero rc
+		t.Fatal(err)
+	}
+
+	// already exists with another rc
+	if _, err := dst.Set(k2, v2, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := data.CopyCXDS(dst, src); err != nil {
+		t.Fatal(err)
+	}
+
+	shouldExistInCXDS(t, dst, k1, 1, v1)
+	shouldExistInCXDS(t, dst, k2, 2, v2)
+	shouldExistInCXDS(t, dst, k3, 0, v3)
+
+	// many objects (more then one batch)
+
+	var kvs = make([]struct {
+		key cipher.SHA256
+		val []byte
+	}, 3000)
+
+	for i := range kvs {
+		var kv = &kvs[i]
+		kv.key, kv.val = testKeyValue(fmt.Sprintf("object %d", i))
+		if _, err := src.Set(kv.key, kv.val, 1+i%3); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := data.CopyCXDS(dst, src); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, kv := range kvs {
+		shouldExistInCXDS(t, dst, kv.key, uint32(1+i%3), kv.val)
+	}
+}
+
+// CopyIdxDB is test case for data.CopyIdxDB,
+// the dst must be empty
+func CopyIdxDB(t *testing.T, dst, src data.IdxDB) {
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		r      = newRoot("r", sk)
+		key    = []byte("key")
+		val    = []byte("value")
+	)
+
+	if addFeed(t, src, pk); t.Failed() {
+		return
+	}
+
+	if addRoot(t, src, pk, 1, r); t.Failed() {
+		return
+	}
+
+	err := src.Tx(func(feeds data.Feeds) error {
+		return feeds.SetMeta(pk, key, val)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = data.CopyIdxDB(dst, src); err != nil {
+		t.Fatal(err)
+	}
+
+	err = dst.Tx(func(feeds data.Feeds) (err error) {
+		var got []byte
+		if got, err = feeds.GetMeta(pk, key); err != nil {
+			return
+		}
+		if string(got) != string(val) {
+			t.Errorf("wrong meta %q", got)
+		}
+		var hs data.Heads
+		if hs, err = feeds.Heads(pk); err != nil {
+			return
+		}
+		var rs data.Roots
+		if rs, err = hs.Roots(1); err != nil {
+			return
+		}
+		var x *data.Root
+		if x, err = rs.Get(r.Seq); err != nil {
+			return
+		}
+		if x.Equal(r) == false {
+			t.Error("wrong Root copied")
+		}
+		return
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -349,3 +349,123 @@ func FeedsHeads(t *testing.T, idx data.IdxDB) {
 	})
 
 }
+
+// FeedsMeta is test case for Feeds.GetMeta, Feeds.SetMeta
+// and Feeds.IterateMeta
+func FeedsMeta(t *testing.T, idx data.IdxDB) {
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+		key   = []byte("key")
+		val   = []byte("value")
+	)
+
+	t.Run("no such feed", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (_ error) {
+			if _, err := feeds.GetMeta(pk, key); err != data.ErrNoSuchFeed {
+				t.Error("wrong error:", err)
+			}
+			if err := feeds.SetMeta(pk, key, val); err != data.ErrNoSuchFeed {
+				t.Error("wrong error:", err)
+			}
+			err := feeds.IterateMeta(pk, func(_, _ []byte) (_ error) {
+				return
+			})
+			if err != data.ErrNoSuchFeed {
+				t.Error("wrong error:", err)
+			}
+			return
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("set get", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			if err = feeds.Add(pk); err != nil {
+				return
+			}
+			if _, err = feeds.GetMeta(pk, key); err != data.ErrNotFound {
+				t.Error("wrong error:", err)
+			}
+			if err = feeds.SetMeta(pk, key, val); err != nil {
+				return
+			}
+			var got []byte
+			if got, err = feeds.GetMeta(pk, key); err != nil {
+				return
+			}
+			if string(got) != string(val) {
+				t.Errorf("wrong value %q", got)
+			}
+			return
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("iterate", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			if err = feeds.SetMeta(pk, []byte("another"), val); err != nil {
+				return
+			}
+			var keys []string
+			err = feeds.IterateMeta(pk, func(k, v []byte) (_ error) {
+				if string(v) != string(val) {
+					t.Errorf("wrong value %q", v)
+				}
+				keys = append(keys, string(k))
+				return
+			})
+			if err != nil {
+				return
+			}
+			if len(keys) != 2 || keys[0] != "another" || keys[1] != "key" {
+				t.Error("wrong keys:", keys)
+			}
+			return feeds.SetMeta(pk, []byte("another"), nil)
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			if err = feeds.SetMeta(pk, key, nil); err != nil {
+				return
+			}
+			if _, err = feeds.GetMeta(pk, key); err != data.ErrNotFound {
+				t.Error("wrong error:", err)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("del feed", func(t *testing.T) {
+		err := idx.Tx(func(feeds data.Feeds) (err error) {
+			if err = feeds.SetMeta(pk, key, val); err != nil {
+				return
+			}
+			if err = feeds.Del(pk); err != nil {
+				return
+			}
+			if err = feeds.Add(pk); err != nil {
+				return
+			}
+			if _, err = feeds.GetMeta(pk, key); err != data.ErrNotFound {
+				t.Error("meta information is not removed with feed:", err)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+}
@@ -469,7 +469,7 @@ func RootsSet(t *testing.T, idx data.IdxDB) {
 			if x, err = rs.Get(r.Seq); err != nil {
 				return
 			}
-			if *x != *r {
+			if x.Equal(r) == false {
 				t.Error("wrong")
 			}
 			return
@@ -497,7 +497,7 @@ func RootsSet(t *testing.T, idx data.IdxDB) {
 				return
 			}
 			r.Access = x.Access
-			if *x != *r {
+			if x.Equal(r) == false {
 				t.Error("wrong")
 			}
 			return
@@ -509,6 +509,51 @@ func RootsSet(t *testing.T, idx data.IdxDB) {
 
 }
 
+// RootsPut is test case for Roots.Put
+func RootsPut(t *testing.T, idx data.IdxDB) {
+
+	const nonce = 1
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	if addFeed(t, idx, pk); t.Failed() {
+		return
+	}
+
+	r := newRoot("r", sk) // Create: 111, Access: 222
+
+	for _, name := range []string{"create", "replace"} {
+		t.Run(name, func(t *testing.T) {
+			err := idx.Tx(func(feeds data.Feeds) (err error) {
+				var hs data.Heads
+				if hs, err = feeds.Heads(pk); err != nil {
+					return
+				}
+				var rs data.Roots
+				if rs, err = hs.Add(nonce); err != nil {
+					return
+				}
+				if err = rs.Put(r); err != nil {
+					return
+				}
+				var x *data.Root
+				if x, err = rs.Get(r.Seq); err != nil {
+					return
+				}
+				if x.Equal(r) == false {
+					t.Error("wrong")
+				}
+				return
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+		r.Access++ // replace
+	}
+
+}
+
 // RootsDel is test case for Roots.Del
 func RootsDel(t *testing.T, idx data.IdxDB) {
 
@@ -18,6 +18,11 @@ const (
 	MaxConnections        int           = 1000 * 1000
 	MaxPendingConnections int           = 1000
 	MaxFillingTime        time.Duration = 10 * time.Minute
+	MaxFillingObjects     uint64        = 0 // no limit
+	MaxFillingVolume      uint64        = 0 // no limit
+	MaxRefsDepth          int           = 0 // no limit
+	MaxRefsDegree         int           = 0 // no limit
+	MaxDynamicNesting     int           = 0 // no limit
 	MaxHeads              int           = 10
 	ListenTCP             string        = ":8870"
 	ListenUDP             string        = "" // don't listen
@@ -25,6 +30,11 @@ const (
 	ResponseTimeout       time.Duration = 59 * time.Second
 	Pings                 time.Duration = 118 * time.Second
 	Public                bool          = false
+	Compression           bool          = true
+	LANGroup              string        = "" // disabled
+	LANInterval           time.Duration = 10 * time.Second
+	Light                 bool          = false
+	LightStoreVolume      int           = 16 * 1024 * 1024
 )
 
 // Addresses are discovery addresses
@@ -60,6 +70,24 @@ func (a *Addresses) Set(addr string) error {
 // Root is going to be filled
 type OnRootReceivedFunc func(c *Conn, r *registry.Root) (reject error)
 
+// OnRootValidateFunc represents callback that called
+// when all objects of a Root have been received, but
+// before the Root saved and can be used. The callback
+// can decode objects of the Root using given Pack to
+// check application invariants. The Pack must not be
+// used to change anything. The callback rejects the
+// Root returning an error. In this case, the filling
+// breaks with the error (see OnFillingBreaksFunc) and
+// objects of the Root are not kept. The Conn is
+// connection the Root received from
+type OnRootValidateFunc func(
+	c *Conn,
+	r *registry.Root,
+	pack registry.Pack,
+) (
+	reject error,
+)
+
 // OnRootFilledFunc represents callback that
 // called when new Root filled and can be used.
 // The callback called once per Root.
@@ -74,6 +102,21 @@ type OnRootFilledFunc func(n *Node, r *registry.Root)
 // then the Root can be filled (or can be not).
 type OnFillingBreaksFunc func(n *Node, r *registry.Root, err error)
 
+// OnLimitExceededFunc represents callback that
+// called when a new Root object can't be filled,
+// because its tree exceeds filling limits (see
+// MaxFillingObjects and other limits below). The
+// callback called after the OnFillingBreaks. The
+// Conn is connection the Root received from. A
+// valid Root can't exceed the limits, thus the
+// callback can be used to close the connection
+// or to blacklist the peer
+type OnLimitExceededFunc func(
+	c *Conn,
+	r *registry.Root,
+	err *registry.LimitError,
+)
+
 // OnConnectFunc represents callback that called
 // when a connection created and established. It's
 // possible to terminate connection returning error
@@ -149,6 +192,32 @@ type NetConfig struct {
 	Pings time.Duration
 }
 
+// LANConfig represents configurations of LAN
+// discovery. A Node periodically multicasts its
+// ID, listening addresses and feeds it shares
+// to the Group. And other nodes of the Group add
+// the Node to swarms of the feeds (see JoinSwarm).
+// A Node announces its feeds only if it's public
+// (see Config.Public), but every Node receives
+// announcements of others
+type LANConfig struct {
+	// Group is UDP multicast group address,
+	// for example "239.255.88.70:8870". Blank
+	// string disables LAN discovery
+	Group string
+
+	// Interface is name of network interface
+	// to use. Blank string means system default.
+	// Use loopback interface (e.g. "lo") to find
+	// nodes on the same machine only
+	Interface string
+
+	// Interval of announcements. The Node sends
+	// an announcement after Share and DontShare
+	// too
+	Interval time.Duration
+}
+
 // SwarmConfig defines on how node finds peers, belonging
 // to the same swarm, and how it interacts with them later.
 // Nodes can belong to one swarm if they share the same feed.
@@ -238,10 +307,59 @@ type Config struct {
 	// limit.
 	MaxFillingTime time.Duration
 
+	// MaxFillingObjects is max number of objects of
+	// a Root tree, including the Root. Set it to zero
+	// to disable the limit.
+	MaxFillingObjects uint64
+	// MaxFillingVolume is max total size of objects
+	// of a Root tree in bytes. Set it to zero to
+	// disable the limit.
+	MaxFillingVolume uint64
+	// MaxRefsDepth is max depth of a Refs of a Root
+	// tree. Set it to zero to disable the limit.
+	MaxRefsDepth int
+	// MaxRefsDegree is max degree of a Refs of a Root
+	// tree. Set it to zero to disable the limit.
+	MaxRefsDegree int
+	// MaxDynamicNesting is max number of nested Dynamic
+	// references of a Root tree. Set it to zero to
+	// disable the limit.
+	//
+	// If a Root exceeds one of the limits above, then
+	// the filling breaks with *registry.LimitError. A
+	// valid Root can't exceed the limits; thus, the
+	// error means that the feed or the peer the Root
+	// received from is hostile (see OnLimitExceeded)
+	MaxDynamicNesting int
+
+	// Light turns on light mode. A light Node doesn't
+	// fill Root objects of feeds it subscribed to. It
+	// keeps last Root objects in DB without objects (see
+	// LightRoot method of the Node) and requests objects
+	// of the Root objects on demand from connected peers
+	// (see LightPack method of the Node). The OnRootFilled
+	// callback called when a Root received and accepted.
+	// The light mode is useful for clients with limited
+	// resources
+	Light bool
+
+	// LightStoreVolume is max total size of objects
+	// in bytes a light Node keeps in memory. Least
+	// recently used objects are dropped. Set it to
+	// zero to keep nothing
+	LightStoreVolume int
+
 	// RPC is RPC listening address. Empty string
 	// disables RPC.
 	RPC string
 
+	// Compression enables compression of Root and
+	// Object messages. Peers negotiate compression
+	// during handshake, and the compression is used
+	// only if both peers support it. Hashes of the
+	// objects are not affected
+	Compression bool
+
 	//
 	// Networks
 	//
@@ -277,6 +395,9 @@ type Config struct {
 	// Public is true.
 	Public bool
 
+	// LAN discovery configurations
+	LAN LANConfig
+
 	//
 	// Subscription related callbacks
 	//
@@ -305,6 +426,12 @@ type Config struct {
 	// OnRootReceivedFunc for details.
 	OnRootReceived OnRootReceivedFunc
 
+	// OnRootValidate is a callback that called
+	// when all objects of a Root received but the
+	// Root is not saved yet. See OnRootValidateFunc
+	// for details
+	OnRootValidate OnRootValidateFunc
+
 	// OnRootFilled is a callback that called
 	// when new Root object filled and can be
 	// used. See OnRootFilledFunc for details.
@@ -315,6 +442,12 @@ type Config struct {
 	// used. See OnRootFilledFunc for details.
 	OnFillingBreaks OnFillingBreaksFunc
 
+	// OnLimitExceeded is a callback that called
+	// when tree of a new Root object exceeds the
+	// filling limits. See OnLimitExceededFunc for
+	// details.
+	OnLimitExceeded OnLimitExceededFunc
+
 	// OnPeerAdded
 	OnPeerAdded OnPeerAddedFunc
 
@@ -332,7 +465,10 @@ func NewConfig() (c *Config) {
 	c = new(Config)
 
 	// logger
+	c.Logger = log.NewConfig()
 	c.Logger.Prefix = Prefix
+	c.Logger.Pins = log.No
+	c.Logger.Subsystems = LogSubsystems
 
 	// container
 	c.Config = skyobject.NewConfig()
@@ -341,7 +477,14 @@ func NewConfig() (c *Config) {
 	c.MaxConnections = MaxConnections
 	c.MaxPendingConnections = MaxPendingConnections
 	c.MaxFillingTime = MaxFillingTime
+	c.MaxFillingObjects = MaxFillingObjects
+	c.MaxFillingVolume = MaxFillingVolume
+	c.MaxRefsDepth = MaxRefsDepth
+	c.MaxRefsDegree = MaxRefsDegree
+	c.MaxDynamicNesting = MaxDynamicNesting
 	c.MaxHeads = MaxHeads
+	c.Light = Light
+	c.LightStoreVolume = LightStoreVolume
 
 	c.TCP.Listen = ListenTCP
 	c.TCP.Pings = Pings
@@ -351,8 +494,12 @@ func NewConfig() (c *Config) {
 	c.UDP.ResponseTimeout = ResponseTimeout
 
 	c.RPC = RPCAddress
+	c.Compression = Compression
 	c.Public = Public
 
+	c.LAN.Group = LANGroup
+	c.LAN.Interval = LANInterval
+
 	return
 
 }
@@ -394,16 +541,56 @@ func (c *Config) FromFlags() {
 		c.MaxFillingTime,
 		"max time to fill a Root")
 
+	flag.Uint64Var(&c.MaxFillingObjects,
+		"max-filling-objects",
+		c.MaxFillingObjects,
+		"max number of objects of a Root tree")
+
+	flag.Uint64Var(&c.MaxFillingVolume,
+		"max-filling-volume",
+		c.MaxFillingVolume,
+		"max total size of objects of a Root tree")
+
+	flag.IntVar(&c.MaxRefsDepth,
+		"max-refs-depth",
+		c.MaxRefsDepth,
+		"max depth of a Refs of a Root tree")
+
+	flag.IntVar(&c.MaxRefsDegree,
+		"max-refs-degree",
+		c.MaxRefsDegree,
+		"max degree of a Refs of a Root tree")
+
+	flag.IntVar(&c.MaxDynamicNesting,
+		"max-dynamic-nesting",
+		c.MaxDynamicNesting,
+		"max nesting of Dynamic references of a Root tree")
+
 	flag.IntVar(&c.MaxHeads,
 		"max-heads",
 		c.MaxHeads,
 		"max heads of a feed allowed")
 
+	flag.BoolVar(&c.Light,
+		"light",
+		c.Light,
+		"light mode, don't fill Root objects, get objects on demand")
+
+	flag.IntVar(&c.LightStoreVolume,
+		"light-store-volume",
+		c.LightStoreVolume,
+		"max total size of objects a light node keeps in memory")
+
 	flag.StringVar(&c.RPC,
 		"rpc",
 		c.RPC,
 		"RPC listening address")
 
+	flag.BoolVar(&c.Compression,
+		"compression",
+		c.Compression,
+		"compress Root and Object messages if peer supports it")
+
 	// TCP
 
 	flag.StringVar(&c.TCP.Listen,
@@ -445,13 +632,33 @@ func (c *Config) FromFlags() {
 		c.Public,
 		"public server")
 
+	// LAN
+
+	flag.StringVar(&c.LAN.Group,
+		"lan",
+		c.LAN.Group,
+		"LAN discovery multicast group, e.g. 239.255.88.70:8870")
+
+	flag.StringVar(&c.LAN.Interface,
+		"lan-interface",
+		c.LAN.Interface,
+		"network interface of LAN discovery")
+
+	flag.DurationVar(&c.LAN.Interval,
+		"lan-interval",
+		c.LAN.Interval,
+		"interval of LAN announcements")
+
 }
 
 // Validate configurations. The Validate doesn't
 // validates addresses (TCP, UDP or RPC)
 func (c *Config) Validate() (err error) {
 
-	// nothing to validate in the Logger configurations
+	// logger
+	if err = c.Logger.Validate(); err != nil {
+		return
+	}
 
 	// container
 	if c.Config != nil {
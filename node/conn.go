@@ -13,6 +13,7 @@ import (
 	"github.com/skycoin/net/factory"
 
 	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/node/log"
 	"github.com/skycoin/cxo/node/msg"
 	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
@@ -22,10 +23,12 @@ import (
 type Conn struct {
 	*factory.Connection
 
-	n *Node // back reference
+	n *Node      // back reference
+	l log.Logger // logger with "conn" field
 
 	peerID   cipher.PubKey // peer's pubkey
 	incoming bool          // is incoming or not
+	codec    msg.Codec     // negotiated compression
 
 	initErr error
 	initq   chan struct{}
@@ -55,6 +58,7 @@ func (n *Node) newConnection(
 		Connection: fc,
 
 		n: n,
+		l: n.With("conn", factoryConnStr(fc, isIncoming)),
 
 		incoming: isIncoming,
 
@@ -134,6 +138,27 @@ func (c *Conn) decodeRaw(raw []byte) (seq, rseq uint32, m msg.Msg, err error) {
 	return
 }
 
+// max size of a message is max size of an object
+// and the msgOverhead (encoding and signatures of
+// a Root)
+const msgOverhead = 64 * 1024
+
+// decompress given message if it's compressed
+func (c *Conn) decompress(m msg.Msg) (dm msg.Msg, err error) {
+
+	var cm, ok = m.(*msg.Compressed)
+
+	if ok == false {
+		return m, nil // not compressed
+	}
+
+	if cm.Codec != c.codec || c.codec == msg.NoCompression {
+		return nil, fmt.Errorf("unexpected compression: %s", cm.Codec)
+	}
+
+	return cm.Decompress(c.n.config.MaxObjectSize + msgOverhead)
+}
+
 //
 // info
 //
@@ -239,7 +264,8 @@ func (c *Conn) sendRoot(r *registry.Root) {
 
 		Value: r.Encode(),
 
-		Sig: r.Sig,
+		Sig:  r.Sig,
+		Sigs: r.EncodeSigs(),
 	})
 }
 
@@ -256,8 +282,9 @@ func (c *Conn) sendLastRoot(pk cipher.PubKey) {
 		return
 	}
 
-	c.n.Debugf(MsgSendPin, "[%s] sendLastRoot %s: no Root objects found (%d)",
-		c.String(), pk.Hex()[:7], activeHead)
+	c.l.Debugw(MsgSendPin, "sendLastRoot: no Root objects found",
+		"feed", pk.Hex(),
+		"nonce", activeHead)
 
 }
 
@@ -347,7 +374,8 @@ func (c *Conn) Preview(
 	case *msg.Err:
 		return errors.New("error: " + x.Err)
 	case *msg.Root:
-		if r, err = c.n.c.PreviewRoot(x.Feed, x.Sig, x.Value); err != nil {
+		r, err = c.n.c.PreviewRoot(x.Feed, x.Sig, x.Value, x.Sigs)
+		if err != nil {
 			return
 		}
 	default:
@@ -429,7 +457,7 @@ func (c *Conn) encodeMsg(seq, rseq uint32, m msg.Msg) (raw []byte) {
 }
 
 func (c *Conn) sendMsg(seq, rseq uint32, m msg.Msg) error {
-	c.n.Debugf(MsgSendPin, "[%s] send %d %T", c.String(), rseq, m)
+	c.l.Debugw(MsgSendPin, "send", "rseq", rseq, "type", fmt.Sprintf("%T", m))
 
 	select {
 	case <-c.closeq:
@@ -437,13 +465,22 @@ func (c *Conn) sendMsg(seq, rseq uint32, m msg.Msg) error {
 	default:
 	}
 
+	// Compress Root and Object messages.
+	switch m.(type) {
+	case *msg.Root, *msg.Object:
+		var err error
+		if m, err = msg.Compress(m, c.codec); err != nil {
+			return err
+		}
+	}
+
 	c.sendq <- c.encodeMsg(seq, rseq, m)
 
 	return nil
 }
 
 func (c *Conn) receiveMsg() error {
-	c.n.Debugf(ConnPin, "[%s] receiving", c.String())
+	c.l.Debugw(ConnPin, "receiving")
 
 	receiveq := c.GetChanIn()
 
@@ -469,11 +506,14 @@ func (c *Conn) receiveMsg() error {
 				msgRaw = raw[8:]
 			)
 			msg, err := msg.Decode(msgRaw)
+			if err == nil {
+				msg, err = c.decompress(msg)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to decode message: %s", err)
 			}
 
-			c.n.Debugf(MsgReceivePin, "[%s] receive %T", c.String(), msg)
+			c.l.Debugw(MsgReceivePin, "receive", "type", fmt.Sprintf("%T", msg))
 
 			// Handle message.
 			if rq, ok := c.isResponse(rseq); ok == true {
@@ -523,7 +563,7 @@ func (c *Conn) responseTimeout() (rt time.Duration) {
 
 func (c *Conn) sendRequest(m msg.Msg) (reply msg.Msg, err error) {
 
-	c.n.Debugf(MsgSendPin, "[%s] sendRequest %T", c.String(), m)
+	c.l.Debugw(MsgSendPin, "sendRequest", "type", fmt.Sprintf("%T", m))
 
 	var (
 		tr *time.Timer
@@ -636,8 +676,7 @@ func (c *Conn) handle(seq uint32, m msg.Msg) (err error) {
 // subscribe (with reply)
 func (c *Conn) handleSub(seq uint32, sub *msg.Sub) (_ error) {
 
-	c.n.Debugf(MsgReceivePin, "[%s] handleSub %s",
-		c.String(), sub.Feed.Hex()[:7])
+	c.l.Debugw(MsgReceivePin, "handleSub", "feed", sub.Feed.Hex())
 
 	// don't allow blank
 
@@ -682,8 +721,7 @@ func (c *Conn) handleSub(seq uint32, sub *msg.Sub) (_ error) {
 // unsubscribe (no reply)
 func (c *Conn) handleUnsub(seq uint32, unsub *msg.Unsub) (err error) {
 
-	c.n.Debugf(MsgReceivePin, "[%s] handleUnsub %s",
-		c.String(), unsub.Feed.Hex()[:7])
+	c.l.Debugw(MsgReceivePin, "handleUnsub", "feed", unsub.Feed.Hex())
 
 	if unsub.Feed == (cipher.PubKey{}) {
 		return errors.New("invalid request Unsub blank feed") // fatal
@@ -696,7 +734,7 @@ func (c *Conn) handleUnsub(seq uint32, unsub *msg.Unsub) (err error) {
 // request list of feeds
 func (c *Conn) handleRqList(seq uint32, rq *msg.RqList) (_ error) {
 
-	c.n.Debugf(MsgReceivePin, "[%s] handleRqList", c.String())
+	c.l.Debugw(MsgReceivePin, "handleRqList")
 
 	if c.n.config.Public == false {
 		c.sendErr(seq, ErrNotPublic)
@@ -713,8 +751,10 @@ func (c *Conn) handleRqList(seq uint32, rq *msg.RqList) (_ error) {
 // got Root (preview Root objects are handled by request-responnse, not here)
 func (c *Conn) handleRoot(root *msg.Root) (_ error) {
 
-	c.n.Debugf(MsgReceivePin, "[%s] handleRoot %s/%d/%d",
-		c.String(), root.Feed.Hex()[:7], root.Nonce, root.Seq)
+	c.l.Debugw(MsgReceivePin, "handleRoot",
+		"feed", root.Feed.Hex(),
+		"nonce", root.Nonce,
+		"seq", root.Seq)
 
 	// check seq first (avoid verify-signature for old unwanted Root objects)
 
@@ -737,12 +777,23 @@ func (c *Conn) handleRoot(root *msg.Root) (_ error) {
 
 	}
 
+	// last Root received in light mode
+	if c.n.config.Light == true {
+		if seq, ok := c.n.lr.lastSeq(root.Feed, root.Nonce); ok == true &&
+			seq >= root.Seq {
+			return // we have newer one
+		}
+	}
+
 	var r *registry.Root
 
-	r, err = c.n.c.ReceivedRoot(root.Feed, root.Sig, root.Value)
+	if r, err = c.n.c.ReceivedRoot(root.Feed, root.Sig, root.Value,
+		root.Sigs); err != nil {
 
-	if err != nil {
-		c.n.Printf("[ERR] [%s] received Root error: %s", c.String(), err)
+		c.l.Errorw(err, "received Root error",
+			"feed", root.Feed.Hex(),
+			"nonce", root.Nonce,
+			"seq", root.Seq)
 		return // keep connection ?
 	}
 
@@ -761,8 +812,7 @@ func (c *Conn) handleRoot(root *msg.Root) (_ error) {
 func (c *Conn) handleRqObject(seq uint32, rq *msg.RqObject) {
 	defer c.await.Done()
 
-	c.n.Debugf(MsgReceivePin, "[%s] handleRqObject %s", c.String(),
-		rq.Key.Hex()[:7])
+	c.l.Debugw(MsgReceivePin, "handleRqObject", "key", rq.Key.Hex())
 
 	var (
 		gc = make(chan skyobject.Object, 1)
@@ -779,7 +829,7 @@ func (c *Conn) handleRqObject(seq uint32, rq *msg.RqObject) {
 	//                   only if it is wanted (to think)
 
 	if err := c.n.c.Want(rq.Key, gc, 0); err != nil {
-		c.n.Fatal("DB failure: ", err)
+		c.l.Fatal("DB failure: ", err)
 	}
 	defer c.n.c.Unwant(rq.Key, gc) // to be memory safe
 
@@ -813,8 +863,7 @@ func (c *Conn) handleRqObject(seq uint32, rq *msg.RqObject) {
 
 func (c *Conn) handleRqPreview(seq uint32, rqp *msg.RqPreview) (_ error) {
 
-	c.n.Debugf(MsgReceivePin, "[%s] handleRqPreview %s", c.String(),
-		rqp.Feed.Hex()[:7])
+	c.l.Debugw(MsgReceivePin, "handleRqPreview", "feed", rqp.Feed.Hex())
 
 	var r, err = c.n.c.LastRoot(rqp.Feed, c.n.c.ActiveHead(rqp.Feed))
 
@@ -830,7 +879,8 @@ func (c *Conn) handleRqPreview(seq uint32, rqp *msg.RqPreview) (_ error) {
 
 		Value: r.Encode(),
 
-		Sig: r.Sig,
+		Sig:  r.Sig,
+		Sigs: r.EncodeSigs(),
 	})
 
 	return
@@ -838,8 +888,7 @@ func (c *Conn) handleRqPreview(seq uint32, rqp *msg.RqPreview) (_ error) {
 
 func (c *Conn) handleRqPeers(seq uint32, rqp *msg.RqPeers) error {
 
-	c.n.Debugf(MsgReceivePin, "[%s] handleRqPeers %s", c.String(),
-		rqp.Feed.Hex()[:7])
+	c.l.Debugw(MsgReceivePin, "handleRqPeers", "feed", rqp.Feed.Hex())
 
 	s, ok := c.n.InSwarm(rqp.Feed)
 	if !ok {
@@ -849,8 +898,10 @@ func (c *Conn) handleRqPeers(seq uint32, rqp *msg.RqPeers) error {
 
 	peers := s.peersForExchange(c.PeerID())
 
-	c.n.Debugf(PEXPin, "sending info about %d peers of feed %s to peer %s, addr %s",
-		len(peers), rqp.Feed.Hex()[:8], c.PeerID().Hex()[:8], c.Address())
+	c.l.Debugw(PEXPin, "sending info about peers",
+		"feed", rqp.Feed.Hex(),
+		"peer", c.PeerID().Hex(),
+		"peers", len(peers))
 
 	c.sendMsg(c.nextSeq(), seq, &msg.Peers{
 		Feed: rqp.Feed,
@@ -13,6 +13,8 @@ var (
 	ErrAlreadyHaveConnection   = errors.New("already have connection")
 	ErrInvalidResponse         = errors.New("invalid response")
 	ErrNoConnectionsToFillFrom = errors.New("no connections to fill from")
+	ErrNoConnectionsToGetFrom  = errors.New("no connections to get from")
+	ErrNotLight                = errors.New("not a light node")
 	ErrMaxHeadsLimit           = errors.New("max heads limit")
 	ErrUnsubscribe             = errors.New("unsubscribe")
 	ErrBlankFeed               = errors.New("blank feed")
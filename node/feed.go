@@ -56,6 +56,11 @@ func (n *nodeFeed) receivedRoot(cr connRoot) {
 		return // the connection is not subscribed to the feed
 	}
 
+	if n.node().config.Light == true {
+		n.receivedLightRoot(cr) // don't fill
+		return
+	}
+
 	var nh, ok = n.hs[cr.r.Nonce]
 
 	if ok == false {
@@ -79,7 +84,7 @@ func (n *nodeFeed) receivedRoot(cr connRoot) {
 
 		}
 
-		nh = newNodeHead(n)
+		nh = newNodeHead(n, cr.r.Nonce)
 		n.hs[cr.r.Nonce] = nh
 	}
 
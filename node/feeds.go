@@ -240,7 +240,7 @@ func (n *nodeFeeds) addFeed(pk cipher.PubKey) (added bool) {
 // (handler)
 func (n *nodeFeeds) handleAddFeed(pk cipher.PubKey) {
 
-	n.n.Debug(FeedPin, "handleAddFeed ", pk.Hex()[:7])
+	n.n.Debugw(FeedPin, "handleAddFeed", "feed", pk.Hex())
 
 	var ok bool
 
@@ -275,7 +275,7 @@ func (n *nodeFeeds) delFeed(pk cipher.PubKey) {
 // (handler)
 func (n *nodeFeeds) handleDelFeed(pk cipher.PubKey) {
 
-	n.n.Debug(FeedPin, "handleDelFeed ", pk.Hex()[:7])
+	n.n.Debugw(FeedPin, "handleDelFeed", "feed", pk.Hex())
 
 	var nf, ok = n.fs[pk]
 
@@ -284,6 +284,7 @@ func (n *nodeFeeds) handleDelFeed(pk cipher.PubKey) {
 	}
 
 	nf.close() // close the feed, terminating all internal
+	n.n.lr.delFeed(pk)
 
 	delete(n.fs, pk)
 	n.fl = nil
@@ -303,7 +304,7 @@ func (n *nodeFeeds) addConnFeed(c *Conn, pk cipher.PubKey) {
 // (handler)
 func (n *nodeFeeds) handleAddConnFeed(cf connFeed) {
 
-	n.n.Debugln(FeedPin, "handleAddConnFeed", cf.c.String(), cf.f.Hex()[:7])
+	cf.c.l.Debugw(FeedPin, "handleAddConnFeed", "feed", cf.f.Hex())
 
 	// if the cf.f is blank the this connection is new
 
@@ -337,7 +338,7 @@ func (n *nodeFeeds) delConnFeed(c *Conn, pk cipher.PubKey) {
 // (handler)
 func (n *nodeFeeds) handleDelConnFeed(cf connFeed) {
 
-	n.n.Debugln(FeedPin, "handleDelConnFeed", cf.c.String(), cf.f.Hex()[:7])
+	cf.c.l.Debugw(FeedPin, "handleDelConnFeed", "feed", cf.f.Hex())
 
 	var nf, ok = n.fs[cf.f]
 
@@ -9,7 +9,7 @@ import (
 )
 
 func (c *Conn) handshake() error {
-	c.n.Debugf(ConnHskPin, "[%s] handshake", c.String())
+	c.l.Debugw(ConnHskPin, "handshake")
 
 	if c.incoming == true {
 		return c.acceptHandshake()
@@ -19,7 +19,7 @@ func (c *Conn) handshake() error {
 }
 
 func (c *Conn) performHandshake() error {
-	c.n.Debugf(ConnHskPin, "[%s] perform  handshake", c.String())
+	c.l.Debugw(ConnHskPin, "perform handshake")
 
 	// Send Syn message.
 	var (
@@ -30,6 +30,9 @@ func (c *Conn) performHandshake() error {
 			NodeID:   c.n.idpk,
 		}
 	)
+	if c.n.config.Compression == true {
+		syn.Compression = msg.Codecs
+	}
 	if err := c.sendMsg(seq, 0, syn); err != nil {
 		return err
 	}
@@ -72,7 +75,14 @@ func (c *Conn) performHandshake() error {
 			if _, ok := c.n.hasPeer(x.NodeID); ok {
 				return ErrAlreadyHaveConnection
 			}
+			// Check chosen compression.
+			if x.Compression != msg.NoCompression &&
+				(syn.Compression == nil ||
+					msg.Choose([]msg.Codec{x.Compression}) != x.Compression) {
+				return fmt.Errorf("unexpected compression: %s", x.Compression)
+			}
 			c.peerID = x.NodeID
+			c.codec = x.Compression
 
 		case *msg.Err:
 			return errors.New(x.Err)
@@ -92,7 +102,7 @@ func (c *Conn) performHandshake() error {
 }
 
 func (c *Conn) acceptHandshake() (err error) {
-	c.n.Debugf(ConnHskPin, "[%s] accept handshake", c.String())
+	c.l.Debugw(ConnHskPin, "accept handshake")
 
 	// Check specified response timeout.
 	// TODO: add special parameter for handshake timeout.
@@ -138,7 +148,7 @@ func (c *Conn) acceptHandshake() (err error) {
 				}
 			)
 			if sendErr := c.sendMsg(c.nextSeq(), seq, errMsg); sendErr != nil {
-				c.n.Error(sendErr, "failed to send err message")
+				c.l.Error(sendErr, "failed to send err message")
 			}
 
 			return err
@@ -154,7 +164,7 @@ func (c *Conn) acceptHandshake() (err error) {
 				}
 			)
 			if sendErr := c.sendMsg(c.nextSeq(), seq, errMsg); sendErr != nil {
-				c.n.Error(sendErr, "faield to send err message")
+				c.l.Error(sendErr, "faield to send err message")
 			}
 
 			return err
@@ -164,12 +174,16 @@ func (c *Conn) acceptHandshake() (err error) {
 		ack := &msg.Ack{
 			NodeID: c.n.idpk,
 		}
+		if c.n.config.Compression == true {
+			ack.Compression = msg.Choose(syn.Compression)
+		}
 		if err := c.sendMsg(c.nextSeq(), seq, ack); err != nil {
 			return fmt.Errorf("failed to send ack message: %s", err)
 		}
 
 		// Handshake is complete.
 		c.peerID = syn.NodeID
+		c.codec = ack.Compression
 
 	case <-tm.C:
 		return ErrTimeout
@@ -0,0 +1,146 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func Test_handshake_compression(t *testing.T) {
+
+	for _, tt := range []struct {
+		sender, receiver bool      // compression
+		codec            msg.Codec // negotiated
+	}{
+		{true, true, msg.Deflate},
+		{true, false, msg.NoCompression},
+		{false, true, msg.NoCompression},
+		{false, false, msg.NoCompression},
+	} {
+		t.Run(fmt.Sprintf("%t-%t", tt.sender, tt.receiver), func(t *testing.T) {
+			testHandshakeCompression(t, tt.sender, tt.receiver, tt.codec)
+		})
+	}
+
+}
+
+func testHandshakeCompression(
+	t *testing.T,
+	sender, receiver bool,
+	codec msg.Codec,
+) {
+
+	var (
+		fr, onRootFilled = onRootFilledToChannel(100)
+		sconf            = getTestConfig("sender")
+		rconf            = getTestConfigNotListen("receiver")
+	)
+
+	sconf.Compression = sender
+	rconf.Compression = receiver
+	rconf.OnRootFilled = onRootFilled
+
+	var sn, err = NewNode(sconf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var rn *Node
+	if rn, err = NewNode(rconf); err != nil {
+		t.Fatal(err)
+	}
+	defer rn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, rn.Share(pk))
+
+	var (
+		reg = getTestRegistry()
+		sc  = sn.Container()
+
+		up *skyobject.Unpack
+	)
+
+	if up, err = sc.Unpack(sk, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	var r = new(registry.Root)
+
+	r.Nonce = 9021
+	r.Pub = pk
+
+	// long enough to be compressed
+	var name = strings.Repeat("Alice ", 100)
+
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{name, 19, nil}),
+	)
+
+	if err = sc.Save(up, r); err != nil {
+		t.Fatal(err)
+	}
+
+	// the receiver initiates the handshake
+	var c *Conn
+	if c, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.codec != codec {
+		t.Errorf("wrong codec of initiator: %s, want %s", c.codec, codec)
+	}
+
+	var cs = sn.Connections()
+
+	if len(cs) != 1 {
+		t.Fatal("wrong number of connections:", len(cs))
+	}
+
+	if cs[0].codec != codec {
+		t.Errorf("wrong codec of acceptor: %s, want %s", cs[0].codec, codec)
+	}
+
+	if err = c.Subscribe(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	<-time.After(TM)
+
+	sn.Publish(r)
+
+	var rr *registry.Root
+
+	select {
+	case rr = <-fr:
+	case <-time.After(4 * TM):
+		t.Fatal("slow")
+	}
+
+	if rr.Hash != r.Hash {
+		t.Fatal("wrong Root received")
+	}
+
+	var pack *skyobject.Pack
+	if pack, err = rn.Container().Pack(rr, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	var usr User
+	assertNil(t, rr.Refs[0].Value(pack, &usr))
+
+	if usr.Name != name {
+		t.Error("wrong user received")
+	}
+
+}
@@ -7,14 +7,17 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/node/log"
 	"github.com/skycoin/cxo/node/msg"
 	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
 	"github.com/skycoin/cxo/skyobject/statutil"
 )
 
 // a head
 type nodeHead struct {
-	n *nodeFeed // back reference
+	n *nodeFeed  // back reference
+	l log.Logger // logger with "feed" and "nonce" fields
 
 	delcq chan *Conn    // delete connection
 	rrq   chan connRoot // received roots
@@ -31,11 +34,12 @@ type nodeHead struct {
 	closeq chan struct{}  // terminate
 }
 
-func newNodeHead(nf *nodeFeed) (n *nodeHead) {
+func newNodeHead(nf *nodeFeed, nonce uint64) (n *nodeHead) {
 
 	n = new(nodeHead)
 
 	n.n = nf
+	n.l = nf.node().With("feed", nf.this.Hex(), "nonce", nonce)
 
 	n.delcq = make(chan *Conn)
 	n.rrq = make(chan connRoot)
@@ -215,14 +219,14 @@ func (n *nodeHead) handle() {
 }
 
 func (f *fillHead) handleRequest(key cipher.SHA256) {
-	f.node().Debugln(FillPin, "[fill] handleRequest", key.Hex()[:7])
+	f.l.Debugw(FillPin, "[fill] handleRequest", "key", key.Hex())
 
 	f.rqo.PushBack(key)
 	f.triggerRequest()
 }
 
 func (f *fillHead) handleSuccess(c *Conn) {
-	f.node().Debugln(FillPin, "[fill] handleSuccess", c.String())
+	f.l.Debugw(FillPin, "[fill] handleSuccess", "conn", c.String())
 
 	f.requesting--
 	f.fc.PushBack(c) // push
@@ -230,8 +234,10 @@ func (f *fillHead) handleSuccess(c *Conn) {
 }
 
 func (f *fillHead) handleRequestFailure(fr failedRequest) {
-	f.node().Debugln(FillPin, "[fill] handleRequestFailure", fr.c.String(),
-		fr.key.Hex()[:7])
+	f.l.Debugw(FillPin, "[fill] handleRequestFailure",
+		"conn", fr.c.String(),
+		"key", fr.key.Hex(),
+		"seq", fr.seq)
 
 	f.requesting--
 
@@ -239,7 +245,7 @@ func (f *fillHead) handleRequestFailure(fr failedRequest) {
 	case ErrInvalidResponse:
 
 		// close connections that sends invalid responses
-		f.n.node().Errorf(fr.err, "[%s]", fr.c.Address())
+		f.l.Errorw(fr.err, "[fill] invalid response", "conn", fr.c.String())
 		go fr.c.Close()
 		delete(f.cs, fr.c) // remove connection
 
@@ -265,8 +271,9 @@ func (f *fillHead) handleRequestFailure(fr failedRequest) {
 }
 
 func (f *fillHead) handleReceivedRoot(cr connRoot) {
-	f.node().Debugln(FillPin, "[fill] handleReceivedRoot",
-		cr.c.String(), cr.r.Short())
+	f.l.Debugw(FillPin, "[fill] handleReceivedRoot",
+		"conn", cr.c.String(),
+		"seq", cr.r.Seq)
 
 	// there are a filling Root
 
@@ -335,8 +342,9 @@ func (f *fillHead) maxParallel() (mp int) {
 }
 
 func (f *fillHead) createFiller(cr connRoot) {
-	f.node().Debugln(FillPin, "[fill] createFiller", cr.c.String(),
-		cr.r.Short())
+	f.l.Debugw(FillPin, "[fill] createFiller",
+		"conn", cr.c.String(),
+		"seq", cr.r.Seq)
 
 	// broadcast the Root we are going to fill
 	f.nodeHead.n.fs.broadcastRoot(cr)
@@ -351,6 +359,8 @@ func (f *fillHead) createFiller(cr connRoot) {
 	f.r = cr
 	f.rq = make(chan cipher.SHA256, f.maxParallel())
 	f.f = f.node().c.Fill(cr.r, f.rq, f.maxParallel())
+	f.f.ValidateWith(f.node().rootValidator(cr.c))
+	f.f.LimitWith(f.node().fillingLimits())
 
 	f.rqo = list.New()                   // create list of keys
 	f.fc = f.cs.buildConnsList(cr.r.Seq) // create list of connections
@@ -391,7 +401,9 @@ func (f *fillHead) closeFiller() {
 
 func (f *fillHead) handleFillingResult(err error) {
 
-	f.node().Debugf(FillPin, "handleFillingResult %s: %v", f.r.r.Short(), err)
+	f.l.Debugw(FillPin, "[fill] handleFillingResult",
+		"seq", f.r.r.Seq,
+		"err", err)
 
 	if err == nil {
 		f.node().onRootFilled(f.r.r)     // callback
@@ -399,6 +411,9 @@ func (f *fillHead) handleFillingResult(err error) {
 		f.cs.moveForward(f.r.r.Seq + 1)  // move forward
 	} else {
 		f.node().onFillingBreaks(f.r.r, err) // callback
+		if le, ok := err.(*registry.LimitError); ok == true {
+			f.node().onLimitExceeded(f.r.c, f.r.r, le) // callback
+		}
 	}
 
 	f.closeFiller() // close the filler and wait it's goroutines
@@ -477,8 +492,10 @@ func (f *fillHead) node() *Node {
 func (f *fillHead) request(c *Conn, seq uint64, key cipher.SHA256) {
 	defer f.await.Done()
 
-	f.node().Debugf(FillPin, "[fill] request from [%s] %d %s", c.String(), seq,
-		key.Hex()[:7])
+	f.l.Debugw(FillPin, "[fill] request",
+		"conn", c.String(),
+		"seq", seq,
+		"key", key.Hex())
 
 	var reply, err = c.sendRequest(&msg.RqObject{Key: key})
 
@@ -498,7 +515,7 @@ func (f *fillHead) request(c *Conn, seq uint64, key cipher.SHA256) {
 
 		// incremented by the Want call(s)
 		if _, err := f.node().c.SetWanted(key, x.Value); err != nil {
-			f.node().Fatal("DB failure:", err)
+			f.l.Fatal("DB failure:", err)
 			return
 		}
 
@@ -0,0 +1,328 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/node/log"
+)
+
+// LAN discovery packet is
+//
+//     [magic][signature][encoded lanInfo]
+//
+// the signature is signature of SHA256 hash of the
+// encoded lanInfo, signed by secret key of a Node
+
+var lanMagic = []byte("CXOLAN1") // magic and version
+
+const (
+	lanMaxFeeds  int = 256      // feeds per packet
+	lanMaxPacket int = 64 << 10 // max UDP packet size
+)
+
+// errors of LAN packets
+var (
+	errLANMagic     = errors.New("not a LAN discovery packet")
+	errLANMalformed = errors.New("malformed LAN discovery packet")
+)
+
+// lanInfo is announcement of a Node
+type lanInfo struct {
+	ID    cipher.PubKey   // node ID
+	TCP   string          // TCP listening address
+	UDP   string          // UDP listening address
+	Feeds []cipher.PubKey // shared feeds (or part of them)
+}
+
+func (li *lanInfo) encode(sk cipher.SecKey) (p []byte, err error) {
+
+	var (
+		body = encoder.Serialize(li)
+		sig  cipher.Sig
+	)
+
+	if sig, err = cipher.SignHash(cipher.SumSHA256(body), sk); err != nil {
+		return
+	}
+
+	p = make([]byte, 0, len(lanMagic)+len(sig)+len(body))
+	p = append(p, lanMagic...)
+	p = append(p, sig[:]...)
+	p = append(p, body...)
+	return
+}
+
+func decodeLANInfo(p []byte) (li *lanInfo, err error) {
+
+	if bytes.HasPrefix(p, lanMagic) == false {
+		return nil, errLANMagic
+	}
+
+	p = p[len(lanMagic):]
+
+	var sig cipher.Sig
+
+	if len(p) < len(sig) {
+		return nil, errLANMalformed
+	}
+
+	copy(sig[:], p)
+	p = p[len(sig):]
+
+	li = new(lanInfo)
+
+	if _, err = encoder.DeserializeRaw(p, li); err != nil {
+		return nil, errLANMalformed
+	}
+
+	err = cipher.VerifyPubKeySignedHash(li.ID, sig, cipher.SumSHA256(p))
+	return
+}
+
+// lan discovery
+type lan struct {
+	n *Node
+	l log.Logger // logger with "group" field
+
+	sk    cipher.SecKey
+	group *net.UDPAddr
+	conn  *net.UDPConn
+
+	interval time.Duration
+
+	update chan struct{} // announce now
+	quit   chan struct{}
+	done   chan struct{} // announcing
+	rdone  chan struct{} // receiving
+}
+
+func newLAN(n *Node, conf *LANConfig) (l *lan, err error) {
+
+	l = new(lan)
+	l.n = n
+	l.l = n.With("group", conf.Group)
+
+	if l.sk, err = cipher.SecKeyFromHex(n.id.SecKey); err != nil {
+		return nil, err
+	}
+
+	if l.group, err = net.ResolveUDPAddr("udp", conf.Group); err != nil {
+		return nil, err
+	}
+
+	var ifi *net.Interface
+
+	if conf.Interface != "" {
+		if ifi, err = net.InterfaceByName(conf.Interface); err != nil {
+			return nil, err
+		}
+	}
+
+	if l.conn, err = net.ListenMulticastUDP("udp", ifi, l.group); err != nil {
+		return nil, err
+	}
+
+	if l.interval = conf.Interval; l.interval <= 0 {
+		l.interval = LANInterval
+	}
+
+	l.update = make(chan struct{}, 1)
+	l.quit = make(chan struct{})
+	l.done = make(chan struct{})
+	l.rdone = make(chan struct{})
+
+	go l.receiving()
+	go l.announcing()
+
+	return
+}
+
+// trigger announcement
+func (l *lan) announce() {
+	select {
+	case l.update <- struct{}{}:
+	default:
+	}
+}
+
+func (l *lan) announcing() {
+	defer close(l.done)
+
+	var tk = time.NewTicker(l.interval)
+	defer tk.Stop()
+
+	l.sendAnnouncements()
+
+	for {
+		select {
+		case <-tk.C:
+		case <-l.update:
+		case <-l.quit:
+			return
+		}
+		l.sendAnnouncements()
+	}
+}
+
+func (l *lan) sendAnnouncements() {
+
+	// a Node that is not public doesn't share
+	// list of its feeds (see Config.Public)
+	if l.n.config.Public == false {
+		return
+	}
+
+	var li = lanInfo{ID: l.n.ID()}
+
+	if t := l.n.getTCP(); t != nil {
+		li.TCP = t.Address()
+	}
+
+	if u := l.n.getUDP(); u != nil {
+		li.UDP = u.Address()
+	}
+
+	if li.TCP == "" && li.UDP == "" {
+		return // not listening
+	}
+
+	var feeds = l.n.Feeds()
+
+	for len(feeds) > 0 {
+
+		var chunk = feeds
+
+		if len(chunk) > lanMaxFeeds {
+			chunk = chunk[:lanMaxFeeds]
+		}
+
+		feeds = feeds[len(chunk):]
+		li.Feeds = chunk
+
+		var p, err = li.encode(l.sk)
+
+		if err != nil {
+			l.l.Errorw(err, "can't encode LAN announcement")
+			return
+		}
+
+		if _, err = l.conn.WriteToUDP(p, l.group); err != nil {
+			l.l.Errorw(err, "can't send LAN announcement")
+			return
+		}
+
+		l.l.Debugw(DiscoveryPin, "LAN announcement sent",
+			"feeds", len(chunk))
+
+	}
+
+}
+
+func (l *lan) receiving() {
+	defer close(l.rdone)
+
+	var p = make([]byte, lanMaxPacket)
+
+	for {
+
+		var n, src, err = l.conn.ReadFromUDP(p)
+
+		if err != nil {
+			select {
+			case <-l.quit:
+			default:
+				l.l.Errorw(err, "LAN discovery receiving error")
+			}
+			return
+		}
+
+		var li *lanInfo
+
+		if li, err = decodeLANInfo(p[:n]); err != nil {
+			if err != errLANMagic {
+				l.l.Debugw(DiscoveryPin, "invalid LAN announcement",
+					"src", src.String(),
+					"err", err)
+			}
+			continue
+		}
+
+		if li.ID == l.n.ID() {
+			continue // from this node
+		}
+
+		l.handleInfo(li, src)
+
+	}
+
+}
+
+// add the node to swarms of feeds this Node joined
+func (l *lan) handleInfo(li *lanInfo, src *net.UDPAddr) {
+
+	var (
+		tcp = lanAddress(li.TCP, src)
+		udp = lanAddress(li.UDP, src)
+	)
+
+	for _, feed := range li.Feeds {
+
+		var s, ok = l.n.InSwarm(feed)
+
+		if ok == false {
+			continue
+		}
+
+		if err := s.AddPeer(li.ID, nil, tcp, udp); err != nil {
+			l.l.Debugw(DiscoveryPin, "can't add LAN peer",
+				"peer", li.ID.Hex(),
+				"feed", feed.Hex(),
+				"err", err)
+			continue
+		}
+
+		l.l.Debugw(DiscoveryPin, "LAN peer",
+			"peer", li.ID.Hex(),
+			"feed", feed.Hex(),
+			"tcp", tcp,
+			"udp", udp)
+
+	}
+
+}
+
+// replace unspecified host of announced listening
+// address with IP address of the announcement
+func lanAddress(address string, src *net.UDPAddr) string {
+
+	if address == "" {
+		return ""
+	}
+
+	var host, port, err = net.SplitHostPort(address)
+
+	if err != nil {
+		return address // let the Swarm reject it
+	}
+
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		return net.JoinHostPort(src.IP.String(), port)
+	}
+
+	return address
+}
+
+// Close the lan discovery
+func (l *lan) Close() (err error) {
+	close(l.quit)
+	<-l.done
+	err = l.conn.Close()
+	<-l.rdone
+	return
+}
@@ -0,0 +1,156 @@
+package node
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func Test_lanInfo_encode(t *testing.T) {
+
+	var (
+		pk, sk  = cipher.GenerateKeyPair()
+		feed, _ = cipher.GenerateKeyPair()
+
+		li = &lanInfo{
+			ID:    pk,
+			TCP:   "127.0.0.1:8870",
+			Feeds: []cipher.PubKey{feed},
+		}
+	)
+
+	var p, err = li.encode(sk)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dl *lanInfo
+
+	if dl, err = decodeLANInfo(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if dl.ID != pk || dl.TCP != li.TCP || dl.UDP != "" ||
+		len(dl.Feeds) != 1 || dl.Feeds[0] != feed {
+
+		t.Error("wrong decoded info")
+	}
+
+	// wrong signature
+
+	p[len(p)-1]++
+
+	if _, err = decodeLANInfo(p); err == nil {
+		t.Error("missing error")
+	}
+
+	// not a LAN packet
+
+	if _, err = decodeLANInfo([]byte("hello")); err != errLANMagic {
+		t.Error("unexpected error:", err)
+	}
+
+}
+
+func Test_lanAddress(t *testing.T) {
+
+	var src = &net.UDPAddr{IP: net.IPv4(192, 168, 0, 10), Port: 8870}
+
+	for _, tt := range []struct{ address, want string }{
+		{"", ""},
+		{":8870", "192.168.0.10:8870"},
+		{"0.0.0.0:8870", "192.168.0.10:8870"},
+		{"[::]:8870", "192.168.0.10:8870"},
+		{"127.0.0.1:8870", "127.0.0.1:8870"},
+	} {
+		if got := lanAddress(tt.address, src); got != tt.want {
+			t.Errorf("%q: want %q, got %q", tt.address, tt.want, got)
+		}
+	}
+
+}
+
+func loopbackInterface(t *testing.T) string {
+
+	var ifs, err = net.Interfaces()
+
+	if err != nil {
+		t.Skip(err)
+	}
+
+	for _, ifi := range ifs {
+		if ifi.Flags&net.FlagLoopback != 0 && ifi.Flags&net.FlagUp != 0 {
+			return ifi.Name
+		}
+	}
+
+	t.Skip("no loopback interface")
+	return ""
+}
+
+func TestNode_lan(t *testing.T) {
+
+	var (
+		lo      = loopbackInterface(t)
+		feed, _ = cipher.GenerateKeyPair()
+
+		ac = getTestConfig("a")
+		bc = getTestConfigNotListen("b")
+	)
+
+	ac.TCP.Listen = "127.0.0.1:8089"
+	ac.UDP.Listen = ""
+	ac.Public = true
+
+	for _, c := range []*Config{ac, bc} {
+		c.LAN.Group = "239.255.88.70:8878"
+		c.LAN.Interface = lo
+		c.LAN.Interval = TM / 5
+	}
+
+	var a, b *Node
+	var err error
+
+	if a, err = NewNode(ac); err != nil {
+		t.Skip("can't use LAN discovery: ", err)
+	}
+	defer a.Close()
+
+	if b, err = NewNode(bc); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	var s *Swarm
+	if s, err = b.JoinSwarm(feed, DefaultSwarmConfig()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = a.Share(feed); err != nil {
+		t.Fatal(err)
+	}
+
+	var tm = time.After(10 * TM)
+
+	for {
+		select {
+		case <-tm:
+			t.Fatal("slow or not found")
+		case <-time.After(TM / 10):
+		}
+
+		for _, p := range s.Peers() {
+			if p.PubKey != a.ID() {
+				continue
+			}
+			if p.TCPAddr != a.TCP().Address() {
+				t.Error("wrong TCP address", p.TCPAddr)
+			}
+			return
+		}
+	}
+
+}
@@ -0,0 +1,257 @@
+package node
+
+import (
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// last Root objects received by a light Node, the
+// Root objects are stored in IdxDB without objects
+// (see SetLightRoot method of the skyobject.Container)
+// and cached in memory
+type lightRoots struct {
+	c  *skyobject.Container
+	mx sync.Mutex
+	fs map[cipher.PubKey]map[uint64]*registry.Root // feed -> head -> Root
+}
+
+func newLightRoots(c *skyobject.Container) (l *lightRoots) {
+	l = new(lightRoots)
+	l.c = c
+	l.fs = make(map[cipher.PubKey]map[uint64]*registry.Root)
+	return
+}
+
+// heads of given feed, loading them from DB
+// if need; the lock must be held
+func (l *lightRoots) heads(
+	pk cipher.PubKey, // : feed
+) (
+	hs map[uint64]*registry.Root, // : heads
+	err error, //                    : DB failure
+) {
+
+	var ok bool
+	if hs, ok = l.fs[pk]; ok == true {
+		return
+	}
+
+	var rs []*registry.Root
+	if rs, err = l.c.LightRoots(pk); err != nil {
+		return
+	}
+
+	hs = make(map[uint64]*registry.Root)
+
+	for _, r := range rs {
+		hs[r.Nonce] = r
+	}
+
+	l.fs[pk] = hs
+	return
+}
+
+// seq of last Root of given head
+func (l *lightRoots) lastSeq(
+	pk cipher.PubKey, // : feed
+	nonce uint64, //     : head
+) (
+	seq uint64, //       : seq of the last Root
+	ok bool, //          : has Root
+) {
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	var hs, err = l.heads(pk)
+	if err != nil {
+		return // the set reports the error
+	}
+
+	var r *registry.Root
+	if r, ok = hs[nonce]; ok == true {
+		seq = r.Seq
+	}
+
+	return
+}
+
+// set given Root as last of its head if it's
+// newer, the maxHeads is limit of heads per feed,
+// that drops the oldest head if reached
+func (l *lightRoots) set(
+	r *registry.Root, // : the Root
+	maxHeads int, //     : max heads
+) (
+	ok bool, //          : set
+	err error, //        : DB failure
+) {
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	var hs map[uint64]*registry.Root
+	if hs, err = l.heads(r.Pub); err != nil {
+		return
+	}
+
+	var lr, has = hs[r.Nonce]
+
+	if has == true && lr.Seq >= r.Seq {
+		return // we have newer one
+	}
+
+	if err = l.c.SetLightRoot(r); err != nil {
+		return
+	}
+
+	// max heads limit
+
+	if has == false && maxHeads > 0 && len(hs) >= maxHeads {
+
+		var (
+			torm  uint64 // to remove
+			first = true
+		)
+
+		for nonce, hr := range hs {
+			if first == true || hr.Time < hs[torm].Time {
+				torm, first = nonce, false
+			}
+		}
+
+		if err = l.c.DelLightRoot(r.Pub, torm); err != nil {
+			return
+		}
+
+		delete(hs, torm)
+
+	}
+
+	hs[r.Nonce] = r
+	return true, nil
+}
+
+// the newest Root of given feed
+func (l *lightRoots) last(pk cipher.PubKey) (r *registry.Root, err error) {
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	var hs map[uint64]*registry.Root
+	if hs, err = l.heads(pk); err != nil {
+		return
+	}
+
+	for _, hr := range hs {
+		if r == nil || r.Time < hr.Time {
+			r = hr
+		}
+	}
+
+	return
+}
+
+// drop cached Root objects of given feed,
+// the Root objects are kept in DB
+func (l *lightRoots) delFeed(pk cipher.PubKey) {
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	delete(l.fs, pk)
+}
+
+// received Root in light mode (instead of filling)
+func (n *nodeFeed) receivedLightRoot(cr connRoot) {
+
+	var node = n.node()
+
+	if seq, ok := node.lr.lastSeq(cr.r.Pub, cr.r.Nonce); ok && seq >= cr.r.Seq {
+		return // we have newer one
+	}
+
+	// callback
+	if reject := node.onRootReceived(cr.c, cr.r); reject != nil {
+		return // rejected
+	}
+
+	if ok, err := node.lr.set(cr.r, node.config.MaxHeads); err != nil {
+		node.Errorw(err, "can't save light Root", "root", cr.r.Short())
+		return
+	} else if ok == false {
+		return
+	}
+
+	n.broadcastRoot(cr)
+	node.onRootFilled(cr.r) // can be used (see LightPack)
+
+}
+
+// implements skyobject.Getter
+// using connections of a feed
+type lightGetter struct {
+	n    *Node
+	feed cipher.PubKey
+}
+
+func (l *lightGetter) Get(key cipher.SHA256) (val []byte, err error) {
+
+	err = ErrNoConnectionsToGetFrom
+
+	for _, c := range l.n.ConnectionsOfFeed(l.feed) {
+		if val, err = c.getter().Get(key); err == nil {
+			return
+		}
+	}
+
+	return
+}
+
+// LightRoot returns the newest Root of given feed
+// received by light Node (see Config.Light). The
+// Root is saved in DB without objects, and objects
+// of the Root should be obtained using LightPack.
+// The LightRoot returns data.ErrNotFound if the Node
+// has not received a Root of the feed. And it returns
+// ErrNotLight if the Node is not light
+func (n *Node) LightRoot(feed cipher.PubKey) (r *registry.Root, err error) {
+
+	if n.config.Light == false {
+		return nil, ErrNotLight
+	}
+
+	if r, err = n.lr.last(feed); err != nil {
+		return nil, err
+	}
+
+	if r == nil {
+		return nil, data.ErrNotFound
+	}
+
+	return
+}
+
+// LightPack returns registry.Pack for given Root
+// received by light Node (see Config.Light). The
+// Pack gets objects from DB or from bounded store
+// of the Node. Objects the Node doesn't have are
+// requested from connections of feed of the Root.
+// Every received object is verified and kept in
+// the store (see Config.LightStoreVolume). The
+// LightPack returns ErrNotLight if the Node is
+// not light. The Pack can block requesting
+// objects from remote peers
+func (n *Node) LightPack(r *registry.Root) (pack *skyobject.Light, err error) {
+
+	if n.config.Light == false {
+		return nil, ErrNotLight
+	}
+
+	return n.c.Light(r, &lightGetter{n, r.Pub}, n.ls)
+}
@@ -0,0 +1,175 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func Test_light(t *testing.T) {
+
+	var (
+		fr, onRootFilled = onRootFilledToChannel(100)
+		sn               = getTestNode("sender")
+		rconf            = getTestConfigNotListen("receiver")
+	)
+
+	rconf.Light = true                // light mode
+	rconf.OnRootFilled = onRootFilled // callback
+
+	var rn, err = NewNode(rconf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sn.Close()
+	defer rn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, rn.Share(pk))
+
+	if _, err = rn.LightRoot(pk); err != data.ErrNotFound {
+		t.Error("wrong error:", err)
+	}
+
+	var (
+		reg = getTestRegistry()
+		sc  = sn.Container()
+
+		up *skyobject.Unpack
+	)
+
+	if up, err = sc.Unpack(sk, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	var feed Feed
+
+	for i := 0; i < 32; i++ {
+
+		err := feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+			Body: fmt.Sprintf("Body #%d", i),
+			Time: time.Now().UnixNano(),
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+	}
+
+	var r = new(registry.Root)
+
+	r.Nonce = 9021 // random
+	r.Pub = pk     // set
+
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}),
+		dynamicByValue(t, up, "test.Feed", feed),
+	)
+
+	// save the Root
+	if err = sc.Save(up, r); err != nil {
+		t.Fatal(err)
+	}
+
+	// connect the nodes between
+	var c *Conn
+	if c, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.Subscribe(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	<-time.After(TM)
+
+	// the Root can be received before the connection
+	// added to the feed, thus, send it again
+	sn.Publish(r)
+
+	var rr *registry.Root // received Root
+
+	select {
+	case rr = <-fr:
+	case <-time.After(4 * TM):
+		t.Fatal("slow")
+	}
+
+	if rr.Hash != r.Hash {
+		t.Fatal("wrong Root received")
+	}
+
+	// the Root is not saved and not filled
+
+	if _, err = rn.Container().LastRoot(pk, r.Nonce); err == nil {
+		t.Error("the Root saved")
+	}
+
+	var lr *registry.Root
+	if lr, err = rn.LightRoot(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	if lr.Hash != r.Hash {
+		t.Error("wrong light Root")
+	}
+
+	// the Root is saved in DB without objects
+
+	if lr, err = newLightRoots(rn.Container()).last(pk); err != nil {
+		t.Fatal(err)
+	} else if lr == nil || lr.Hash != r.Hash {
+		t.Fatal("light Root is not saved")
+	}
+
+	var pack *skyobject.Light
+	if pack, err = rn.LightPack(lr); err != nil {
+		t.Fatal(err)
+	}
+
+	var usr User
+	assertNil(t, lr.Refs[0].Value(pack, &usr))
+
+	if usr.Name != "Alice" || usr.Age != 19 {
+		t.Error("wrong user:", usr)
+	}
+
+	var lf Feed
+	assertNil(t, lr.Refs[1].Value(pack, &lf))
+
+	var post Post
+	if _, err = lf.Posts.ValueByIndex(pack, 17, &post); err != nil {
+		t.Fatal(err)
+	}
+
+	if post.Head != "Head #17" {
+		t.Error("wrong post:", post.Head)
+	}
+
+	if pack.Store().Len() == 0 {
+		t.Error("objects are not kept")
+	}
+
+	// not light
+
+	if _, err = sn.LightRoot(pk); err != ErrNotLight {
+		t.Error("wrong error:", err)
+	}
+
+	if _, err = sn.LightPack(r); err != ErrNotLight {
+		t.Error("wrong error:", err)
+	}
+
+}
@@ -4,27 +4,115 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
 )
 
 // defaults
 const (
-	Prefix string = ""      // default prefix
-	Debug  bool   = false   // don't show debug logs by default
-	All    Pin    = ^Pin(0) // default Debug pins (all pins)
-	No     Pin    = 0       // no pins
+	Prefix string = ""         // default prefix
+	Debug  bool   = false      // don't show debug logs by default
+	All    Pin    = ^Pin(0)    // default Debug pins (all pins)
+	No     Pin    = 0          // no pins
+	Format string = TextFormat // default output format
+)
+
+// output formats
+const (
+	TextFormat string = "text" // human readable output
+	JSONFormat string = "json" // one JSON object per line
 )
 
 // A Pin of a debug log
 type Pin uint
 
+// A Subsystems maps debug pins to names. A named
+// pin is a subsystem. Name of a subsystem attached
+// to every debug log entry the pin of which is
+// named. And subsystems can be turned on and off
+// by names (see -debug-subsystems flag)
+type Subsystems map[Pin]string
+
+// Name returns names of all subsystems given pin
+// contains joined with comma. It returns empty
+// string if there are not named pins in given one
+func (s Subsystems) Name(pin Pin) (name string) {
+
+	var names []string
+
+	for p := Pin(1); p != 0; p <<= 1 {
+		if pin&p == 0 {
+			continue
+		}
+		if n, ok := s[p]; ok == true {
+			names = append(names, n)
+		}
+	}
+
+	return strings.Join(names, ",")
+}
+
+// Pins returns pins by given names of subsystems.
+// The names can be separated by comma. Name "all"
+// means all pins
+func (s Subsystems) Pins(names string) (pins Pin, err error) {
+
+	for _, name := range strings.Split(names, ",") {
+
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+
+		if name == "all" {
+			pins = All
+			continue
+		}
+
+		var found bool
+
+		for p, n := range s {
+			if n == name {
+				pins, found = pins|p, true
+			}
+		}
+
+		if found == false {
+			return No, fmt.Errorf("unknown subsystem %q", name)
+		}
+
+	}
+
+	return
+}
+
 // A Config represents configuration for logger
 type Config struct {
 	Prefix string    // log prefix
 	Debug  bool      // show debug logs
 	Pins   Pin       // debug pins
 	Output io.Writer // provide an output
+
+	// Level is minimal level of log entries to
+	// output. Entries with lower level will be
+	// dropped. The Debug and the Pins fields are
+	// checked too for debug entries
+	Level Level
+
+	// Format is output format, that can be
+	// TextFormat or JSONFormat
+	Format string
+
+	// Subsystems are names of debug pins
+	Subsystems Subsystems
+
+	// Sinks are additional destinations of log
+	// entries. The entries are written to the
+	// Output (using the Format) and to all of
+	// the Sinks
+	Sinks []Sink
 }
 
 // NewConfig returns Config with default values
@@ -32,6 +120,8 @@ func NewConfig() (c Config) {
 	c.Prefix = Prefix
 	c.Debug = Debug
 	c.Pins = All
+	c.Level = DebugLevel
+	c.Format = Format
 	return
 }
 
@@ -39,7 +129,10 @@ func NewConfig() (c Config) {
 // flag.Parse after this method. There is -log-prefix flag.
 // And also, it provides -debug flag and -debug-pins. If
 // the debug flag set to false, then -debug-pins ignored and
-// the pins set to No.
+// the pins set to No. The -debug-subsystems flag is the same
+// as the -debug-pins, but it uses names of subsystems (see
+// Subsystems field). There are -log-level and -log-format
+// flags too.
 func (c *Config) FromFlags() {
 
 	flag.StringVar(&c.Prefix,
@@ -52,14 +145,75 @@ func (c *Config) FromFlags() {
 		c.Debug,
 		"print debug logs")
 
-	var pins uint
-
-	flag.UintVar(&pins,
+	flag.Var((*pinsFlag)(c),
 		"debug-pins",
-		uint(c.Pins),
 		"debug pins (default all)")
 
-	c.Pins = Pin(pins)
+	flag.Var((*subsystemsFlag)(c),
+		"debug-subsystems",
+		"comma separated names of debug subsystems to show (default all)")
+
+	flag.Var(&c.Level,
+		"log-level",
+		"minimal level of logs: debug, info, error, panic or fatal")
+
+	flag.StringVar(&c.Format,
+		"log-format",
+		c.Format,
+		"log output format: text or json")
+
+}
+
+// Validate the Config
+func (c *Config) Validate() (err error) {
+
+	switch c.Format {
+	case "", TextFormat, JSONFormat:
+	default:
+		return fmt.Errorf("unknown log format %q", c.Format)
+	}
+
+	if c.Level < DebugLevel || c.Level > FatalLevel {
+		return fmt.Errorf("invalid log level %d", c.Level)
+	}
+
+	return
+}
+
+// -debug-pins
+type pinsFlag Config
+
+func (p *pinsFlag) String() string {
+	if p == nil {
+		return ""
+	}
+	return fmt.Sprint(uint(p.Pins))
+}
+
+func (p *pinsFlag) Set(s string) (err error) {
+	var pins uint
+	if _, err = fmt.Sscan(s, &pins); err == nil {
+		p.Pins = Pin(pins)
+	}
+	return
+}
+
+// -debug-subsystems
+type subsystemsFlag Config
+
+func (s *subsystemsFlag) String() string {
+	if s == nil {
+		return ""
+	}
+	return s.Subsystems.Name(s.Pins)
+}
+
+func (s *subsystemsFlag) Set(names string) (err error) {
+	var pins Pin
+	if pins, err = s.Subsystems.Pins(names); err == nil {
+		s.Pins = pins
+	}
+	return
 }
 
 // A Logger is similar to log.Logger with Debug methods.
@@ -111,7 +265,22 @@ func (c *Config) FromFlags() {
 //
 // This way you can provide detailed logs without caring
 // about big output. This feature applies only to debug logs.
-// Set Debug field of the Config to turn all debug logs off
+// Set Debug field of the Config to turn all debug logs off.
+//
+// Also, the Logger is structured. Every log entry has a
+// level and can have key-value fields attached. Use the
+// With method to create a Logger that attaches given
+// fields to all entries it writes. The methods that
+// ends with 'w' (Debugw, Infow and Errorw) takes a
+// message and key-value pairs. For example
+//
+//     cl := l.With("conn", "tcp://127.0.0.1:8870")
+//     cl.Infow("subscribed", "feed", pk.Hex())
+//
+//     // [node] subscribed conn=tcp://127.0.0.1:8870 feed=03ab...
+//
+// Name a pin (see Subsystems) to attach name of the pin to
+// debug log entries
 type Logger interface {
 	// Pins of the Logger
 	Pins() Pin
@@ -139,11 +308,32 @@ type Logger interface {
 	Error(err error, args ...interface{})
 	Errorln(err error, args ...interface{})
 	Errorf(err error, format string, args ...interface{})
+
+	// structured
+
+	// With returns Logger that attaches given
+	// key-value pairs to every entry
+	With(keyValues ...interface{}) Logger
+
+	Debugw(pin Pin, msg string, keyValues ...interface{}) //
+	Infow(msg string, keyValues ...interface{})           //
+	Errorw(err error, msg string, keyValues ...interface{})
+}
+
+// shared between a logger and its children
+type output struct {
+	mx    sync.Mutex
+	main  Sink   // created using Config.Output and Config.Format
+	sinks []Sink // all sinks, including the main
 }
 
 type logger struct {
-	*log.Logger
-	pins Pin
+	out *output
+
+	pins   Pin
+	level  Level
+	names  Subsystems
+	fields []Field
 }
 
 // NewLogger create new Logger using given Config.
@@ -155,54 +345,221 @@ func NewLogger(c Config) Logger {
 	if c.Output == nil {
 		c.Output = os.Stderr
 	}
+
+	var out = new(output)
+
+	if c.Format == JSONFormat {
+		out.main = NewJSONSink(c.Output)
+	} else {
+		out.main = NewTextSink(c.Output, c.Prefix, Lshortfile|Ltime)
+	}
+
+	out.sinks = append([]Sink{out.main}, c.Sinks...)
+
 	return &logger{
-		Logger: log.New(c.Output, c.Prefix, log.Lshortfile|log.Ltime),
-		pins:   c.Pins,
+		out:   out,
+		pins:  c.Pins,
+		level: c.Level,
+		names: c.Subsystems,
 	}
 }
 
+// write an entry to all sinks, the depth is
+// number of calls between the caller and the
+// write method
+func (l *logger) write(depth int, level Level, pin Pin, msg string,
+	fields []Field) {
+
+	var e = Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+	}
+
+	if level == DebugLevel {
+		e.Subsystem = l.names.Name(pin)
+	}
+
+	if _, file, line, ok := runtime.Caller(depth + 1); ok == true {
+		e.File, e.Line = file, line
+	}
+
+	if len(l.fields) > 0 {
+		e.Fields = make([]Field, 0, len(l.fields)+len(fields))
+		e.Fields = append(e.Fields, l.fields...)
+		e.Fields = append(e.Fields, fields...)
+	} else {
+		e.Fields = fields
+	}
+
+	l.out.mx.Lock()
+	defer l.out.mx.Unlock()
+
+	for _, s := range l.out.sinks {
+		s.Write(&e) // ignore error
+	}
+}
+
+func (l *logger) isDebug(pin Pin) bool {
+	return pin&l.pins != 0 && l.level <= DebugLevel
+}
+
+func (l *logger) is(level Level) bool {
+	return l.level <= level
+}
+
+func (l *logger) Pins() Pin {
+	return l.pins
+}
+
+func (l *logger) SetPrefix(prefix string) {
+	l.out.mx.Lock()
+	defer l.out.mx.Unlock()
+
+	if ps, ok := l.out.main.(interface{ SetPrefix(string) }); ok {
+		ps.SetPrefix(prefix)
+	}
+}
+
+func (l *logger) SetFlags(flags int) {
+	l.out.mx.Lock()
+	defer l.out.mx.Unlock()
+
+	if fs, ok := l.out.main.(interface{ SetFlags(int) }); ok {
+		fs.SetFlags(flags)
+	}
+}
+
+func (l *logger) SetOutput(w io.Writer) {
+	l.out.mx.Lock()
+	defer l.out.mx.Unlock()
+
+	if so, ok := l.out.main.(interface{ SetOutput(io.Writer) }); ok {
+		so.SetOutput(w)
+	}
+}
+
+func (l *logger) Print(args ...interface{}) {
+	if l.is(InfoLevel) {
+		l.write(1, InfoLevel, No, fmt.Sprint(args...), nil)
+	}
+}
+
+func (l *logger) Println(args ...interface{}) {
+	if l.is(InfoLevel) {
+		l.write(1, InfoLevel, No, fmt.Sprintln(args...), nil)
+	}
+}
+
+func (l *logger) Printf(format string, args ...interface{}) {
+	if l.is(InfoLevel) {
+		l.write(1, InfoLevel, No, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+func (l *logger) Panic(args ...interface{}) {
+	var s = fmt.Sprint(args...)
+	l.write(1, PanicLevel, No, s, nil)
+	panic(s)
+}
+
+func (l *logger) Panicln(args ...interface{}) {
+	var s = fmt.Sprintln(args...)
+	l.write(1, PanicLevel, No, s, nil)
+	panic(s)
+}
+
+func (l *logger) Panicf(format string, args ...interface{}) {
+	var s = fmt.Sprintf(format, args...)
+	l.write(1, PanicLevel, No, s, nil)
+	panic(s)
+}
+
+func (l *logger) Fatal(args ...interface{}) {
+	l.write(1, FatalLevel, No, fmt.Sprint(args...), nil)
+	os.Exit(1)
+}
+
+func (l *logger) Fatalln(args ...interface{}) {
+	l.write(1, FatalLevel, No, fmt.Sprintln(args...), nil)
+	os.Exit(1)
+}
+
+func (l *logger) Fatalf(format string, args ...interface{}) {
+	l.write(1, FatalLevel, No, fmt.Sprintf(format, args...), nil)
+	os.Exit(1)
+}
+
 func (l *logger) Debug(pin Pin, args ...interface{}) {
-	if pin&l.pins != 0 {
-		args = append([]interface{}{"[DBG] "}, args...)
-		l.Output(2, fmt.Sprint(args...))
+	if l.isDebug(pin) {
+		l.write(1, DebugLevel, pin, fmt.Sprint(args...), nil)
 	}
 }
 
 func (l *logger) Debugln(pin Pin, args ...interface{}) {
-	if pin&l.pins != 0 {
-		args = append([]interface{}{"[DBG]"}, args...)
-		l.Output(2, fmt.Sprintln(args...))
+	if l.isDebug(pin) {
+		l.write(1, DebugLevel, pin, fmt.Sprintln(args...), nil)
 	}
 }
 
 func (l *logger) Debugf(pin Pin, format string, args ...interface{}) {
-	if pin&l.pins != 0 {
-		format = "[DBG] " + format
-		l.Output(2, fmt.Sprintf(format, args...))
+	if l.isDebug(pin) {
+		l.write(1, DebugLevel, pin, fmt.Sprintf(format, args...), nil)
 	}
 }
 
 func (l *logger) Error(err error, args ...interface{}) {
-	errArgs := []interface{}{
-		"[ERR] ",
-		fmt.Sprintf("err=%s ", err),
+	if l.is(ErrorLevel) {
+		l.write(1, ErrorLevel, No, fmt.Sprint(args...), errFields(err))
 	}
-	l.Output(2, fmt.Sprint(append(errArgs, args...)))
 }
 
 func (l *logger) Errorln(err error, args ...interface{}) {
-	errArgs := []interface{}{
-		"[ERR] ",
-		fmt.Sprintf("err=%s ", err),
+	if l.is(ErrorLevel) {
+		l.write(1, ErrorLevel, No, fmt.Sprintln(args...), errFields(err))
 	}
-	l.Output(2, fmt.Sprint(append(errArgs, args...)))
 }
 
 func (l *logger) Errorf(err error, format string, args ...interface{}) {
-	format = "[ERR] " + fmt.Sprintf("err=%s ", err) + format
-	l.Output(2, fmt.Sprintf(format, args...))
+	if l.is(ErrorLevel) {
+		l.write(1, ErrorLevel, No, fmt.Sprintf(format, args...),
+			errFields(err))
+	}
 }
 
-func (l *logger) Pins() Pin {
-	return l.pins
+func (l *logger) With(keyValues ...interface{}) Logger {
+
+	var (
+		fields = Fields(keyValues...)
+		child  = *l
+	)
+
+	child.fields = make([]Field, 0, len(l.fields)+len(fields))
+	child.fields = append(child.fields, l.fields...)
+	child.fields = append(child.fields, fields...)
+
+	return &child
+}
+
+func (l *logger) Debugw(pin Pin, msg string, keyValues ...interface{}) {
+	if l.isDebug(pin) {
+		l.write(1, DebugLevel, pin, msg, Fields(keyValues...))
+	}
+}
+
+func (l *logger) Infow(msg string, keyValues ...interface{}) {
+	if l.is(InfoLevel) {
+		l.write(1, InfoLevel, No, msg, Fields(keyValues...))
+	}
+}
+
+func (l *logger) Errorw(err error, msg string, keyValues ...interface{}) {
+	if l.is(ErrorLevel) {
+		l.write(1, ErrorLevel, No, msg,
+			append(errFields(err), Fields(keyValues...)...))
+	}
+}
+
+func errFields(err error) []Field {
+	return []Field{{Key: "err", Value: err}}
 }
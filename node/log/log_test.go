@@ -2,7 +2,10 @@ package log
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"testing"
 )
 
@@ -106,3 +109,174 @@ func TestLogger_Pins(t *testing.T) {
 	}
 
 }
+
+func TestLogger_With(t *testing.T) {
+
+	l, out := cleanLoggerOut("", true)
+
+	cl := l.With("conn", "tcp://127.0.0.1:8870")
+	cl.Infow("subscribed", "feed", "03ab")
+
+	if want := "subscribed conn=tcp://127.0.0.1:8870 feed=03ab\n"; out.String() != want {
+		t.Errorf("want %q, got %q", want, out.String())
+	}
+
+	out.Reset()
+
+	l.Infow("parent")
+
+	if want := "parent\n"; out.String() != want {
+		t.Errorf("want %q, got %q", want, out.String())
+	}
+
+}
+
+func TestLogger_Errorw(t *testing.T) {
+
+	l, out := cleanLoggerOut("", false)
+
+	l.Errorw(errors.New("boom"), "failed", "seq", 10)
+
+	if want := "[ERR] failed err=boom seq=10\n"; out.String() != want {
+		t.Errorf("want %q, got %q", want, out.String())
+	}
+
+}
+
+func TestLogger_Level(t *testing.T) {
+
+	out := new(bytes.Buffer)
+
+	c := NewConfig()
+	c.Debug = true
+	c.Level = ErrorLevel
+	c.Output = out
+
+	l := NewLogger(c)
+	l.SetFlags(0)
+
+	l.Debug(All, "debug")
+	l.Print("info")
+	l.Error(errors.New("boom"), "error")
+
+	if want := "[ERR] error err=boom\n"; out.String() != want {
+		t.Errorf("want %q, got %q", want, out.String())
+	}
+
+}
+
+func TestLogger_Subsystems(t *testing.T) {
+
+	out := new(bytes.Buffer)
+
+	c := NewConfig()
+	c.Debug = true
+	c.Output = out
+	c.Subsystems = Subsystems{1: "conn", 2: "fill"}
+
+	l := NewLogger(c)
+	l.SetFlags(0)
+
+	l.Debug(2, "A")
+	l.Debug(4, "B")
+
+	if want := "[DBG] [fill] A\n[DBG] B\n"; out.String() != want {
+		t.Errorf("want %q, got %q", want, out.String())
+	}
+
+}
+
+func TestSubsystems_Pins(t *testing.T) {
+
+	var s = Subsystems{1: "conn", 2: "fill", 4: "feed"}
+
+	if pins, err := s.Pins("conn, feed"); err != nil {
+		t.Error(err)
+	} else if pins != 5 {
+		t.Error("wrong pins", pins)
+	}
+
+	if pins, err := s.Pins("all"); err != nil {
+		t.Error(err)
+	} else if pins != All {
+		t.Error("wrong pins", pins)
+	}
+
+	if _, err := s.Pins("unknown"); err == nil {
+		t.Error("missing error")
+	}
+
+	if name := s.Name(3); name != "conn,fill" {
+		t.Error("wrong name", name)
+	}
+
+}
+
+func TestJSONSink_Write(t *testing.T) {
+
+	out := new(bytes.Buffer)
+
+	c := NewConfig()
+	c.Debug = true
+	c.Format = JSONFormat
+	c.Output = out
+	c.Subsystems = Subsystems{1: "fill"}
+
+	l := NewLogger(c).With("feed", "03ab")
+	l.Debugw(1, "request", "seq", 10, "err", errors.New("boom"))
+
+	var obj map[string]interface{}
+
+	if err := json.Unmarshal(out.Bytes(), &obj); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, v := range map[string]interface{}{
+		"level":     "debug",
+		"subsystem": "fill",
+		"msg":       "request",
+		"feed":      "03ab",
+		"seq":       10.0,
+		"err":       "boom",
+	} {
+		if obj[k] != v {
+			t.Errorf("wrong %q: want %v, got %v", k, v, obj[k])
+		}
+	}
+
+	if _, ok := obj["time"]; ok == false {
+		t.Error("missing time")
+	}
+
+	if caller, _ := obj["caller"].(string); caller == "" {
+		t.Error("missing caller")
+	}
+
+}
+
+func TestNewFilterSink(t *testing.T) {
+
+	var (
+		out = new(bytes.Buffer)
+		c   = NewConfig()
+	)
+
+	c.Output = ioutil.Discard
+	c.Sinks = []Sink{
+		NewFilterSink(NewTextSink(out, "", 0), func(e *Entry) bool {
+			var feed, _ = e.Field("feed")
+			return feed == "03ab"
+		}),
+	}
+
+	l := NewLogger(c)
+
+	l.Infow("A", "feed", "03ab")
+	l.Infow("B", "feed", "02cd")
+	l.Print("C")
+
+	if want := "A feed=03ab\n"; out.String() != want {
+		t.Errorf("want %q, got %q", want, out.String())
+	}
+
+}
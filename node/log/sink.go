@@ -0,0 +1,337 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// flags of text output, the same as flags of the log package
+const (
+	Ldate         = log.Ldate         // the date: 2009/01/23
+	Ltime         = log.Ltime         // the time: 01:23:23
+	Lmicroseconds = log.Lmicroseconds // microsecond resolution
+	Llongfile     = log.Llongfile     // full file name and line number
+	Lshortfile    = log.Lshortfile    // final file name element and line
+	LUTC          = log.LUTC          // use UTC rather than local time zone
+)
+
+// A Level represents severity of a log entry
+type Level int
+
+// levels
+const (
+	DebugLevel Level = iota // debug logs (see Pin)
+	InfoLevel               // Print, Println, Printf and Infow
+	ErrorLevel              // Error, Errorln, Errorf and Errorw
+	PanicLevel              // Panic, Panicln and Panicf
+	FatalLevel              // Fatal, Fatalln and Fatalf
+)
+
+var levelNames = [...]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	ErrorLevel: "error",
+	PanicLevel: "panic",
+	FatalLevel: "fatal",
+}
+
+// ParseLevel parses level name
+func ParseLevel(name string) (level Level, err error) {
+	for l, n := range levelNames {
+		if strings.EqualFold(n, name) == true {
+			return Level(l), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q", name)
+}
+
+// String implements fmt.Stringer and flag.Value interfaces
+func (l Level) String() string {
+	if l < DebugLevel || l > FatalLevel {
+		return "Level(" + strconv.Itoa(int(l)) + ")"
+	}
+	return levelNames[l]
+}
+
+// Set implements flag.Value interface
+func (l *Level) Set(name string) (err error) {
+	var level Level
+	if level, err = ParseLevel(name); err == nil {
+		*l = level
+	}
+	return
+}
+
+// A Field represents key-value pair
+// attached to a log entry
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Fields builds list of fields from given key-value
+// pairs. A key should be a string. The keyValues can
+// contain Field values too. For example
+//
+//     Fields("conn", c.String(), "feed", pk.Hex(), Field{"seq", 10})
+//
+func Fields(keyValues ...interface{}) (fs []Field) {
+
+	if len(keyValues) == 0 {
+		return
+	}
+
+	fs = make([]Field, 0, (len(keyValues)+1)/2)
+
+	for i := 0; i < len(keyValues); i++ {
+
+		if f, ok := keyValues[i].(Field); ok == true {
+			fs = append(fs, f)
+			continue
+		}
+
+		var f = Field{Key: fmt.Sprint(keyValues[i])}
+
+		if i+1 < len(keyValues) {
+			i++
+			f.Value = keyValues[i]
+		} else {
+			f.Value = "(missing)"
+		}
+
+		fs = append(fs, f)
+	}
+
+	return
+}
+
+// An Entry represents single log entry. An Entry
+// passed to a Sink is read only
+type Entry struct {
+	Time      time.Time // time of the entry
+	Level     Level     // severity
+	Subsystem string    // names of the Pin (for debug logs)
+	Message   string    // formatted message
+	Fields    []Field   // attached key-value pairs
+
+	File string // source file
+	Line int    // and line
+}
+
+// Field returns value of field with given key.
+// The last value returned if there are many
+func (e *Entry) Field(key string) (value interface{}, ok bool) {
+	for _, f := range e.Fields {
+		if f.Key == key {
+			value, ok = f.Value, true
+		}
+	}
+	return
+}
+
+// message without trailing new line
+func (e *Entry) message() string {
+	return strings.TrimSuffix(e.Message, "\n")
+}
+
+// A Sink is destination of log entries, for example
+// a file, stderr or a log collector. A Sink should
+// not block for a long time. The Write method of a
+// Sink is never called concurrently by a Logger
+type Sink interface {
+	Write(e *Entry) (err error)
+}
+
+// A SinkFunc implements Sink interface
+type SinkFunc func(e *Entry) (err error)
+
+// Write calls the SinkFunc
+func (s SinkFunc) Write(e *Entry) (err error) {
+	return s(e)
+}
+
+// NewFilterSink creates Sink that writes to given
+// one only entries for which given filter returns
+// true. For example
+//
+//     // show errors of the feed only
+//     NewFilterSink(sink, func(e *log.Entry) bool {
+//         var feed, _ = e.Field("feed")
+//         return e.Level >= log.ErrorLevel && feed == pk.Hex()
+//     })
+//
+func NewFilterSink(sink Sink, filter func(e *Entry) bool) Sink {
+	return SinkFunc(func(e *Entry) (err error) {
+		if filter(e) == true {
+			err = sink.Write(e)
+		}
+		return
+	})
+}
+
+// A TextSink writes log entries in human
+// readable format using log.Logger
+type TextSink struct {
+	l     *log.Logger
+	flags int
+}
+
+// NewTextSink creates TextSink using given
+// output, prefix and flags of log.Logger
+func NewTextSink(w io.Writer, prefix string, flags int) (t *TextSink) {
+	t = new(TextSink)
+	t.l = log.New(w, prefix, 0)
+	t.SetFlags(flags)
+	return
+}
+
+// SetPrefix of the TextSink
+func (t *TextSink) SetPrefix(prefix string) {
+	t.l.SetPrefix(prefix)
+}
+
+// SetFlags of the TextSink
+func (t *TextSink) SetFlags(flags int) {
+	t.flags = flags
+	t.l.SetFlags(flags &^ (Lshortfile | Llongfile)) // see Write
+}
+
+// SetOutput of the TextSink
+func (t *TextSink) SetOutput(w io.Writer) {
+	t.l.SetOutput(w)
+}
+
+// Write implements Sink interface
+func (t *TextSink) Write(e *Entry) (err error) {
+
+	var b bytes.Buffer
+
+	// the file and line is not the place the log.Logger
+	// can determine, thus, we are adding them manually
+	if t.flags&(Lshortfile|Llongfile) != 0 && e.File != "" {
+		var file = e.File
+		if t.flags&Lshortfile != 0 {
+			file = filepath.Base(file)
+		}
+		b.WriteString(file)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(e.Line))
+		b.WriteString(": ")
+	}
+
+	switch e.Level {
+	case DebugLevel:
+		b.WriteString("[DBG] ")
+	case ErrorLevel:
+		b.WriteString("[ERR] ")
+	}
+
+	if e.Subsystem != "" {
+		b.WriteString("[" + e.Subsystem + "] ")
+	}
+
+	b.WriteString(e.message())
+
+	for _, f := range e.Fields {
+		b.WriteByte(' ')
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(textValue(f.Value))
+	}
+
+	return t.l.Output(0, b.String())
+}
+
+func textValue(value interface{}) (s string) {
+
+	switch x := value.(type) {
+	case string:
+		s = x
+	case error:
+		s = x.Error()
+	case fmt.Stringer:
+		s = x.String()
+	default:
+		s = fmt.Sprint(x)
+	}
+
+	if s == "" || strings.ContainsAny(s, " =\"\n\t") {
+		s = strconv.Quote(s)
+	}
+
+	return
+}
+
+// A JSONSink writes log entries as JSON objects,
+// one object per line. Fields of an entry are
+// keys of the objects, except the time, level,
+// msg, subsystem and caller, that are reserved
+type JSONSink struct {
+	w io.Writer
+}
+
+// NewJSONSink creates JSONSink that writes to given writer
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{w}
+}
+
+// SetOutput of the JSONSink
+func (j *JSONSink) SetOutput(w io.Writer) {
+	j.w = w
+}
+
+// Write implements Sink interface
+func (j *JSONSink) Write(e *Entry) (err error) {
+
+	var obj = make(map[string]interface{}, len(e.Fields)+5)
+
+	for _, f := range e.Fields {
+		obj[f.Key] = jsonValue(f.Value)
+	}
+
+	obj["time"] = e.Time.Format(time.RFC3339Nano)
+	obj["level"] = e.Level.String()
+	obj["msg"] = e.message()
+
+	if e.Subsystem != "" {
+		obj["subsystem"] = e.Subsystem
+	}
+
+	if e.File != "" {
+		obj["caller"] = filepath.Base(e.File) + ":" + strconv.Itoa(e.Line)
+	}
+
+	var p []byte
+	if p, err = json.Marshal(obj); err != nil {
+		return
+	}
+
+	_, err = j.w.Write(append(p, '\n'))
+	return
+}
+
+func jsonValue(value interface{}) interface{} {
+
+	switch x := value.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8,
+		uint16, uint32, uint64, float32, float64, json.Marshaler:
+		return x
+	case error:
+		return x.Error()
+	case fmt.Stringer:
+		return x.String()
+	}
+
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return value
+}
@@ -43,3 +43,20 @@ const (
 	ConnPin = NewInConnPin | NewOutConnPin | ConnEstPin | ConnHskPin |
 		CloseConnPin // connections
 )
+
+// LogSubsystems are names of the debug log pins. The
+// names attached to debug logs and can be used with
+// -debug-subsystems flag to choose logs to show
+var LogSubsystems = log.Subsystems{
+	NewInConnPin:  "in-conn",
+	NewOutConnPin: "out-conn",
+	ConnHskPin:    "handshake",
+	ConnEstPin:    "conn-est",
+	CloseConnPin:  "conn-close",
+	MsgSendPin:    "msg-send",
+	MsgReceivePin: "msg-receive",
+	FillPin:       "fill",
+	FeedPin:       "feed",
+	DiscoveryPin:  "discovery",
+	PEXPin:        "pex",
+}
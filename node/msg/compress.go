@@ -0,0 +1,139 @@
+package msg
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// A Codec represents compression algorithm
+type Codec uint8
+
+// codecs
+const (
+	NoCompression Codec = iota // no compression
+	Deflate                    // DEFLATE (RFC 1951)
+)
+
+// Codecs is list of supported codecs
+// in order of preference
+var Codecs = []Codec{Deflate}
+
+// MinCompressSize is minimal size of encoded
+// message the Compress compresses
+const MinCompressSize = 128
+
+// compression errors
+var (
+	ErrNestedCompressed = errors.New("nested Compressed message")
+	ErrTooLarge         = errors.New("decompressed message is too large")
+)
+
+// String implements fmt.Stringer interface
+func (c Codec) String() string {
+	switch c {
+	case NoCompression:
+		return "NoCompression"
+	case Deflate:
+		return "Deflate"
+	}
+	return fmt.Sprintf("Codec<%d>", uint8(c))
+}
+
+// Choose first codec of given list (of
+// remote peer) that supported. It returns
+// NoCompression if there are not
+func Choose(codecs []Codec) (codec Codec) {
+	for _, codec = range codecs {
+		for _, sc := range Codecs {
+			if codec == sc {
+				return
+			}
+		}
+	}
+	return NoCompression
+}
+
+// Compress given message using given codec. The
+// Compress returns given message if it's shorter
+// then MinCompressSize, or if compressed message
+// is not shorter, or if the codec is NoCompression
+func Compress(m Msg, codec Codec) (cm Msg, err error) {
+
+	if codec == NoCompression {
+		return m, nil
+	}
+
+	var em = m.Encode()
+
+	if len(em) < MinCompressSize {
+		return m, nil
+	}
+
+	var data []byte
+
+	switch codec {
+	case Deflate:
+		var buf bytes.Buffer
+		var fw *flate.Writer
+		if fw, err = flate.NewWriter(&buf, flate.DefaultCompression); err != nil {
+			return
+		}
+		if _, err = fw.Write(em); err != nil {
+			return
+		}
+		if err = fw.Close(); err != nil {
+			return
+		}
+		data = buf.Bytes()
+	default:
+		return nil, fmt.Errorf("unknown codec %s", codec)
+	}
+
+	// codec, length of the data, data (approximately)
+	if len(data)+6 >= len(em) {
+		return m, nil // not shorter
+	}
+
+	return &Compressed{Codec: codec, Data: data}, nil
+}
+
+// Decompress the Compressed returning decoded
+// message. The Decompress returns ErrTooLarge if
+// the decompressed message is longer then given
+// limit (in bytes)
+func (c *Compressed) Decompress(limit int) (m Msg, err error) {
+
+	var em []byte
+
+	switch c.Codec {
+	case Deflate:
+		var fr = flate.NewReader(bytes.NewReader(c.Data))
+		em, err = ioutil.ReadAll(io.LimitReader(fr, int64(limit)+1))
+		if err != nil {
+			return
+		}
+		if len(em) > limit {
+			fr.Close()
+			return nil, ErrTooLarge
+		}
+		if err = fr.Close(); err != nil {
+			return
+		}
+	default:
+		return nil, fmt.Errorf("unknown codec %s", c.Codec)
+	}
+
+	if m, err = Decode(em); err != nil {
+		return
+	}
+
+	if _, ok := m.(*Compressed); ok == true {
+		return nil, ErrNestedCompressed
+	}
+
+	return
+}
@@ -0,0 +1,153 @@
+package msg
+
+import (
+	"bytes"
+	"compress/flate"
+	"reflect"
+	"testing"
+)
+
+func testObject(size int) *Object {
+	return &Object{Value: bytes.Repeat([]byte("x"), size)}
+}
+
+func TestChoose(t *testing.T) {
+
+	for _, tt := range []struct {
+		codecs []Codec
+		want   Codec
+	}{
+		{nil, NoCompression},
+		{[]Codec{Codec(255)}, NoCompression},
+		{[]Codec{Codec(255), Deflate}, Deflate},
+		{[]Codec{Deflate}, Deflate},
+	} {
+		if got := Choose(tt.codecs); got != tt.want {
+			t.Errorf("Choose(%v): want %s, got %s", tt.codecs, tt.want, got)
+		}
+	}
+
+}
+
+func TestCompress(t *testing.T) {
+
+	var (
+		m   = testObject(1024)
+		cm  Msg
+		err error
+	)
+
+	// no compression
+
+	if cm, err = Compress(m, NoCompression); err != nil {
+		t.Fatal(err)
+	} else if cm != m {
+		t.Error("compressed")
+	}
+
+	// too short
+
+	var short = testObject(MinCompressSize / 2)
+
+	if cm, err = Compress(short, Deflate); err != nil {
+		t.Fatal(err)
+	} else if cm != short {
+		t.Error("compressed")
+	}
+
+	// unknown codec
+
+	if _, err = Compress(m, Codec(255)); err == nil {
+		t.Error("missing error")
+	}
+
+	// round trip
+
+	if cm, err = Compress(m, Deflate); err != nil {
+		t.Fatal(err)
+	}
+
+	var c, ok = cm.(*Compressed)
+
+	if ok == false {
+		t.Fatalf("not compressed: %T", cm)
+	}
+
+	if len(c.Encode()) >= len(m.Encode()) {
+		t.Error("not shorter")
+	}
+
+	// encode and decode the Compressed
+	if cm, err = Decode(c.Encode()); err != nil {
+		t.Fatal(err)
+	}
+
+	var dm Msg
+	if dm, err = cm.(*Compressed).Decompress(2048); err != nil {
+		t.Fatal(err)
+	}
+
+	if reflect.DeepEqual(dm, m) == false {
+		t.Error("wrong decompressed message")
+	}
+
+}
+
+func TestCompressed_Decompress(t *testing.T) {
+
+	var (
+		m     = testObject(1024)
+		em    = m.Encode()
+		cm, _ = Compress(m, Deflate)
+		c     = cm.(*Compressed)
+		dm    Msg
+		err   error
+	)
+
+	// limit
+
+	if dm, err = c.Decompress(len(em)); err != nil {
+		t.Error(err)
+	} else if reflect.DeepEqual(dm, m) == false {
+		t.Error("wrong decompressed message")
+	}
+
+	if _, err = c.Decompress(len(em) - 1); err != ErrTooLarge {
+		t.Error("wrong error:", err)
+	}
+
+	// zip bomb
+
+	var bomb, _ = Compress(testObject(16*1024*1024), Deflate)
+
+	if _, err = bomb.(*Compressed).Decompress(1024 * 1024); err != ErrTooLarge {
+		t.Error("wrong error:", err)
+	}
+
+	// unknown codec
+
+	if _, err = (&Compressed{Codec(255), c.Data}).Decompress(2048); err == nil {
+		t.Error("missing error")
+	}
+
+	// broken data
+
+	if _, err = (&Compressed{Deflate, c.Data[:len(c.Data)/2]}).Decompress(
+		2048); err == nil {
+		t.Error("missing error")
+	}
+
+	// nested
+
+	var buf bytes.Buffer
+	var fw, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	fw.Write(c.Encode())
+	fw.Close()
+
+	var nested = &Compressed{Deflate, buf.Bytes()}
+
+	if _, err = nested.Decompress(2048); err != ErrNestedCompressed {
+		t.Error("wrong error:", err)
+	}
+
+}
@@ -17,7 +17,7 @@ import (
 //
 
 // Version is current protocol version
-const Version uint16 = 3
+const Version uint16 = 6
 
 // be sure that all messages implements Msg interface compiler time
 var (
@@ -63,6 +63,9 @@ var (
 	// peer exchange
 	_ Msg = &RqPeers{}
 	_ Msg = &Peers{}
+
+	// compression
+	_ Msg = &Compressed{} // <-> Compressed (codec, compressed message)
 )
 
 //
@@ -113,6 +116,11 @@ func (*Pong) Encode() []byte {
 type Syn struct {
 	Protocol uint16
 	NodeID   cipher.PubKey // node id
+
+	// Compression is list of codecs the initiator
+	// supports, in order of preference; blank list
+	// disables compression (see Compressed)
+	Compression []Codec
 }
 
 // Type implements Msg interface
@@ -126,6 +134,10 @@ func (s *Syn) Encode() []byte { return encode(s) }
 // Otherwise, the Err returned
 type Ack struct {
 	NodeID cipher.PubKey // node id
+
+	// Compression is codec chosen from list of
+	// the Syn, or NoCompression (see Compressed)
+	Compression Codec
 }
 
 // Type implements Msg interface
@@ -246,6 +258,15 @@ type Root struct {
 	Value []byte // encoded Root in person
 
 	Sig cipher.Sig // signature
+
+	// Sigs is encoded signature container of
+	// the Root (see EncodeSigs method of the
+	// registry.Root), e.g. signatures of Root
+	// of a multisig feed, the Sig is blank
+	// then (see registry.Multisig), or
+	// certificate of authorized key (see
+	// registry.Certificate)
+	Sigs []byte
 }
 
 // Type implements Msg interface
@@ -330,6 +351,25 @@ func (ps *Peers) Type() Type { return PeersType }
 // Encode the Peers
 func (ps *Peers) Encode() []byte { return encode(ps) }
 
+//
+// compression
+//
+
+// A Compressed wraps another compressed message.
+// Peers negotiate codec during handshake (see Syn
+// and Ack). Use Compress and Decompress to
+// create and unpack the Compressed
+type Compressed struct {
+	Codec Codec  // codec
+	Data  []byte // compressed encoded message
+}
+
+// Type implements Msg interface
+func (*Compressed) Type() Type { return CompressedType }
+
+// Encode the Compressed
+func (c *Compressed) Encode() []byte { return encode(c) }
+
 //
 // Type / Encode / Deocode / String()
 //
@@ -363,6 +403,8 @@ const (
 
 	RqPeersType // 15
 	PeersType   // 16
+
+	CompressedType // 17
 )
 
 // Type to string mapping
@@ -391,6 +433,8 @@ var msgTypeString = [...]string{
 
 	RqPeersType: "RqPeers",
 	PeersType:   "Peers",
+
+	CompressedType: "Compressed",
 }
 
 // String implements fmt.Stringer interface
@@ -426,6 +470,8 @@ var forwardRegistry = [...]reflect.Type{
 
 	RqPeersType: reflect.TypeOf(RqPeers{}),
 	PeersType:   reflect.TypeOf(Peers{}),
+
+	CompressedType: reflect.TypeOf(Compressed{}),
 }
 
 // An InvalidTypeError represents decoding error when
@@ -46,6 +46,13 @@ type Node struct {
 
 	ss map[cipher.PubKey]*Swarm // swarms
 
+	//
+	// light mode
+	//
+
+	lr *lightRoots           // last Root objects
+	ls *skyobject.LightStore // received objects
+
 	//
 	// transports
 	//
@@ -53,6 +60,7 @@ type Node struct {
 	// listen and connect
 	tcp *TCP
 	udp *UDP
+	lan *lan // LAN discovery or nil
 
 	//
 	// other
@@ -154,6 +162,9 @@ func NewNodeContainer(
 
 	n.ss = make(map[cipher.PubKey]*Swarm)
 
+	n.lr = newLightRoots(c)
+	n.ls = skyobject.NewLightStore(conf.LightStoreVolume)
+
 	n.config = conf
 	n.config.Config = c.Config() // actual
 
@@ -214,6 +225,15 @@ func NewNodeContainer(
 		}
 	}
 
+	// LAN discovery
+
+	if conf.LAN.Group != "" {
+		if n.lan, err = newLAN(n, &conf.LAN); err != nil {
+			n.Close()
+			return
+		}
+	}
+
 	// TODO (kostyarin): pings (move to connection)
 
 	return
@@ -345,7 +365,7 @@ func (n *Node) onConnect(c *Conn) error {
 		}
 	}
 
-	n.Debugf(ConnEstPin, "[%s] established", c.String())
+	c.l.Debugw(ConnEstPin, "established")
 
 	return nil
 }
@@ -356,9 +376,9 @@ func (n *Node) onDisconenct(c *Conn, reason error) {
 	}
 
 	if reason != nil {
-		n.Debugf(CloseConnPin, "[%s] closed: %v", c.String(), reason)
+		c.l.Debugw(CloseConnPin, "closed", "reason", reason)
 	} else {
-		n.Debugf(CloseConnPin, "[%s] closed", c.String())
+		c.l.Debugw(CloseConnPin, "closed")
 	}
 }
 
@@ -395,7 +415,7 @@ func (n *Node) initConn(
 		connStr = connString(isIncoming, fc.IsTCP(), addr)
 	)
 
-	n.Debugf(ConnHskPin, "[%s] init connection", connStr)
+	n.Debugw(ConnHskPin, "init connection", "conn", connStr)
 
 	// Get existing connection or create new one.
 	c, isNew, isPending, err := n.onNewConn(fc, isIncoming)
@@ -514,6 +534,10 @@ func (n *Node) updateServiceDiscovery() {
 	n.mx.Lock()
 	defer n.mx.Unlock()
 
+	if n.lan != nil {
+		n.lan.announce()
+	}
+
 	var notUsed = (n.tcp == nil || n.tcp.Discovery() == nil) &&
 		(n.udp == nil || n.udp.Discovery() == nil)
 
@@ -627,6 +651,32 @@ func (n *Node) onRootReceived(c *Conn, r *registry.Root) (err error) {
 	return
 }
 
+// validation function for a Filler of
+// Root received from given connection
+func (n *Node) rootValidator(c *Conn) skyobject.ValidateRootFunc {
+
+	var orv = n.config.OnRootValidate
+
+	if orv == nil {
+		return nil
+	}
+
+	return func(r *registry.Root, pack registry.Pack) error {
+		return orv(c, r, pack)
+	}
+}
+
+// limits of Root trees for Fillers
+func (n *Node) fillingLimits() registry.Limits {
+	return registry.Limits{
+		Objects:        n.config.MaxFillingObjects,
+		Volume:         n.config.MaxFillingVolume,
+		RefsDepth:      n.config.MaxRefsDepth,
+		RefsDegree:     registry.Degree(n.config.MaxRefsDegree),
+		DynamicNesting: n.config.MaxDynamicNesting,
+	}
+}
+
 func (n *Node) onRootFilled(r *registry.Root) {
 
 	if orf := n.config.OnRootFilled; orf != nil {
@@ -643,6 +693,18 @@ func (n *Node) onFillingBreaks(r *registry.Root, reason error) {
 
 }
 
+func (n *Node) onLimitExceeded(
+	c *Conn,
+	r *registry.Root,
+	err *registry.LimitError,
+) {
+
+	if ole := n.config.OnLimitExceeded; ole != nil {
+		ole(c, r, err)
+	}
+
+}
+
 // has connection to peer with given id (pk)
 func (n *Node) hasPeer(id cipher.PubKey) (c *Conn, yep bool) {
 	n.mx.Lock()
@@ -674,14 +736,28 @@ func (n *Node) Stat() (s *Stat) {
 // of (skyobject.Container).Close once.
 func (n *Node) Close() (err error) {
 	n.closeo.Do(func() {
+
+		// LAN discovery and swarms use the mx,
+		// thus, they should be closed outside
+		// the lock
+
+		if n.lan != nil {
+			n.lan.Close()
+		}
+
 		n.mx.Lock()
-		defer n.mx.Unlock()
+		var ss = n.ss
+		n.ss = make(map[cipher.PubKey]*Swarm)
+		n.mx.Unlock()
 
 		// Shutdown peer exchange.
-		for _, s := range n.ss {
+		for _, s := range ss {
 			s.shutdown()
 		}
 
+		n.mx.Lock()
+		defer n.mx.Unlock()
+
 		// Close all connections.
 		for _, c := range n.pendConns {
 			c.Close()
@@ -732,17 +808,17 @@ func (n *Node) JoinSwarm(
 
 func (n *Node) LeaveSwarm(feed cipher.PubKey) error {
 	n.mx.Lock()
-	defer n.mx.Unlock()
-
 	s, ok := n.ss[feed]
+	delete(n.ss, feed)
+	n.mx.Unlock()
+
 	if !ok {
 		return errors.New("node is not in swarm")
 	}
 
+	// the Swarm uses the mx
 	s.shutdown()
 
-	delete(n.ss, feed)
-
 	return nil
 }
 
@@ -1,12 +1,15 @@
 package node
 
 import (
+	"encoding/json"
 	"errors"
+	mathrand "math/rand"
 	"net"
 	"net/rpc"
 
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
 
@@ -152,6 +155,48 @@ func (r *RPC) Stat(_ struct{}, stat *Stat) (err error) {
 	return
 }
 
+// A FeedQuota represents Quota of a feed
+type FeedQuota struct {
+	Feed  cipher.PubKey
+	Quota skyobject.Quota
+}
+
+// SetQuota is RPC method
+func (r *RPC) SetQuota(fq FeedQuota, _ *struct{}) (err error) {
+	return r.n.c.SetQuota(fq.Feed, fq.Quota)
+}
+
+// Quota is RPC method
+func (r *RPC) Quota(feed cipher.PubKey, q *skyobject.Quota) (err error) {
+	*q, err = r.n.c.Quota(feed)
+	return
+}
+
+// Usage is RPC method
+func (r *RPC) Usage(feed cipher.PubKey, u *skyobject.Usage) (err error) {
+	*u, err = r.n.c.Usage(feed)
+	return
+}
+
+// Backup is RPC method. The path is
+// path to backup file on host of the Node
+func (r *RPC) Backup(path string, _ *struct{}) (err error) {
+	return r.n.c.BackupFile(path)
+}
+
+// A RestoreFiles represents arguments of the Restore
+// RPC method. All paths are paths on host of the Node
+type RestoreFiles struct {
+	Backup string // backup file
+	CXDS   string // CXDS to restore to
+	IdxDB  string // IdxDB to restore to
+}
+
+// Restore is RPC method
+func (r *RPC) Restore(rf RestoreFiles, _ *struct{}) (err error) {
+	return skyobject.RestoreFile(rf.Backup, rf.CXDS, rf.IdxDB)
+}
+
 // A TCPRPC represents RPC object
 // of TCP transport of the Node
 type TCPRPC struct {
@@ -341,6 +386,174 @@ func (r *RootRPC) Tree(rs RootSelector, tree *string) (err error) {
 	return
 }
 
+// A RootPath represents Root selector
+// and path to a value of the Root (see
+// Get method of the registry.Root)
+type RootPath struct {
+	RootSelector
+	Path string
+}
+
+// A RootValue represents value of a Root
+// selected by path
+type RootValue struct {
+	Schema string // schema of the value
+	Value  []byte // encoded value
+	Tree   string // printed value
+}
+
+// Get value of Root by path (RPC method)
+func (r *RootRPC) Get(rp RootPath, rv *RootValue) (err error) {
+
+	var x *registry.Root
+	if x, err = r.n.c.Root(rp.Feed, rp.Nonce, rp.Seq); err != nil {
+		return
+	}
+
+	var p registry.Pack
+	if p, err = r.n.c.Pack(x, nil); err != nil {
+		return
+	}
+
+	var (
+		sch registry.Schema
+		val []byte
+	)
+
+	if sch, val, err = x.Get(p, rp.Path); err != nil {
+		return
+	}
+
+	rv.Schema = sch.String()
+	rv.Value = val
+	rv.Tree = registry.DataTree(p, sch, val)
+	return
+}
+
+// A RootJSON represents Root selector, path to a
+// value of the Root and depth of references to
+// expand (see DecodeValue of the registry)
+type RootJSON struct {
+	RootPath
+	Depth int
+}
+
+// JSON of Root or value of Root by path (RPC method),
+// the Root is used if the path is blank
+func (r *RootRPC) JSON(rj RootJSON, js *[]byte) (err error) {
+
+	var x *registry.Root
+	if x, err = r.n.c.Root(rj.Feed, rj.Nonce, rj.Seq); err != nil {
+		return
+	}
+
+	var p registry.Pack
+	if p, err = r.n.c.Pack(x, nil); err != nil {
+		return
+	}
+
+	if rj.Path == "" {
+		*js, err = x.JSON(p, rj.Depth)
+		return
+	}
+
+	var (
+		sch registry.Schema
+		val []byte
+	)
+
+	if sch, val, err = x.Get(p, rj.Path); err != nil {
+		return
+	}
+
+	*js, err = registry.ValueJSON(p, sch, val, rj.Depth)
+	return
+}
+
+// A RootPublish represents secret key of a feed
+// and JSON of a Root to publish (see SetJSON method
+// of the registry.Root). The secret key is sent as
+// is, thus the Publish should be called by a trusted
+// local client only (see Config.RPC)
+type RootPublish struct {
+	SecKey cipher.SecKey
+	JSON   []byte
+}
+
+// Publish Root created from JSON (RPC method). The
+// "Nonce" of the JSON is head of the Root, a random
+// new head is used if it's zero or missing. And the
+// "Reg" is hex-encoded RegistryRef. If it's missing,
+// then Registry of last Root of the head is used.
+// The Registry must be in DB
+func (r *RootRPC) Publish(rp RootPublish, z *registry.Root) (err error) {
+
+	if err = rp.SecKey.Verify(); err != nil {
+		return
+	}
+
+	var head struct {
+		Nonce uint64
+		Reg   string
+	}
+
+	if err = json.Unmarshal(rp.JSON, &head); err != nil {
+		return
+	}
+
+	var x = new(registry.Root)
+
+	x.Pub = cipher.PubKeyFromSecKey(rp.SecKey)
+
+	if x.Nonce = head.Nonce; x.Nonce == 0 {
+		x.Nonce = mathrand.Uint64()
+	}
+
+	if head.Reg != "" {
+
+		var hash cipher.SHA256
+		if hash, err = cipher.SHA256FromHex(head.Reg); err != nil {
+			return
+		}
+
+		x.Reg = registry.RegistryRef(hash)
+
+	} else {
+
+		var lr *registry.Root
+		if lr, err = r.n.c.LastRoot(x.Pub, x.Nonce); err != nil {
+			return
+		}
+
+		x.Reg = lr.Reg
+
+	}
+
+	var reg *registry.Registry
+	if reg, err = r.n.c.Registry(x.Reg); err != nil {
+		return
+	}
+
+	var up *skyobject.Unpack
+	if up, err = r.n.c.Unpack(rp.SecKey, reg); err != nil {
+		return
+	}
+	defer up.Close()
+
+	if err = x.SetJSON(up, rp.JSON); err != nil {
+		return
+	}
+
+	if err = r.n.c.Save(up, x); err != nil {
+		return
+	}
+
+	r.n.Publish(x)
+
+	*z = *x
+	return
+}
+
 // Last Root of given Feed (RPC method)
 func (r *RootRPC) Last(feed cipher.PubKey, z *registry.Root) (err error) {
 	var x *registry.Root
@@ -350,3 +563,21 @@ func (r *RootRPC) Last(feed cipher.PubKey, z *registry.Root) (err error) {
 	*z = *x
 	return
 }
+
+// Pin Root (RPC method)
+func (r *RootRPC) Pin(rs RootSelector, _ *struct{}) (err error) {
+	return r.n.c.Pin(rs.Feed, rs.Nonce, rs.Seq)
+}
+
+// Unpin Root (RPC method)
+func (r *RootRPC) Unpin(rs RootSelector, _ *struct{}) (err error) {
+	return r.n.c.Unpin(rs.Feed, rs.Nonce, rs.Seq)
+}
+
+// Pins returns pinned Root objects of given feed (RPC method)
+func (r *RootRPC) Pins(feed cipher.PubKey, pins *[]skyobject.Pinned) (
+	err error,
+) {
+	*pins, err = r.n.c.Pins(feed)
+	return
+}
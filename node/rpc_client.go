@@ -5,6 +5,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
 
@@ -115,6 +116,41 @@ func (r *RPCClientNode) Stat() (stat *Stat, err error) {
 	return &s, nil
 }
 
+// SetQuota sets Quota of given feed,
+// use blank Quota to remove limits
+func (r *RPCClientNode) SetQuota(pk cipher.PubKey, q skyobject.Quota) (
+	err error,
+) {
+	return r.r.c.Call("node.SetQuota", FeedQuota{pk, q}, &struct{}{})
+}
+
+// Quota of given feed
+func (r *RPCClientNode) Quota(pk cipher.PubKey) (q skyobject.Quota, err error) {
+	err = r.r.c.Call("node.Quota", pk, &q)
+	return
+}
+
+// Usage of given feed
+func (r *RPCClientNode) Usage(pk cipher.PubKey) (u skyobject.Usage, err error) {
+	err = r.r.c.Call("node.Usage", pk, &u)
+	return
+}
+
+// Backup writes backup of DB of the Node
+// to given file on host of the Node
+func (r *RPCClientNode) Backup(path string) (err error) {
+	return r.r.c.Call("node.Backup", path, &struct{}{})
+}
+
+// Restore given backup to given CXDS and IdxDB
+// files on host of the Node. The files must not
+// exist. Restart the Node with the files to
+// use the restored DB
+func (r *RPCClientNode) Restore(backup, cxds, idxdb string) (err error) {
+	return r.r.c.Call("node.Restore", RestoreFiles{backup, cxds, idxdb},
+		&struct{}{})
+}
+
 // A RPCClientTCP implements RPC
 // methods related to TCP transport
 type RPCClientTCP struct {
@@ -248,6 +284,63 @@ func (r *RPCClientRoot) Tree(
 	return
 }
 
+// Get value of Root object by path
+// (see Get method of the registry.Root)
+func (r *RPCClientRoot) Get(
+	feed cipher.PubKey,
+	nonce uint64,
+	seq uint64,
+	path string,
+) (
+	rv *RootValue,
+	err error,
+) {
+
+	var x RootValue
+	err = r.r.c.Call("root.Get", RootPath{RootSelector{feed, nonce, seq}, path},
+		&x)
+	if err != nil {
+		return
+	}
+	return &x, nil
+}
+
+// JSON of Root object or of its value by path,
+// the depth is depth of references to expand
+// (see DecodeValue of the registry)
+func (r *RPCClientRoot) JSON(
+	feed cipher.PubKey,
+	nonce uint64,
+	seq uint64,
+	path string,
+	depth int,
+) (
+	js []byte,
+	err error,
+) {
+	var rj = RootJSON{RootPath{RootSelector{feed, nonce, seq}, path}, depth}
+	err = r.r.c.Call("root.JSON", rj, &js)
+	return
+}
+
+// Publish Root object created from JSON
+// (see RootRPC.Publish for details)
+func (r *RPCClientRoot) Publish(
+	sk cipher.SecKey,
+	js []byte,
+) (
+	z *registry.Root,
+	err error,
+) {
+
+	var x registry.Root
+	err = r.r.c.Call("root.Publish", RootPublish{sk, js}, &x)
+	if err != nil {
+		return
+	}
+	return &x, nil
+}
+
 // Last Root object
 func (r *RPCClientRoot) Last(
 	feed cipher.PubKey,
@@ -262,3 +355,24 @@ func (r *RPCClientRoot) Last(
 	}
 	return &x, nil
 }
+
+// Pin Root object
+func (r *RPCClientRoot) Pin(feed cipher.PubKey, nonce, seq uint64) (err error) {
+	return r.r.c.Call("root.Pin", RootSelector{feed, nonce, seq}, &struct{}{})
+}
+
+// Unpin Root object
+func (r *RPCClientRoot) Unpin(feed cipher.PubKey, nonce, seq uint64) (
+	err error,
+) {
+	return r.r.c.Call("root.Unpin", RootSelector{feed, nonce, seq}, &struct{}{})
+}
+
+// Pins returns pinned Root objects of given feed
+func (r *RPCClientRoot) Pins(feed cipher.PubKey) (
+	pins []skyobject.Pinned,
+	err error,
+) {
+	err = r.r.c.Call("root.Pins", feed, &pins)
+	return
+}
@@ -376,3 +376,105 @@ func Test_send_receive_refs(t *testing.T) {
 	_ = rr
 
 }
+
+func Test_limitExceeded(t *testing.T) {
+
+	type limitExceeded struct {
+		c   *Conn
+		r   *registry.Root
+		err *registry.LimitError
+	}
+
+	var (
+		lc    = make(chan limitExceeded, 1)
+		sn    = getTestNode("sender")
+		rconf = getTestConfigNotListen("receiver")
+	)
+
+	rconf.MaxFillingObjects = 2 // the Root and the Registry only
+	rconf.OnLimitExceeded = func(
+		c *Conn,
+		r *registry.Root,
+		err *registry.LimitError,
+	) {
+		lc <- limitExceeded{c, r, err}
+	}
+
+	var rn, err = NewNode(rconf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sn.Close()
+	defer rn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, rn.Share(pk))
+
+	var (
+		reg = getTestRegistry()
+		sc  = sn.Container()
+
+		up *skyobject.Unpack
+	)
+
+	if up, err = sc.Unpack(sk, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	var r = new(registry.Root)
+
+	r.Nonce = 9021 // random
+	r.Pub = pk     // set
+
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}),
+	)
+
+	if err = sc.Save(up, r); err != nil {
+		t.Fatal(err)
+	}
+
+	var c *Conn
+	if c, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.Subscribe(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	<-time.After(TM)
+
+	// the Root can be received before the connection
+	// added to the feed, thus, send it again
+	sn.Publish(r)
+
+	var le limitExceeded
+
+	select {
+	case le = <-lc:
+	case <-time.After(4 * TM):
+		t.Fatal("slow")
+	}
+
+	if le.c != c {
+		t.Error("wrong connection")
+	}
+
+	if le.r.Hash != r.Hash {
+		t.Error("wrong Root")
+	}
+
+	if le.err.Name != "objects" || le.err.Limit != 2 {
+		t.Error("wrong error:", le.err)
+	}
+
+	if _, err = rn.Container().LastRoot(pk, r.Nonce); err == nil {
+		t.Error("the Root saved")
+	}
+
+}
@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/skycoin/cxo/node/log"
 	"github.com/skycoin/cxo/node/msg"
 	"github.com/skycoin/skycoin/src/cipher"
 )
@@ -76,6 +77,7 @@ type Swarm struct {
 	feed cipher.PubKey
 
 	node *Node
+	l    log.Logger // logger with "feed" field
 
 	quit chan struct{}
 	done chan struct{}
@@ -118,6 +120,7 @@ func newSwarm(
 		feed: f,
 
 		node: n,
+		l:    n.With("feed", f.Hex()),
 
 		quit: make(chan struct{}),
 		done: make(chan struct{}),
@@ -228,8 +231,9 @@ func (s *Swarm) requestPeers() {
 		wg.Add(1)
 
 		go func(c *Conn) {
-			s.node.Debugf(PEXPin, "requesting peers for feed %s, peer %s, addr %s",
-				s.feed.Hex()[:8], c.PeerID().Hex()[:8], c.Address())
+			s.l.Debugw(PEXPin, "requesting peers",
+				"peer", c.PeerID().Hex(),
+				"conn", c.String())
 
 			defer wg.Done()
 
@@ -239,8 +243,9 @@ func (s *Swarm) requestPeers() {
 			}
 			resp, err := c.sendRequest(req)
 			if err != nil {
-				s.node.Errorf(err, "failed to send request for feed %s, peer %s, addr %s",
-					s.feed.Hex()[:8], c.PeerID().Hex()[:8], c.Address())
+				s.l.Errorw(err, "failed to send request",
+					"peer", c.PeerID().Hex(),
+					"conn", c.String())
 				// TODO: maybe call s.incPeerRetryTimes(c.PeerID())
 				return
 			}
@@ -264,8 +269,9 @@ func (s *Swarm) requestPeers() {
 			}
 
 			if err != nil {
-				s.node.Errorf(err, "failed to request peers for feed %s, peer %s, addr %s",
-					s.feed.Hex()[:8], c.PeerID().Hex()[:8], c.Address())
+				s.l.Errorw(err, "failed to request peers",
+					"peer", c.PeerID().Hex(),
+					"conn", c.String())
 			}
 		}(conns[i])
 	}
@@ -285,12 +291,10 @@ func (s *Swarm) addPeers(peers []msg.PeerInfo) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.node.Debugf(PEXPin, "adding %d peers for feed %s",
-		len(peers), s.feed.Hex()[:8])
+	s.l.Debugw(PEXPin, "adding peers", "peers", len(peers))
 
 	if s.cfg.MaxPeers > 0 && s.cfg.MaxPeers <= uint64(len(s.peers)) {
-		s.node.Debugf(PEXPin, "feed %s already have maximum number of peers",
-			s.feed.Hex()[:8])
+		s.l.Debugw(PEXPin, "feed already have maximum number of peers")
 		return
 	}
 
@@ -301,8 +305,7 @@ func (s *Swarm) addPeers(peers []msg.PeerInfo) {
 			continue
 		}
 		if err := s.validatePeer(pi.PubKey, pi.TCPAddr, pi.UDPAddr); err != nil {
-			s.node.Errorf(err, "failed to add peer %s for feed %s",
-				pi.PubKey.Hex()[:8], s.feed.Hex()[:8])
+			s.l.Errorw(err, "failed to add peer", "peer", pi.PubKey.Hex())
 			continue
 		}
 		validPeers = append(validPeers, pi)
@@ -316,8 +319,8 @@ func (s *Swarm) addPeers(peers []msg.PeerInfo) {
 	if s.cfg.MaxPeers > 0 {
 		rcap := s.cfg.MaxPeers - uint64(len(s.peers))
 		if uint64(len(peers)) > rcap {
-			s.node.Debugf(PEXPin, "capping number of peers to be added for feed %s to %d",
-				s.feed.Hex()[:8], rcap)
+			s.l.Debugw(PEXPin, "capping number of peers to be added",
+				"cap", rcap)
 			peers = peers[:rcap]
 		}
 	}
@@ -326,19 +329,18 @@ func (s *Swarm) addPeers(peers []msg.PeerInfo) {
 	for _, pi := range peers {
 		p, ok := s.peers[pi.PubKey]
 		if !ok {
-			s.node.Debugf(PEXPin, "updating last seen time of peer %s for feed %s",
-				pi.PubKey.Hex()[:8], s.feed.Hex()[:8])
+			s.l.Debugw(PEXPin, "updating last seen time of peer",
+				"peer", pi.PubKey.Hex())
 			p.seen()
 
 			if ok = p.update(pi); ok {
-				s.node.Debugf(PEXPin, "updating info about peer %s for feed %s",
-					pi.PubKey.Hex()[:8], s.feed.Hex()[:8])
+				s.l.Debugw(PEXPin, "updating info about peer",
+					"peer", pi.PubKey.Hex())
 
 				s.onPeerUpdated(p)
 			}
 		} else {
-			s.node.Debugf(PEXPin, "adding new peer %s for feed %s",
-				pi.PubKey.Hex()[:8], s.feed.Hex()[:8])
+			s.l.Debugw(PEXPin, "adding new peer", "peer", pi.PubKey.Hex())
 
 			p = msgToPeer(pi)
 			s.onPeerAdded(p)
@@ -370,8 +372,7 @@ func (s *Swarm) clearOldPeers() {
 
 	for _, p := range s.peers {
 		if now.Sub(p.LastSeen) > s.cfg.PeerExpirePeriod {
-			s.node.Debugf(PEXPin, "removing expired peer %s from feed %s",
-				p.PubKey.Hex()[:8], s.feed.Hex()[:8])
+			s.l.Debugw(PEXPin, "removing expired peer", "peer", p.PubKey.Hex())
 
 			delete(s.peers, p.PubKey)
 			s.onPeerRemoved(p)
@@ -440,8 +441,7 @@ func (s *Swarm) createOutgoingConns(count uint64) {
 		wg.Add(1)
 
 		go func(p Peer) {
-			s.node.Debugf(PEXPin, "connecting to peer %s, sharing feed %s",
-				p.PubKey.Hex()[:8], s.feed.Hex()[:8])
+			s.l.Debugw(PEXPin, "connecting to peer", "peer", p.PubKey.Hex())
 
 			defer wg.Done()
 
@@ -452,12 +452,12 @@ func (s *Swarm) createOutgoingConns(count uint64) {
 
 			conn, err = s.node.TCP().Connect(p.TCPAddr)
 			if err != nil {
-				s.node.Errorf(err, "failed to connect to peer %s, sharing feed %s",
-					p.PubKey.Hex()[:8], s.feed.Hex()[:8])
+				s.l.Errorw(err, "failed to connect to peer",
+					"peer", p.PubKey.Hex())
 			} else {
 				if err = conn.Subscribe(s.feed); err != nil {
-					s.node.Errorf(err, "failed to subscribe for feed %s, shared by peer %s",
-						s.feed.Hex()[:8], p.PubKey.Hex()[:8])
+					s.l.Errorw(err, "failed to subscribe to feed of peer",
+						"peer", p.PubKey.Hex())
 				}
 			}
 
@@ -95,8 +95,8 @@ func (t *TCP) Address() string {
 // with given address already exists, then the Connect returns this
 // existing connection.
 func (t *TCP) Connect(address string) (*Conn, error) {
-	t.n.Debugf(NewOutConnPin, "[%s] connecting",
-		connString(false, true, address))
+	t.n.Debugw(NewOutConnPin, "connecting",
+		"conn", connString(false, true, address))
 
 	// Check if connections to/from address already exists.
 	c := t.getConn(address)
@@ -116,10 +116,10 @@ func (t *TCP) Connect(address string) (*Conn, error) {
 	if c, err = t.n.initConn(fc, false); err == nil {
 		t.addConn(c)
 	} else {
-		t.n.Errorf(err, "[%s] failed to connect", factoryConnStr(fc, false))
+		t.n.Errorw(err, "failed to connect", "conn", factoryConnStr(fc, false))
 		if !fc.IsClosed() {
-			t.n.Debugf(CloseConnPin, "[%s] closing factory.Connection",
-				factoryConnStr(fc, false))
+			t.n.Debugw(CloseConnPin, "closing factory.Connection",
+				"conn", factoryConnStr(fc, false))
 			fc.Close()
 		}
 	}
@@ -128,8 +128,7 @@ func (t *TCP) Connect(address string) (*Conn, error) {
 }
 
 func (t *TCP) acceptConn(fc *factory.Connection) {
-	t.n.Debugf(NewInConnPin, "[%s] accepting",
-		factoryConnStr(fc, true))
+	t.n.Debugw(NewInConnPin, "accepting", "conn", factoryConnStr(fc, true))
 
 	// Check if connections to/from address already exists.
 	var (
@@ -149,10 +148,10 @@ func (t *TCP) acceptConn(fc *factory.Connection) {
 	if err == nil {
 		t.addConn(c)
 	} else {
-		t.n.Errorf(err, "[%s] failed to accept", factoryConnStr(fc, true))
+		t.n.Errorw(err, "failed to accept", "conn", factoryConnStr(fc, true))
 		if !fc.IsClosed() {
-			t.n.Debugf(CloseConnPin, "[%s] closing factory.Connection",
-				factoryConnStr(fc, true))
+			t.n.Debugw(CloseConnPin, "closing factory.Connection",
+				"conn", factoryConnStr(fc, true))
 			fc.Close()
 		}
 	}
@@ -165,7 +164,7 @@ func (t *TCP) closeConn(addr string) error {
 
 	if c, ok := t.cs[addr]; ok {
 		if !c.Connection.IsClosed() {
-			t.n.Debugf(CloseConnPin, "[%s] closing factory.Connection", c.String())
+			c.l.Debugw(CloseConnPin, "closing factory.Connection")
 			c.Connection.Close()
 		}
 	} else {
@@ -256,19 +255,18 @@ func (t *TCP) findServiceNodes(resp *discovery.QueryResp) {
 
 			if yep == false {
 				if c, err = t.Connect(ni.Address); err != nil { // block
-					t.n.Debugf(DiscoveryPin, "can't Connect to tcp://%q: %v",
-						ni.Address,
-						err)
+					t.n.Debugw(DiscoveryPin, "can't Connect",
+						"address", "tcp://"+ni.Address,
+						"err", err)
 					continue
 				}
 			}
 
 			// block
 			if err = c.Subscribe(si.PubKey); err != nil {
-				t.n.Debugf(DiscoveryPin, "[%s] can't Subscribe to %s: %v",
-					c.Address(),
-					si.PubKey.Hex()[:7],
-					err)
+				c.l.Debugw(DiscoveryPin, "can't Subscribe",
+					"feed", si.PubKey.Hex(),
+					"err", err)
 			}
 
 			// continue
@@ -438,8 +436,8 @@ func (u *UDP) Address() string {
 // address already exists, then the Connect returns this
 // existing connection.
 func (u *UDP) Connect(address string) (*Conn, error) {
-	u.n.Debugf(NewOutConnPin, "[%s] connecting",
-		connString(false, false, address))
+	u.n.Debugw(NewOutConnPin, "connecting",
+		"conn", connString(false, false, address))
 
 	// Check if connections to/from address already exists.
 	c := u.getConn(address)
@@ -459,10 +457,10 @@ func (u *UDP) Connect(address string) (*Conn, error) {
 	if c, err = u.n.initConn(fc, false); err == nil {
 		u.addConn(c)
 	} else {
-		u.n.Errorf(err, "[%s] failed to connect", factoryConnStr(fc, false))
+		u.n.Errorw(err, "failed to connect", "conn", factoryConnStr(fc, false))
 		if !fc.IsClosed() {
-			u.n.Debugf(CloseConnPin, "[%] closing factory.Connection",
-				factoryConnStr(fc, false))
+			u.n.Debugw(CloseConnPin, "closing factory.Connection",
+				"conn", factoryConnStr(fc, false))
 			fc.Close()
 		}
 	}
@@ -471,8 +469,7 @@ func (u *UDP) Connect(address string) (*Conn, error) {
 }
 
 func (u *UDP) acceptConn(fc *factory.Connection) {
-	u.n.Debugf(NewInConnPin, "[%s] accepting",
-		factoryConnStr(fc, true))
+	u.n.Debugw(NewInConnPin, "accepting", "conn", factoryConnStr(fc, true))
 
 	// Check if connections to/from address already exists.
 	var (
@@ -492,10 +489,10 @@ func (u *UDP) acceptConn(fc *factory.Connection) {
 	if err == nil {
 		u.addConn(c)
 	} else {
-		u.n.Errorf(err, "[%s] failed to accept", factoryConnStr(fc, true))
+		u.n.Errorw(err, "failed to accept", "conn", factoryConnStr(fc, true))
 		if !fc.IsClosed() {
-			u.n.Debugf(CloseConnPin, "[%s] closing factory.Connection",
-				factoryConnStr(fc, true))
+			u.n.Debugw(CloseConnPin, "closing factory.Connection",
+				"conn", factoryConnStr(fc, true))
 			fc.Close()
 		}
 	}
@@ -508,7 +505,7 @@ func (u *UDP) closeConn(addr string) error {
 
 	if c, ok := u.cs[addr]; ok {
 		if !c.Connection.IsClosed() {
-			u.n.Debugf(CloseConnPin, "[%s] closing factory.Connection", c.String())
+			c.l.Debugw(CloseConnPin, "closing factory.Connection")
 			c.Connection.Close()
 		}
 	} else {
@@ -599,19 +596,18 @@ func (u *UDP) findServiceNodes(resp *discovery.QueryResp) {
 
 			if yep == false {
 				if c, err = u.Connect(ni.Address); err != nil { // block
-					u.n.Debugf(DiscoveryPin, "can't Connect to udp://%q: %v",
-						ni.Address,
-						err)
+					u.n.Debugw(DiscoveryPin, "can't Connect",
+						"address", "udp://"+ni.Address,
+						"err", err)
 					continue
 				}
 			}
 
 			// block
 			if err = c.Subscribe(si.PubKey); err != nil {
-				u.n.Debugln(DiscoveryPin, "[%s] can't Subscribe to %s: %v",
-					c.Address(),
-					si.PubKey.Hex()[:7],
-					err)
+				c.l.Debugw(DiscoveryPin, "can't Subscribe",
+					"feed", si.PubKey.Hex(),
+					"err", err)
 			}
 
 			// continue
@@ -0,0 +1,409 @@
+package skyobject
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds"
+	"github.com/skycoin/cxo/data/idxdb"
+)
+
+// BackupVersion is version of backup format
+const BackupVersion uint32 = 2
+
+// backup related errors
+var (
+	ErrBackupNotSupported = errors.New("the DB doesn't support backups")
+	ErrNotBackup          = errors.New("not a backup")
+	ErrBackupVersion      = errors.New("unsupported version of backup")
+)
+
+// beginning of every backup
+var backupMagic = [4]byte{'C', 'X', 'O', 'B'}
+
+// header of a backup, the header followed by CXDS
+// and IdxDB (database files) and by increments of
+// fillers (see backupFinc); all the fields are
+// big-endian encoded
+type backupHeader struct {
+	Magic   [4]byte // backupMagic
+	Version uint32  // BackupVersion
+
+	CXDSVersion  uint32 // cxds.Version
+	IdxDBVersion uint32 // idxdb.Version
+
+	CXDSSize  int64 // size of the CXDS
+	IdxDBSize int64 // size of the IdxDB
+
+	Fincs int64 // number of increments of fillers
+}
+
+// rc of objects in CXDS includes increments of
+// fillers (of Root objects being filled), the
+// increments are not a part of the backup and
+// the Restore subtracts them
+type backupFinc struct {
+	Key cipher.SHA256 // object
+	Inc uint32        // increments of fillers
+}
+
+// Backup writes consistent snapshot of DB of the Container
+// to given writer. The Container is not blocked while the
+// snapshot being written. The CXDS and the IdxDB must
+// support snapshots (see data.Snapshotter). The boltdb
+// based databases support them. Otherwise, the Backup
+// returns ErrBackupNotSupported. Use Restore to restore
+// the backup. Objects of Root objects being filled at
+// the moment are kept in the backup, but the Root
+// objects are not. Increments of the fillers are not
+// kept too, and rc of the objects will be real after
+// the Restore
+func (c *Container) Backup(w io.Writer) (err error) {
+
+	var (
+		cs, is data.Snapshot
+		fincs  []backupFinc
+	)
+
+	if cs, is, fincs, err = c.snapshots(); err != nil {
+		return
+	}
+
+	defer cs.Close()
+	defer is.Close()
+
+	var hd = backupHeader{
+		Magic:        backupMagic,
+		Version:      BackupVersion,
+		CXDSVersion:  uint32(cxds.Version),
+		IdxDBVersion: uint32(idxdb.Version),
+		CXDSSize:     cs.Size(),
+		IdxDBSize:    is.Size(),
+		Fincs:        int64(len(fincs)),
+	}
+
+	if err = binary.Write(w, binary.BigEndian, &hd); err != nil {
+		return
+	}
+
+	if _, err = cs.WriteTo(w); err != nil {
+		return
+	}
+
+	if _, err = is.WriteTo(w); err != nil {
+		return
+	}
+
+	if len(fincs) == 0 {
+		return
+	}
+
+	return binary.Write(w, binary.BigEndian, fincs)
+}
+
+// BackupFile is the same as the Backup, but it writes the
+// backup to file with given name. If the file exists, then
+// it will be replaced when the backup has been written
+func (c *Container) BackupFile(name string) (err error) {
+
+	var (
+		tmp = name + ".tmp"
+		fl  *os.File
+	)
+
+	if fl, err = os.Create(tmp); err != nil {
+		return
+	}
+
+	if err = c.Backup(fl); err == nil {
+		err = fl.Sync()
+	}
+
+	if cerr := fl.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		os.Remove(tmp) // clean up
+		return
+	}
+
+	return os.Rename(tmp, name)
+}
+
+// snapshots of CXDS and IdxDB of the Container and
+// increments of fillers; the snapshots are consistent
+func (c *Container) snapshots() (
+	cs data.Snapshot, //      :
+	is data.Snapshot, //      :
+	fincs []backupFinc, //    :
+	err error, //             :
+) {
+
+	var (
+		csr, csok = c.db.CXDS().(data.Snapshotter)
+		isr, isok = c.db.IdxDB().(data.Snapshotter)
+	)
+
+	if csok == false || isok == false {
+		return nil, nil, nil, ErrBackupNotSupported
+	}
+
+	// lock the Index and the Cache (this order)
+	// to get snapshots of the same state
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	c.Cache.mx.Lock()
+	defer c.Cache.mx.Unlock()
+
+	if err = c.Cache.flush(); err != nil {
+		return
+	}
+
+	if cs, err = csr.Snapshot(); err != nil {
+		return
+	}
+
+	if is, err = isr.Snapshot(); err != nil {
+		cs.Close()
+		return nil, nil, nil, err
+	}
+
+	fincs = c.Cache.fincs()
+	return
+}
+
+// flush changes of rc of cached items
+// to CXDS (write-behind) under lock
+func (c *Cache) flush() (err error) {
+
+	for key, it := range c.is {
+
+		var inc = it.cc - it.rc // not synced
+
+		if inc == 0 {
+			continue
+		}
+
+		if _, err = c.db().Inc(key, inc); err != nil {
+			return
+		}
+
+		c.stat.addWritingDBRequest() // write DB
+
+		it.rc = it.cc // synced
+	}
+
+	return
+}
+
+// increments of fillers, the increments are
+// included in rc of the objects in CXDS;
+// under lock
+func (c *Cache) fincs() (fincs []backupFinc) {
+
+	for key, it := range c.is {
+		if it.fc > 0 {
+			fincs = append(fincs, backupFinc{key, uint32(it.fc)})
+		}
+	}
+
+	return
+}
+
+// Restore restores backup (see Backup) from given reader
+// to database files with given names. The files must not
+// exist. Versions of the databases of the backup must be
+// equal to versions of this CXO (see Version of data/cxds
+// and data/idxdb packages). Otherwise, the Restore returns
+// ErrOldVersion or ErrNewVersion of data/cxds or
+// data/idxdb package. The restored databases are checked
+// out by opening. The Restore doesn't change anything if
+// it fails. Use the files to create a Container
+//
+//     err = skyobject.RestoreFile("cxo.backup",
+//         "/path/to/db.cxds", "/path/to/db.idx")
+//
+//     // [...]
+//
+//     var conf = skyobject.NewConfig()
+//     conf.DBPath = "/path/to/db" // the restored DB
+//
+func Restore(r io.Reader, cxdsName, idxdbName string) (err error) {
+
+	var hd backupHeader
+
+	if err = binary.Read(r, binary.BigEndian, &hd); err != nil {
+		return
+	}
+
+	if err = hd.validate(); err != nil {
+		return
+	}
+
+	if err = restoreFile(r, cxdsName, hd.CXDSSize); err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(cxdsName) // clean up
+		}
+	}()
+
+	if err = restoreFile(r, idxdbName, hd.IdxDBSize); err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(idxdbName) // clean up
+		}
+	}()
+
+	var fincs []backupFinc
+	if fincs, err = readFincs(r, hd.Fincs); err != nil {
+		return
+	}
+
+	// check out the databases, and remove
+	// increments of fillers from the CXDS
+
+	var ds data.CXDS
+	if ds, err = cxds.NewDriveCXDS(cxdsName); err != nil {
+		return
+	}
+
+	if err = restoreFincs(ds, fincs); err != nil {
+		ds.Close()
+		return
+	}
+
+	if err = ds.Close(); err != nil {
+		return
+	}
+
+	var idx data.IdxDB
+	if idx, err = idxdb.NewDriveIdxDB(idxdbName); err != nil {
+		return
+	}
+
+	return idx.Close()
+}
+
+// read given number of increments of fillers one by one;
+// the number is taken from header of the backup and can't
+// be used to allocate memory before the increments read
+func readFincs(r io.Reader, n int64) (fincs []backupFinc, err error) {
+
+	var fi backupFinc
+
+	for i := int64(0); i < n; i++ {
+		if err = binary.Read(r, binary.BigEndian, &fi); err != nil {
+			if err == io.EOF {
+				err = fmt.Errorf("unexpected end of backup: %d"+
+					" increments of fillers of %d read", i, n)
+			}
+			return nil, err
+		}
+		fincs = append(fincs, fi)
+	}
+
+	return
+}
+
+// subtract increments of fillers
+func restoreFincs(ds data.CXDS, fincs []backupFinc) (err error) {
+
+	for _, fi := range fincs {
+		if _, err = ds.Inc(fi.Key, -int(fi.Inc)); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// RestoreFile is the same as the Restore, but it
+// reads backup from file with given name
+func RestoreFile(name, cxdsName, idxdbName string) (err error) {
+
+	var fl *os.File
+	if fl, err = os.Open(name); err != nil {
+		return
+	}
+	defer fl.Close()
+
+	return Restore(fl, cxdsName, idxdbName)
+}
+
+// validate header of a backup
+func (b *backupHeader) validate() (err error) {
+
+	if bytes.Equal(b.Magic[:], backupMagic[:]) == false {
+		return ErrNotBackup
+	}
+
+	if b.Version != BackupVersion {
+		return ErrBackupVersion
+	}
+
+	switch vers := int(b.CXDSVersion); {
+	case vers < cxds.Version:
+		return cxds.ErrOldVersion
+	case vers > cxds.Version:
+		return cxds.ErrNewVersion
+	}
+
+	switch vers := int(b.IdxDBVersion); {
+	case vers < idxdb.Version:
+		return idxdb.ErrOldVersion
+	case vers > idxdb.Version:
+		return idxdb.ErrNewVersion
+	}
+
+	if b.CXDSSize <= 0 || b.IdxDBSize <= 0 || b.Fincs < 0 {
+		return ErrNotBackup
+	}
+
+	return
+}
+
+// copy given number of bytes from given reader to
+// new file with given name, the file must not exist
+func restoreFile(r io.Reader, name string, size int64) (err error) {
+
+	var fl *os.File
+	fl, err = os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+
+	if err != nil {
+		return
+	}
+
+	var n int64
+	if n, err = io.CopyN(fl, r, size); err == nil {
+		err = fl.Sync()
+	} else if err == io.EOF {
+		err = fmt.Errorf("unexpected end of backup: %d bytes of %d read",
+			n, size)
+	}
+
+	if cerr := fl.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		os.Remove(name) // clean up
+	}
+
+	return
+}
@@ -0,0 +1,162 @@
+package skyobject
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_Backup(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-backup")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var conf = getTestConfig()
+	conf.InMemoryDB = false
+	conf.DBPath = filepath.Join(dir, "db")
+
+	var c *Container
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{
+			Name: "Alice",
+			Age:  21,
+		}),
+	}
+
+	assertNil(t, c.Save(up, r))
+
+	var buf bytes.Buffer
+	assertNil(t, c.Backup(&buf))
+	assertNil(t, c.Close())
+
+	var (
+		backup    = buf.Bytes()
+		cxdsName  = filepath.Join(dir, "restored.cxds")
+		idxdbName = filepath.Join(dir, "restored.idx")
+	)
+
+	// broken
+	assertTrue(t, Restore(bytes.NewReader(backup[:len(backup)-1]),
+		cxdsName, idxdbName) != nil, "missing error")
+
+	_, err = os.Stat(cxdsName)
+	assertTrue(t, os.IsNotExist(err), "not cleaned up")
+
+	// huge number of increments of fillers in header
+	var huge = append([]byte{}, backup...)
+	binary.BigEndian.PutUint64(huge[32:], 1<<62)
+	assertTrue(t, Restore(bytes.NewReader(huge), cxdsName,
+		idxdbName) != nil, "missing error")
+
+	_, err = os.Stat(idxdbName)
+	assertTrue(t, os.IsNotExist(err), "not cleaned up")
+
+	assertNil(t, Restore(bytes.NewReader(backup), cxdsName, idxdbName))
+
+	// files exist
+	assertTrue(t, Restore(bytes.NewReader(backup), cxdsName, idxdbName) != nil,
+		"missing error")
+
+	conf.DBPath = filepath.Join(dir, "restored")
+
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var rr *registry.Root
+	rr, err = c.LastRoot(pk, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, rr.Hash == r.Hash, "wrong Root restored")
+
+	var pack *Pack
+	pack, err = c.Pack(rr, nil)
+	assertNil(t, err)
+
+	var usr User
+	assertNil(t, rr.Refs[0].Value(pack, &usr))
+	assertTrue(t, usr.Name == "Alice", "wrong object restored")
+
+}
+
+func TestContainer_Backup_filling(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-backup")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var conf = getTestConfig()
+	conf.InMemoryDB = false
+	conf.DBPath = filepath.Join(dir, "db")
+
+	var c *Container
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+
+	// object received by a filler, but the filling
+	// is not finished yet (not applied, not rejected)
+
+	var (
+		val = []byte("filling")
+		key = cipher.SumSHA256(val)
+		gc  = make(chan Object, 1)
+	)
+
+	assertNil(t, c.Want(key, gc, 1))
+
+	_, err = c.SetWanted(key, val)
+	assertNil(t, err)
+
+	var buf bytes.Buffer
+	assertNil(t, c.Backup(&buf))
+
+	// the fill increments are kept by the Container
+	var rc uint32
+	_, rc, err = c.db.CXDS().Get(key, 0)
+	assertNil(t, err)
+	assertTrue(t, rc == 1, "wrong rc after backup")
+
+	assertNil(t, c.Finc(key, -1)) // reject
+	assertNil(t, c.Close())
+
+	var (
+		cxdsName  = filepath.Join(dir, "restored.cxds")
+		idxdbName = filepath.Join(dir, "restored.idx")
+	)
+
+	assertNil(t, Restore(bytes.NewReader(buf.Bytes()), cxdsName, idxdbName))
+
+	conf.DBPath = filepath.Join(dir, "restored")
+
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	// but the backup keeps real rc
+	_, rc, err = c.db.CXDS().Get(key, 0)
+	assertNil(t, err)
+	assertTrue(t, rc == 0, "wrong rc restored")
+
+}
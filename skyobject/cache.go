@@ -1144,8 +1144,9 @@ func (c *Cache) SetWanted(
 
 // Finc is like the Inc, but it used by fillers to
 // apply incs of filler or reject them. See the
-// Want for details. The method does nothing is
-// item with given key is not a filling item.
+// Want for details. The apply does nothing if
+// item with given key is not a filling item,
+// and the reject decrements rc of such item.
 // If the inc argument greater then zero, then
 // it is the apply. Otherwise, it is the reject.
 // The Finc must be called after the moment when
@@ -1168,12 +1169,23 @@ func (c *Cache) Finc(
 
 	var it, ok = c.is[key]
 
+	// a filler gets objects the DB already has incrementing
+	// their rc (not fc) to hold them; thus, the apply does
+	// nothing for such objects, but the reject rolls the
+	// increment back, otherwise the objects leak
+
 	if ok == false {
+		if inc < 0 {
+			_, err = c.inc(key, inc) // in db
+		}
 		return
 	}
 
 	if it.fc == 0 {
-		return // not a filling item
+		if inc < 0 {
+			_, err = c.incItem(key, inc, it) // not a filling item
+		}
+		return
 	}
 
 	// apply
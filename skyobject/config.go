@@ -1,13 +1,16 @@
 package skyobject
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/crypt"
 	"github.com/skycoin/cxo/node/log"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
@@ -35,10 +38,16 @@ const (
 
 	MaxFillingParallel int = 10 // ten parallel subtrees
 
+	// retention
+
+	RetentionInterval time.Duration = 0 // don't prune
+
 	// DB related constants
 	CXDS  string = "cxds.db" // default CXDS file name
 	IdxDB string = "idx.db"  // default IdxDB file name
 
+	CompressMinSize int = 128 // compress objects not shorter
+
 	PackSavePin       log.Pin = 1 << iota // show time of (*Pack).Save in logs
 	CleanUpVerbosePin                     // show collecting and removing times
 	FillVerbosePin                        // show filling debug logs
@@ -168,6 +177,12 @@ type Config struct {
 	// to number of connections that used to fill a Root.
 	MaxFillingParallel int
 
+	// RetentionInterval is interval of pruning. The Container
+	// calls the Prune method with the interval to remove old
+	// Root objects (see Retention). Set it to zero to turn
+	// the pruning off
+	RetentionInterval time.Duration
+
 	// DB configs
 
 	// CheckSizes force Container to check sizes of objects
@@ -192,6 +207,35 @@ type Config struct {
 	// be sure that path created. The DBPath used for tests
 	// and examples. But it can be used for other
 	DBPath string
+	// Compression of objects in on-drive DB. Objects
+	// shorter then CompressMinSize are stored raw. The
+	// compression is transparent and doesn't affect
+	// hashes. The DB can contain raw and compressed
+	// objects. Thus, the compression can be turned on
+	// and off any time. The option is ignored if the
+	// DB field (see below) is provided or if the
+	// InMemoryDB is true. Encrypted objects can't be
+	// compressed, thus the option is ignored if the
+	// DB is encrypted (see KeyFile and Passphrase).
+	// See also cxds.NewDriveCXDSCompressed
+	Compression bool
+	// KeyFile is path to file with key used to encrypt
+	// DB (see data/crypt package). Values of objects,
+	// meta information of feeds and signatures of Root
+	// objects are encrypted, but hashes are not. The
+	// KeyFile and the Passphrase are mutually exclusive.
+	// If both are empty, then the DB is not encrypted.
+	// The key should be the same every time. Use the
+	// RotateKey to change it or to encrypt existing
+	// not encrypted DB (see crypt.ErrPlaintextDB).
+	// The KeyFile affects the DB field too
+	KeyFile string
+	// Passphrase is used to derive key to encrypt DB
+	// (see crypt.NewDBPassphrase). Random salt of the
+	// passphrase is kept in the DB. The same as the
+	// KeyFile. A wrong key or passphrase is detected
+	// when the DB is opened (see crypt.ErrWrongKey)
+	Passphrase string
 	// DataDir will be created if it's not empty. If DB field
 	// of the config is nil, InMemoryDB is false and DBPath
 	// is empty, then database will be created under the
@@ -221,6 +265,8 @@ func NewConfig() (conf *Config) {
 
 	conf.MaxObjectSize = MaxObjectSize
 
+	conf.RetentionInterval = RetentionInterval
+
 	// data dir
 	conf.DataDir = DataDir()
 
@@ -242,6 +288,50 @@ func (c *Config) FromFlags() {
 		"db-path",
 		c.DBPath,
 		"path to database")
+	flag.BoolVar(&c.Compression,
+		"db-compression",
+		c.Compression,
+		"compress objects in database")
+	flag.StringVar(&c.KeyFile,
+		"db-key-file",
+		c.KeyFile,
+		"path to file with key to encrypt database")
+	flag.DurationVar(&c.RetentionInterval,
+		"retention-interval",
+		c.RetentionInterval,
+		"interval of pruning of old Root objects, zero to disable")
+}
+
+// is DB encrypted (see KeyFile and Passphrase)
+func (c *Config) encrypted() bool {
+	return c.KeyFile != "" || c.Passphrase != ""
+}
+
+// encrypt given DB using the KeyFile or the
+// Passphrase, the encrypt returns given DB
+// if the DB should not be encrypted
+func (c *Config) encrypt(db *data.DB) (edb *data.DB, err error) {
+
+	switch {
+	case c.KeyFile != "":
+		var key crypt.Key
+		if key, err = crypt.LoadKeyFile(c.KeyFile); err != nil {
+			return
+		}
+		return crypt.NewDB(db, key)
+	case c.Passphrase != "":
+		return crypt.NewDBPassphrase(db, c.Passphrase)
+	}
+
+	return db, nil // not encrypted
+}
+
+// paths to CXDS and IdxDB files
+func (c *Config) dbPaths() (cxPath, idxPath string) {
+	if c.DBPath == "" {
+		return filepath.Join(c.DataDir, CXDS), filepath.Join(c.DataDir, IdxDB)
+	}
+	return c.DBPath + ".cxds", c.DBPath + ".idx"
 }
 
 // Validate the Config
@@ -292,6 +382,16 @@ func (c *Config) Validate() error {
 			c.CacheMaxItemSize, cacheMaxItemSize)
 	}
 
+	if c.RetentionInterval < 0 {
+		return fmt.Errorf("skyobject.Config.RetentionInterval is negative: %s",
+			c.RetentionInterval)
+	}
+
+	if c.KeyFile != "" && c.Passphrase != "" {
+		return errors.New("skyobject.Config: both KeyFile and Passphrase " +
+			"provided")
+	}
+
 	if c.MaxObjectSize < 1024 {
 		return fmt.Errorf("skyobject.Config.MAxObjectSize is too small: %d",
 			c.MaxObjectSize)
@@ -2,7 +2,7 @@ package skyobject
 
 import (
 	"log"
-	"path/filepath"
+	"sync"
 
 	"github.com/skycoin/skycoin/src/cipher"
 
@@ -27,6 +27,11 @@ type Container struct {
 
 	// human readable (used by node for debugging)
 	cxPath, idxPath string
+
+	// pruning (see Retention)
+	quit   chan struct{}
+	await  sync.WaitGroup
+	closeo sync.Once
 }
 
 // HumanCXDSPath returns human readable path
@@ -79,6 +84,8 @@ func NewContainer(conf *Config) (c *Container, err error) {
 		return
 	}
 
+	c.startPruning()
+
 	return // done
 }
 
@@ -90,7 +97,10 @@ func (c *Container) createDB(conf *Config) (err error) {
 		}
 	}
 
-	var db *data.DB
+	var (
+		db  *data.DB
+		enc = conf.encrypted()
+	)
 
 	if conf.DB != nil {
 
@@ -104,35 +114,54 @@ func (c *Container) createDB(conf *Config) (err error) {
 
 	} else {
 
-		if conf.DBPath == "" {
-			c.cxPath = filepath.Join(conf.DataDir, CXDS)
-			c.idxPath = filepath.Join(conf.DataDir, IdxDB)
-		} else {
-			c.cxPath = conf.DBPath + ".cxds"
-			c.idxPath = conf.DBPath + ".idx"
-		}
+		c.cxPath, c.idxPath = conf.dbPaths()
 
-		var cx data.CXDS
-		var idx data.IdxDB
-
-		if cx, err = cxds.NewDriveCXDS(c.cxPath); err != nil {
+		if err = finishRotate(c.cxPath, c.idxPath); err != nil {
 			return
 		}
 
-		if idx, err = idxdb.NewDriveIdxDB(c.idxPath); err != nil {
-			cx.Close()
+		var minSize = -1 // no compression
+		if conf.Compression == true && enc == false {
+			minSize = CompressMinSize
+		}
+
+		if db, err = openDriveDB(c.cxPath, c.idxPath, minSize); err != nil {
 			return
 		}
 
-		db = data.NewDB(cx, idx)
+	}
 
+	var edb *data.DB
+	if edb, err = conf.encrypt(db); err != nil {
+		if conf.DB == nil {
+			db.Close() // opened by the Container
+		}
+		return
 	}
 
-	c.db = db
+	c.db = edb
 
 	return
 }
 
+// open or create boltdb based CXDS and IdxDB
+func openDriveDB(cxPath, idxPath string, minSize int) (db *data.DB, err error) {
+
+	var cx data.CXDS
+	var idx data.IdxDB
+
+	if cx, err = cxds.NewDriveCXDSCompressed(cxPath, minSize); err != nil {
+		return
+	}
+
+	if idx, err = idxdb.NewDriveIdxDB(idxPath); err != nil {
+		cx.Close()
+		return
+	}
+
+	return data.NewDB(cx, idx), nil
+}
+
 type rcs struct {
 	rc uint32 // saved rc (DB)
 	cc uint32 // correct rc (determined by walking)
@@ -344,8 +373,8 @@ func (c *Container) RootByHash(
 		return
 	}
 
-	r.Sig = dr.Sig
 	r.IsFull = true
+	err = setRootSigs(r, dr)
 	return
 }
 
@@ -354,6 +383,8 @@ func (c *Container) RootByHash(
 // with user-provided DB.
 func (c *Container) Close() (err error) {
 
+	c.stopPruning()
+
 	// the Cache.Close closes CXDS
 	if err = c.Cache.Close(); err == nil {
 		err = c.db.Close()
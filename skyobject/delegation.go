@@ -0,0 +1,200 @@
+package skyobject
+
+import (
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// Revocations are stored as meta information of a feed
+// (see data.Feeds), the key is "r" + revoked key
+
+func revocationKey(key cipher.PubKey) []byte {
+	return append([]byte("r"), key[:]...)
+}
+
+// Revoke adds given Revocation of a key of a feed. Root objects
+// signed by the key (see registry.Certificate) will not be
+// accepted after this call, regardless of their Time. Root
+// objects saved before are kept. The Revocation can be carried
+// by a Root also (see Revs field of registry.Root). A Root that carries a
+// Revocation adds it to a Container that receives or saves
+// the Root. The Revoke returns data.ErrNoSuchFeed if the
+// Container doesn't have the feed
+func (c *Container) Revoke(rev *registry.Revocation) (err error) {
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	return c.db.IdxDB().Tx(func(fs data.Feeds) error {
+		return storeRevocation(fs, rev.Feed, rev)
+	})
+}
+
+// Revocation returns Revocation of given key of given feed.
+// It returns data.ErrNotFound if the key is not revoked
+func (c *Container) Revocation(
+	feed cipher.PubKey,
+	key cipher.PubKey,
+) (
+	rev *registry.Revocation,
+	err error,
+) {
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	err = c.db.IdxDB().Tx(func(fs data.Feeds) (err error) {
+
+		var val []byte
+		if val, err = fs.GetMeta(feed, revocationKey(key)); err != nil {
+			return
+		}
+
+		rev, err = registry.DecodeRevocation(val)
+		return
+	})
+
+	return
+}
+
+// verify and store given Revocation of given feed,
+// if the key already revoked, then the earliest
+// Revocation is kept
+func storeRevocation(
+	fs data.Feeds,
+	feed cipher.PubKey,
+	rev *registry.Revocation,
+) (
+	err error,
+) {
+
+	if rev.Feed != feed {
+		return ErrRevocationFeed
+	}
+
+	if err = rev.Verify(); err != nil {
+		return
+	}
+
+	var ex *registry.Revocation
+	if ex, err = revocation(fs, feed, rev.Key); err != nil {
+		return
+	}
+
+	if ex != nil && ex.Time <= rev.Time {
+		return // keep the earliest
+	}
+
+	return fs.SetMeta(feed, revocationKey(rev.Key), rev.Encode())
+}
+
+// store Revocations carried by given Root
+func storeRootRevocations(fs data.Feeds, r *registry.Root) (err error) {
+	for i := range r.Revs {
+		if err = storeRevocation(fs, r.Pub, &r.Revs[i]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// revocation returns Revocation of given key of
+// given feed or nil if the key is not revoked
+func revocation(
+	fs data.Feeds,
+	feed cipher.PubKey,
+	key cipher.PubKey,
+) (
+	rev *registry.Revocation,
+	err error,
+) {
+
+	var val []byte
+
+	switch val, err = fs.GetMeta(feed, revocationKey(key)); err {
+	case nil:
+		return registry.DecodeRevocation(val)
+	case data.ErrNotFound, data.ErrNoSuchFeed:
+		return nil, nil
+	}
+
+	return
+}
+
+// verify Certificate of given Root signed by an
+// authorized key at given time, the method doesn't
+// verify signature of the Root; it should be called
+// inside a transaction after storing Revocations
+// carried by the Root
+func verifyRootCertificate(
+	fs data.Feeds,
+	r *registry.Root,
+	now time.Time,
+) (
+	err error,
+) {
+
+	if err = r.Cert.Allows(r, now); err != nil {
+		return
+	}
+
+	var rev *registry.Revocation
+	if rev, err = revocation(fs, r.Pub, r.Cert.Key); err != nil {
+		return
+	}
+
+	if rev != nil && rev.Revokes(r) == true {
+		return ErrRevokedKey
+	}
+
+	return
+}
+
+// verify received Root signed by an authorized key or
+// a Root that carries Revocations, the Revocations are
+// stored if this Container has the feed (under lock)
+func (i *Index) verifyCertifiedRoot(r *registry.Root) (err error) {
+
+	var (
+		signer = r.Pub
+		now    = time.Now()
+	)
+
+	if r.Cert != nil {
+		signer = r.Cert.Key
+	}
+
+	err = cipher.VerifyPubKeySignedHash(signer, r.Sig, r.SigHash())
+
+	if err != nil {
+		return
+	}
+
+	return i.c.db.IdxDB().Tx(func(fs data.Feeds) (err error) {
+
+		if err = storeRootRevocations(fs, r); err == data.ErrNoSuchFeed {
+			err = nil // preview, revocations of the Root are checked below
+		}
+
+		if err != nil || r.Cert == nil {
+			return
+		}
+
+		if err = verifyRootCertificate(fs, r, now); err != nil {
+			return
+		}
+
+		for j := range r.Revs {
+			if r.Revs[j].Revokes(r) == true {
+				return ErrRevokedKey
+			}
+		}
+
+		return
+	})
+
+}
@@ -0,0 +1,188 @@
+package skyobject
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_delegation(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+
+		feed, fsk = cipher.GenerateKeyPair()
+		hot, hsk  = cipher.GenerateKeyPair()
+	)
+
+	defer sc.Close()
+	defer rc.Close()
+
+	assertNil(t, sc.AddFeed(feed))
+	assertNil(t, rc.AddFeed(feed))
+
+	var cert, err = registry.NewCertificate(fsk, hot, 1,
+		time.Now().Add(time.Hour))
+	assertNil(t, err)
+
+	var up *Unpack
+	up, err = sc.Unpack(hsk, testRegistry)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+	r.Pub = feed
+	r.Nonce = 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+	}
+	r.Cert = cert
+
+	assertNil(t, sc.Save(up, r))
+
+	var lr *registry.Root
+	lr, err = sc.LastRoot(feed, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, lr.Cert != nil && *lr.Cert == *cert, "missing Certificate")
+
+	// wrong key
+	var wup *Unpack
+	wup, err = sc.Unpack(fsk, testRegistry)
+	assertNil(t, err)
+	assertTrue(t, sc.Save(wup, r) == ErrCertificateKey, "wrong key")
+
+	// wrong head
+	r.Nonce = 2
+	assertTrue(t, sc.Save(up, r) == registry.ErrCertificateHead, "wrong head")
+	r.Nonce = 1
+
+	// received
+
+	var rr *registry.Root
+	rr, err = rc.ReceivedRoot(lr.Pub, lr.Sig, lr.Encode(), lr.EncodeSigs())
+	assertNil(t, err)
+	assertTrue(t, rr.Cert != nil, "missing Certificate")
+
+	_, err = rc.ReceivedRoot(lr.Pub, lr.Sig, lr.Encode(), nil)
+	assertTrue(t, err != nil, "missing error")
+
+	testFillRoot(t, sc, rc, rr)
+
+	// revoke by side channel
+
+	var rev *registry.Revocation
+	rev, err = registry.NewRevocation(fsk, hot)
+	assertNil(t, err)
+
+	_, err = rc.Revocation(feed, hot)
+	assertTrue(t, err == data.ErrNotFound, "revoked")
+
+	assertNil(t, rc.Revoke(rev))
+
+	var gr *registry.Revocation
+	gr, err = rc.Revocation(feed, hot)
+	assertNil(t, err)
+	assertTrue(t, *gr == *rev, "wrong Revocation")
+
+	// received before the Revocation is kept
+	var kr *registry.Root
+	kr, err = rc.LastRoot(feed, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, kr.Hash == lr.Hash, "wrong Root kept")
+
+	// but it's not accepted anymore
+	_, err = rc.ReceivedRoot(lr.Pub, lr.Sig, lr.Encode(), lr.EncodeSigs())
+	assertTrue(t, err == ErrRevokedKey, "revoked key accepted")
+
+	// signed after the Revocation (the sc doesn't know about it)
+	assertNil(t, sc.Save(up, r))
+	lr, err = sc.LastRoot(feed, r.Nonce)
+	assertNil(t, err)
+
+	_, err = rc.ReceivedRoot(lr.Pub, lr.Sig, lr.Encode(), lr.EncodeSigs())
+	assertTrue(t, err == ErrRevokedKey, "revoked key accepted")
+
+	// backdated by the revoked key
+	var br = *lr
+	br.Time = rev.Time - int64(time.Hour)
+	br.Hash = cipher.SumSHA256(br.Encode())
+	br.Sig, err = cipher.SignHash(br.SigHash(), hsk)
+	assertNil(t, err)
+
+	_, err = rc.ReceivedRoot(br.Pub, br.Sig, br.Encode(), br.EncodeSigs())
+	assertTrue(t, err == ErrRevokedKey, "backdated Root of revoked key accepted")
+
+	// revoke by a Root
+
+	var mup *Unpack
+	mup, err = sc.Unpack(fsk, testRegistry)
+	assertNil(t, err)
+
+	r.Cert = nil
+	r.Revs = []registry.Revocation{*rev}
+	assertNil(t, sc.Save(mup, r))
+
+	r.Cert, r.Revs = cert, nil
+	assertTrue(t, sc.Save(up, r) == ErrRevokedKey, "revoked key accepted")
+
+	// revocation carried by received Root
+
+	var xc = getTestContainer()
+	defer xc.Close()
+
+	assertNil(t, xc.AddFeed(feed))
+
+	lr, err = sc.LastRoot(feed, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, len(lr.Revs) == 1, "missing Revocation")
+
+	// stripped by a relay
+	var stripped = *lr
+	stripped.Revs = nil
+	_, err = xc.ReceivedRoot(lr.Pub, lr.Sig, lr.Encode(), stripped.EncodeSigs())
+	assertTrue(t, err != nil, "Root with stripped Revocations accepted")
+
+	_, err = xc.ReceivedRoot(lr.Pub, lr.Sig, lr.Encode(), lr.EncodeSigs())
+	assertNil(t, err)
+
+	_, err = xc.Revocation(feed, hot)
+	assertNil(t, err)
+
+	// expired
+
+	var eup *Unpack
+	_, esk := cipher.GenerateKeyPair()
+	eup, err = sc.Unpack(esk, testRegistry)
+	assertNil(t, err)
+
+	r.Cert, err = registry.NewCertificate(fsk, cipher.PubKeyFromSecKey(esk),
+		0, time.Now().Add(-time.Second))
+	assertNil(t, err)
+	assertTrue(t, sc.Save(eup, r) == registry.ErrCertificateExpired,
+		"expired certificate accepted")
+
+	// received after expiration
+
+	r.Cert, err = registry.NewCertificate(fsk, cipher.PubKeyFromSecKey(esk),
+		0, time.Now().Add(100*time.Millisecond))
+	assertNil(t, err)
+	assertNil(t, sc.Save(eup, r))
+
+	lr, err = sc.LastRoot(feed, r.Nonce)
+	assertNil(t, err)
+
+	time.Sleep(150 * time.Millisecond)
+
+	var yc = getTestContainer()
+	defer yc.Close()
+
+	assertNil(t, yc.AddFeed(feed))
+
+	_, err = yc.ReceivedRoot(lr.Pub, lr.Sig, lr.Encode(), lr.EncodeSigs())
+	assertTrue(t, err == registry.ErrCertificateExpired,
+		"expired certificate accepted")
+
+}
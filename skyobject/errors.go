@@ -11,6 +11,21 @@ var (
 	ErrObjectIsTooLarge = errors.New("object is too large (see MaxObjectSize)")
 	ErrTerminated       = errors.New("terminated")
 	ErrBlankRegistryRef = errors.New("blank registry reference")
+
+	ErrUnknownMultisig = errors.New("unknown multisig feed (see AddMultisigFeed)")
+	ErrRootNotPrepared = errors.New("the Root is not prepared (see PrepareRoot)")
+	ErrOutdatedRoot    = errors.New("the Root is outdated, prepare it again")
+
+	ErrRevokedKey     = errors.New("the key is revoked")
+	ErrRevocationFeed = errors.New("the revocation is not of the feed")
+	ErrCertificateKey = errors.New("the certificate is not of the key")
+
+	ErrPinnedRoot = errors.New("the Root is pinned")
+
+	ErrInvalidHash = errors.New("hash of the object doesn't match its key")
+
+	ErrCantRepair = errors.New("can't repair DB with missing or " +
+		"corrupted objects, or with broken Root objects")
 )
 
 // ObjectIsTooLargeError represents error that
@@ -9,6 +9,14 @@ import (
 	"github.com/skycoin/cxo/skyobject/registry"
 )
 
+// A ValidateRootFunc used to validate a filled Root
+// before it saved (see ValidateWith method of the
+// Filler). The function receives the Root and Pack
+// to decode objects of the Root. The Pack must not
+// be used to change anything. A non-nil error
+// rejects the Root
+type ValidateRootFunc func(r *registry.Root, pack registry.Pack) (reject error)
+
 // A Filler implements registry.Splitter interface
 // and used for filling.
 type Filler struct {
@@ -16,6 +24,12 @@ type Filler struct {
 	r *registry.Root
 
 	reg *registry.Registry
+	vf  ValidateRootFunc // validation or nil
+
+	quota Quota // quota of the feed
+	usage Usage // usage of the feed before the filling
+
+	lim registry.Limits // limits of the Root tree
 
 	rq chan<- cipher.SHA256
 
@@ -23,6 +37,12 @@ type Filler struct {
 	incs map[cipher.SHA256]int
 	pre  map[cipher.SHA256]struct{} // prerequested by RC
 
+	objects uint64 // objects added to DB (for the Quota)
+	volume  uint64 // total size of the objects
+
+	tobjects uint64 // objects of the Root tree (for the Limits)
+	tvolume  uint64 // total size of the objects
+
 	limit chan struct{} // max
 
 	errq chan error
@@ -56,7 +76,19 @@ func (f *Filler) get(
 
 	if err == nil {
 		if inc > 0 {
+			// in DB, but not used by anything (the rc is
+			// the inc of this filler); the subtree of the
+			// object can be incomplete, thus, it should be
+			// treated like received one: zero hard rc
+			// makes the Split go deeper
+			var garbage = (rc == inc)
+			if garbage == true {
+				rc = 0
+			}
 			rc = f.inc(key, rc) // ++
+			if err = f.got(len(val)); err == nil && garbage == true {
+				err = f.added(len(val))
+			}
 		}
 		return
 	}
@@ -86,9 +118,13 @@ func (f *Filler) get(
 		val = obj.Val
 		if inc > 0 {
 			rc = f.inc(key, obj.RC)
+			if err = f.got(len(val)); err != nil {
+				return
+			}
 		} else {
 			rc = obj.RC
 		}
+		err = f.added(len(val))
 	case <-f.closeq:
 		err = ErrTerminated
 	}
@@ -168,6 +204,86 @@ func (f *Filler) inc(key cipher.SHA256, drc int) (rc int) {
 	return
 }
 
+// an object added to DB, the added checks out
+// the Quota of the feed
+func (f *Filler) added(size int) (err error) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.objects++
+	f.volume += uint64(size)
+
+	var q = &f.quota
+
+	switch {
+	case q.Objects != 0 && f.usage.Objects+f.objects > q.Objects:
+		err = &QuotaError{f.r.Pub, "objects", q.Objects}
+	case q.Volume != 0 && f.usage.Volume+f.volume > q.Volume:
+		err = &QuotaError{f.r.Pub, "volume", q.Volume}
+	}
+
+	return
+}
+
+// an object of the Root tree got, the got
+// checks out the Limits of the Filler
+func (f *Filler) got(size int) (err error) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.tobjects++
+	f.tvolume += uint64(size)
+
+	var l = &f.lim
+
+	switch {
+	case l.Objects != 0 && f.tobjects > l.Objects:
+		err = &registry.LimitError{Name: "objects", Limit: l.Objects}
+	case l.Volume != 0 && f.tvolume > l.Volume:
+		err = &registry.LimitError{Name: "volume", Limit: l.Volume}
+	}
+
+	return
+}
+
+// load Quota and Usage of the feed, if the feed
+// has a Quota, and check out number of Root objects
+func (f *Filler) loadQuota() (err error) {
+
+	if f.quota, err = f.c.Quota(f.r.Pub); err != nil {
+		return
+	}
+
+	if f.quota == (Quota{}) {
+		return // no limits
+	}
+
+	if f.usage, err = f.c.Usage(f.r.Pub); err != nil {
+		return
+	}
+
+	if f.quota.Roots != 0 && f.usage.Roots+1 > f.quota.Roots {
+		err = &QuotaError{f.r.Pub, "roots", f.quota.Roots}
+	}
+
+	return
+}
+
+// check out the Quota of the feed counting all
+// objects of the filled Root, not only objects
+// added to DB by the Filler; since objects of
+// the Root the DB already has are not walked
+// through by the Filler, but they are counted
+// by the Usage after the Root saved
+func (f *Filler) checkQuota() (err error) {
+
+	if f.quota.Objects == 0 && f.quota.Volume == 0 {
+		return // no limits
+	}
+
+	return f.c.checkQuota(f.r, f.usage, f.quota)
+}
+
 func (f *Filler) requset(key cipher.SHA256) (ok bool) {
 
 	select {
@@ -219,6 +335,40 @@ func (c *Container) Fill(
 	return
 }
 
+// ValidateWith sets function that validates the Root
+// after all its objects has been received, but before
+// the Root saved. If the function rejects the Root,
+// then the filling fails with the error returned by
+// the function, and all changes the Filler made are
+// rolled back. The ValidateWith must be called before
+// the Run. By default, there is no validation
+func (f *Filler) ValidateWith(vf ValidateRootFunc) {
+	f.vf = vf
+}
+
+// LimitWith sets Limits of the Root tree. If the
+// tree exceeds the Limits, then the filling fails
+// with *registry.LimitError. The LimitWith must be
+// called before the Run. By default, there are
+// no limits
+func (f *Filler) LimitWith(l registry.Limits) {
+	f.lim = l
+}
+
+// Limits of the Filler. The method implements
+// registry.Limiter interface
+func (f *Filler) Limits() (l registry.Limits) {
+	return f.lim
+}
+
+// validate filled Root
+func (f *Filler) validate() (err error) {
+	if f.vf != nil {
+		err = f.vf(f.r, f.c.getPack(f.reg))
+	}
+	return
+}
+
 func (f *Filler) apply() {
 	for key, inc := range f.incs {
 		if err := f.c.Finc(key, inc); err != nil {
@@ -277,12 +427,26 @@ func (f *Filler) Go(fn func()) {
 }
 
 // Run the Filler. The Run method blocks
-// until finish or first error
+// until finish or first error. If the Root
+// exceeds a Quota of its feed, then the Run
+// returns *QuotaError (see SetQuota). If tree
+// of the Root exceeds Limits of the Filler,
+// then the Run returns *registry.LimitError
+// (see LimitWith)
 func (f *Filler) Run() (err error) {
 
+	if err = f.loadQuota(); err != nil {
+		return
+	}
+
 	// save Root
 
-	if _, err = f.c.Set(f.r.Hash, f.r.Encode(), 1); err != nil {
+	var (
+		val = f.r.Encode()
+		rc  int
+	)
+
+	if rc, err = f.c.Set(f.r.Hash, val, 1); err != nil {
 		return
 	}
 
@@ -297,6 +461,16 @@ func (f *Filler) Run() (err error) {
 		}
 	}()
 
+	if err = f.got(len(val)); err != nil {
+		return
+	}
+
+	if rc == 1 {
+		if err = f.added(len(val)); err != nil {
+			return
+		}
+	}
+
 	if err = f.getRegistry(); err != nil {
 		return
 	}
@@ -320,8 +494,13 @@ func (f *Filler) Run() (err error) {
 	select {
 	case err = <-f.errq:
 	case <-done:
-		f.r.IsFull = true // full!
-		_, err = f.c.AddRoot(f.r)
+		if err = f.validate(); err == nil {
+			err = f.checkQuota()
+		}
+		if err == nil {
+			f.r.IsFull = true // full!
+			_, err = f.c.AddRoot(f.r)
+		}
 	}
 
 	f.Close()
@@ -1,12 +1,17 @@
 package skyobject
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"sync"
 	"testing"
 
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
 
@@ -141,11 +146,54 @@ func testFillRoot(t *testing.T, sc, rc *Container, r *registry.Root) {
 		return true, nil
 	}))
 
+	assertNil(t, testFill(t, sc, rc, r, nil))
+
+	var i int
+
+	assertNil(t, rc.Walk(r, func(key cipher.SHA256, _ int) (bool, error) {
+		assertTrue(t, key == hs[i], "wrong hash")
+		i++
+		return true, nil
+	}))
+
+	assertTrue(t, i == len(hs), "wrong number of hashes")
+
+}
+
+// fill given Root of the sc in the rc using given
+// validation function, returns result of the filling
+func testFill(
+	t *testing.T,
+	sc, rc *Container,
+	r *registry.Root,
+	vf ValidateRootFunc,
+) (
+	err error,
+) {
+
+	return testFillWith(t, sc, rc, r, func(f *Filler) {
+		f.ValidateWith(vf)
+	})
+}
+
+// fill given Root of the sc in the rc using given
+// function to set up the Filler before the Run
+func testFillWith(
+	t *testing.T,
+	sc, rc *Container,
+	r *registry.Root,
+	setup func(f *Filler),
+) (
+	err error,
+) {
+
 	var (
 		rq = make(chan cipher.SHA256, 10)
 		f  = rc.Fill(r, rq, 10)
 	)
 
+	setup(f)
+
 	var wg sync.WaitGroup
 
 	// the rq channel
@@ -169,20 +217,400 @@ func testFillRoot(t *testing.T, sc, rc *Container, r *registry.Root) {
 
 	}()
 
-	assertNil(t, f.Run())
+	err = f.Run()
 
 	close(rq)
 	wg.Wait()
 
-	var i int
+	return
+}
 
-	assertNil(t, rc.Walk(r, func(key cipher.SHA256, _ int) (bool, error) {
-		assertTrue(t, key == hs[i], "wrong hash")
-		i++
+func TestFiller_ValidateWith(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer sc.Close()
+	defer rc.Close()
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+	}
+
+	assertNil(t, sc.Save(up, r))
+
+	var (
+		errTooYoung = errors.New("too young")
+		validate    = func(r *registry.Root, pack registry.Pack) (err error) {
+			var usr User
+			if err = r.Refs[0].Value(pack, &usr); err != nil {
+				return
+			}
+			if usr.Age < 21 {
+				return errTooYoung
+			}
+			return
+		}
+	)
+
+	var fr = *r
+	fr.IsFull = false
+
+	assertTrue(t, testFill(t, sc, rc, &fr, validate) == errTooYoung,
+		"not rejected")
+	assertTrue(t, fr.IsFull == false, "rejected Root is full")
+
+	_, err = rc.LastRoot(pk, r.Nonce)
+	assertTrue(t, err != nil, "rejected Root saved")
+
+	// the rejected Root is not kept by anything
+	var rcs int
+	_, rcs, err = rc.Get(r.Refs[0].Hash, 0)
+	assertTrue(t, err == data.ErrNotFound || rcs == 0,
+		"objects of rejected Root are kept")
+
+	// accept
+	assertNil(t, testFill(t, sc, rc, &fr, func(*registry.Root,
+		registry.Pack) error {
+
+		return nil
+	}))
+	assertTrue(t, fr.IsFull == true, "not full")
+
+	var lr *registry.Root
+	lr, err = rc.LastRoot(pk, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, lr.Hash == r.Hash, "wrong Root")
+
+}
+
+func TestFiller_rejectHeldObjects(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer sc.Close()
+	defer rc.Close()
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var (
+		alice = createDynamic(up, testRegistry, "test.User", &User{"Alice", 19})
+		r     = new(registry.Root)
+	)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{alice}
+
+	assertNil(t, sc.Save(up, r))
+	testFillRoot(t, sc, rc, r)
+
+	// objects of the filled Root are held by rc
+	var before = make(map[cipher.SHA256]int)
+
+	for _, key := range []cipher.SHA256{
+		alice.Hash,
+		cipher.SHA256(testRegistry.Reference()),
+	} {
+		_, before[key], err = rc.Get(key, 0)
+		assertNil(t, err)
+	}
+
+	// the next Root shares the objects
+
+	r.Refs = append(r.Refs,
+		createDynamic(up, testRegistry, "test.User", &User{"Eva", 21}))
+
+	assertNil(t, sc.Save(up, r))
+
+	var (
+		fr     = *r
+		reject = errors.New("reject")
+	)
+
+	fr.IsFull = false
+
+	assertTrue(t, testFill(t, sc, rc, &fr, func(*registry.Root,
+		registry.Pack) error {
+
+		return reject
+	}) == reject, "not rejected")
+
+	// the reject rolls back rc of the objects it got from DB
+
+	for key, brc := range before {
+		var arc int
+		_, arc, err = rc.Get(key, 0)
+		assertNil(t, err)
+		assertTrue(t, arc == brc, fmt.Sprintf("rc of %s changed %d -> %d",
+			key.Hex()[:7], brc, arc))
+	}
+
+}
+
+func TestFiller_garbage(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer sc.Close()
+	defer rc.Close()
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	assertNil(t, up.SetDegree(2))
+
+	var feed Feed
+
+	for i := 0; i < 8; i++ {
+		assertNil(t, feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+			Body: fmt.Sprintf("Body #%d", i),
+		}))
+	}
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, sc.Save(up, r))
+
+	// the rc has the Refs, but not its subtree, and
+	// the Refs is not used by anything (rc is zero),
+	// e.g. after a failed filling or removed Root
+
+	var val []byte
+	val, _, err = sc.Get(feed.Posts.Hash, 0)
+	assertNil(t, err)
+
+	_, err = rc.db.CXDS().Set(feed.Posts.Hash, val, 1)
+	assertNil(t, err)
+	_, err = rc.db.CXDS().Inc(feed.Posts.Hash, -1)
+	assertNil(t, err)
+
+	// the filler must go deeper
+
+	var fr = *r
+	fr.IsFull = false
+	testFillRoot(t, sc, rc, &fr)
+
+}
+
+// A Box contains a Dynamic reference
+type Box struct {
+	Item registry.Dynamic
+}
+
+func TestFiller_LimitWith(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+		reg    = registry.NewRegistry(func(r *registry.Reg) {
+			r.Register("test.Feed", Feed{})
+			r.Register("test.Post", Post{})
+			r.Register("test.Box", Box{})
+		})
+	)
+
+	defer sc.Close()
+	defer rc.Close()
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, reg)
+	assertNil(t, err)
+
+	assertNil(t, up.SetDegree(2))
+
+	var feed Feed
+
+	for i := 0; i < 5; i++ {
+		assertNil(t, feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+			Body: fmt.Sprintf("Body #%d", i),
+		}))
+	}
+
+	var depth int
+	depth, err = feed.Posts.Depth(up)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, reg, "test.Box", &Box{
+			Item: createDynamic(up, reg, "test.Feed", &feed),
+		}),
+	}
+
+	assertNil(t, sc.Save(up, r))
+
+	var u Usage
+	u, err = sc.Usage(pk)
+	assertNil(t, err)
+
+	var fill = func(l registry.Limits) (err error) {
+		var fr = *r
+		fr.IsFull = false
+		return testFillWith(t, sc, rc, &fr, func(f *Filler) {
+			f.LimitWith(l)
+		})
+	}
+
+	for _, l := range []registry.Limits{
+		{Objects: u.Objects - 1},
+		{Volume: u.Volume - 1},
+		{RefsDepth: depth - 1},
+		{RefsDegree: 1},
+		{DynamicNesting: 1},
+	} {
+		err = fill(l)
+		var le, ok = err.(*registry.LimitError)
+		assertTrue(t, ok == true, fmt.Sprint("unexpected error: ", err))
+		t.Log(le)
+	}
+
+	_, err = rc.LastRoot(pk, r.Nonce)
+	assertTrue(t, err != nil, "rejected Root saved")
+
+	assertNil(t, fill(registry.Limits{
+		Objects:        u.Objects,
+		Volume:         u.Volume,
+		RefsDepth:      depth,
+		RefsDegree:     2,
+		DynamicNesting: 2,
+	}))
+
+	_, err = rc.LastRoot(pk, r.Nonce)
+	assertNil(t, err)
+
+}
+
+type File struct {
+	Name    string
+	Content registry.Blob
+}
+
+func Test_fillingBlob(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+		reg    = registry.NewRegistry(func(r *registry.Reg) {
+			r.Register("test.File", File{})
+		})
+	)
+
+	defer sc.Close()
+	defer rc.Close()
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, reg)
+	assertNil(t, err)
+
+	var (
+		file = File{Name: "file.bin"}
+		data = make([]byte, 5<<20)
+	)
+
+	rand.New(rand.NewSource(9021)).Read(data)
+	assertNil(t, file.Content.SetBytes(up, data))
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, reg, "test.File", &file),
+	}
+
+	assertNil(t, sc.Save(up, r))
+	testFillRoot(t, sc, rc, r)
+	testFillDBs(t, sc, rc)
+
+	// change in the middle
+
+	var bw *registry.BlobWriter
+	bw, err = file.Content.Writer(up)
+	assertNil(t, err)
+
+	_, err = bw.Seek(3<<20, io.SeekStart)
+	assertNil(t, err)
+
+	_, err = bw.Write([]byte("changed"))
+	assertNil(t, err)
+	assertNil(t, bw.Close())
+
+	assertNil(t, r.Refs[0].SetValue(up, &file))
+	assertNil(t, sc.Save(up, r))
+
+	// only changed chunks and nodes should be sent
+
+	var missing, total int
+	assertNil(t, sc.Walk(r, func(hash cipher.SHA256, _ int) (bool, error) {
+		if _, _, err := rc.Get(hash, 0); err != nil {
+			missing++
+		}
+		total++
 		return true, nil
 	}))
 
-	assertTrue(t, i == len(hs), "wrong number of hashes")
+	t.Log("missing", missing, "of", total)
+	assertTrue(t, missing < 10, "too many changed objects")
+
+	testFillRoot(t, sc, rc, r)
+	testFillDBs(t, sc, rc)
+
+	// read from the relay
+
+	var pack *Pack
+	pack, err = rc.Pack(r, reg)
+	assertNil(t, err)
+
+	var rf File
+	assertNil(t, r.Refs[0].Value(pack, &rf))
+
+	copy(data[3<<20:], "changed")
+
+	var got []byte
+	got, err = rf.Content.Bytes(pack)
+	assertNil(t, err)
+	assertTrue(t, bytes.Equal(got, data), "wrong data")
 
 }
 
@@ -0,0 +1,283 @@
+package skyobject
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A RCMismatch represents object with wrong rc
+type RCMismatch struct {
+	Key      cipher.SHA256 // hash of the object
+	RC       uint32        // rc of the object in CXDS
+	Expected uint32        // rc computed by walking Root objects
+}
+
+// A BrokenRoot represents Root that can't be walked
+type BrokenRoot struct {
+	Feed  cipher.PubKey // feed
+	Nonce uint64        // head
+	Seq   uint64        // seq number
+	Hash  cipher.SHA256 // hash of the Root
+	Err   error         // reason
+}
+
+// Error implements error interface
+func (b *BrokenRoot) Error() string {
+	return fmt.Sprintf("broken Root %s/%d/%d (%s): %v", b.Feed.Hex()[:7],
+		b.Nonce, b.Seq, b.Hash.Hex()[:7], b.Err)
+}
+
+// A FsckReport represents result of the Fsck
+type FsckReport struct {
+	Objects int // number of checked objects
+	Roots   int // number of walked Root objects
+
+	Corrupted  []cipher.SHA256 // hash of value doesn't match its key
+	Missing    []cipher.SHA256 // referenced, but not found
+	Orphans    []RCMismatch    // non-zero rc, but nobody refers to
+	Mismatches []RCMismatch    // wrong rc
+	Broken     []BrokenRoot    // Root objects that can't be walked
+
+	Repaired int // number of objects with repaired rc
+}
+
+// IsClean returns true if the Fsck found nothing
+func (f *FsckReport) IsClean() bool {
+	return len(f.Corrupted) == 0 &&
+		len(f.Missing) == 0 &&
+		len(f.Orphans) == 0 &&
+		len(f.Mismatches) == 0 &&
+		len(f.Broken) == 0
+}
+
+// an object of CXDS
+type fsckObject struct {
+	rc        uint32 // rc in CXDS
+	cc        uint32 // expected rc
+	corrupted bool   // wrong hash
+}
+
+// Fsck checks DB of the Container. It checks hash of every
+// object, walks all Root objects (see Walk) to compute
+// expected rc of every object and finds missing objects,
+// orphans (objects with non-zero rc that nobody refers to)
+// and objects with wrong rc. If the repair argument is
+// true, then the Fsck fixes rc of the orphans and the
+// mismatches. The repaired orphans will have zero rc and
+// can be removed (see Prune). It's impossible to repair
+// DB with missing or corrupted objects and with broken
+// Root objects, in this case the Fsck returns ErrCantRepair
+// with the report. The Fsck can't be used while the
+// Container saves, fills or removes Root objects, since
+// rc of objects changes. It's better to use the Fsck
+// right after NewContainer. The Fsck skips objects
+// being filled
+func (c *Container) Fsck(repair bool) (rep *FsckReport, err error) {
+
+	var filling map[cipher.SHA256]struct{}
+	if filling, err = c.Cache.fsckFlush(); err != nil {
+		return
+	}
+
+	rep = new(FsckReport)
+
+	// objects
+
+	var (
+		keys []cipher.SHA256
+		hr   = make(map[cipher.SHA256]*fsckObject)
+	)
+
+	err = c.db.CXDS().Iterate(
+		func(key cipher.SHA256, rc uint32, val []byte) (_ error) {
+
+			var obj = &fsckObject{rc: rc}
+
+			if cipher.SumSHA256(val) != key {
+				obj.corrupted = true
+				rep.Corrupted = append(rep.Corrupted, key)
+			}
+
+			hr[key] = obj
+			keys = append(keys, key)
+
+			rep.Objects++
+			return
+		})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var missing = make(map[cipher.SHA256]struct{})
+
+	// refer to object, the refer returns true
+	// if the object referenced first time
+	var refer = func(key cipher.SHA256) (first bool) {
+
+		var obj, ok = hr[key]
+
+		if ok == false {
+			if _, ok = missing[key]; ok == false {
+				missing[key] = struct{}{}
+				rep.Missing = append(rep.Missing, key)
+			}
+			return
+		}
+
+		obj.cc++
+		return obj.cc == 1 && obj.corrupted == false
+	}
+
+	// Root objects and multisig feeds
+
+	var roots []BrokenRoot
+
+	err = c.db.IdxDB().Tx(func(fs data.Feeds) (err error) {
+
+		return fs.Iterate(func(pk cipher.PubKey) (err error) {
+
+			if registry.IsMultisigFeed(pk) == true {
+				var hash cipher.SHA256
+				if hash, err = registry.MultisigHash(pk); err != nil {
+					return
+				}
+				refer(hash) // see AddMultisigFeed
+			}
+
+			var hs data.Heads
+			if hs, err = fs.Heads(pk); err != nil {
+				return
+			}
+
+			return hs.Iterate(func(nonce uint64) (err error) {
+
+				var rs data.Roots
+				if rs, err = hs.Roots(nonce); err != nil {
+					return
+				}
+
+				return rs.Ascend(func(dr *data.Root) (_ error) {
+					roots = append(roots, BrokenRoot{
+						Feed:  pk,
+						Nonce: nonce,
+						Seq:   dr.Seq,
+						Hash:  dr.Hash,
+					})
+					return
+				})
+
+			})
+
+		})
+
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var walkFunc = func(hash cipher.SHA256, _ int) (deepper bool, _ error) {
+		if hash == (cipher.SHA256{}) {
+			return // blank
+		}
+		return refer(hash), nil
+	}
+
+	for _, br := range roots {
+
+		rep.Roots++
+
+		if _, ok := hr[br.Hash]; ok == false {
+			refer(br.Hash) // missing
+			br.Err = data.ErrNotFound
+			rep.Broken = append(rep.Broken, br)
+			continue
+		}
+
+		var r *registry.Root
+		if r, err = c.rootByHash(br.Hash); err == nil {
+			err = c.Walk(r, walkFunc)
+		}
+
+		if err != nil {
+			br.Err, err = err, nil
+			rep.Broken = append(rep.Broken, br)
+		}
+
+	}
+
+	// rc
+
+	for _, key := range keys {
+
+		if _, ok := filling[key]; ok == true {
+			continue // skip
+		}
+
+		var obj = hr[key]
+
+		if obj.rc == obj.cc {
+			continue
+		}
+
+		var rm = RCMismatch{Key: key, RC: obj.rc, Expected: obj.cc}
+
+		if obj.cc == 0 {
+			rep.Orphans = append(rep.Orphans, rm)
+		} else {
+			rep.Mismatches = append(rep.Mismatches, rm)
+		}
+
+	}
+
+	if repair == false {
+		return
+	}
+
+	if len(rep.Corrupted) != 0 || len(rep.Missing) != 0 ||
+		len(rep.Broken) != 0 {
+
+		return rep, ErrCantRepair
+	}
+
+	for _, list := range [][]RCMismatch{rep.Orphans, rep.Mismatches} {
+		for _, rm := range list {
+			if _, err = c.Inc(rm.Key, int(rm.Expected)-int(rm.RC)); err != nil {
+				return
+			}
+			rep.Repaired++
+		}
+	}
+
+	return
+}
+
+// flush the Cache (see flush) and return
+// keys of objects being filled
+func (c *Cache) fsckFlush() (
+	filling map[cipher.SHA256]struct{},
+	err error,
+) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if err = c.flush(); err != nil {
+		return
+	}
+
+	filling = make(map[cipher.SHA256]struct{})
+
+	for key, it := range c.is {
+		if it.fc != 0 {
+			filling[key] = struct{}{}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,94 @@
+package skyobject
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_Fsck(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+
+	var alice = createDynamic(up, testRegistry, "test.User", &User{
+		Name: "Alice",
+		Age:  21,
+	})
+
+	for i := 0; i < 3; i++ {
+		r.Refs = []registry.Dynamic{
+			alice, // shared
+			createDynamic(up, testRegistry, "test.User", &User{
+				Name: fmt.Sprintf("User #%d", i),
+				Age:  uint32(i),
+			}),
+		}
+		assertNil(t, c.Save(up, r))
+	}
+
+	var fsck = func(repair bool) (rep *FsckReport) {
+		t.Helper()
+		var err error
+		rep, err = c.Fsck(repair)
+		assertNil(t, err)
+		assertTrue(t, rep.Roots == 3, fmt.Sprint("wrong Roots ", rep.Roots))
+		return
+	}
+
+	var rep = fsck(false)
+	assertTrue(t, rep.IsClean(), fmt.Sprintf("not clean %+v", rep))
+
+	// break rc and create an orphan
+
+	var (
+		db     = c.DB().CXDS()
+		orphan = encoder.Serialize(User{Name: "Eva"})
+	)
+
+	_, err = db.Inc(alice.Hash, 1)
+	assertNil(t, err)
+
+	_, err = db.Set(cipher.SumSHA256(orphan), orphan, 1)
+	assertNil(t, err)
+
+	rep = fsck(true)
+	assertTrue(t, len(rep.Mismatches) == 1 &&
+		rep.Mismatches[0] == RCMismatch{alice.Hash, 4, 3},
+		fmt.Sprint("wrong mismatches ", rep.Mismatches))
+	assertTrue(t, len(rep.Orphans) == 1 &&
+		rep.Orphans[0] == RCMismatch{cipher.SumSHA256(orphan), 1, 0},
+		fmt.Sprint("wrong orphans ", rep.Orphans))
+	assertTrue(t, rep.Repaired == 2, "not repaired")
+
+	rep = fsck(false)
+	assertTrue(t, rep.IsClean(), fmt.Sprintf("not repaired %+v", rep))
+
+	// missing object
+
+	assertNil(t, db.Del(r.Refs[1].Hash))
+
+	rep, err = c.Fsck(true)
+	assertTrue(t, err == ErrCantRepair, fmt.Sprint("wrong error ", err))
+	assertTrue(t, len(rep.Missing) == 1 && rep.Missing[0] == r.Refs[1].Hash,
+		fmt.Sprint("wrong missing ", rep.Missing))
+
+}
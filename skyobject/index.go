@@ -57,6 +57,9 @@ type Index struct {
 	feeds  map[cipher.PubKey]*indexHeads
 	feedsl []cipher.PubKey // change on write
 
+	umx   sync.Mutex                   // lock for the usage
+	usage map[cipher.PubKey]*feedUsage // counted usage of feeds
+
 	stat   *indexStat
 	closeo sync.Once // close once
 }
@@ -67,6 +70,7 @@ func (i *Index) load(c *Container) (err error) {
 	i.stat = newIndexStat(c.conf.RollAvgSamples)
 
 	i.feeds = make(map[cipher.PubKey]*indexHeads)
+	i.usage = make(map[cipher.PubKey]*feedUsage)
 	i.c = c
 
 	err = i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
@@ -246,22 +250,31 @@ func (i *Index) receivedRoot(
 	pk cipher.PubKey,
 	sig cipher.Sig,
 	val []byte,
+	sigs []byte,
 ) (
 	r *registry.Root,
 	err error,
 ) {
 
-	var hash = cipher.SumSHA256(val)
-	if err = cipher.VerifyPubKeySignedHash(pk, sig, hash); err != nil {
+	if r, err = registry.DecodeRoot(val); err != nil {
 		return
 	}
 
-	if r, err = registry.DecodeRoot(val); err != nil {
-		return
+	if r.Pub != pk {
+		return nil, errors.New("feed of the Root is not the feed given")
 	}
 
-	r.Hash = hash // set the hash
-	r.Sig = sig   // set the signature
+	r.Hash = cipher.SumSHA256(val) // set the hash
+	r.Sig = sig                    // set the signature
+
+	// signature container
+	if err = r.DecodeSigs(sigs); err != nil {
+		return nil, err
+	}
+
+	if err = i.verifyRoot(r); err != nil {
+		return nil, err
+	}
 
 	return
 }
@@ -272,11 +285,14 @@ func (i *Index) receivedRoot(
 // can returns data.ErrNoSuchFeed error. This method
 // never return this error. And this method never set
 // IsFull fields to true, if this Container already
-// have this Root
+// have this Root. The sigs is encoded signature
+// container of the Root (see EncodeSigs method of
+// the registry.Root) or nil
 func (i *Index) PreviewRoot(
 	pk cipher.PubKey,
 	sig cipher.Sig,
 	val []byte,
+	sigs []byte,
 ) (
 	r *registry.Root,
 	err error,
@@ -285,7 +301,7 @@ func (i *Index) PreviewRoot(
 	i.mx.Lock()
 	defer i.mx.Unlock()
 
-	return i.receivedRoot(pk, sig, val)
+	return i.receivedRoot(pk, sig, val, sigs)
 }
 
 // ReceivedRoot called by the node package to
@@ -295,11 +311,19 @@ func (i *Index) PreviewRoot(
 // root. The method changes nothing in DB, it
 // only checks the Root. The method set IsFull
 // field of the Root to true if DB already have
-// this Root
+// this Root. The sigs is encoded signature
+// container of the Root or nil. A Root of
+// multisig feed (see registry.Multisig) has
+// blank sig and at least M valid signatures
+// in the container. A Root signed by an
+// authorized key (see registry.Certificate)
+// carries the Certificate in the container.
+// Revocations carried by the Root are saved
 func (i *Index) ReceivedRoot(
 	pk cipher.PubKey,
 	sig cipher.Sig,
 	val []byte,
+	sigs []byte,
 ) (
 	r *registry.Root,
 	err error,
@@ -308,7 +332,7 @@ func (i *Index) ReceivedRoot(
 	i.mx.Lock()
 	defer i.mx.Unlock()
 
-	if r, err = i.receivedRoot(pk, sig, val); err != nil {
+	if r, err = i.receivedRoot(pk, sig, val, sigs); err != nil {
 		r = nil // GC
 		return
 	}
@@ -338,7 +362,7 @@ func (i *Index) addRoot(r *registry.Root) (alreadyHave bool, err error) {
 		return false, errors.New("blank hash of Root: " + r.Short())
 	}
 
-	if r.Sig == (cipher.Sig{}) {
+	if r.Sig == (cipher.Sig{}) && len(r.Sigs) == 0 {
 		return false, errors.New("blank signature of Root: " + r.Short())
 	}
 
@@ -383,6 +407,7 @@ func (i *Index) addRoot(r *registry.Root) (alreadyHave bool, err error) {
 		dr.Prev = r.Prev
 		dr.Hash = r.Hash
 		dr.Sig = r.Sig
+		dr.Sigs = r.EncodeSigs()
 		dr.Time = r.Time
 
 		return rs.Set(dr)
@@ -558,10 +583,12 @@ func (i *Index) LastRoot(
 		return
 	}
 
-	r, err = i.c.rootByHash(lr.Hash)
+	if r, err = i.c.rootByHash(lr.Hash); err != nil {
+		return
+	}
 
 	r.IsFull = true
-	r.Sig = lr.Sig
+	err = setRootSigs(r, lr)
 	return
 }
 
@@ -646,6 +673,8 @@ func (i *Index) DelFeed(pk cipher.PubKey) (err error) {
 		return
 	}
 
+	i.unuseFeed(pk)
+
 	// without lock
 	for _, hash := range rhs {
 		if err = i.delRootRelatedValues(hash); err != nil {
@@ -698,6 +727,10 @@ func (i *Index) delHead(
 			return
 		}
 
+		if err = unpinHead(feed, pk, nonce); err != nil {
+			return
+		}
+
 		return hs.Del(nonce) // remove the head
 	})
 
@@ -742,6 +775,7 @@ func (i *Index) DelHead(pk cipher.PubKey, nonce uint64) (err error) {
 	// without lock
 
 	for _, hash := range rhs {
+		i.unuseRoot(pk, hash)
 		if err = i.delRootRelatedValues(hash); err != nil {
 			return
 		}
@@ -759,6 +793,7 @@ func (i *Index) delRoot(
 	pk cipher.PubKey, //       : feed
 	nonce uint64, //           : head
 	seq uint64, //             : seq
+	force bool, //             : remove pinned Root
 ) (
 	rootHash cipher.SHA256, // : hash of the Root
 	err error, //              : an error
@@ -803,6 +838,20 @@ func (i *Index) delRoot(
 			return // DB failure or  'not found'
 		}
 
+		var pins []Pinned
+		if pins, err = getPins(feeds, pk); err != nil {
+			return
+		}
+
+		if findPin(pins, nonce, seq) >= 0 {
+			if force == false {
+				return ErrPinnedRoot
+			}
+			if err = unpin(feeds, pk, nonce, seq); err != nil {
+				return
+			}
+		}
+
 		// keep hash of the Root to remove
 		// from CXDS with all related objects
 
@@ -852,6 +901,7 @@ func (i *Index) delRootLock(
 	pk cipher.PubKey, //       : feed
 	nonce uint64, //           : head
 	seq uint64, //             : seq
+	force bool, //             : remove pinned Root
 ) (
 	rootHash cipher.SHA256, // : hash of the Root
 	err error, //              : an error
@@ -860,7 +910,7 @@ func (i *Index) delRootLock(
 	i.mx.Lock()
 	defer i.mx.Unlock()
 
-	return i.delRoot(pk, nonce, seq)
+	return i.delRoot(pk, nonce, seq, force)
 }
 
 func (i *Index) delPackWalkFunc(
@@ -936,16 +986,35 @@ func (i *Index) delRootRelatedValues(rootHash cipher.SHA256) (err error) {
 }
 
 // DelRoot deletes Root. The method returns data.ErrNotFound if
-// Root doesn't exist
+// Root doesn't exist, and ErrPinnedRoot if the Root is pinned
+// (see Pin method of the Container)
 func (i *Index) DelRoot(pk cipher.PubKey, nonce, seq uint64) (err error) {
+	return i.delRootRelated(pk, nonce, seq, false)
+}
+
+// ForceDelRoot is the same as the DelRoot, but it
+// removes pinned Root too (unpinning it)
+func (i *Index) ForceDelRoot(pk cipher.PubKey, nonce, seq uint64) (err error) {
+	return i.delRootRelated(pk, nonce, seq, true)
+}
+
+func (i *Index) delRootRelated(
+	pk cipher.PubKey, // : feed
+	nonce uint64, //     : head
+	seq uint64, //       : seq
+	force bool, //       : remove pinned Root
+) (
+	err error, //        : an error
+) {
 
 	// with lock
 	var rootHash cipher.SHA256
-	if rootHash, err = i.delRootLock(pk, nonce, seq); err != nil {
+	if rootHash, err = i.delRootLock(pk, nonce, seq, force); err != nil {
 		return
 	}
 
 	// without lock
+	i.unuseRoot(pk, rootHash)
 	return i.delRootRelatedValues(rootHash)
 }
 
@@ -1127,7 +1196,7 @@ func (i *Index) Root(
 	}
 
 	r.IsFull = true
-	r.Sig = dr.Sig
+	err = setRootSigs(r, dr)
 
 	return
 }
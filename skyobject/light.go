@@ -0,0 +1,343 @@
+package skyobject
+
+import (
+	"container/list"
+	"encoding/binary"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// pack for light clients
+
+// A LightStore is bounded in-memory store of
+// objects used by light clients (see Light). The
+// LightStore keeps recently used objects and drops
+// least recently used objects if total size of the
+// objects exceeds the limit. The LightStore verifies
+// hash of every object added. The LightStore is safe
+// for concurrent use
+type LightStore struct {
+	mx     sync.Mutex
+	max    int                             // max volume
+	volume int                             // current volume
+	m      map[cipher.SHA256]*list.Element // hash -> element
+	l      *list.List                      // recently used first (*lightItem)
+}
+
+// element of the LightStore
+type lightItem struct {
+	key cipher.SHA256
+	val []byte
+}
+
+// NewLightStore creates LightStore with given
+// limit of total size of objects in bytes. If
+// the limit is zero or less, then the LightStore
+// keeps nothing
+func NewLightStore(maxVolume int) (s *LightStore) {
+
+	s = new(LightStore)
+
+	s.max = maxVolume
+	s.m = make(map[cipher.SHA256]*list.Element)
+	s.l = list.New()
+
+	return
+}
+
+// Get object by hash. It returns false
+// if the LightStore doesn't have the object
+func (s *LightStore) Get(key cipher.SHA256) (val []byte, ok bool) {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var el *list.Element
+	if el, ok = s.m[key]; ok == false {
+		return
+	}
+
+	s.l.MoveToFront(el) // recently used
+	return el.Value.(*lightItem).val, true
+}
+
+// Set object. The Set returns ErrInvalidHash if
+// given key is not hash of given value. An object
+// larger then the limit is not kept
+func (s *LightStore) Set(key cipher.SHA256, val []byte) (err error) {
+
+	if cipher.SumSHA256(val) != key {
+		return ErrInvalidHash
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if el, ok := s.m[key]; ok == true {
+		s.l.MoveToFront(el)
+		return
+	}
+
+	if len(val) > s.max {
+		return // too large
+	}
+
+	s.m[key] = s.l.PushFront(&lightItem{key, val})
+	s.volume += len(val)
+
+	// drop least recently used objects
+
+	for s.volume > s.max {
+
+		var li = s.l.Remove(s.l.Back()).(*lightItem)
+
+		delete(s.m, li.key)
+		s.volume -= len(li.val)
+
+	}
+
+	return
+}
+
+// Len returns number of objects
+func (s *LightStore) Len() (ln int) {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return s.l.Len()
+}
+
+// Volume returns total size of objects
+func (s *LightStore) Volume() (volume int) {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return s.volume
+}
+
+// A Light implements registry.Pack for light
+// clients. The Light gets objects of a Root from
+// database, or from LightStore, otherwise it
+// requests the objects using provided Getter and
+// keeps them in the LightStore. Thus, a light
+// client doesn't need to fill Root objects. The
+// Light verifies hash of every object received.
+// The Light used by the node package in light mode
+type Light struct {
+	r *registry.Root // the Root
+	g Getter         // get from remote peer
+	s *LightStore    // received objects
+
+	*Pack // with Registry
+}
+
+// Root of the Light
+func (l *Light) Root() (r *registry.Root) {
+	return l.r
+}
+
+// Store returns LightStore of the Light
+func (l *Light) Store() (s *LightStore) {
+	return l.s
+}
+
+// Get from DB, from the LightStore or from remote peer
+func (l *Light) Get(key cipher.SHA256) (val []byte, err error) {
+
+	if val, err = l.Pack.Get(key); err != data.ErrNotFound {
+		return // found or DB failure
+	}
+
+	return l.s.getOrRequest(key, l.g)
+}
+
+// get from the LightStore or using given Getter
+func (s *LightStore) getOrRequest(
+	key cipher.SHA256, // : hash of object
+	g Getter, //          : getter to get from remote peer
+) (
+	val []byte, //        : the object
+	err error, //         : an error
+) {
+
+	var ok bool
+	if val, ok = s.Get(key); ok == true {
+		return // already received
+	}
+
+	if val, err = g.Get(key); err != nil {
+		return
+	}
+
+	if err = s.Set(key, val); err != nil {
+		val = nil // wrong object received
+	}
+
+	return
+}
+
+// Light creates Light using given Getter and
+// LightStore. It returns error if the Light
+// method can't obtain related Registry using
+// DB, the LightStore or the Getter. The Light
+// method can blocks calling Get from given
+// Getter. The Light method used by the node
+// package in light mode
+func (c *Container) Light(
+	r *registry.Root, // : root to get objects of
+	g Getter, //         : getter to get objects from remote peer
+	s *LightStore, //    : store for received objects
+) (
+	pack *Light, //      : pack for light client
+	err error, //        : error
+) {
+
+	pack = new(Light)
+
+	pack.r = r
+	pack.g = g
+	pack.s = s
+
+	var reg *registry.Registry
+	if reg, err = c.Registry(r.Reg); err != nil {
+
+		if err != data.ErrNotFound {
+			return nil, err // DB failure
+		}
+
+		// not found, let's get it using the LightStore or the Getter
+
+		var val []byte
+		if val, err = s.getOrRequest(cipher.SHA256(r.Reg), g); err != nil {
+			return nil, err // can't receive
+		}
+
+		if reg, err = registry.DecodeRegistry(val); err != nil {
+			return nil, err // invalid data received
+		}
+
+	}
+
+	pack.Pack = c.getPack(reg)
+
+	return
+
+}
+
+// last Root objects of a light client are stored
+// as meta information of their feeds (see data.Feeds)
+// without objects; the key is "l" + nonce
+func lightRootKey(nonce uint64) (key []byte) {
+
+	var bn [8]byte
+	binary.BigEndian.PutUint64(bn[:], nonce)
+
+	return append([]byte("l"), bn[:]...)
+}
+
+// a Root of a light client
+type lightRoot struct {
+	Value []byte     // encoded Root
+	Sig   cipher.Sig // signature of the Root
+	Sigs  []byte     // encoded signature container
+}
+
+// SetLightRoot keeps given Root as last Root of its head
+// for a light client (see Light). The Root is stored in
+// IdxDB as meta information of its feed. Objects of the
+// Root are not filled, and the Root is not a Root of the
+// Index. Use LightRoots to get the Root back. It returns
+// data.ErrNoSuchFeed if the Container doesn't have the
+// feed. The SetLightRoot used by the node package in
+// light mode
+func (c *Container) SetLightRoot(r *registry.Root) (err error) {
+
+	var val = encoder.Serialize(&lightRoot{
+		Value: r.Encode(),
+		Sig:   r.Sig,
+		Sigs:  r.EncodeSigs(),
+	})
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	return c.db.IdxDB().Tx(func(fs data.Feeds) error {
+		return fs.SetMeta(r.Pub, lightRootKey(r.Nonce), val)
+	})
+}
+
+// DelLightRoot removes Root of given head kept
+// by the SetLightRoot. It returns data.ErrNoSuchFeed
+// if the Container doesn't have the feed
+func (c *Container) DelLightRoot(feed cipher.PubKey, nonce uint64) (err error) {
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	return c.db.IdxDB().Tx(func(fs data.Feeds) error {
+		return fs.SetMeta(feed, lightRootKey(nonce), nil)
+	})
+}
+
+// LightRoots returns Root objects of given feed kept
+// by the SetLightRoot, a Root per head. The Root
+// objects are not full. It returns data.ErrNoSuchFeed
+// if the Container doesn't have the feed
+func (c *Container) LightRoots(feed cipher.PubKey) (rs []*registry.Root, err error) {
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	err = c.db.IdxDB().Tx(func(fs data.Feeds) error {
+
+		return fs.IterateMeta(feed, func(key, val []byte) (err error) {
+
+			if len(key) != 9 || key[0] != 'l' {
+				return // not a light Root
+			}
+
+			var r *registry.Root
+			if r, err = decodeLightRoot(val); err != nil {
+				return
+			}
+
+			rs = append(rs, r)
+			return
+		})
+
+	})
+
+	if err != nil {
+		rs = nil
+	}
+
+	return
+}
+
+func decodeLightRoot(val []byte) (r *registry.Root, err error) {
+
+	var lr lightRoot
+	if _, err = encoder.DeserializeRaw(val, &lr); err != nil {
+		return
+	}
+
+	if r, err = registry.DecodeRoot(lr.Value); err != nil {
+		return
+	}
+
+	r.Hash = cipher.SumSHA256(lr.Value)
+	r.Sig = lr.Sig
+
+	if err = r.DecodeSigs(lr.Sigs); err != nil {
+		return nil, err
+	}
+
+	return
+}
@@ -0,0 +1,206 @@
+package skyobject
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestLightStore_Set(t *testing.T) {
+
+	var (
+		s = NewLightStore(10)
+
+		a, b, c = []byte("aaaa"), []byte("bbbb"), []byte("cccc")
+	)
+
+	assertTrue(t, s.Set(cipher.SumSHA256(a), b) == ErrInvalidHash,
+		"wrong hash")
+
+	for _, val := range [][]byte{a, b} {
+		assertNil(t, s.Set(cipher.SumSHA256(val), val))
+	}
+
+	assertTrue(t, s.Len() == 2, "wrong length")
+	assertTrue(t, s.Volume() == 8, "wrong volume")
+
+	// touch the a
+	if _, ok := s.Get(cipher.SumSHA256(a)); ok == false {
+		t.Fatal("missing object")
+	}
+
+	// drops the b
+	assertNil(t, s.Set(cipher.SumSHA256(c), c))
+
+	assertTrue(t, s.Len() == 2, "wrong length")
+	assertTrue(t, s.Volume() == 8, "wrong volume")
+
+	if _, ok := s.Get(cipher.SumSHA256(b)); ok == true {
+		t.Error("object is not dropped")
+	}
+
+	for _, val := range [][]byte{a, c} {
+		if got, ok := s.Get(cipher.SumSHA256(val)); ok == false {
+			t.Error("missing object")
+		} else if string(got) != string(val) {
+			t.Error("wrong object")
+		}
+	}
+
+	// too large
+	var large = []byte("large object")
+	assertNil(t, s.Set(cipher.SumSHA256(large), large))
+	assertTrue(t, s.Len() == 2, "large object kept")
+
+}
+
+// implements Getter
+type testLightGetter struct {
+	c    *Container
+	gets int
+}
+
+func (t *testLightGetter) Get(key cipher.SHA256) (val []byte, err error) {
+	t.gets++
+	val, _, err = t.c.Get(key, 0)
+	return
+}
+
+func TestContainer_Light(t *testing.T) {
+
+	var (
+		sc, lc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer sc.Close()
+	defer lc.Close()
+
+	assertNil(t, sc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var feed = Feed{Head: "Alices' feed", Info: "an average feed"}
+
+	for i := 0; i < 10; i++ {
+		assertNil(t, feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+			Body: fmt.Sprintf("Body #%d", i),
+		}))
+	}
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, sc.Save(up, r))
+
+	var (
+		g = &testLightGetter{c: sc}
+		s = NewLightStore(1 << 20)
+
+		light *Light
+	)
+
+	light, err = lc.Light(r, g, s)
+	assertNil(t, err)
+
+	var lf Feed
+	assertNil(t, r.Refs[0].Value(light, &lf))
+
+	assertTrue(t, lf.Head == feed.Head, "wrong feed")
+
+	var ln int
+	ln, err = lf.Posts.Len(light)
+	assertNil(t, err)
+	assertTrue(t, ln == 10, "wrong length")
+
+	var post Post
+	_, err = lf.Posts.ValueByIndex(light, 7, &post)
+	assertNil(t, err)
+	assertTrue(t, post.Head == "Head #7", "wrong post")
+
+	// from the LightStore
+
+	var gets = g.gets
+
+	light, err = lc.Light(r, g, s)
+	assertNil(t, err)
+
+	lf = Feed{}
+	assertNil(t, r.Refs[0].Value(light, &lf))
+	_, err = lf.Posts.ValueByIndex(light, 7, &post)
+	assertNil(t, err)
+
+	assertTrue(t, g.gets == gets, "not from the LightStore")
+
+	// not found
+	_, err = light.Get(cipher.SumSHA256([]byte("other")))
+	assertTrue(t, err != nil, "missing error")
+
+}
+
+func TestContainer_SetLightRoot(t *testing.T) {
+
+	var (
+		sc, lc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer sc.Close()
+	defer lc.Close()
+
+	assertNil(t, sc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var feed = Feed{Head: "Alices' feed", Info: "an average feed"}
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, sc.Save(up, r))
+
+	assertTrue(t, lc.SetLightRoot(r) == data.ErrNoSuchFeed, "wrong error")
+	assertNil(t, lc.AddFeed(pk))
+	assertNil(t, lc.SetLightRoot(r))
+
+	var rs []*registry.Root
+	rs, err = lc.LightRoots(pk)
+	assertNil(t, err)
+
+	assertTrue(t, len(rs) == 1, "wrong number of Root objects")
+	assertTrue(t, rs[0].Hash == r.Hash, "wrong hash")
+	assertTrue(t, rs[0].Sig == r.Sig, "wrong signature")
+	assertTrue(t, rs[0].IsFull == false, "full")
+
+	// the Root is not in the Index and objects are not filled
+
+	_, err = lc.LastRoot(pk, r.Nonce)
+	assertTrue(t, err != nil, "light Root in the Index")
+
+	_, _, err = lc.Get(r.Refs[0].Hash, 0)
+	assertTrue(t, err == data.ErrNotFound, "object filled")
+
+	assertNil(t, lc.DelLightRoot(pk, r.Nonce))
+
+	rs, err = lc.LightRoots(pk)
+	assertNil(t, err)
+	assertTrue(t, len(rs) == 0, "Root is not removed")
+
+}
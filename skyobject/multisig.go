@@ -0,0 +1,286 @@
+package skyobject
+
+import (
+	"errors"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// AddMultisigFeed adds feed of given Multisig. The Multisig
+// saved in CXDS and used to verify signatures of Root objects
+// of the feed. A node that receives Root objects of a multisig
+// feed should add the feed using this method, since the feed
+// itself (see (*registry.Multisig).Feed) doesn't contain keys.
+// The method returns the feed. It's possible to call this
+// method many times. To remove a multisig feed use DelFeed
+func (c *Container) AddMultisigFeed(
+	ms *registry.Multisig,
+) (
+	feed cipher.PubKey,
+	err error,
+) {
+
+	if err = ms.Validate(); err != nil {
+		return
+	}
+
+	feed = ms.Feed()
+
+	if c.HasFeed(feed) == true {
+		return // already have
+	}
+
+	var val = ms.Encode()
+
+	if _, err = c.Set(cipher.SumSHA256(val), val, 1); err != nil {
+		return
+	}
+
+	err = c.AddFeed(feed)
+	return
+}
+
+// Multisig returns Multisig of given multisig feed.
+// It returns ErrUnknownMultisig if the Multisig has
+// not been added (see AddMultisigFeed)
+func (c *Container) Multisig(
+	feed cipher.PubKey,
+) (
+	ms *registry.Multisig,
+	err error,
+) {
+
+	var hash cipher.SHA256
+
+	if hash, err = registry.MultisigHash(feed); err != nil {
+		return
+	}
+
+	var val []byte
+
+	if val, _, err = c.Get(hash, 0); err != nil {
+		if err == data.ErrNotFound {
+			err = ErrUnknownMultisig
+		}
+		return
+	}
+
+	return registry.DecodeMultisig(val)
+}
+
+// PrepareRoot prepares given Root of a multisig feed
+// to be signed. The PrepareRoot sets Reg, Seq, Prev, Time
+// and Hash fields of the Root and resets its signatures.
+// After the preparation, the Root should be signed by
+// at least M keys of the Multisig (see SignRoot and
+// AddRootSignature) and saved using the Save method.
+// The Root must not be changed after the preparation,
+// and the Root should be prepared again if another
+// Root of the head has been saved or received. A
+// Root of multisig feed can be shared between signers
+// as is (all the fields are encoded, see Encode
+// method of the Root) and its hash should be
+// checked by every signer
+//
+//     // a signer has Root 'r' of a multisig feed
+//     // prepared by another signer
+//
+//     if r.Hash != cipher.SumSHA256(r.Encode()) {
+//         // wrong Root
+//     }
+//
+//     var sig, err = ms.Sign(r.Hash, sk)
+//
+//     // send the sig back
+//
+func (c *Container) PrepareRoot(up *Unpack, r *registry.Root) (err error) {
+
+	if registry.IsMultisigFeed(r.Pub) == false {
+		return registry.ErrNotMultisigFeed
+	}
+
+	if r.Nonce == 0 {
+		return errors.New("zero Nonce field of the Root")
+	}
+
+	if rr := up.Registry().Reference(); r.Reg == (registry.RegistryRef{}) {
+		r.Reg = rr
+	} else if r.Reg != rr {
+		if len(r.Refs) != 0 {
+			return errors.New("can't change Registry of non-blank Root")
+		}
+		r.Reg = rr
+	}
+
+	var lastSeq, lastHash, has = uint64(0), cipher.SHA256{}, false
+
+	if lastSeq, lastHash, has, err = c.Index.lastSeqHash(r.Pub,
+		r.Nonce); err != nil {
+
+		return
+	}
+
+	if has == true {
+		r.Seq = lastSeq + 1
+		r.Prev = lastHash
+	} else {
+		r.Seq = 0
+		r.Prev = cipher.SHA256{}
+	}
+
+	r.Time = time.Now().UnixNano()
+	r.Hash = cipher.SumSHA256(r.Encode())
+	r.Sig = cipher.Sig{}
+	r.Sigs = nil
+	r.IsFull = false
+
+	return
+}
+
+// SignRoot adds signature of given secret key to
+// given prepared Root of a multisig feed (see
+// PrepareRoot). The secret key should be secret
+// key of one of keys of the Multisig
+func (c *Container) SignRoot(r *registry.Root, sk cipher.SecKey) (err error) {
+
+	var ms *registry.Multisig
+
+	if ms, err = c.preparedMultisig(r); err != nil {
+		return
+	}
+
+	var sig registry.Signature
+
+	if sig, err = ms.Sign(r.Hash, sk); err != nil {
+		return
+	}
+
+	r.Sigs = r.Sigs.Add(sig)
+	return
+}
+
+// AddRootSignature adds given signature to given prepared
+// Root of a multisig feed. The signature is verified. Use
+// the AddRootSignature to add signatures of remote signers
+func (c *Container) AddRootSignature(
+	r *registry.Root,
+	sig registry.Signature,
+) (
+	err error,
+) {
+
+	var ms *registry.Multisig
+
+	if ms, err = c.preparedMultisig(r); err != nil {
+		return
+	}
+
+	if err = ms.VerifySignature(r.Hash, sig); err != nil {
+		return
+	}
+
+	r.Sigs = r.Sigs.Add(sig)
+	return
+}
+
+// Multisig of given Root, the Root should be prepared
+func (c *Container) preparedMultisig(
+	r *registry.Root,
+) (
+	ms *registry.Multisig,
+	err error,
+) {
+
+	if ms, err = c.Multisig(r.Pub); err != nil {
+		return
+	}
+
+	if r.Hash == (cipher.SHA256{}) || r.Hash != cipher.SumSHA256(r.Encode()) {
+		return nil, ErrRootNotPrepared
+	}
+
+	return
+}
+
+// verify Root of a multisig feed before saving
+func (c *Container) verifyMultisigRoot(r *registry.Root) (err error) {
+
+	var ms *registry.Multisig
+
+	if ms, err = c.preparedMultisig(r); err != nil {
+		return
+	}
+
+	return ms.Verify(r.Hash, r.Sigs)
+}
+
+// verify signatures of given received
+// Root (under lock)
+func (i *Index) verifyRoot(r *registry.Root) (err error) {
+
+	if registry.IsMultisigFeed(r.Pub) == false {
+
+		if r.Cert == nil && len(r.Revs) == 0 {
+			return cipher.VerifyPubKeySignedHash(r.Pub, r.Sig, r.Hash)
+		}
+
+		return i.verifyCertifiedRoot(r)
+	}
+
+	var ms *registry.Multisig
+
+	if ms, err = i.c.Multisig(r.Pub); err != nil {
+		return
+	}
+
+	return ms.Verify(r.Hash, r.Sigs)
+}
+
+// set Sig field and signature container of given Root
+func setRootSigs(r *registry.Root, dr *data.Root) (err error) {
+	r.Sig = dr.Sig
+	return r.DecodeSigs(dr.Sigs)
+}
+
+// lastSeqHash returns seq and hash of last Root of given head
+func (i *Index) lastSeqHash(
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	seq uint64,
+	hash cipher.SHA256,
+	has bool,
+	err error,
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	err = i.c.db.IdxDB().Tx(func(fs data.Feeds) (err error) {
+
+		var hs data.Heads
+		if hs, err = fs.Heads(pk); err != nil {
+			return // no such feed
+		}
+
+		var roots data.Roots
+		if roots, err = hs.Roots(nonce); err != nil {
+			if err == data.ErrNoSuchHead {
+				err = nil // blank
+			}
+			return
+		}
+
+		return roots.Descend(func(dr *data.Root) (err error) {
+			seq, hash, has = dr.Seq, dr.Hash, true
+			return data.ErrStopIteration // enough
+		})
+
+	})
+
+	return
+}
@@ -0,0 +1,116 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_multisig(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+
+		pks = make([]cipher.PubKey, 3)
+		sks = make([]cipher.SecKey, 3)
+	)
+
+	defer sc.Close()
+	defer rc.Close()
+
+	for i := range pks {
+		pks[i], sks[i] = cipher.GenerateKeyPair()
+	}
+
+	var ms, err = registry.NewMultisig(2, pks...)
+	assertNil(t, err)
+
+	var feed cipher.PubKey
+	feed, err = sc.AddMultisigFeed(ms)
+	assertNil(t, err)
+
+	assertTrue(t, feed == ms.Feed(), "wrong feed")
+	assertTrue(t, sc.HasFeed(feed), "feed not added")
+
+	var gms *registry.Multisig
+	gms, err = sc.Multisig(feed)
+	assertNil(t, err)
+	assertTrue(t, gms.Feed() == feed, "wrong Multisig")
+
+	var up *Unpack
+	up, err = sc.Unpack(sks[0], testRegistry)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+	r.Pub = feed
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+	}
+
+	// not prepared
+	assertTrue(t, sc.Save(up, r) == ErrRootNotPrepared, "saved")
+
+	assertNil(t, sc.PrepareRoot(up, r))
+	assertTrue(t, r.Hash == cipher.SumSHA256(r.Encode()), "wrong hash")
+
+	// not enough signatures
+	assertNil(t, sc.SignRoot(r, sks[0]))
+	assertNil(t, sc.SignRoot(r, sks[0])) // twice
+	assertTrue(t, sc.Save(up, r) == registry.ErrNotEnoughSignatures,
+		"saved without enough signatures")
+
+	// sign by remote signer
+	var sig registry.Signature
+	sig, err = ms.Sign(r.Hash, sks[2])
+	assertNil(t, err)
+	assertNil(t, sc.AddRootSignature(r, sig))
+	assertTrue(t, len(r.Sigs) == 2, "wrong number of signatures")
+
+	assertNil(t, sc.Save(up, r))
+	assertTrue(t, r.Seq == 0, "wrong seq")
+
+	var lr *registry.Root
+	lr, err = sc.LastRoot(feed, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, lr.Hash == r.Hash, "wrong last Root")
+	assertTrue(t, len(lr.Sigs) == 2, "missing signatures")
+
+	// outdated
+	assertTrue(t, sc.Save(up, r) == ErrOutdatedRoot, "saved twice")
+
+	// change after preparation
+	assertNil(t, sc.PrepareRoot(up, r))
+	assertTrue(t, r.Seq == 1, "wrong seq")
+	assertNil(t, sc.SignRoot(r, sks[0]))
+	assertNil(t, sc.SignRoot(r, sks[1]))
+	r.Descriptor = []byte("changed")
+	assertTrue(t, sc.Save(up, r) == ErrRootNotPrepared, "changed Root saved")
+
+	// received Root
+
+	_, err = rc.ReceivedRoot(lr.Pub, lr.Sig, lr.Encode(), lr.EncodeSigs())
+	assertTrue(t, err == ErrUnknownMultisig, "unknown multisig")
+
+	_, err = rc.AddMultisigFeed(ms)
+	assertNil(t, err)
+
+	var one = *lr
+	one.Sigs = lr.Sigs[:1]
+	_, err = rc.ReceivedRoot(lr.Pub, lr.Sig, lr.Encode(), one.EncodeSigs())
+	assertTrue(t, err == registry.ErrNotEnoughSignatures, "not enough")
+
+	var rr *registry.Root
+	rr, err = rc.ReceivedRoot(lr.Pub, lr.Sig, lr.Encode(), lr.EncodeSigs())
+	assertNil(t, err)
+	assertTrue(t, len(rr.Sigs) == 2, "missing signatures")
+
+	testFillRoot(t, sc, rc, lr)
+
+	lr, err = rc.LastRoot(feed, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, len(lr.Sigs) == 2, "signatures not saved")
+
+}
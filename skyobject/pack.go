@@ -12,6 +12,9 @@ type Pack struct {
 	c     *Container
 	deg   registry.Degree
 	flags registry.Flags
+
+	key     registry.ContentKey // content key of a private Root
+	private bool                // has the key
 }
 
 // Registry returns related registry
@@ -19,12 +22,28 @@ func (p *Pack) Registry() (reg *registry.Registry) {
 	return p.reg
 }
 
-// Get value by hash
+// Get value by hash. If the Pack has content
+// key (see PrivatePack), then the Get opens
+// sealed objects
 func (p *Pack) Get(key cipher.SHA256) (val []byte, err error) {
-	val, _, err = p.c.Get(key, 0)
+
+	if val, _, err = p.c.Get(key, 0); err != nil {
+		return
+	}
+
+	if p.private == true && registry.IsSealed(val) == true {
+		return p.key.Open(val)
+	}
+
 	return
 }
 
+// ContentKey of a private Root the Pack created
+// for. The method implements registry.PrivatePack
+func (p *Pack) ContentKey() (key registry.ContentKey, ok bool) {
+	return p.key, p.private
+}
+
 // Set key-value pair
 func (p *Pack) Set(key cipher.SHA256, val []byte) (err error) {
 
@@ -0,0 +1,38 @@
+package skyobject
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// PathProof returns proof that object with given hash
+// is reachable from given Root. The Root must be signed
+// (see RootByHash and LastRoot). A light client can
+// verify the proof using Verify method of the PathProof
+// trusting the object without the Root tree. The
+// PathProof returns registry.ErrNotFound if the object
+// is not reachable from the Root
+func (c *Container) PathProof(
+	r *registry.Root,
+	hash cipher.SHA256,
+) (
+	pp *registry.PathProof,
+	err error,
+) {
+
+	var pack *Pack
+	if pack, err = c.Pack(r, nil); err != nil {
+		return
+	}
+
+	var ms *registry.Multisig
+
+	if registry.IsMultisigFeed(r.Pub) == true {
+		if ms, err = c.Multisig(r.Pub); err != nil {
+			return
+		}
+	}
+
+	return registry.NewPathProof(pack, r, ms, hash)
+}
@@ -0,0 +1,98 @@
+package skyobject
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_PathProof(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var (
+		usr  = User{"Alice", 19}
+		feed = Feed{Head: "Alices' feed", Info: "an average feed"}
+	)
+
+	for i := 0; i < 100; i++ {
+		assertNil(t, feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+			Body: fmt.Sprintf("Body #%d", i),
+		}))
+	}
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &usr),
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, c.Save(up, r))
+
+	var lr *registry.Root
+	lr, err = c.LastRoot(pk, r.Nonce)
+	assertNil(t, err)
+
+	var post cipher.SHA256
+	post, err = feed.Posts.HashByIndex(up, 57)
+	assertNil(t, err)
+
+	for _, hash := range []cipher.SHA256{
+		r.Refs[0].Hash,  // User
+		feed.Posts.Hash, // Refs
+		post,            // element of the Refs
+	} {
+
+		var pp *registry.PathProof
+		pp, err = c.PathProof(lr, hash)
+		assertNil(t, err)
+
+		if pp, err = registry.DecodePathProof(pp.Encode()); err != nil {
+			t.Fatal(err)
+		}
+
+		var vr *registry.Root
+		vr, err = pp.Verify(hash)
+		assertNil(t, err)
+		assertTrue(t, vr.Hash == lr.Hash, "wrong Root")
+
+		_, err = pp.Verify(cipher.SumSHA256([]byte("other")))
+		assertTrue(t, err == registry.ErrInvalidProof, "wrong object")
+
+		// broken link
+		if len(pp.Path) > 1 {
+			var path = pp.Path
+			pp.Path = append([][]byte{}, path[1:]...)
+			_, err = pp.Verify(hash)
+			assertTrue(t, err == registry.ErrInvalidProof, "broken link")
+			pp.Path = path
+		}
+
+		// wrong signature
+		pp.Sig = cipher.Sig{}
+		_, err = pp.Verify(hash)
+		assertTrue(t, err != nil, "wrong signature")
+
+	}
+
+	_, err = c.PathProof(lr, cipher.SumSHA256([]byte("other")))
+	assertTrue(t, err == registry.ErrNotFound, "not reachable")
+
+}
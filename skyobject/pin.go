@@ -0,0 +1,210 @@
+package skyobject
+
+import (
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// pinned Root objects of a feed are stored
+// as meta information of the feed (see
+// data.Feeds), the key is "p"
+var pinsKey = []byte("p")
+
+// A Pinned represents pinned Root of a feed
+type Pinned struct {
+	Nonce uint64 // head
+	Seq   uint64 // seq number of the Root
+}
+
+// encoded list of pinned Root objects
+type pinnedList struct {
+	Pins []Pinned
+}
+
+// Pin given Root. A pinned Root can't be removed by
+// the DelRoot method or by the Prune method (see
+// Retention). Use ForceDelRoot to remove a pinned
+// Root. Removing a head or a feed, removes its
+// pinned Root objects too. Pins are kept in DB.
+// It returns data.ErrNotFound if the Root
+// doesn't exist
+func (c *Container) Pin(feed cipher.PubKey, nonce, seq uint64) (err error) {
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	return c.db.IdxDB().Tx(func(fs data.Feeds) (err error) {
+
+		var hs data.Heads
+		if hs, err = fs.Heads(feed); err != nil {
+			return
+		}
+
+		var rs data.Roots
+		if rs, err = hs.Roots(nonce); err != nil {
+			return
+		}
+
+		var ok bool
+		if ok, err = rs.Has(seq); err != nil {
+			return
+		}
+
+		if ok == false {
+			return data.ErrNotFound
+		}
+
+		var pins []Pinned
+		if pins, err = getPins(fs, feed); err != nil {
+			return
+		}
+
+		if findPin(pins, nonce, seq) >= 0 {
+			return // already pinned
+		}
+
+		pins = append(pins, Pinned{nonce, seq})
+
+		sort.Slice(pins, func(i, j int) bool {
+			if pins[i].Nonce == pins[j].Nonce {
+				return pins[i].Seq < pins[j].Seq
+			}
+			return pins[i].Nonce < pins[j].Nonce
+		})
+
+		return setPins(fs, feed, pins)
+	})
+
+}
+
+// Unpin given Root. It does nothing if the Root is not
+// pinned. It returns data.ErrNoSuchFeed if the Container
+// doesn't have the feed
+func (c *Container) Unpin(feed cipher.PubKey, nonce, seq uint64) (err error) {
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	return c.db.IdxDB().Tx(func(fs data.Feeds) error {
+		return unpin(fs, feed, nonce, seq)
+	})
+}
+
+// Pins returns pinned Root objects of given feed ordered
+// by nonce and seq. It returns data.ErrNoSuchFeed if
+// the Container doesn't have the feed
+func (c *Container) Pins(feed cipher.PubKey) (pins []Pinned, err error) {
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	err = c.db.IdxDB().Tx(func(fs data.Feeds) (err error) {
+		pins, err = getPins(fs, feed)
+		return
+	})
+
+	return
+}
+
+// IsPinned returns true if given Root is pinned
+func (c *Container) IsPinned(
+	feed cipher.PubKey,
+	nonce uint64,
+	seq uint64,
+) (
+	pinned bool,
+	err error,
+) {
+
+	var pins []Pinned
+	if pins, err = c.Pins(feed); err != nil {
+		return
+	}
+
+	return findPin(pins, nonce, seq) >= 0, nil
+}
+
+func getPins(fs data.Feeds, feed cipher.PubKey) (pins []Pinned, err error) {
+
+	var val []byte
+
+	switch val, err = fs.GetMeta(feed, pinsKey); err {
+	case nil:
+	case data.ErrNotFound:
+		return nil, nil // no pins
+	default:
+		return
+	}
+
+	var pl pinnedList
+	if _, err = encoder.DeserializeRaw(val, &pl); err != nil {
+		return
+	}
+
+	return pl.Pins, nil
+}
+
+func setPins(fs data.Feeds, feed cipher.PubKey, pins []Pinned) (err error) {
+
+	var val []byte
+
+	if len(pins) != 0 {
+		val = encoder.Serialize(&pinnedList{pins})
+	}
+
+	return fs.SetMeta(feed, pinsKey, val)
+}
+
+// index of given Root in given pins or -1
+func findPin(pins []Pinned, nonce, seq uint64) (i int) {
+	for i = range pins {
+		if pins[i].Nonce == nonce && pins[i].Seq == seq {
+			return
+		}
+	}
+	return -1
+}
+
+// unpin given Root
+func unpin(fs data.Feeds, feed cipher.PubKey, nonce, seq uint64) (err error) {
+
+	var pins []Pinned
+	if pins, err = getPins(fs, feed); err != nil {
+		return
+	}
+
+	var i = findPin(pins, nonce, seq)
+
+	if i < 0 {
+		return // not pinned
+	}
+
+	return setPins(fs, feed, append(pins[:i], pins[i+1:]...))
+}
+
+// unpin all Root objects of given head
+func unpinHead(fs data.Feeds, feed cipher.PubKey, nonce uint64) (err error) {
+
+	var pins []Pinned
+	if pins, err = getPins(fs, feed); err != nil {
+		return
+	}
+
+	var keep = pins[:0]
+
+	for _, p := range pins {
+		if p.Nonce != nonce {
+			keep = append(keep, p)
+		}
+	}
+
+	if len(keep) == len(pins) {
+		return // nothing to unpin
+	}
+
+	return setPins(fs, feed, keep)
+}
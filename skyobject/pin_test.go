@@ -0,0 +1,103 @@
+package skyobject
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_Pin(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+
+	for i := 0; i < 4; i++ {
+		r.Refs = []registry.Dynamic{
+			createDynamic(up, testRegistry, "test.User", &User{
+				Name: fmt.Sprintf("User #%d", i),
+				Age:  uint32(i),
+			}),
+		}
+		assertNil(t, c.Save(up, r))
+	}
+
+	assertTrue(t, c.Pin(pk, r.Nonce, 10) == data.ErrNotFound, "wrong error")
+
+	assertNil(t, c.Pin(pk, r.Nonce, 1))
+	assertNil(t, c.Pin(pk, r.Nonce, 0))
+	assertNil(t, c.Pin(pk, r.Nonce, 1)) // twice
+
+	var pins []Pinned
+	pins, err = c.Pins(pk)
+	assertNil(t, err)
+	assertTrue(t, len(pins) == 2 &&
+		pins[0] == Pinned{r.Nonce, 0} &&
+		pins[1] == Pinned{r.Nonce, 1}, fmt.Sprint("wrong pins ", pins))
+
+	var pinned bool
+	pinned, err = c.IsPinned(pk, r.Nonce, 1)
+	assertNil(t, err)
+	assertTrue(t, pinned, "not pinned")
+
+	// DelRoot
+
+	assertTrue(t, c.DelRoot(pk, r.Nonce, 0) == ErrPinnedRoot, "wrong error")
+
+	_, err = c.Root(pk, r.Nonce, 0)
+	assertNil(t, err)
+
+	// Prune
+
+	assertNil(t, c.SetRetention(pk, 0, Retention{KeepLast: 1}))
+
+	var roots int
+	roots, _, err = c.Prune()
+	assertNil(t, err)
+	assertTrue(t, roots == 1, "pinned Root removed")
+
+	_, err = c.Root(pk, r.Nonce, 1)
+	assertNil(t, err)
+
+	// Unpin
+
+	assertNil(t, c.Unpin(pk, r.Nonce, 1))
+	assertNil(t, c.Unpin(pk, r.Nonce, 1)) // twice
+
+	assertNil(t, c.DelRoot(pk, r.Nonce, 1))
+
+	// ForceDelRoot
+
+	assertNil(t, c.ForceDelRoot(pk, r.Nonce, 0))
+
+	pins, err = c.Pins(pk)
+	assertNil(t, err)
+	assertTrue(t, len(pins) == 0, "not unpinned")
+
+	// DelHead
+
+	assertNil(t, c.Pin(pk, r.Nonce, 3))
+	assertNil(t, c.DelHead(pk, r.Nonce))
+
+	pins, err = c.Pins(pk)
+	assertNil(t, err)
+	assertTrue(t, len(pins) == 0, "not unpinned")
+
+}
@@ -0,0 +1,78 @@
+package skyobject
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// PrivateUnpack is the same as the Unpack, but objects
+// created using the Unpack are sealed (encrypted) by
+// given content key. Use SetPrivate method of the Root
+// to distribute the key between readers. For example
+//
+//     var key = registry.NewContentKey()
+//
+//     up, err := c.PrivateUnpack(sk, reg, key)
+//     if err != nil {
+//         // [...]
+//     }
+//     defer up.Close()
+//
+//     // [...] create objects
+//
+//     err = r.SetPrivate(key, []cipher.PubKey{pk, alice, bob}, nil)
+//     if err != nil {
+//         // [...]
+//     }
+//
+//     err = c.Save(up, r)
+//
+// The same content key should be used for next Root
+// objects of the feed. Nodes that don't have the key
+// store and share the Root and its objects as usual,
+// but can't read them. See also PrivatePack
+func (c *Container) PrivateUnpack(
+	sk cipher.SecKey,
+	reg *registry.Registry,
+	key registry.ContentKey,
+) (
+	up *Unpack,
+	err error,
+) {
+
+	if up, err = c.Unpack(sk, reg); err != nil {
+		return
+	}
+
+	up.key, up.private = key, true
+	return
+}
+
+// PrivatePack is the same as the Pack, but the PrivatePack
+// opens sealed objects of given private Root. The content
+// key of the Root is unwrapped using given secret key of
+// a reader. See SetPrivate method of the registry.Root
+// for details. The PrivatePack returns registry.ErrNotReader
+// if given key is not a key of a reader
+func (c *Container) PrivatePack(
+	r *registry.Root,
+	reg *registry.Registry,
+	sk cipher.SecKey,
+) (
+	p *Pack,
+	err error,
+) {
+
+	var key registry.ContentKey
+	if key, err = r.ContentKey(sk); err != nil {
+		return
+	}
+
+	if p, err = c.Pack(r, reg); err != nil {
+		return
+	}
+
+	p.key, p.private = key, true
+	return
+}
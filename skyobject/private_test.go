@@ -0,0 +1,124 @@
+package skyobject
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_PrivateUnpack(t *testing.T) {
+
+	var (
+		sc, rc   = getTestContainer(), getTestContainer()
+		pk, sk   = cipher.GenerateKeyPair()
+		apk, ask = cipher.GenerateKeyPair() // reader
+		_, esk   = cipher.GenerateKeyPair() // not a reader
+		key      = registry.NewContentKey()
+	)
+
+	defer sc.Close()
+	defer rc.Close()
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.PrivateUnpack(sk, testRegistry, key)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+
+	var feed = Feed{
+		Head: "secret feed",
+		Info: "private",
+	}
+
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{
+			Name: "Alice",
+			Age:  21,
+		}),
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, r.SetPrivate(key, []cipher.PubKey{pk, apk}, []byte("hey")))
+
+	assertNil(t, sc.Save(up, r))
+	testFillRoot(t, sc, rc, r)
+	testFillDBs(t, sc, rc)
+
+	for i := 0; i < 10; i++ {
+
+		assertNil(t, feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Secret head #%d", i),
+			Body: fmt.Sprintf("Secret body #%d", i),
+		}))
+
+		assertNil(t, r.Refs[1].SetValue(up, &feed))
+
+		assertNil(t, sc.Save(up, r))
+		testFillRoot(t, sc, rc, r)
+		testFillDBs(t, sc, rc)
+	}
+
+	// ciphertext only
+
+	assertNil(t, rc.db.CXDS().Iterate(
+		func(_ cipher.SHA256, _ uint32, val []byte) (_ error) {
+			for _, s := range []string{"Alice", "secret feed", "Secret"} {
+				assertTrue(t, bytes.Contains(val, []byte(s)) == false,
+					"not encrypted")
+			}
+			return
+		}))
+
+	// fsck walks the Root without the key
+
+	var rep *FsckReport
+	rep, err = rc.Fsck(false)
+	assertNil(t, err)
+	assertTrue(t, rep.IsClean(), fmt.Sprintf("not clean %+v", rep))
+
+	// read
+
+	var pack *Pack
+
+	_, err = rc.PrivatePack(r, nil, esk)
+	assertTrue(t, err == registry.ErrNotReader, fmt.Sprint("wrong error ", err))
+
+	pack, err = rc.PrivatePack(r, nil, ask)
+	assertNil(t, err)
+
+	var usr User
+	assertNil(t, r.Refs[0].Value(pack, &usr))
+	assertTrue(t, usr.Name == "Alice", "wrong User")
+
+	var gf Feed
+	assertNil(t, r.Refs[1].Value(pack, &gf))
+	assertTrue(t, gf.Head == feed.Head, "wrong Feed")
+
+	var post Post
+	_, err = gf.Posts.ValueByIndex(pack, 9, &post)
+	assertNil(t, err)
+	assertTrue(t, post.Body == "Secret body #9", "wrong Post")
+
+	var data []byte
+	data, err = r.PrivateData(key)
+	assertNil(t, err)
+	assertTrue(t, string(data) == "hey", "wrong data")
+
+	// without the key
+
+	pack, err = rc.Pack(r, nil)
+	assertNil(t, err)
+
+	assertTrue(t, r.Refs[0].Value(pack, &usr) != nil || usr.Name != "Alice",
+		"decrypted without the key")
+
+}
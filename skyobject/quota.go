@@ -0,0 +1,369 @@
+package skyobject
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// quota of a feed is stored as meta
+// information of the feed (see data.Feeds)
+var quotaKey = []byte("q")
+
+// A Quota represents limits of a feed. A Root that
+// exceeds a limit of its feed can't be filled. Zero
+// value of a field means no limit
+type Quota struct {
+	Roots   uint64 // max number of Root objects
+	Objects uint64 // max number of objects
+	Volume  uint64 // max total size of objects in bytes
+}
+
+// A Usage represents resources a feed uses. Objects
+// shared by Root objects of the feed counted once
+type Usage struct {
+	Roots   uint64 // number of Root objects
+	Objects uint64 // number of objects
+	Volume  uint64 // total size of objects in bytes
+}
+
+// A QuotaError occurs when a Root exceeds a Quota of its feed
+type QuotaError struct {
+	Feed  cipher.PubKey // the feed
+	Name  string        // "roots", "objects" or "volume"
+	Limit uint64        // the limit
+}
+
+// Error implements error interface
+func (q *QuotaError) Error() string {
+	return fmt.Sprintf("quota of feed %s exceeded: %s limit is %d",
+		q.Feed.Hex()[:7], q.Name, q.Limit)
+}
+
+// SetQuota sets Quota of given feed. Use blank Quota to
+// remove the limits. The Quota is kept in DB and used
+// by Filler for Root objects received by this Container.
+// It's possible to exceed the Quota saving Root objects
+// locally. The SetQuota returns data.ErrNoSuchFeed if
+// the Container doesn't have the feed
+func (c *Container) SetQuota(feed cipher.PubKey, q Quota) (err error) {
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	var val []byte
+
+	if q != (Quota{}) {
+		val = encoder.Serialize(&q)
+	}
+
+	return c.db.IdxDB().Tx(func(fs data.Feeds) error {
+		return fs.SetMeta(feed, quotaKey, val)
+	})
+}
+
+// Quota returns Quota of given feed. It returns
+// data.ErrNoSuchFeed if the Container doesn't
+// have the feed
+func (c *Container) Quota(feed cipher.PubKey) (q Quota, err error) {
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	err = c.db.IdxDB().Tx(func(fs data.Feeds) (err error) {
+
+		var val []byte
+
+		switch val, err = fs.GetMeta(feed, quotaKey); err {
+		case nil:
+		case data.ErrNotFound:
+			return nil // no limits
+		default:
+			return
+		}
+
+		_, err = encoder.DeserializeRaw(val, &q)
+		return
+	})
+
+	return
+}
+
+// Usage returns Usage of given feed. First time, the Usage
+// walks through all Root objects of the feed to count objects.
+// After that, the Usage keeps references counters of objects
+// of the feed in memory, and walks only through new parts of
+// Root objects added since last call. Removing a Root walks
+// through the parts used by the Root only. It returns
+// data.ErrNoSuchFeed if the Container doesn't have the feed
+func (c *Container) Usage(feed cipher.PubKey) (u Usage, err error) {
+
+	c.Index.umx.Lock()
+	defer c.Index.umx.Unlock()
+
+	var rhs []cipher.SHA256 // hashes of Root objects
+	if rhs, err = c.Index.feedRoots(feed); err != nil {
+		return
+	}
+
+	var fu, ok = c.Index.usage[feed]
+
+	if ok == false {
+		fu = newFeedUsage()
+		c.Index.usage[feed] = fu
+	}
+
+	var actual = make(map[cipher.SHA256]struct{}, len(rhs))
+
+	for _, rh := range rhs {
+
+		actual[rh] = struct{}{}
+
+		if _, ok = fu.roots[rh]; ok == true {
+			continue // already counted
+		}
+
+		if err = c.countRoot(fu, rh); err != nil {
+			delete(c.Index.usage, feed) // can be broken
+			return
+		}
+
+	}
+
+	// a Root removed without the unuseRoot can't be
+	// uncounted, since its objects can be removed
+
+	for rh := range fu.roots {
+		if _, ok = actual[rh]; ok == false {
+			delete(c.Index.usage, feed) // count next time
+			break
+		}
+	}
+
+	u.Roots = uint64(len(rhs))
+	u.Objects, u.Volume = fu.objects, fu.volume
+	return
+}
+
+// usage of a feed; the rc of an object is number of
+// references to the object from counted Root objects
+// and from counted objects of the feed; thus shared
+// objects are counted once
+type feedUsage struct {
+	objects uint64
+	volume  uint64
+
+	rc    map[cipher.SHA256]int      // objects of the feed
+	roots map[cipher.SHA256]struct{} // counted Root objects
+}
+
+func newFeedUsage() (fu *feedUsage) {
+	fu = new(feedUsage)
+	fu.rc = make(map[cipher.SHA256]int)
+	fu.roots = make(map[cipher.SHA256]struct{})
+	return
+}
+
+// count objects of Root with given hash walking
+// through objects not counted yet (under the umx)
+func (c *Container) countRoot(fu *feedUsage, rh cipher.SHA256) (err error) {
+
+	var r *registry.Root
+	if r, err = c.rootByHash(rh); err != nil {
+		return
+	}
+
+	err = c.Walk(r, func(key cipher.SHA256, _ int) (deepper bool,
+		err error) {
+
+		if key == (cipher.SHA256{}) {
+			return // blank Refs
+		}
+
+		if fu.rc[key]++; fu.rc[key] > 1 {
+			return // already counted with its subtree
+		}
+
+		var val []byte
+		if val, _, err = c.Get(key, 0); err != nil {
+			return
+		}
+
+		fu.objects++
+		fu.volume += uint64(len(val))
+
+		return true, nil
+	})
+
+	if err == nil {
+		fu.roots[rh] = struct{}{}
+	}
+
+	return
+}
+
+// checkQuota walks through objects of given filled, but
+// not saved Root, and returns *QuotaError if objects not
+// counted for the feed yet, added to given Usage of the
+// feed, exceed given Quota; the objects counted the same
+// way the countRoot counts them, thus objects the DB
+// already has (e.g. shared with other feeds) counted too
+func (c *Container) checkQuota(
+	r *registry.Root, // : filled Root
+	u Usage, //          : usage of the feed before the Root
+	q Quota, //          : quota of the feed
+) (
+	err error, //        : *QuotaError or another error
+) {
+
+	c.Index.umx.Lock()
+	defer c.Index.umx.Unlock()
+
+	var (
+		fu      = c.Index.usage[r.Pub] // nil if not counted
+		counted = make(map[cipher.SHA256]struct{})
+	)
+
+	return c.Walk(r, func(key cipher.SHA256, _ int) (deepper bool,
+		err error) {
+
+		if key == (cipher.SHA256{}) {
+			return // blank Refs
+		}
+
+		if fu != nil && fu.rc[key] > 0 {
+			return // already counted with its subtree
+		}
+
+		if _, ok := counted[key]; ok == true {
+			return // already counted with its subtree
+		}
+
+		counted[key] = struct{}{}
+
+		var val []byte
+		if val, _, err = c.Get(key, 0); err != nil {
+			return
+		}
+
+		u.Objects++
+		u.Volume += uint64(len(val))
+
+		switch {
+		case q.Objects != 0 && u.Objects > q.Objects:
+			err = &QuotaError{r.Pub, "objects", q.Objects}
+		case q.Volume != 0 && u.Volume > q.Volume:
+			err = &QuotaError{r.Pub, "volume", q.Volume}
+		default:
+			deepper = true
+		}
+
+		return
+	})
+}
+
+// uncount objects of Root with given hash walking through
+// objects not used by other Root objects of the feed; the
+// Root and its objects must be in DB yet (under the umx)
+func (c *Container) uncountRoot(fu *feedUsage, rh cipher.SHA256) (err error) {
+
+	var r *registry.Root
+	if r, err = c.rootByHash(rh); err != nil {
+		return
+	}
+
+	err = c.Walk(r, func(key cipher.SHA256, _ int) (deepper bool,
+		err error) {
+
+		if key == (cipher.SHA256{}) {
+			return // blank Refs
+		}
+
+		if fu.rc[key]--; fu.rc[key] > 0 {
+			return // used by another Root
+		}
+
+		delete(fu.rc, key)
+
+		var val []byte
+		if val, _, err = c.Get(key, 0); err != nil {
+			return
+		}
+
+		fu.objects--
+		fu.volume -= uint64(len(val))
+
+		return true, nil
+	})
+
+	delete(fu.roots, rh)
+	return
+}
+
+// unuseRoot updates usage of given feed, if the usage
+// counted, removing Root with given hash; it must be
+// called after the Root removed from IdxDB, but before
+// its objects removed from DB
+func (i *Index) unuseRoot(feed cipher.PubKey, rh cipher.SHA256) {
+
+	i.umx.Lock()
+	defer i.umx.Unlock()
+
+	var fu, ok = i.usage[feed]
+
+	if ok == false {
+		return // not counted
+	}
+
+	if _, ok = fu.roots[rh]; ok == false {
+		return // not counted
+	}
+
+	if err := i.c.uncountRoot(fu, rh); err != nil {
+		delete(i.usage, feed) // count next time
+	}
+
+}
+
+// unuseFeed removes usage of given feed
+func (i *Index) unuseFeed(feed cipher.PubKey) {
+
+	i.umx.Lock()
+	defer i.umx.Unlock()
+
+	delete(i.usage, feed)
+}
+
+// hashes of all Root objects of given feed
+func (i *Index) feedRoots(feed cipher.PubKey) (rhs []cipher.SHA256, err error) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	err = i.c.db.IdxDB().Tx(func(fs data.Feeds) (err error) {
+
+		var hs data.Heads
+		if hs, err = fs.Heads(feed); err != nil {
+			return
+		}
+
+		return hs.Iterate(func(nonce uint64) (err error) {
+
+			var rs data.Roots
+			if rs, err = hs.Roots(nonce); err != nil {
+				return
+			}
+
+			return rs.Ascend(func(dr *data.Root) (_ error) {
+				rhs = append(rhs, dr.Hash)
+				return
+			})
+		})
+	})
+
+	return
+}
@@ -0,0 +1,336 @@
+package skyobject
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_Quota(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, _  = cipher.GenerateKeyPair()
+		q      Quota
+		err    error
+		quota1 = Quota{Roots: 1, Objects: 2, Volume: 3}
+	)
+
+	defer c.Close()
+
+	_, err = c.Quota(pk)
+	assertTrue(t, err == data.ErrNoSuchFeed, "wrong error")
+	assertTrue(t, c.SetQuota(pk, quota1) == data.ErrNoSuchFeed, "wrong error")
+
+	assertNil(t, c.AddFeed(pk))
+
+	q, err = c.Quota(pk)
+	assertNil(t, err)
+	assertTrue(t, q == Quota{}, "not blank")
+
+	assertNil(t, c.SetQuota(pk, quota1))
+
+	q, err = c.Quota(pk)
+	assertNil(t, err)
+	assertTrue(t, q == quota1, "wrong Quota")
+
+	assertNil(t, c.SetQuota(pk, Quota{}))
+
+	q, err = c.Quota(pk)
+	assertNil(t, err)
+	assertTrue(t, q == Quota{}, "not removed")
+
+}
+
+func TestFiller_quota(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer sc.Close()
+	defer rc.Close()
+
+	assertNil(t, sc.AddFeed(pk))
+	assertNil(t, rc.AddFeed(pk))
+
+	var up, err = sc.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var (
+		r    = new(registry.Root)
+		feed Feed
+	)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, sc.Save(up, r))
+
+	var u Usage
+	u, err = sc.Usage(pk)
+	assertNil(t, err)
+	// Root, Registry and the Feed
+	assertTrue(t, u == Usage{1, 3, u.Volume}, fmt.Sprint("wrong usage ", u))
+
+	// limit number of Root objects
+
+	assertNil(t, rc.SetQuota(pk, Quota{Roots: 1}))
+
+	var fr = *r
+	fr.IsFull = false
+	assertNil(t, testFill(t, sc, rc, &fr, nil))
+
+	u, err = rc.Usage(pk)
+	assertNil(t, err)
+	assertTrue(t, u == Usage{1, 3, u.Volume}, fmt.Sprint("wrong usage ", u))
+
+	var addPost = func(i int) {
+		assertNil(t, feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+			Body: fmt.Sprintf("Body #%d", i),
+		}))
+		assertNil(t, r.Refs[0].SetValue(up, &feed))
+		assertNil(t, sc.Save(up, r))
+		fr = *r
+		fr.IsFull = false
+	}
+
+	addPost(0)
+
+	var qe *QuotaError
+	err = testFill(t, sc, rc, &fr, nil)
+	qe, _ = err.(*QuotaError)
+	assertTrue(t, qe != nil && qe.Name == "roots", fmt.Sprint(err))
+
+	// limit number of objects
+
+	assertNil(t, rc.SetQuota(pk, Quota{Objects: u.Objects + 1}))
+
+	err = testFill(t, sc, rc, &fr, nil)
+	qe, _ = err.(*QuotaError)
+	assertTrue(t, qe != nil && qe.Name == "objects", fmt.Sprint(err))
+
+	_, err = rc.LastRoot(pk, r.Nonce)
+	assertNil(t, err) // the first one
+
+	// limit volume
+
+	assertNil(t, rc.SetQuota(pk, Quota{Volume: u.Volume + 1}))
+
+	err = testFill(t, sc, rc, &fr, nil)
+	qe, _ = err.(*QuotaError)
+	assertTrue(t, qe != nil && qe.Name == "volume", fmt.Sprint(err))
+
+	// no limits
+
+	assertNil(t, rc.SetQuota(pk, Quota{}))
+	assertNil(t, testFill(t, sc, rc, &fr, nil))
+
+	var su Usage
+	su, err = sc.Usage(pk)
+	assertNil(t, err)
+
+	u, err = rc.Usage(pk)
+	assertNil(t, err)
+	assertTrue(t, u == su, fmt.Sprint("wrong usage ", u, su))
+
+	var s = rc.Stat()
+	assertTrue(t, s.Feeds[pk].Usage == su, "wrong usage in Stat")
+
+}
+
+func TestFiller_quotaShared(t *testing.T) {
+
+	var (
+		sc, rc = getTestContainer(), getTestContainer()
+
+		apk, ask = cipher.GenerateKeyPair()
+		bpk, bsk = cipher.GenerateKeyPair()
+	)
+
+	defer sc.Close()
+	defer rc.Close()
+
+	for _, pk := range []cipher.PubKey{apk, bpk} {
+		assertNil(t, sc.AddFeed(pk))
+		assertNil(t, rc.AddFeed(pk))
+	}
+
+	var aup, err = sc.Unpack(ask, testRegistry)
+	assertNil(t, err)
+
+	var feed Feed
+
+	for i := 0; i < 5; i++ {
+		assertNil(t, feed.Posts.AppendValues(aup, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+			Body: fmt.Sprintf("Body #%d", i),
+		}))
+	}
+
+	var ar = new(registry.Root)
+
+	ar.Pub = apk
+	ar.Nonce = 9021
+	ar.Refs = []registry.Dynamic{
+		createDynamic(aup, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, sc.Save(aup, ar))
+
+	var fr = *ar
+	fr.IsFull = false
+	assertNil(t, testFill(t, sc, rc, &fr, nil))
+
+	// the same objects in another feed
+
+	var bup *Unpack
+	bup, err = sc.Unpack(bsk, testRegistry)
+	assertNil(t, err)
+
+	var br = new(registry.Root)
+
+	br.Pub = bpk
+	br.Nonce = 9021
+	br.Refs = []registry.Dynamic{ar.Refs[0]}
+
+	assertNil(t, sc.Save(bup, br))
+
+	var bu Usage
+	bu, err = sc.Usage(bpk)
+	assertNil(t, err)
+
+	// all objects except the Root are shared
+	// with the first feed, but they are counted
+
+	var qe *QuotaError
+
+	for _, q := range []Quota{
+		{Objects: bu.Objects - 1},
+		{Volume: bu.Volume - 1},
+	} {
+
+		assertNil(t, rc.SetQuota(bpk, q))
+
+		fr = *br
+		fr.IsFull = false
+		err = testFill(t, sc, rc, &fr, nil)
+		qe, _ = err.(*QuotaError)
+		assertTrue(t, qe != nil, fmt.Sprint("missing QuotaError ", err))
+
+	}
+
+	var u Usage
+	u, err = rc.Usage(bpk)
+	assertNil(t, err)
+	assertTrue(t, u == Usage{}, fmt.Sprint("wrong usage ", u))
+
+	// exactly
+
+	assertNil(t, rc.SetQuota(bpk, Quota{Objects: bu.Objects,
+		Volume: bu.Volume}))
+
+	fr = *br
+	fr.IsFull = false
+	assertNil(t, testFill(t, sc, rc, &fr, nil))
+
+	u, err = rc.Usage(bpk)
+	assertNil(t, err)
+	assertTrue(t, u == bu, fmt.Sprint("wrong usage ", u, bu))
+
+}
+
+func TestContainer_Usage(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var u Usage
+	u, err = c.Usage(pk)
+	assertNil(t, err)
+	assertTrue(t, u == Usage{}, fmt.Sprint("wrong usage of empty feed ", u))
+
+	// usage counted from scratch
+	var counted = func() (u Usage) {
+		c.Index.unuseFeed(pk)
+		var err error
+		u, err = c.Usage(pk)
+		assertNil(t, err)
+		return
+	}
+
+	var (
+		r    = new(registry.Root)
+		feed Feed
+	)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	for i := 0; i < 10; i++ {
+
+		assertNil(t, feed.Posts.AppendValues(up, Post{
+			Head: fmt.Sprintf("Head #%d", i),
+			Body: fmt.Sprintf("Body #%d", i),
+		}))
+		assertNil(t, r.Refs[0].SetValue(up, &feed))
+		assertNil(t, c.Save(up, r))
+
+		u, err = c.Usage(pk)
+		assertNil(t, err)
+		assertTrue(t, u.Roots == uint64(i+1), "wrong number of Root objects")
+		assertTrue(t, u == counted(), fmt.Sprint("wrong usage ", u))
+
+	}
+
+	// the usage is updated incrementally, but the
+	// counted usage is the same
+	var before Usage
+	before, err = c.Usage(pk)
+	assertNil(t, err)
+
+	assertNil(t, c.DelRoot(pk, r.Nonce, 0))
+
+	u, err = c.Usage(pk)
+	assertNil(t, err)
+	assertTrue(t, u.Roots == before.Roots-1, "wrong number of Root objects")
+	assertTrue(t, u.Objects < before.Objects, "objects are not uncounted")
+	assertTrue(t, u == counted(), fmt.Sprint("wrong usage ", u))
+
+	// remove last, objects of the last Root are not shared
+	// with the rest, since the Feed object changed
+
+	assertNil(t, c.DelRoot(pk, r.Nonce, r.Seq))
+
+	u, err = c.Usage(pk)
+	assertNil(t, err)
+	assertTrue(t, u == counted(), fmt.Sprint("wrong usage ", u))
+
+	assertNil(t, c.DelHead(pk, r.Nonce))
+
+	u, err = c.Usage(pk)
+	assertNil(t, err)
+	assertTrue(t, u == Usage{}, fmt.Sprint("wrong usage of empty feed ", u))
+
+}
@@ -0,0 +1,511 @@
+package registry
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// chunking and tree parameters of a Blob; the
+// parameters must not be changed, since they
+// are part of the Blob format (different
+// parameters produce different Merkle-trees
+// for the same content)
+const (
+	blobMinChunk   int    = 16 << 10                // 16K
+	blobMaxChunk   int    = 256 << 10               // 256K
+	blobChunkMask  uint64 = 0xffff << 48            // ~64K average
+	blobFanoutMask byte   = 0x7f                    // ~128 average
+	blobMaxFanout  int    = int(MaxDegree)          // 1024
+	blobGearSeed   string = "\x00cxo:blob:gear\x00" // gear table seed
+)
+
+// gear table of the content-defined chunking
+var blobGear [256]uint64
+
+func init() {
+	for i := range blobGear {
+		var hash = cipher.SumSHA256(append([]byte(blobGearSeed), byte(i)))
+		blobGear[i] = binary.LittleEndian.Uint64(hash[:8])
+	}
+}
+
+// A Blob represents large binary data. Unlike a
+// []byte field, that is limited by max object
+// size, the Blob splits the data into chunks
+// and keeps them in a Merkle-tree. Boundaries of
+// the chunks depends on the data (content-defined
+// chunking). Thus, if a part of the data changed,
+// then only chunks of the part will be changed.
+// Other chunks will be reused. This way, a Blob
+// can be changed in the middle cheaply, and only
+// new chunks will be sent through network
+//
+// Use Reader and Writer methods to get streaming
+// io.Reader and io.Writer (both of them implement
+// io.Seeker). Use Bytes and SetBytes for small
+// data
+//
+// The Blob is a reference and it can be used as
+// field of a struct, or element of an array or
+// slice. The Blob doesn't require schema tag
+//
+//	type File struct {
+//	    Name    string
+//	    Content registry.Blob
+//	}
+//
+// All blank Blobs are equal and represent empty
+// data. A Blob of a private Root (see SetPrivate
+// method of Root) seals its chunks and nodes
+// by the content key
+type Blob struct {
+	// Hash of root node of the Merkle-tree,
+	// blank if the Blob is blank (empty)
+	Hash cipher.SHA256
+}
+
+// IsBlank returns true if the Blob is blank
+func (b *Blob) IsBlank() bool {
+	return b.Hash == (cipher.SHA256{})
+}
+
+// Short returns first 7 bytes of String
+func (b *Blob) Short() string {
+	return b.Hash.Hex()[:7]
+}
+
+// String implements fmt.Stringer interface
+func (b *Blob) String() string {
+	return b.Hash.Hex()
+}
+
+// Clear the Blob making it blank
+func (b *Blob) Clear() {
+	b.Hash = cipher.SHA256{}
+}
+
+// Size of the Blob data in bytes
+func (b *Blob) Size(pack Pack) (size int64, err error) {
+
+	if b.IsBlank() == true {
+		return
+	}
+
+	var bn *blobNode
+	if bn, err = getBlobNode(pack, b.Hash); err != nil {
+		return
+	}
+
+	return int64(bn.size()), nil
+}
+
+// Bytes returns all data of the Blob. Use Reader
+// for large Blobs
+func (b *Blob) Bytes(pack Pack) (p []byte, err error) {
+
+	var br *BlobReader
+	if br, err = b.Reader(pack); err != nil {
+		return
+	}
+
+	return ioutil.ReadAll(br)
+}
+
+// SetBytes replaces data of the Blob with given.
+// Use Writer for large Blobs
+func (b *Blob) SetBytes(pack Pack, p []byte) (err error) {
+
+	var bw = &BlobWriter{b: b, pack: pack} // blank
+
+	if _, err = bw.Write(p); err != nil {
+		return
+	}
+
+	return bw.Close()
+}
+
+// Walk through the Blob. See WalkFunc for details.
+// The depth is zero for chunks of the Blob and is
+// greater than zero for nodes of the Merkle-tree
+func (b *Blob) Walk(
+	pack Pack, //         :
+	walkFunc WalkFunc, // :
+) (
+	err error,
+) {
+
+	if walkFunc == nil {
+		panic("walkFunc is nil") // for developers
+	}
+
+	if err = walkBlobNode(pack, b.Hash, 1, walkFunc); err == ErrStopIteration {
+		err = nil
+	}
+
+	return
+}
+
+// Split used by the node package to fill the Blob
+func (b *Blob) Split(s Splitter) {
+	splitBlobNode(s, b.Hash)
+}
+
+// an item of a Blob node (a chunk
+// or a child node)
+type blobItem struct {
+	Hash cipher.SHA256 // hash of chunk or node
+	Size uint64        // size of the chunk or data of the node
+}
+
+// a node of a Blob
+type blobNode struct {
+	Depth uint32     // zero if the Items are chunks
+	Items []blobItem // chunks or nodes
+}
+
+// size of data of the node
+func (b *blobNode) size() (size uint64) {
+	for _, it := range b.Items {
+		size += it.Size
+	}
+	return
+}
+
+func decodeBlobNode(val []byte) (bn *blobNode, err error) {
+
+	bn = new(blobNode)
+
+	if err = deserializeExact(val, bn); err != nil {
+		return nil, ErrInvalidBlob
+	}
+
+	if len(bn.Items) == 0 || len(bn.Items) > blobMaxFanout {
+		return nil, ErrInvalidBlob
+	}
+
+	for _, it := range bn.Items {
+		if it.Size == 0 {
+			return nil, ErrInvalidBlob
+		}
+	}
+
+	return
+}
+
+func getBlobNode(pack Pack, hash cipher.SHA256) (bn *blobNode, err error) {
+
+	var val []byte
+	if val, err = pack.Get(hash); err != nil {
+		return
+	}
+
+	return decodeBlobNode(val)
+}
+
+func getBlobChunk(pack Pack, it blobItem) (chunk []byte, err error) {
+
+	if chunk, err = pack.Get(it.Hash); err != nil {
+		return
+	}
+
+	if uint64(len(chunk)) != it.Size {
+		return nil, ErrInvalidBlob
+	}
+
+	return
+}
+
+// add chunk or node of a Blob sealing it if the
+// Pack is a PrivatePack with content key
+func addBlob(
+	pack Pack, //             : pack to save
+	val []byte, //            : chunk or encoded node
+	objs []cipher.SHA256, //  : items of the node
+) (
+	hash cipher.SHA256, //    : hash of the chunk or node
+	err error, //             : an error
+) {
+
+	if key, ok := contentKey(pack); ok == true {
+		return pack.Add(key.seal(val, objs, nil, nil))
+	}
+
+	return pack.Add(val)
+}
+
+func walkBlobNode(
+	pack Pack, //          : pack to get
+	hash cipher.SHA256, // : hash of the node
+	depth int, //          : depth of the node (> 0)
+	walkFunc WalkFunc, //  : the function
+) (
+	err error, //          : an error
+) {
+
+	var deepper bool
+	if deepper, err = walkFunc(hash, depth); err != nil || deepper == false {
+		return
+	}
+
+	if hash == (cipher.SHA256{}) {
+		return // ignore the deepper
+	}
+
+	var val []byte
+	if val, err = pack.Get(hash); err != nil {
+		return
+	}
+
+	// the node of a private Root (see SetPrivate)
+	if IsSealed(val) == true {
+		return walkSealed(pack, val, walkFunc)
+	}
+
+	var bn *blobNode
+	if bn, err = decodeBlobNode(val); err != nil {
+		return
+	}
+
+	for _, it := range bn.Items {
+
+		if bn.Depth == 0 {
+			if _, err = walkFunc(it.Hash, 0); err != nil {
+				return
+			}
+			continue
+		}
+
+		err = walkBlobNode(pack, it.Hash, int(bn.Depth), walkFunc)
+		if err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+func splitBlobNode(
+	s Splitter, //         : splitter
+	hash cipher.SHA256, // : hash of the node
+) {
+
+	if hash == (cipher.SHA256{}) {
+		return // nothing to split
+	}
+
+	var (
+		rc  int
+		val []byte
+		err error
+	)
+
+	if val, rc, err = s.Get(hash); err != nil {
+		s.Fail(err)
+		return
+	}
+
+	if rc > 1 {
+		return
+	}
+
+	// the node of a private Root (see SetPrivate)
+	if IsSealed(val) == true {
+		splitSealed(s, val)
+		return
+	}
+
+	var bn *blobNode
+	if bn, err = decodeBlobNode(val); err != nil {
+		s.Fail(err)
+		return
+	}
+
+	for _, it := range bn.Items {
+
+		var hash = it.Hash
+
+		if bn.Depth == 0 {
+			s.Go(func() {
+				if _, _, err := s.Get(hash); err != nil {
+					s.Fail(err)
+				}
+			})
+			continue
+		}
+
+		s.Go(func() { splitBlobNode(s, hash) })
+
+	}
+
+}
+
+// content-defined chunker of a Blob (gear hash)
+type blobChunker struct {
+	buf  []byte
+	hash uint64
+}
+
+// write given data calling the emit function for
+// every chunk; the emit function owns the chunk
+func (b *blobChunker) write(p []byte, emit func([]byte) error) (err error) {
+
+	for _, c := range p {
+
+		b.buf = append(b.buf, c)
+		b.hash = (b.hash << 1) + blobGear[c]
+
+		if len(b.buf) < blobMinChunk {
+			continue
+		}
+
+		if b.hash&blobChunkMask != 0 && len(b.buf) < blobMaxChunk {
+			continue
+		}
+
+		if err = b.flush(emit); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+// emit the rest, even if it's not a full chunk
+func (b *blobChunker) flush(emit func([]byte) error) (err error) {
+
+	if len(b.buf) == 0 {
+		return
+	}
+
+	var chunk = b.buf
+
+	b.buf, b.hash = nil, 0
+	return emit(chunk)
+}
+
+// is the chunker on a boundary of chunks
+func (b *blobChunker) empty() bool {
+	return len(b.buf) == 0
+}
+
+// builder of Merkle-tree of a Blob from chunks; the
+// nodes are content-defined too, to keep the tree
+// mostly the same after a change
+type blobBuilder struct {
+	pack   Pack
+	levels [][]blobItem // levels[0] are chunks
+}
+
+func (b *blobBuilder) add(level int, it blobItem) (err error) {
+
+	if level == len(b.levels) {
+		b.levels = append(b.levels, nil)
+	}
+
+	b.levels[level] = append(b.levels[level], it)
+
+	if it.Hash[0]&blobFanoutMask == 0 || len(b.levels[level]) >= blobMaxFanout {
+		return b.flush(level)
+	}
+
+	return
+}
+
+// create node of given level
+func (b *blobBuilder) flush(level int) (err error) {
+
+	var (
+		bn   = blobNode{Depth: uint32(level), Items: b.levels[level]}
+		objs = make([]cipher.SHA256, 0, len(bn.Items))
+		it   = blobItem{Size: bn.size()}
+	)
+
+	b.levels[level] = nil
+
+	for _, x := range bn.Items {
+		objs = append(objs, x.Hash)
+	}
+
+	if it.Hash, err = addBlob(b.pack, encoder.Serialize(&bn), objs); err != nil {
+		return
+	}
+
+	return b.add(level+1, it)
+}
+
+// root node of the tree, or blank hash if empty
+func (b *blobBuilder) root() (hash cipher.SHA256, err error) {
+
+	for level := 0; level < len(b.levels); level++ {
+
+		var items = b.levels[level]
+
+		if level > 0 && level == len(b.levels)-1 && len(items) == 1 {
+			return items[0].Hash, nil // the root
+		}
+
+		if len(items) == 0 {
+			continue
+		}
+
+		if err = b.flush(level); err != nil {
+			return
+		}
+
+	}
+
+	return // blank
+}
+
+// leafs of Blob tree (chunks) in order
+func blobLeafs(pack Pack, hash cipher.SHA256) (leafs []blobItem, err error) {
+
+	if hash == (cipher.SHA256{}) {
+		return
+	}
+
+	var bn *blobNode
+	if bn, err = getBlobNode(pack, hash); err != nil {
+		return
+	}
+
+	return appendBlobLeafs(pack, bn, nil)
+}
+
+func appendBlobLeafs(
+	pack Pack, //          : pack to get
+	bn *blobNode, //       : the node
+	leafs []blobItem, //   : leafs to append to
+) (
+	_ []blobItem, //       : leafs
+	err error, //          : an error
+) {
+
+	if bn.Depth == 0 {
+		return append(leafs, bn.Items...), nil
+	}
+
+	for _, it := range bn.Items {
+
+		var cn *blobNode
+		if cn, err = getBlobNode(pack, it.Hash); err != nil {
+			return
+		}
+
+		if cn.Depth != bn.Depth-1 || cn.size() != it.Size {
+			return nil, ErrInvalidBlob
+		}
+
+		if leafs, err = appendBlobLeafs(pack, cn, leafs); err != nil {
+			return
+		}
+
+	}
+
+	return leafs, nil
+}
@@ -0,0 +1,576 @@
+package registry
+
+import (
+	"io"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A BlobReader reads data of a Blob. The
+// BlobReader implements io.Reader, io.Seeker
+// and io.ReaderAt interfaces. The BlobReader
+// loads nodes of the Blob lazily. The BlobReader
+// is not thread safe
+type BlobReader struct {
+	pack Pack
+	root cipher.SHA256
+	size uint64
+	pos  uint64
+
+	path []blobPathNode // last loaded nodes from the root
+
+	chunk []byte // last loaded chunk
+	off   uint64 // offset of the chunk
+}
+
+// a loaded node
+type blobPathNode struct {
+	hash cipher.SHA256
+	node *blobNode
+}
+
+// Reader returns BlobReader of the Blob. The
+// BlobReader reads the Blob as it was when the
+// Reader has been called
+func (b *Blob) Reader(pack Pack) (br *BlobReader, err error) {
+
+	br = &BlobReader{pack: pack, root: b.Hash}
+
+	if b.IsBlank() == true {
+		return
+	}
+
+	var bn *blobNode
+	if bn, err = getBlobNode(pack, b.Hash); err != nil {
+		return nil, err
+	}
+
+	br.path = []blobPathNode{{b.Hash, bn}}
+	br.size = bn.size()
+	return
+}
+
+// Size of the Blob data
+func (b *BlobReader) Size() int64 {
+	return int64(b.size)
+}
+
+// node returns node of given depth of the path
+func (b *BlobReader) node(
+	i int, //              : index in the path
+	hash cipher.SHA256, // : hash of the node
+) (
+	bn *blobNode, //       : the node
+	err error, //          : an error
+) {
+
+	if i < len(b.path) && b.path[i].hash == hash {
+		return b.path[i].node, nil
+	}
+
+	b.path = b.path[:i]
+
+	if bn, err = getBlobNode(b.pack, hash); err != nil {
+		return
+	}
+
+	b.path = append(b.path, blobPathNode{hash, bn})
+	return
+}
+
+// load chunk that contains given offset
+func (b *BlobReader) load(off uint64) (err error) {
+
+	if b.chunk != nil && off >= b.off && off < b.off+uint64(len(b.chunk)) {
+		return // already loaded
+	}
+
+	var (
+		hash  = b.root
+		start uint64 // offset of current node
+		bn    *blobNode
+		it    blobItem
+	)
+
+	for i := 0; ; i++ {
+
+		var depth = -1
+		if bn != nil {
+			depth = int(bn.Depth) - 1
+		}
+
+		if bn, err = b.node(i, hash); err != nil {
+			return
+		}
+
+		if depth >= 0 && int(bn.Depth) != depth {
+			return ErrInvalidBlob
+		}
+
+		var found bool
+
+		for _, it = range bn.Items {
+			if off < start+it.Size {
+				found = true
+				break
+			}
+			start += it.Size
+		}
+
+		if found == false {
+			return ErrInvalidBlob
+		}
+
+		if bn.Depth == 0 {
+			break
+		}
+
+		hash = it.Hash
+
+	}
+
+	var chunk []byte
+	if chunk, err = getBlobChunk(b.pack, it); err != nil {
+		return
+	}
+
+	b.chunk, b.off = chunk, start
+	return
+}
+
+// ReadAt implements io.ReaderAt interface
+func (b *BlobReader) ReadAt(p []byte, off int64) (n int, err error) {
+
+	if off < 0 {
+		return 0, ErrInvalidOffset
+	}
+
+	for n < len(p) {
+
+		var pos = uint64(off) + uint64(n)
+
+		if pos >= b.size {
+			return n, io.EOF
+		}
+
+		if err = b.load(pos); err != nil {
+			return
+		}
+
+		n += copy(p[n:], b.chunk[pos-b.off:])
+
+	}
+
+	return
+}
+
+// Read implements io.Reader interface
+func (b *BlobReader) Read(p []byte) (n int, err error) {
+
+	if len(p) == 0 {
+		return
+	}
+
+	if b.pos >= b.size {
+		return 0, io.EOF
+	}
+
+	if err = b.load(b.pos); err != nil {
+		return
+	}
+
+	n = copy(p, b.chunk[b.pos-b.off:])
+	b.pos += uint64(n)
+	return
+}
+
+// Seek implements io.Seeker interface
+func (b *BlobReader) Seek(offset int64, whence int) (pos int64, err error) {
+
+	if pos, err = seekPosition(int64(b.pos), int64(b.size), offset,
+		whence); err != nil {
+
+		return
+	}
+
+	b.pos = uint64(pos)
+	return
+}
+
+func seekPosition(
+	pos int64, //    : current position
+	size int64, //   : size of data
+	offset int64, // : offset
+	whence int, //   : whence
+) (
+	np int64, //     : new position
+	err error, //    : an error
+) {
+
+	switch whence {
+	case io.SeekStart:
+		np = offset
+	case io.SeekCurrent:
+		np = pos + offset
+	case io.SeekEnd:
+		np = size + offset
+	default:
+		return 0, ErrInvalidWhence
+	}
+
+	if np < 0 {
+		return 0, ErrInvalidOffset
+	}
+
+	return
+}
+
+// A BlobWriter changes data of a Blob. The
+// BlobWriter implements io.Writer and io.Seeker
+// interfaces. It's possible to write beyond end
+// of the data, the gap is filled with zeroes. The
+// BlobWriter saves new chunks in the Pack during
+// writing and updates the Blob on Close. The Blob
+// must not be changed until the Close. The
+// BlobWriter is not thread safe
+//
+// Writes in order (from the beginning to the end)
+// are streaming. A write before previous one
+// rechunks data from the previous write to the
+// first unchanged chunk. Thus, random writes can
+// be expensive
+type BlobWriter struct {
+	b    *Blob
+	pack Pack
+
+	leafs []blobItem // chunks
+	offs  []uint64   // offsets of the chunks
+	fsize uint64     // size of the leafs data
+
+	size uint64 // size of the data, including unsaved writes
+	pos  uint64 // position
+
+	pass *blobPass // rechunking
+
+	cached int    // index of the cached chunk
+	chunk  []byte // the cached chunk
+
+	closed bool
+}
+
+// a rechunking pass of BlobWriter
+type blobPass struct {
+	leafs []blobItem  // new leafs
+	ch    blobChunker // chunker
+	fed   uint64      // bytes fed
+}
+
+// Writer returns BlobWriter of the Blob. The
+// BlobWriter points to the beginning of data of
+// the Blob. Call Close to update the Blob
+func (b *Blob) Writer(pack Pack) (bw *BlobWriter, err error) {
+
+	bw = &BlobWriter{b: b, pack: pack}
+
+	if bw.leafs, err = blobLeafs(pack, b.Hash); err != nil {
+		return nil, err
+	}
+
+	bw.offsets()
+	bw.size = bw.fsize
+	return
+}
+
+// offsets of leafs
+func (b *BlobWriter) offsets() {
+
+	b.offs, b.fsize = make([]uint64, 0, len(b.leafs)), 0
+
+	for _, it := range b.leafs {
+		b.offs = append(b.offs, b.fsize)
+		b.fsize += it.Size
+	}
+
+	b.chunk = nil
+}
+
+// index of leaf that contains given offset,
+// or len(leafs) if the offset is out of range
+func (b *BlobWriter) leaf(off uint64) int {
+	return sort.Search(len(b.offs), func(i int) bool {
+		return b.offs[i]+b.leafs[i].Size > off
+	})
+}
+
+// Size of the data
+func (b *BlobWriter) Size() int64 {
+	return int64(b.size)
+}
+
+// Seek implements io.Seeker interface
+func (b *BlobWriter) Seek(offset int64, whence int) (pos int64, err error) {
+
+	if b.closed == true {
+		return 0, ErrBlobWriterClosed
+	}
+
+	if pos, err = seekPosition(int64(b.pos), int64(b.size), offset,
+		whence); err != nil {
+
+		return
+	}
+
+	b.pos = uint64(pos)
+	return
+}
+
+// Write implements io.Writer interface
+func (b *BlobWriter) Write(p []byte) (n int, err error) {
+
+	if b.closed == true {
+		return 0, ErrBlobWriterClosed
+	}
+
+	if len(p) == 0 {
+		return
+	}
+
+	if b.pass != nil && b.pos < b.pass.fed {
+		if err = b.finish(); err != nil {
+			return
+		}
+	}
+
+	if b.pass == nil {
+		b.start(b.pos)
+	}
+
+	if err = b.feedOld(b.pos); err != nil {
+		return
+	}
+
+	if err = b.feed(p); err != nil {
+		return
+	}
+
+	n = len(p)
+
+	if b.pos += uint64(n); b.pos > b.size {
+		b.size = b.pos
+	}
+
+	return
+}
+
+// Truncate changes size of the data. If the size
+// is greater than current, then data is extended
+// with zeroes. The Truncate doesn't change position
+func (b *BlobWriter) Truncate(size int64) (err error) {
+
+	if b.closed == true {
+		return ErrBlobWriterClosed
+	}
+
+	if size < 0 {
+		return ErrInvalidOffset
+	}
+
+	if err = b.finish(); err != nil {
+		return
+	}
+
+	var ns = uint64(size)
+
+	if ns == b.size {
+		return
+	}
+
+	if ns > b.size {
+		b.start(b.size)
+	} else {
+		b.start(ns)
+	}
+
+	if err = b.feedOld(ns); err != nil {
+		return
+	}
+
+	b.size = ns
+	return b.finish()
+}
+
+// Close saves changes and updates the Blob
+func (b *BlobWriter) Close() (err error) {
+
+	if b.closed == true {
+		return ErrBlobWriterClosed
+	}
+
+	if err = b.finish(); err != nil {
+		return
+	}
+
+	var bb = blobBuilder{pack: b.pack}
+
+	for _, it := range b.leafs {
+		if err = bb.add(0, it); err != nil {
+			return
+		}
+	}
+
+	var hash cipher.SHA256
+	if hash, err = bb.root(); err != nil {
+		return
+	}
+
+	b.b.Hash, b.closed = hash, true
+	return
+}
+
+// start rechunking from chunk that contains given offset
+func (b *BlobWriter) start(off uint64) {
+
+	var i = b.leaf(off)
+
+	if i == len(b.leafs) && i > 0 {
+		i-- // the last chunk is not a full chunk
+	}
+
+	b.pass = &blobPass{
+		leafs: append([]blobItem{}, b.leafs[:i]...),
+		fed:   b.fsize,
+	}
+
+	if i < len(b.leafs) {
+		b.pass.fed = b.offs[i]
+	}
+
+}
+
+// emit a chunk
+func (b *BlobWriter) emit(chunk []byte) (err error) {
+
+	var hash cipher.SHA256
+	if hash, err = addBlob(b.pack, chunk, nil); err != nil {
+		return
+	}
+
+	b.pass.leafs = append(b.pass.leafs, blobItem{
+		Hash: hash,
+		Size: uint64(len(chunk)),
+	})
+
+	return
+}
+
+// feed data to the chunker
+func (b *BlobWriter) feed(p []byte) (err error) {
+
+	if err = b.pass.ch.write(p, b.emit); err != nil {
+		return
+	}
+
+	b.pass.fed += uint64(len(p))
+	return
+}
+
+// feed old data (or zeroes) up to given offset
+func (b *BlobWriter) feedOld(to uint64) (err error) {
+
+	for b.pass.fed < to {
+
+		var fed = b.pass.fed
+
+		if fed >= b.fsize {
+			var zeroes = make([]byte, minUint64(to-fed, uint64(blobMaxChunk)))
+			if err = b.feed(zeroes); err != nil {
+				return
+			}
+			continue
+		}
+
+		var (
+			i     = b.leaf(fed)
+			chunk []byte
+		)
+
+		if chunk, err = b.old(i); err != nil {
+			return
+		}
+
+		var end = minUint64(uint64(len(chunk)), to-b.offs[i])
+
+		if err = b.feed(chunk[fed-b.offs[i] : end]); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+// old chunk by index
+func (b *BlobWriter) old(i int) (chunk []byte, err error) {
+
+	if b.chunk != nil && b.cached == i {
+		return b.chunk, nil
+	}
+
+	if chunk, err = getBlobChunk(b.pack, b.leafs[i]); err != nil {
+		return
+	}
+
+	b.cached, b.chunk = i, chunk
+	return
+}
+
+// finish current rechunking pass reusing old chunks
+// from first common boundary
+func (b *BlobWriter) finish() (err error) {
+
+	if b.pass == nil {
+		return
+	}
+
+	for b.pass.fed < b.size {
+
+		var fed = b.pass.fed
+
+		if fed < b.fsize && b.size == b.fsize && b.pass.ch.empty() == true {
+			if i := b.leaf(fed); b.offs[i] == fed {
+				// the same boundary, the rest is the same
+				b.pass.leafs = append(b.pass.leafs, b.leafs[i:]...)
+				b.pass.fed = b.size
+				break
+			}
+		}
+
+		var to = b.size
+
+		if fed < b.fsize {
+			var i = b.leaf(fed)
+			to = minUint64(to, b.offs[i]+b.leafs[i].Size)
+		}
+
+		if err = b.feedOld(to); err != nil {
+			return
+		}
+
+	}
+
+	if err = b.pass.ch.flush(b.emit); err != nil {
+		return
+	}
+
+	b.leafs, b.pass = b.pass.leafs, nil
+	b.offsets()
+	return
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
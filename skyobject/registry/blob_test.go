@@ -0,0 +1,700 @@
+package registry
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+type testFile struct {
+	Name    string
+	Content Blob
+	Parts   []Blob
+}
+
+func testBlobData(seed int64, size int) (p []byte) {
+	p = make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(p)
+	return
+}
+
+// chunks of the Blob
+func testBlobChunks(t *testing.T, pack Pack, b *Blob) (cs []cipher.SHA256) {
+	t.Helper()
+
+	var err = b.Walk(pack, func(hash cipher.SHA256, depth int) (bool, error) {
+		if depth == 0 {
+			cs = append(cs, hash)
+		}
+		return true, nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func testBlobEqual(t *testing.T, pack Pack, b *Blob, want []byte) {
+	t.Helper()
+
+	if got, err := b.Bytes(pack); err != nil {
+		t.Fatal(err)
+	} else if bytes.Equal(got, want) == false {
+		t.Fatal("wrong data")
+	}
+
+	if size, err := b.Size(pack); err != nil {
+		t.Fatal(err)
+	} else if size != int64(len(want)) {
+		t.Fatal("wrong size", size, len(want))
+	}
+}
+
+func TestBlob_SetBytes(t *testing.T) {
+	// SetBytes(pack Pack, p []byte) (err error)
+
+	var pack = getTestPack()
+
+	for _, size := range []int{
+		0,
+		1,
+		blobMinChunk,
+		blobMaxChunk + 1,
+		10 << 20,
+	} {
+
+		var (
+			b    Blob
+			data = testBlobData(int64(size), size)
+		)
+
+		if err := b.SetBytes(pack, data); err != nil {
+			t.Fatal(err)
+		}
+
+		if size == 0 && b.IsBlank() == false {
+			t.Error("empty Blob is not blank")
+		}
+
+		testBlobEqual(t, pack, &b, data)
+
+		// the same data, the same tree
+
+		var (
+			c   Blob
+			bw  *BlobWriter
+			err error
+		)
+
+		if bw, err = c.Writer(pack); err != nil {
+			t.Fatal(err)
+		}
+
+		for p := data; len(p) > 0; {
+			var n = 1000
+			if n > len(p) {
+				n = len(p)
+			}
+			if _, err = bw.Write(p[:n]); err != nil {
+				t.Fatal(err)
+			}
+			p = p[n:]
+		}
+
+		if err = bw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if c.Hash != b.Hash {
+			t.Error("different trees of the same data")
+		}
+
+	}
+
+}
+
+func TestBlob_Walk(t *testing.T) {
+	// Walk(pack Pack, walkFunc WalkFunc) (err error)
+
+	var (
+		pack = getTestPack()
+		data = testBlobData(1, 40<<20)
+		b    Blob
+	)
+
+	if err := b.SetBytes(pack, data); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		hs    = make(map[cipher.SHA256]struct{})
+		nodes int
+	)
+
+	var err = b.Walk(pack, func(hash cipher.SHA256, depth int) (bool, error) {
+		hs[hash] = struct{}{}
+		if depth > 0 {
+			nodes++
+		}
+		return true, nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nodes < 2 {
+		t.Error("single node tree of large Blob")
+	}
+
+	if len(hs) != len(pack.vals) {
+		t.Error("wrong number of hashes", len(hs), len(pack.vals))
+	}
+
+	for hash := range pack.vals {
+		if _, ok := hs[hash]; ok == false {
+			t.Error("missing object", hash.Hex()[:7])
+		}
+	}
+
+	// stop
+
+	var n int
+	err = b.Walk(pack, func(cipher.SHA256, int) (bool, error) {
+		n++
+		return true, ErrStopIteration
+	})
+
+	if err != nil {
+		t.Error(err)
+	} else if n != 1 {
+		t.Error("not stopped")
+	}
+
+}
+
+func TestBlobWriter_Write(t *testing.T) {
+	// Write(p []byte) (n int, err error)
+
+	var (
+		pack = getTestPack()
+		data = testBlobData(2, 4<<20)
+		b    Blob
+	)
+
+	if err := b.SetBytes(pack, data); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		old = make(map[cipher.SHA256]struct{})
+		bw  *BlobWriter
+		err error
+	)
+
+	for _, hash := range testBlobChunks(t, pack, &b) {
+		old[hash] = struct{}{}
+	}
+
+	t.Run("middle", func(t *testing.T) {
+
+		if bw, err = b.Writer(pack); err != nil {
+			t.Fatal(err)
+		}
+
+		var patch = []byte("patch in the middle")
+
+		if _, err = bw.Seek(2<<20, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = bw.Write(patch); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = bw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		copy(data[2<<20:], patch)
+		testBlobEqual(t, pack, &b, data)
+
+		var created int
+		for _, hash := range testBlobChunks(t, pack, &b) {
+			if _, ok := old[hash]; ok == false {
+				created++
+			}
+		}
+
+		if created == 0 || created > 2 {
+			t.Error("wrong number of new chunks:", created)
+		}
+
+		if _, err = bw.Write(patch); err != ErrBlobWriterClosed {
+			t.Error("wrong error:", err)
+		}
+
+	})
+
+	t.Run("random", func(t *testing.T) {
+
+		if bw, err = b.Writer(pack); err != nil {
+			t.Fatal(err)
+		}
+
+		var rnd = rand.New(rand.NewSource(3))
+
+		for i := 0; i < 20; i++ {
+
+			var (
+				off   = rnd.Intn(len(data) + 100<<10)
+				patch = testBlobData(int64(i), rnd.Intn(200<<10))
+			)
+
+			if _, err = bw.Seek(int64(off), io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = bw.Write(patch); err != nil {
+				t.Fatal(err)
+			}
+
+			if end := off + len(patch); end > len(data) {
+				data = append(data, make([]byte, end-len(data))...)
+			}
+
+			copy(data[off:], patch)
+
+			if bw.Size() != int64(len(data)) {
+				t.Fatal("wrong size")
+			}
+
+		}
+
+		if err = bw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		testBlobEqual(t, pack, &b, data)
+
+		// the same as written at once
+
+		var c Blob
+		if err = c.SetBytes(pack, data); err != nil {
+			t.Fatal(err)
+		}
+
+		if c.Hash != b.Hash {
+			t.Error("different trees of the same data")
+		}
+
+	})
+
+}
+
+func TestBlobWriter_Truncate(t *testing.T) {
+	// Truncate(size int64) (err error)
+
+	var (
+		pack = getTestPack()
+		data = testBlobData(4, 1<<20)
+		b    Blob
+		bw   *BlobWriter
+		err  error
+	)
+
+	if err = b.SetBytes(pack, data); err != nil {
+		t.Fatal(err)
+	}
+
+	if bw, err = b.Writer(pack); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = bw.Truncate(-1); err != ErrInvalidOffset {
+		t.Error("wrong error:", err)
+	}
+
+	if err = bw.Truncate(100 << 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = bw.Truncate(200 << 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = bw.Seek(-10, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = bw.Write([]byte("end")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var want = append(data[:100<<10:100<<10], make([]byte, 100<<10)...)
+	copy(want[len(want)-10:], "end")
+
+	testBlobEqual(t, pack, &b, want)
+
+	if bw, err = b.Writer(pack); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = bw.Truncate(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.IsBlank() == false {
+		t.Error("empty Blob is not blank")
+	}
+
+}
+
+func TestBlobReader_Seek(t *testing.T) {
+	// Seek(offset int64, whence int) (pos int64, err error)
+
+	var (
+		pack = getTestPack()
+		data = testBlobData(5, 3<<20)
+		b    Blob
+		br   *BlobReader
+		err  error
+	)
+
+	if err = b.SetBytes(pack, data); err != nil {
+		t.Fatal(err)
+	}
+
+	if br, err = b.Reader(pack); err != nil {
+		t.Fatal(err)
+	}
+
+	if br.Size() != int64(len(data)) {
+		t.Error("wrong size")
+	}
+
+	for _, tt := range []struct {
+		offset int64
+		whence int
+		pos    int64
+	}{
+		{100, io.SeekStart, 100},
+		{1 << 20, io.SeekCurrent, 1<<20 + 300<<10 + 100},
+		{-100, io.SeekEnd, 3<<20 - 100},
+		{0, io.SeekStart, 0},
+	} {
+
+		var pos int64
+		if pos, err = br.Seek(tt.offset, tt.whence); err != nil {
+			t.Fatal(err)
+		} else if pos != tt.pos {
+			t.Error("wrong position", pos, tt.pos)
+		}
+
+		var got []byte
+		if got, err = ioutil.ReadAll(io.LimitReader(br, 300<<10)); err != nil {
+			t.Fatal(err)
+		}
+
+		var end = tt.pos + 300<<10
+		if end > int64(len(data)) {
+			end = int64(len(data))
+		}
+
+		if bytes.Equal(got, data[tt.pos:end]) == false {
+			t.Error("wrong data")
+		}
+
+	}
+
+	if _, err = br.Seek(-1, io.SeekStart); err != ErrInvalidOffset {
+		t.Error("wrong error:", err)
+	}
+
+	if _, err = br.Seek(0, 10); err != ErrInvalidWhence {
+		t.Error("wrong error:", err)
+	}
+
+	// ReadAt
+
+	var p = make([]byte, 100)
+
+	if n, err := br.ReadAt(p, 2<<20); err != nil {
+		t.Error(err)
+	} else if n != 100 || bytes.Equal(p, data[2<<20:2<<20+100]) == false {
+		t.Error("wrong data")
+	}
+
+	if n, err := br.ReadAt(p, int64(len(data))-10); err != io.EOF {
+		t.Error("wrong error:", err)
+	} else if n != 10 {
+		t.Error("wrong n:", n)
+	}
+
+}
+
+func TestBlob_schema(t *testing.T) {
+
+	var reg = NewRegistry(func(r *Reg) {
+		r.Register("test.File", testFile{})
+	})
+
+	var sch, err = reg.SchemaByName("test.File")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fs := sch.Fields(); len(fs) != 3 {
+		t.Fatal("wrong number of fields")
+	} else if fs[1].Schema().ReferenceType() != ReferenceTypeBlob {
+		t.Error("wrong ReferenceType")
+	} else if fs[2].Schema().Elem().ReferenceType() != ReferenceTypeBlob {
+		t.Error("wrong ReferenceType of element")
+	}
+
+	// encode-decode
+
+	var dr *Registry
+	if dr, err = DecodeRegistry(reg.Encode()); err != nil {
+		t.Fatal(err)
+	}
+
+	if dr.Reference() != reg.Reference() {
+		t.Error("wrong decoded Registry")
+	}
+
+	// walk through
+
+	var (
+		pack = testPackReg(reg)
+		file = testFile{Name: "file.txt"}
+	)
+
+	if err = file.Content.SetBytes(pack, testBlobData(6, 1<<20)); err != nil {
+		t.Fatal(err)
+	}
+
+	file.Parts = make([]Blob, 2)
+
+	if err = file.Parts[1].SetBytes(pack, []byte("part")); err != nil {
+		t.Fatal(err)
+	}
+
+	var want = len(pack.vals)
+
+	var ref Ref
+	if err = ref.SetValue(pack, &file); err != nil {
+		t.Fatal(err)
+	}
+
+	var hs = make(map[cipher.SHA256]struct{})
+
+	err = ref.Walk(pack, sch, func(hash cipher.SHA256, _ int) (bool, error) {
+		if hash != (cipher.SHA256{}) {
+			hs[hash] = struct{}{}
+		}
+		return true, nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hs) != want+1 {
+		t.Error("wrong number of hashes", len(hs), want+1)
+	}
+
+	// tree
+
+	var r = Root{Refs: []Dynamic{{Hash: ref.Hash, Schema: sch.Reference()}}}
+
+	var tree string
+	if tree, err = r.Tree(pack); err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains([]byte(tree), []byte("*(blob) "+file.Content.Short())) ==
+		false {
+
+		t.Error("missing Blob in the tree:\n", tree)
+	}
+
+	// sure
+
+	var dec testFile
+	if _, err = encoder.DeserializeRaw(pack.vals[ref.Hash], &dec); err != nil {
+		t.Fatal(err)
+	}
+
+	if dec.Content != file.Content {
+		t.Error("wrong decoded Blob")
+	}
+
+}
+
+// pack with content key
+type privatePack struct {
+	*dummyPack
+	key ContentKey
+}
+
+func (p *privatePack) ContentKey() (ContentKey, bool) {
+	return p.key, true
+}
+
+func (p *privatePack) Get(key cipher.SHA256) (val []byte, err error) {
+	if val, err = p.dummyPack.Get(key); err != nil {
+		return
+	}
+	if IsSealed(val) == true {
+		return p.key.Open(val)
+	}
+	return
+}
+
+func TestBlob_private(t *testing.T) {
+
+	var (
+		dp   = getTestPack()
+		pp   = &privatePack{dp, NewContentKey()}
+		data = testBlobData(7, 2<<20)
+		b    Blob
+	)
+
+	if err := b.SetBytes(pp, data); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, val := range dp.vals {
+		if IsSealed(val) == false {
+			t.Fatal("not sealed")
+		}
+	}
+
+	testBlobEqual(t, pp, &b, data)
+
+	// walk without the key
+
+	var walk = func(pack Pack) (hs []cipher.SHA256) {
+		var err = b.Walk(pack, func(hash cipher.SHA256, _ int) (bool, error) {
+			hs = append(hs, hash)
+			return true, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	var with, without = walk(pp), walk(dp)
+
+	if len(with) != len(without) || len(with) != len(dp.vals) {
+		t.Fatal("wrong number of hashes", len(with), len(without), len(dp.vals))
+	}
+
+	for i, hash := range with {
+		if without[i] != hash {
+			t.Error("different walking")
+		}
+	}
+
+}
+
+func TestBlob_privateField(t *testing.T) {
+
+	var reg = NewRegistry(func(r *Reg) {
+		r.Register("test.File", testFile{})
+	})
+
+	var (
+		dp   = testPackReg(reg)
+		pp   = &privatePack{dp, NewContentKey()}
+		file = testFile{Name: "file.txt"}
+		err  error
+	)
+
+	if err = file.Content.SetBytes(pp, testBlobData(8, 1<<20)); err != nil {
+		t.Fatal(err)
+	}
+
+	file.Parts = make([]Blob, 1)
+
+	if err = file.Parts[0].SetBytes(pp, []byte("part")); err != nil {
+		t.Fatal(err)
+	}
+
+	var ref Ref
+	if err = ref.SetValue(pp, &file); err != nil {
+		t.Fatal(err)
+	}
+
+	var s *sealed
+	if s, err = decodeSealed(dp.vals[ref.Hash]); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Objects) != 0 || len(s.Refs) != 0 {
+		t.Error("Blobs in Objects or Refs", len(s.Objects), len(s.Refs))
+	}
+
+	if len(s.Blobs) != 2 ||
+		s.Blobs[0] != file.Content.Hash ||
+		s.Blobs[1] != file.Parts[0].Hash {
+		t.Error("wrong Blobs of sealed object")
+	}
+
+	var sch Schema
+	if sch, err = reg.SchemaByName("test.File"); err != nil {
+		t.Fatal(err)
+	}
+
+	// walk without the key
+
+	var hs = map[cipher.SHA256]struct{}{ref.Hash: {}}
+
+	err = ref.Walk(dp, sch, func(hash cipher.SHA256, _ int) (bool, error) {
+		hs[hash] = struct{}{}
+		return true, nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hs) != len(dp.vals) {
+		t.Error("wrong number of hashes", len(hs), len(dp.vals))
+	}
+
+	// split without the key
+
+	var sp = &testSplitter{
+		pack: dp,
+		keys: make(map[cipher.SHA256]struct{}),
+	}
+
+	ref.Split(sp, sch)
+
+	if sp.err != nil {
+		t.Fatal(sp.err)
+	}
+
+	if len(sp.keys) != len(dp.vals) {
+		t.Error("wrong number of keys", len(sp.keys), len(dp.vals))
+	}
+
+}
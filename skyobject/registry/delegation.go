@@ -0,0 +1,333 @@
+package registry
+
+import (
+	"errors"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A Certificate authorizes a key to sign Root objects
+// of a feed instead of the feed (the master key). The
+// Certificate signed by the feed. It's possible to
+// restrict the Certificate by a head and by time.
+// A Root signed by an authorized key carries the
+// Certificate (see Cert field of the Root). Thus
+// the master key can be kept offline, and a leaked
+// authorized key can be revoked (see Revocation)
+//
+//     // master
+//
+//     var cert, err = registry.NewCertificate(feedSK, hotPK, 0,
+//         time.Now().Add(30*24*time.Hour))
+//
+//     // send the cert to hot server, that uses it
+//
+//     var up, err = c.Unpack(hotSK, reg)
+//
+//     // [...]
+//
+//     r.Cert = cert
+//     err = c.Save(up, r)
+//
+type Certificate struct {
+	Feed cipher.PubKey // feed (the master key)
+	Key  cipher.PubKey // authorized key
+
+	Nonce  uint64 // allowed head, zero for any head
+	Expire int64  // expiration time (unix nano), zero for never
+
+	Sig cipher.Sig `enc:"-"` // signature of the feed
+}
+
+// NewCertificate creates Certificate signed by
+// given secret key of a feed. Use zero nonce to
+// allow any head and zero time for a Certificate
+// that never expires
+func NewCertificate(
+	sk cipher.SecKey,
+	key cipher.PubKey,
+	nonce uint64,
+	expire time.Time,
+) (
+	c *Certificate,
+	err error,
+) {
+
+	if err = key.Verify(); err != nil {
+		return
+	}
+
+	c = new(Certificate)
+	c.Feed = cipher.PubKeyFromSecKey(sk)
+	c.Key = key
+	c.Nonce = nonce
+
+	if expire.IsZero() == false {
+		c.Expire = expire.UnixNano()
+	}
+
+	if c.Key == c.Feed {
+		return nil, errors.New("the key is the feed")
+	}
+
+	if c.Sig, err = cipher.SignHash(c.Hash(), sk); err != nil {
+		c = nil
+	}
+
+	return
+}
+
+// Hash of the Certificate, the hash is signed
+func (c *Certificate) Hash() cipher.SHA256 {
+	return cipher.SumSHA256(encoder.Serialize(c))
+}
+
+// Verify signature of the Certificate
+func (c *Certificate) Verify() (err error) {
+	return cipher.VerifyPubKeySignedHash(c.Feed, c.Sig, c.Hash())
+}
+
+// Allows returns error if the Certificate doesn't
+// allow its Key to sign given Root at given time.
+// The Time of the Root is chosen by the signer, thus
+// the expiration is checked against the given time
+// too. Use time the Root received (local clock).
+// The Allows verifies signature of the Certificate,
+// but not signature of the Root
+func (c *Certificate) Allows(r *Root, now time.Time) (err error) {
+
+	if c.Feed != r.Pub {
+		return ErrCertificateFeed
+	}
+
+	if c.Nonce != 0 && c.Nonce != r.Nonce {
+		return ErrCertificateHead
+	}
+
+	if c.Expire != 0 &&
+		(r.Time >= c.Expire || now.UnixNano() >= c.Expire) {
+
+		return ErrCertificateExpired
+	}
+
+	return c.Verify()
+}
+
+// Encode the Certificate with its signature
+func (c *Certificate) Encode() []byte {
+	return encoder.Serialize(signedCertificate{*c, c.Sig})
+}
+
+// DecodeCertificate decodes encoded Certificate.
+// It doesn't verify the Certificate
+func DecodeCertificate(p []byte) (c *Certificate, err error) {
+
+	var sc signedCertificate
+
+	if _, err = encoder.DeserializeRaw(p, &sc); err != nil {
+		return
+	}
+
+	c = &sc.Cert
+	c.Sig = sc.Sig
+	return
+}
+
+// A Revocation revokes a key authorized by a Certificate.
+// The Revocation signed by the feed. Root objects signed
+// by revoked key are not accepted after the Revocation
+// regardless of their Time, because the Time of a Root
+// is chosen by its signer and a leaked key can backdate
+// it (see Revokes method). The Time of the Revocation
+// is informational.
+// A Revocation can be carried by any Root of the feed
+// (see Revs field of the Root) or can be added to a
+// Container directly (see Revoke method of the
+// skyobject.Container)
+type Revocation struct {
+	Feed cipher.PubKey // feed (the master key)
+	Key  cipher.PubKey // revoked key
+	Time int64         // time of the revocation (unix nano)
+
+	Sig cipher.Sig `enc:"-"` // signature of the feed
+}
+
+// NewRevocation creates Revocation of given key
+// signed by given secret key of a feed
+func NewRevocation(
+	sk cipher.SecKey,
+	key cipher.PubKey,
+) (
+	r *Revocation,
+	err error,
+) {
+
+	r = new(Revocation)
+	r.Feed = cipher.PubKeyFromSecKey(sk)
+	r.Key = key
+	r.Time = time.Now().UnixNano()
+
+	if r.Sig, err = cipher.SignHash(r.Hash(), sk); err != nil {
+		r = nil
+	}
+
+	return
+}
+
+// Hash of the Revocation, the hash is signed
+func (r *Revocation) Hash() cipher.SHA256 {
+	return cipher.SumSHA256(encoder.Serialize(r))
+}
+
+// Verify signature of the Revocation
+func (r *Revocation) Verify() (err error) {
+	return cipher.VerifyPubKeySignedHash(r.Feed, r.Sig, r.Hash())
+}
+
+// Revokes reports true if the Revocation revokes
+// given Root signed by an authorized key. Time of
+// the Root is not checked, since it's chosen by
+// the signer. Thus, every Root signed by revoked
+// key is revoked. Root objects received before the
+// Revocation are kept by a Container. The Revokes
+// doesn't verify the Revocation
+func (r *Revocation) Revokes(x *Root) bool {
+	return x.Cert != nil &&
+		x.Cert.Key == r.Key &&
+		x.Pub == r.Feed
+}
+
+// Encode the Revocation with its signature
+func (r *Revocation) Encode() []byte {
+	return encoder.Serialize(signedRevocation{*r, r.Sig})
+}
+
+// DecodeRevocation decodes encoded Revocation.
+// It doesn't verify the Revocation
+func DecodeRevocation(p []byte) (r *Revocation, err error) {
+
+	var sr signedRevocation
+
+	if _, err = encoder.DeserializeRaw(p, &sr); err != nil {
+		return
+	}
+
+	r = &sr.Rev
+	r.Sig = sr.Sig
+	return
+}
+
+// Sig field of the Revocation is not encoded
+type signedRevocation struct {
+	Rev Revocation
+	Sig cipher.Sig
+}
+
+// Sig field of the Certificate is not encoded
+type signedCertificate struct {
+	Cert Certificate
+	Sig  cipher.Sig
+}
+
+// signed with hash of a Root
+type signedHash struct {
+	Hash cipher.SHA256
+	Cert []cipher.SHA256 // zero or one
+	Revs []cipher.SHA256
+}
+
+// SigHash returns hash that signed by signer of the Root.
+// The SigHash is the Hash of the Root, if the Root doesn't
+// carry a Certificate or Revocations. Otherwise, hashes of
+// the Certificate and the Revocations signed too. Thus,
+// they can't be stripped or replaced
+func (r *Root) SigHash() cipher.SHA256 {
+
+	if r.Cert == nil && len(r.Revs) == 0 {
+		return r.Hash
+	}
+
+	var sh = signedHash{Hash: r.Hash}
+
+	if r.Cert != nil {
+		sh.Cert = []cipher.SHA256{r.Cert.Hash()}
+	}
+
+	for i := range r.Revs {
+		sh.Revs = append(sh.Revs, r.Revs[i].Hash())
+	}
+
+	return cipher.SumSHA256(encoder.Serialize(&sh))
+}
+
+// encoded signature container of a Root
+type rootSigs struct {
+	Sigs Signatures
+	Cert []signedCertificate // zero or one
+	Revs []signedRevocation
+}
+
+// EncodeSigs encodes signature container of the
+// Root: the Sigs, the Cert and the Revs. It returns
+// nil if all of them are blank. The signature
+// container is not a part of the Root and not
+// encoded by the Encode method
+func (r *Root) EncodeSigs() []byte {
+
+	if len(r.Sigs) == 0 && r.Cert == nil && len(r.Revs) == 0 {
+		return nil
+	}
+
+	var rs rootSigs
+
+	rs.Sigs = r.Sigs
+
+	if r.Cert != nil {
+		rs.Cert = []signedCertificate{{*r.Cert, r.Cert.Sig}}
+	}
+
+	for _, rev := range r.Revs {
+		rs.Revs = append(rs.Revs, signedRevocation{rev, rev.Sig})
+	}
+
+	return encoder.Serialize(&rs)
+}
+
+// DecodeSigs decodes given signature container and
+// sets the Sigs, the Cert and the Revs of the Root.
+// The DecodeSigs resets them for empty input. The
+// DecodeSigs doesn't verify the signatures
+func (r *Root) DecodeSigs(p []byte) (err error) {
+
+	r.Sigs, r.Cert, r.Revs = nil, nil, nil
+
+	if len(p) == 0 {
+		return
+	}
+
+	var rs rootSigs
+
+	if _, err = encoder.DeserializeRaw(p, &rs); err != nil {
+		return
+	}
+
+	if len(rs.Cert) > 1 {
+		return errors.New("malformed signature container: many certificates")
+	}
+
+	r.Sigs = rs.Sigs
+
+	if len(rs.Cert) == 1 {
+		r.Cert = &rs.Cert[0].Cert
+		r.Cert.Sig = rs.Cert[0].Sig
+	}
+
+	for _, sr := range rs.Revs {
+		sr.Rev.Sig = sr.Sig
+		r.Revs = append(r.Revs, sr.Rev)
+	}
+
+	return
+}
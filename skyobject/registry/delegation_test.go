@@ -0,0 +1,284 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestCertificate_Allows(t *testing.T) {
+
+	var (
+		feed, fsk = cipher.GenerateKeyPair()
+		key, _    = cipher.GenerateKeyPair()
+		now       = time.Now()
+	)
+
+	var cert, err = NewCertificate(fsk, key, 1, now.Add(time.Hour))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r = &Root{Pub: feed, Nonce: 1, Time: now.UnixNano()}
+
+	if err = cert.Allows(r, now); err != nil {
+		t.Error(err)
+	}
+
+	r.Nonce = 2
+	if err = cert.Allows(r, now); err != ErrCertificateHead {
+		t.Error("wrong error:", err)
+	}
+
+	r.Nonce, r.Time = 1, now.Add(2*time.Hour).UnixNano()
+	if err = cert.Allows(r, now); err != ErrCertificateExpired {
+		t.Error("wrong error:", err)
+	}
+
+	// backdated Root received after expiration
+	r.Time = now.UnixNano()
+	if err = cert.Allows(r, now.Add(2*time.Hour)); err != ErrCertificateExpired {
+		t.Error("wrong error:", err)
+	}
+
+	r.Pub = key
+	if err = cert.Allows(r, now); err != ErrCertificateFeed {
+		t.Error("wrong error:", err)
+	}
+
+	r.Pub = feed
+	cert.Nonce = 0 // change
+	if err = cert.Allows(r, now); err == nil {
+		t.Error("missing error")
+	}
+
+	if _, err = NewCertificate(fsk, feed, 0, time.Time{}); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestDecodeCertificate(t *testing.T) {
+
+	var (
+		_, fsk = cipher.GenerateKeyPair()
+		key, _ = cipher.GenerateKeyPair()
+	)
+
+	var cert, err = NewCertificate(fsk, key, 0, time.Time{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dc *Certificate
+	if dc, err = DecodeCertificate(cert.Encode()); err != nil {
+		t.Fatal(err)
+	}
+
+	if *dc != *cert {
+		t.Error("wrong Certificate decoded")
+	}
+
+	if err = dc.Verify(); err != nil {
+		t.Error(err)
+	}
+
+}
+
+func TestDecodeRevocation(t *testing.T) {
+
+	var (
+		feed, fsk = cipher.GenerateKeyPair()
+		key, _    = cipher.GenerateKeyPair()
+	)
+
+	var rev, err = NewRevocation(fsk, key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rev.Feed != feed || rev.Key != key {
+		t.Error("wrong Revocation")
+	}
+
+	var dr *Revocation
+	if dr, err = DecodeRevocation(rev.Encode()); err != nil {
+		t.Fatal(err)
+	}
+
+	if *dr != *rev {
+		t.Error("wrong Revocation decoded")
+	}
+
+	if err = dr.Verify(); err != nil {
+		t.Error(err)
+	}
+
+}
+
+func TestRevocation_Revokes(t *testing.T) {
+
+	var (
+		feed, fsk = cipher.GenerateKeyPair()
+		key, _    = cipher.GenerateKeyPair()
+		other, _  = cipher.GenerateKeyPair()
+	)
+
+	var cert, err = NewCertificate(fsk, key, 0, time.Time{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rev *Revocation
+	if rev, err = NewRevocation(fsk, key); err != nil {
+		t.Fatal(err)
+	}
+
+	var r = &Root{Pub: feed, Cert: cert, Time: rev.Time}
+
+	if rev.Revokes(r) == false {
+		t.Error("Root signed at time of the Revocation is not revoked")
+	}
+
+	r.Time = rev.Time + 1
+	if rev.Revokes(r) == false {
+		t.Error("Root signed after the Revocation is not revoked")
+	}
+
+	r.Time = rev.Time - 1 // backdated
+	if rev.Revokes(r) == false {
+		t.Error("backdated Root is not revoked")
+	}
+
+	r.Time, r.Cert = rev.Time, nil
+	if rev.Revokes(r) == true {
+		t.Error("Root signed by the feed is revoked")
+	}
+
+	if cert, err = NewCertificate(fsk, other, 0, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	r.Cert = cert
+	if rev.Revokes(r) == true {
+		t.Error("Root signed by other key is revoked")
+	}
+
+}
+
+func TestRoot_SigHash(t *testing.T) {
+
+	var (
+		feed, fsk = cipher.GenerateKeyPair()
+		key, _    = cipher.GenerateKeyPair()
+		other, _  = cipher.GenerateKeyPair()
+	)
+
+	var r = &Root{Pub: feed, Time: 1}
+	r.Hash = cipher.SumSHA256(r.Encode())
+
+	if r.SigHash() != r.Hash {
+		t.Error("SigHash is not Hash of Root without Cert and Revs")
+	}
+
+	var cert, err = NewCertificate(fsk, key, 0, time.Time{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rev *Revocation
+	if rev, err = NewRevocation(fsk, other); err != nil {
+		t.Fatal(err)
+	}
+
+	r.Cert = cert
+	var withCert = r.SigHash()
+
+	if withCert == r.Hash {
+		t.Error("Certificate is not signed")
+	}
+
+	r.Revs = []Revocation{*rev}
+	var withRevs = r.SigHash()
+
+	if withRevs == withCert {
+		t.Error("Revocations are not signed")
+	}
+
+	// swap the Certificate
+	if r.Cert, err = NewCertificate(fsk, other, 0, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.SigHash() == withRevs {
+		t.Error("Certificate can be swapped")
+	}
+
+	// decoded
+	r.Cert = cert
+	var dr = &Root{Hash: r.Hash}
+	if err = dr.DecodeSigs(r.EncodeSigs()); err != nil {
+		t.Fatal(err)
+	}
+
+	if dr.SigHash() != withRevs {
+		t.Error("different SigHash of decoded Root")
+	}
+
+}
+
+func TestRoot_EncodeSigs(t *testing.T) {
+
+	var (
+		_, fsk = cipher.GenerateKeyPair()
+		key, _ = cipher.GenerateKeyPair()
+		r      Root
+	)
+
+	if r.EncodeSigs() != nil {
+		t.Error("not nil")
+	}
+
+	if err := r.DecodeSigs(nil); err != nil {
+		t.Error(err)
+	}
+
+	var cert, err = NewCertificate(fsk, key, 0, time.Time{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rev *Revocation
+	if rev, err = NewRevocation(fsk, key); err != nil {
+		t.Fatal(err)
+	}
+
+	r.Cert = cert
+	r.Revs = []Revocation{*rev}
+	r.Sigs = Signatures{{Key: 1, Sig: rev.Sig}}
+
+	var dr Root
+	if err = dr.DecodeSigs(r.EncodeSigs()); err != nil {
+		t.Fatal(err)
+	}
+
+	if dr.Cert == nil || *dr.Cert != *cert {
+		t.Error("wrong Cert")
+	}
+
+	if len(dr.Revs) != 1 || dr.Revs[0] != *rev {
+		t.Error("wrong Revs")
+	}
+
+	if len(dr.Sigs) != 1 || dr.Sigs[0] != r.Sigs[0] {
+		t.Error("wrong Sigs")
+	}
+
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/skycoin/skycoin/src/cipher"
-	"github.com/skycoin/skycoin/src/cipher/encoder"
 )
 
 // A Dynamic represents reference to object
@@ -79,7 +78,7 @@ func (d *Dynamic) SetValue(
 	}
 
 	var hash cipher.SHA256
-	if hash, err = pack.Add(encoder.Serialize(obj)); err != nil {
+	if hash, err = add(pack, obj); err != nil {
 		return
 	}
 
@@ -168,6 +167,10 @@ func (d *Dynamic) Split(s Splitter) {
 		return
 	}
 
+	if s = splitDynamic(s); s == nil {
+		return // too deep
+	}
+
 	splitSchemaHash(s, sch, d.Hash)
 
 }
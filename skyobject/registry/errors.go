@@ -32,4 +32,25 @@ var (
 	ErrNotFound        = errors.New("not found")
 	ErrStopIteration   = errors.New("stop iteration")
 	ErrMissingRegistry = errors.New("missing registry")
+
+	ErrNotMultisigFeed     = errors.New("not a multisig feed")
+	ErrNotEnoughSignatures = errors.New("not enough signatures")
+	ErrNotMultisigKey      = errors.New("not a key of the multisig")
+
+	ErrCertificateFeed    = errors.New("the certificate is not of the feed")
+	ErrCertificateHead    = errors.New("the certificate is not of the head")
+	ErrCertificateExpired = errors.New("the certificate is expired")
+
+	ErrNotSealed  = errors.New("not a sealed object")
+	ErrCantOpen   = errors.New("can't open, wrong key or corrupted value")
+	ErrNotPrivate = errors.New("not a private Root")
+	ErrNotReader  = errors.New("not a reader of the private Root")
+
+	ErrInvalidBlob      = errors.New("invalid Blob")
+	ErrInvalidOffset    = errors.New("invalid offset")
+	ErrInvalidWhence    = errors.New("invalid whence")
+	ErrBlobWriterClosed = errors.New("BlobWriter is closed")
+
+	ErrInvalidProof = errors.New("invalid proof")
+	ErrInvalidPath  = errors.New("invalid path")
 )
@@ -0,0 +1,518 @@
+// Package gogen generates Go source code from a
+// registry.Registry. It's useful if a feed uses
+// unknown Registry. The generated types have the
+// same Schemas, including names of fields and
+// tags. Thus, registered under the same names,
+// they produce Registry with the same RegistryRef.
+// The generated code can also contain static
+// (without reflection) Encode and Decode methods
+// for every registered type.
+package gogen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// defaults
+const (
+	Package  string = "types"    // default name of package
+	Registry string = "Registry" // default name of Registry variable
+)
+
+// A Config represents configurations of the Generate
+type Config struct {
+	// Package is name of package of generated code
+	Package string
+	// Registry is name of variable that holds
+	// registry.Registry of the generated types.
+	// Keep it blank to omit the variable
+	Registry string
+	// Methods turns on generating static Encode
+	// and Decode methods for every registered type.
+	// The methods produce the same result as the
+	// encoder.Serialize and encoder.DeserializeRaw
+	// do, but without reflection
+	Methods bool
+}
+
+// NewConfig returns Config with default values
+func NewConfig() (conf *Config) {
+	conf = new(Config)
+	conf.Package = Package
+	conf.Registry = Registry
+	return
+}
+
+// Generate Go source code of types of given Registry.
+// Registered types are named by last part of their
+// names. E.g. "cxo.User" becomes "User". If the last
+// parts of two names are the same, then all parts are
+// used. E.g. "cxo.User" and "app.User" become "CxoUser"
+// and "AppUser". Named types that are not registered
+// (arrays, slices, etc) keep their names. The Generate
+// returns error if it can't generate valid code for
+// the Registry. Use nil-Config for defaults
+func Generate(
+	reg *registry.Registry, // : the Registry
+	conf *Config, //           : configurations
+) (
+	src []byte, //             : formatted source code
+	err error, //              : an error
+) {
+
+	if conf == nil {
+		conf = NewConfig()
+	}
+
+	if isIdent(conf.Package) == false {
+		return nil, fmt.Errorf("invalid package name %q", conf.Package)
+	}
+
+	if conf.Registry != "" && isIdent(conf.Registry) == false {
+		return nil, fmt.Errorf("invalid name of Registry variable %q",
+			conf.Registry)
+	}
+
+	var g = &generator{
+		conf:  conf,
+		reg:   reg,
+		regs:  reg.Names(),
+		names: make(map[string]string),
+		named: make(map[string]registry.Schema),
+	}
+
+	if err = g.collect(); err != nil {
+		return
+	}
+
+	if err = g.generate(); err != nil {
+		return
+	}
+
+	if src, err = format.Source(g.buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("can't format generated code: %v", err)
+	}
+
+	return
+}
+
+type generator struct {
+	conf *Config
+	reg  *registry.Registry
+
+	regs  []string                   // sorted names of registered types
+	names map[string]string          // registered name -> Go name
+	named map[string]registry.Schema // named not registered types
+	order []string                   // sorted names of the named types
+
+	usesRegistry bool // import registry package
+	usesMath     bool // import math package
+
+	buf bytes.Buffer // the code (body)
+}
+
+// collect named types and choose Go names
+// for registered types
+func (g *generator) collect() (err error) {
+
+	var sch registry.Schema
+
+	for _, name := range g.regs {
+
+		if sch, err = g.reg.SchemaByName(name); err != nil {
+			return
+		}
+
+		for _, fl := range sch.Fields() {
+			if err = g.collectNamed(fl.Schema()); err != nil {
+				return
+			}
+		}
+
+	}
+
+	for name := range g.named {
+		g.order = append(g.order, name)
+	}
+
+	sort.Strings(g.order)
+
+	// Go names of registered types
+
+	var (
+		short = make(map[string]int)    // short name -> times
+		taken = make(map[string]string) // Go name -> name
+	)
+
+	for _, name := range g.regs {
+		short[shortGoName(name)]++
+	}
+
+	for _, name := range g.order {
+		taken[name] = name
+	}
+
+	if g.conf.Registry != "" {
+		taken[g.conf.Registry] = g.conf.Registry
+	}
+
+	for _, name := range g.regs {
+
+		var gn = shortGoName(name)
+
+		if _, ok := taken[gn]; ok == true || short[gn] > 1 {
+			gn = fullGoName(name)
+		}
+
+		if other, ok := taken[gn]; ok == true {
+			return fmt.Errorf("can't choose Go name for %q, %q takes %q",
+				name, other, gn)
+		}
+
+		taken[gn] = name
+		g.names[name] = gn
+
+	}
+
+	return
+}
+
+// collect named types that are not registered
+func (g *generator) collectNamed(sch registry.Schema) (err error) {
+
+	if sch.IsReference() == true || sch.IsRegistered() == true {
+		return // Ref, Refs, Dynamic, Blob or registered type
+	}
+
+	if isNamed(sch) == true {
+
+		var name = sch.Name()
+
+		if isIdent(name) == false {
+			return fmt.Errorf("invalid name of type %q", name)
+		}
+
+		if ex, ok := g.named[name]; ok == true {
+			if bytes.Equal(ex.Encode(), sch.Encode()) == false {
+				return fmt.Errorf("different types with the same name %q",
+					name)
+			}
+			return // already
+		}
+
+		g.named[name] = sch
+
+	}
+
+	switch sch.Kind() {
+
+	case reflect.Array, reflect.Slice:
+
+		if sch.Elem() == nil {
+			return fmt.Errorf("missing schema of element of %q", sch)
+		}
+
+		return g.collectNamed(sch.Elem())
+
+	case reflect.Struct:
+
+		for _, fl := range sch.Fields() {
+			if err = g.collectNamed(fl.Schema()); err != nil {
+				return
+			}
+		}
+
+	}
+
+	return
+}
+
+// generate the code
+func (g *generator) generate() (err error) {
+
+	var body bytes.Buffer
+
+	// the Registry variable
+
+	if g.conf.Registry != "" {
+
+		g.usesRegistry = true
+
+		fmt.Fprintf(&body, "// %s of the types\n", g.conf.Registry)
+		fmt.Fprintf(&body, "var %s = registry.NewRegistry("+
+			"func(r *registry.Reg) {\n", g.conf.Registry)
+
+		for _, name := range g.regs {
+			fmt.Fprintf(&body, "r.Register(%q, %s{})\n", name, g.names[name])
+		}
+
+		body.WriteString("})\n\n")
+
+	}
+
+	// registered types
+
+	var sch registry.Schema
+
+	for _, name := range g.regs {
+
+		if sch, err = g.reg.SchemaByName(name); err != nil {
+			return
+		}
+
+		var lit string
+		if lit, err = g.typeLiteral(sch); err != nil {
+			return
+		}
+
+		fmt.Fprintf(&body, "// %s is %q\n", g.names[name], name)
+		fmt.Fprintf(&body, "type %s %s\n\n", g.names[name], lit)
+
+	}
+
+	// named types
+
+	for _, name := range g.order {
+
+		var lit string
+		if lit, err = g.typeLiteral(g.named[name]); err != nil {
+			return
+		}
+
+		fmt.Fprintf(&body, "// %s is named type\n", name)
+		fmt.Fprintf(&body, "type %s %s\n\n", name, lit)
+
+	}
+
+	// methods
+
+	if g.conf.Methods == true {
+		if err = g.generateMethods(&body); err != nil {
+			return
+		}
+	}
+
+	// header and imports
+
+	fmt.Fprintf(&g.buf, "// Code generated from registry %s. DO NOT EDIT.\n\n",
+		g.reg.Reference().String())
+	fmt.Fprintf(&g.buf, "package %s\n\n", g.conf.Package)
+
+	switch {
+	case g.usesMath == true && g.usesRegistry == true:
+		g.buf.WriteString("import (\n\"math\"\n\n" +
+			"\"github.com/skycoin/cxo/skyobject/registry\"\n)\n\n")
+	case g.usesMath == true:
+		g.buf.WriteString("import \"math\"\n\n")
+	case g.usesRegistry == true:
+		g.buf.WriteString(
+			"import \"github.com/skycoin/cxo/skyobject/registry\"\n\n")
+	}
+
+	g.buf.Write(body.Bytes())
+	return
+}
+
+// goType returns Go type of given Schema
+// that is name or literal of the type
+func (g *generator) goType(sch registry.Schema) (typ string, err error) {
+
+	if sch.IsReference() == true {
+
+		g.usesRegistry = true
+
+		switch rt := sch.ReferenceType(); rt {
+		case registry.ReferenceTypeSingle:
+			return "registry.Ref", nil
+		case registry.ReferenceTypeSlice:
+			return "registry.Refs", nil
+		case registry.ReferenceTypeDynamic:
+			return "registry.Dynamic", nil
+		case registry.ReferenceTypeBlob:
+			return "registry.Blob", nil
+		default:
+			return "", fmt.Errorf("invalid ReferenceType %d", rt)
+		}
+
+	}
+
+	if sch.IsRegistered() == true {
+
+		var ok bool
+		if typ, ok = g.names[sch.Name()]; ok == false {
+			return "", fmt.Errorf("missing schema %q", sch.Name())
+		}
+
+		return
+	}
+
+	if isNamed(sch) == true {
+		return sch.Name(), nil
+	}
+
+	return g.typeLiteral(sch)
+}
+
+// typeLiteral returns literal of given Schema
+// ignoring name of the Schema
+func (g *generator) typeLiteral(sch registry.Schema) (lit string, err error) {
+
+	if sch.Kind() == reflect.Uint8 {
+		return "byte", nil // the same
+	}
+
+	if isBasic(sch.Kind()) == true {
+		return sch.Kind().String(), nil
+	}
+
+	var el string
+
+	switch sch.Kind() {
+
+	case reflect.Slice, reflect.Array:
+
+		if sch.Elem() == nil {
+			return "", fmt.Errorf("missing schema of element of %q", sch)
+		}
+
+		if el, err = g.goType(sch.Elem()); err != nil {
+			return
+		}
+
+		if sch.Kind() == reflect.Slice {
+			return "[]" + el, nil
+		}
+
+		return fmt.Sprintf("[%d]%s", sch.Len(), el), nil
+
+	case reflect.Struct:
+
+		var sl bytes.Buffer
+
+		sl.WriteString("struct {\n")
+
+		for _, fl := range sch.Fields() {
+
+			if isIdent(fl.Name()) == false {
+				return "", fmt.Errorf("invalid name of field %q of %q",
+					fl.Name(), sch)
+			}
+
+			if el, err = g.goType(fl.Schema()); err != nil {
+				return
+			}
+
+			fmt.Fprintf(&sl, "%s %s%s\n", fl.Name(), el,
+				tagLiteral(fl.Tag()))
+
+		}
+
+		sl.WriteString("}")
+		return sl.String(), nil
+
+	}
+
+	return "", fmt.Errorf("invalid Kind <%s> of Schema %q", sch.Kind(), sch)
+}
+
+// tag of a field as is
+func tagLiteral(tag reflect.StructTag) string {
+
+	if tag == "" {
+		return ""
+	}
+
+	if strings.Contains(string(tag), "`") == false {
+		return " `" + string(tag) + "`"
+	}
+
+	return " " + strconv.Quote(string(tag))
+}
+
+func isBasic(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	}
+	return false
+}
+
+// is named, but not registered; basic types
+// like int32 are named by their kinds
+func isNamed(sch registry.Schema) bool {
+
+	if sch.Name() == "" || sch.IsRegistered() == true {
+		return false
+	}
+
+	return isBasic(sch.Kind()) == false || sch.Name() != sch.Kind().String()
+}
+
+func isIdent(s string) bool {
+
+	if s == "" {
+		return false
+	}
+
+	for i, r := range s {
+		switch {
+		case r == '_', unicode.IsLetter(r):
+		case unicode.IsDigit(r) && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// split name of registered type to parts
+// that can be used in Go identifiers
+func nameParts(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsLetter(r) == false && unicode.IsDigit(r) == false
+	})
+}
+
+// join parts making them exported
+func joinParts(parts []string) (gn string) {
+
+	for _, part := range parts {
+		var rs = []rune(part)
+		gn += string(unicode.ToUpper(rs[0])) + string(rs[1:])
+	}
+
+	if gn == "" || unicode.IsDigit([]rune(gn)[0]) == true {
+		gn = "T" + gn
+	}
+
+	return
+}
+
+// "cxo.User" -> "User"
+func shortGoName(name string) string {
+
+	if i := strings.LastIndexAny(name, "./"); i >= 0 && i < len(name)-1 {
+		name = name[i+1:]
+	}
+
+	return joinParts(nameParts(name))
+}
+
+// "cxo.User" -> "CxoUser"
+func fullGoName(name string) string {
+	return joinParts(nameParts(name))
+}
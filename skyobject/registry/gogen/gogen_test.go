@@ -0,0 +1,376 @@
+package gogen
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+	"github.com/skycoin/cxo/skyobject/registry/gogen/testtypes"
+)
+
+// regenerate the testtypes package
+var update = flag.Bool("update", false, "update generated testtypes")
+
+type Point [2]float32
+
+type Tags []string
+
+type User struct {
+	Name   string `json:"name"`
+	Age    uint32
+	Hidden []byte `enc:"-"`
+}
+
+type Post struct {
+	Head   string
+	Body   string
+	Time   int64
+	Author registry.Ref `skyobject:"schema=test.User" json:"author"`
+	Tags   Tags
+	Where  Point
+	Meta   struct {
+		Draft bool
+		Hash  [32]byte
+	}
+}
+
+type Feed struct {
+	Posts   registry.Refs `skyobject:"schema=test.Post"`
+	Owner   User
+	Admins  []User
+	Any     registry.Dynamic
+	Avatar  registry.Blob
+	Picture []byte
+	Rate    float64
+	Small   []int8
+}
+
+type OtherUser struct {
+	Login string
+}
+
+func getTestRegistry() *registry.Registry {
+	return registry.NewRegistry(func(r *registry.Reg) {
+		r.Register("test.User", User{})
+		r.Register("test.Post", Post{})
+		r.Register("test.Feed", Feed{})
+		r.Register("other.User", OtherUser{})
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	// Generate(reg *registry.Registry, conf *Config) (src []byte, err error)
+
+	var reg = getTestRegistry()
+
+	for _, methods := range []bool{false, true} {
+
+		var conf = NewConfig()
+
+		conf.Methods = methods
+
+		var src, err = Generate(reg, conf)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			fs   = token.NewFileSet()
+			file *ast.File
+		)
+
+		if file, err = parser.ParseFile(fs, "", src, 0); err != nil {
+			t.Fatal(err, "\n", string(src))
+		}
+
+		if file.Name.Name != Package {
+			t.Error("wrong package name:", file.Name.Name)
+		}
+
+		var (
+			types = make(map[string]bool)
+			funcs int
+		)
+
+		for _, decl := range file.Decls {
+			switch x := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range x.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok == true {
+						types[ts.Name.Name] = true
+					}
+				}
+			case *ast.FuncDecl:
+				funcs++
+			}
+		}
+
+		for _, name := range []string{
+			"TestUser", "OtherUser", "Post", "Feed", "Point", "Tags",
+		} {
+			if types[name] == false {
+				t.Errorf("missing type %s", name)
+			}
+		}
+
+		if len(types) != 6 {
+			t.Error("wrong number of types:", len(types))
+		}
+
+		if methods == true && funcs == 0 {
+			t.Error("missing methods")
+		} else if methods == false && funcs != 0 {
+			t.Error("unexpected functions:", funcs)
+		}
+
+		for _, part := range []string{
+			"`skyobject:\"schema=test.User\" json:\"author\"`",
+			"`json:\"name\"`",
+			`r.Register("test.Feed", Feed{})`,
+			reg.Reference().String(),
+		} {
+			if strings.Contains(string(src), part) == false {
+				t.Errorf("missing %s", part)
+			}
+		}
+
+		if strings.Contains(string(src), "Hidden") == true {
+			t.Error("field that is not encoded generated")
+		}
+
+		// decoded Registry
+
+		var dec *registry.Registry
+		if dec, err = registry.DecodeRegistry(reg.Encode()); err != nil {
+			t.Fatal(err)
+		}
+
+		var ds []byte
+		if ds, err = Generate(dec, conf); err != nil {
+			t.Fatal(err)
+		}
+
+		if string(ds) != string(src) {
+			t.Error("different code for decoded Registry")
+		}
+
+	}
+
+}
+
+func testFeed() (feed Feed) {
+
+	feed.Posts.Hash = cipher.SumSHA256([]byte("posts"))
+	feed.Owner = User{Name: "Alice", Age: 21}
+	feed.Admins = []User{{Name: "Eva", Age: 30}, {Name: "Ammy"}}
+	feed.Any.Hash = cipher.SumSHA256([]byte("any"))
+	feed.Avatar.Hash = cipher.SumSHA256([]byte("avatar"))
+	feed.Picture = []byte("picture")
+	feed.Rate = 3.14
+	feed.Small = []int8{-1, 0, 1}
+
+	return
+}
+
+func testPost() (post Post) {
+
+	post.Head = "Head"
+	post.Body = "Body"
+	post.Time = -1024
+	post.Author.Hash = cipher.SumSHA256([]byte("author"))
+	post.Tags = Tags{"one", "two"}
+	post.Where = Point{1.5, -2.5}
+	post.Meta.Draft = true
+	post.Meta.Hash = cipher.SumSHA256([]byte("hash"))
+
+	return
+}
+
+// the generated types are encoded the same way
+// the original types are
+func testGeneratedEncoding(
+	t *testing.T,
+	orig interface{}, // pointer to original value
+	gen interface { // pointer to blank generated value
+		Encode() []byte
+		Decode([]byte) error
+	},
+) {
+
+	var p = encoder.Serialize(orig)
+
+	if err := gen.Decode(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if e := gen.Encode(); bytes.Equal(e, p) == false {
+		t.Errorf("%T: Encode differs from encoder.Serialize", gen)
+	}
+
+	if e := encoder.Serialize(gen); bytes.Equal(e, p) == false {
+		t.Errorf("%T: generated type encoded differently", gen)
+	}
+
+	// decode back to original type and compare with
+	// the original value decoded by the encoder
+	var (
+		typ  = reflect.TypeOf(orig).Elem()
+		dec  = reflect.New(typ)
+		want = reflect.New(typ)
+	)
+
+	var _, err = encoder.DeserializeRaw(gen.Encode(), dec.Interface())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = encoder.DeserializeRaw(p, want.Interface()); err != nil {
+		t.Fatal(err)
+	}
+
+	if reflect.DeepEqual(dec.Interface(), want.Interface()) == false {
+		t.Errorf("%T: wrong decoded value", gen)
+	}
+
+}
+
+func TestGenerate_compile(t *testing.T) {
+
+	const name = "testtypes/types.go"
+
+	var (
+		reg  = getTestRegistry()
+		conf = NewConfig()
+	)
+
+	conf.Package = "testtypes"
+	conf.Methods = true
+
+	var src, err = Generate(reg, conf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *update == true {
+		if err = ioutil.WriteFile(name, src, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var compiled []byte
+	if compiled, err = ioutil.ReadFile(name); err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(compiled, src) == false {
+		t.Fatal(name, "is outdated, regenerate it using -update flag")
+	}
+
+	if rr := testtypes.Registry.Reference(); rr != reg.Reference() {
+		t.Errorf("wrong RegistryRef of generated types: %s, want %s",
+			rr.Short(), reg.Reference().Short())
+	}
+
+	var (
+		feed = testFeed()
+		post = testPost()
+		user = User{Name: "Bob", Age: 42}
+		othr = OtherUser{Login: "bob"}
+	)
+
+	testGeneratedEncoding(t, &feed, new(testtypes.Feed))
+	testGeneratedEncoding(t, &post, new(testtypes.Post))
+	testGeneratedEncoding(t, &user, new(testtypes.TestUser))
+	testGeneratedEncoding(t, &othr, new(testtypes.OtherUser))
+
+	// blank values
+	testGeneratedEncoding(t, &Feed{}, new(testtypes.Feed))
+	testGeneratedEncoding(t, &Post{}, new(testtypes.Post))
+
+	// decoded values
+
+	var gf testtypes.Feed
+	if err = gf.Decode(encoder.Serialize(&feed)); err != nil {
+		t.Fatal(err)
+	}
+
+	if gf.Owner.Name != "Alice" || len(gf.Admins) != 2 ||
+		gf.Avatar.Hash != feed.Avatar.Hash || gf.Rate != 3.14 {
+
+		t.Error("wrong decoded Feed:", gf)
+	}
+
+}
+
+func TestGenerate_errors(t *testing.T) {
+
+	var reg = getTestRegistry()
+
+	for _, conf := range []*Config{
+		{Package: ""},
+		{Package: "1types"},
+		{Package: "types", Registry: "a.b"},
+	} {
+		if _, err := Generate(reg, conf); err == nil {
+			t.Errorf("missing error for %#v", conf)
+		}
+	}
+
+	type Method struct {
+		Encode string
+	}
+
+	reg = registry.NewRegistry(func(r *registry.Reg) {
+		r.Register("test.Method", Method{})
+	})
+
+	var conf = NewConfig()
+
+	if _, err := Generate(reg, conf); err != nil {
+		t.Error(err)
+	}
+
+	conf.Methods = true
+
+	if _, err := Generate(reg, conf); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func Test_goNames(t *testing.T) {
+
+	for _, tc := range []struct {
+		name, short, full string
+	}{
+		{"test.User", "User", "TestUser"},
+		{"cxo/app.feed-item", "FeedItem", "CxoAppFeedItem"},
+		{"user", "User", "User"},
+		{"app.2d", "T2d", "App2d"},
+	} {
+
+		if short := shortGoName(tc.name); short != tc.short {
+			t.Errorf("%s: wrong short name %s, want %s", tc.name, short,
+				tc.short)
+		}
+
+		if full := fullGoName(tc.name); full != tc.full {
+			t.Errorf("%s: wrong full name %s, want %s", tc.name, full, tc.full)
+		}
+
+	}
+
+}
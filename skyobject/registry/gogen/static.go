@@ -0,0 +1,436 @@
+package gogen
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// names of generated methods
+var methods = []string{"Encode", "Decode", "encodeTo", "decodeFrom"}
+
+// little-endian helpers of the static methods
+const staticHelpers = `
+func cxoPutUint16(p []byte, x uint16) []byte {
+	return append(p, byte(x), byte(x>>8))
+}
+
+func cxoPutUint32(p []byte, x uint32) []byte {
+	return append(p, byte(x), byte(x>>8), byte(x>>16), byte(x>>24))
+}
+
+func cxoPutUint64(p []byte, x uint64) []byte {
+	return append(p, byte(x), byte(x>>8), byte(x>>16), byte(x>>24),
+		byte(x>>32), byte(x>>40), byte(x>>48), byte(x>>56))
+}
+
+func cxoUint16(p []byte) uint16 {
+	return uint16(p[0]) | uint16(p[1])<<8
+}
+
+func cxoUint32(p []byte) uint32 {
+	return uint32(p[0]) | uint32(p[1])<<8 | uint32(p[2])<<16 |
+		uint32(p[3])<<24
+}
+
+func cxoUint64(p []byte) uint64 {
+	return uint64(cxoUint32(p)) | uint64(cxoUint32(p[4:]))<<32
+}
+`
+
+// generate static Encode and Decode methods
+// for every registered type
+func (g *generator) generateMethods(body *bytes.Buffer) (err error) {
+
+	g.usesRegistry = true // for registry.ErrInvalidSchemaOrData
+
+	var sch registry.Schema
+
+	for _, name := range g.regs {
+
+		if sch, err = g.reg.SchemaByName(name); err != nil {
+			return
+		}
+
+		for _, fl := range sch.Fields() {
+			for _, m := range methods {
+				if fl.Name() == m {
+					return fmt.Errorf("field %s of %q conflicts with method",
+						m, name)
+				}
+			}
+		}
+
+		var gn = g.names[name]
+
+		fmt.Fprintf(body, `// Encode the %[1]s
+func (x *%[1]s) Encode() (p []byte) {
+	return x.encodeTo(nil)
+}
+
+// Decode given encoded %[1]s
+func (x *%[1]s) Decode(p []byte) (err error) {
+	var n int
+	if n, err = x.decodeFrom(p); err != nil {
+		return
+	}
+	if n != len(p) {
+		return registry.ErrInvalidSchemaOrData
+	}
+	return
+}
+
+`, gn)
+
+		fmt.Fprintf(body, "func (x *%s) encodeTo(p []byte) []byte {\n", gn)
+
+		for _, fl := range sch.Fields() {
+			if err = g.encode(body, fl.Schema(), "x."+fl.Name(), 0); err != nil {
+				return
+			}
+		}
+
+		body.WriteString("return p\n}\n\n")
+
+		fmt.Fprintf(body, "func (x *%s) decodeFrom(p []byte) "+
+			"(n int, err error) {\n", gn)
+
+		for _, fl := range sch.Fields() {
+			if err = g.decode(body, fl.Schema(), "x."+fl.Name(), 0); err != nil {
+				return
+			}
+		}
+
+		body.WriteString("return\n}\n\n")
+
+	}
+
+	body.WriteString(staticHelpers)
+	return
+}
+
+// is uint8 (not named)
+func isByte(sch registry.Schema) bool {
+	return sch.IsReference() == false &&
+		sch.Kind() == reflect.Uint8 &&
+		isNamed(sch) == false
+}
+
+// write code that appends encoded value (expr)
+// of given Schema to p
+func (g *generator) encode(
+	w *bytes.Buffer, //        : the code
+	sch registry.Schema, //    : schema of the value
+	expr string, //            : the value
+	depth int, //              : depth of loops
+) (
+	err error, //              : an error
+) {
+
+	if sch.IsReference() == true {
+
+		if sch.ReferenceType() == registry.ReferenceTypeDynamic {
+			fmt.Fprintf(w, "p = append(p, %[1]s.Hash[:]...)\n"+
+				"p = append(p, %[1]s.Schema[:]...)\n", expr)
+			return
+		}
+
+		fmt.Fprintf(w, "p = append(p, %s.Hash[:]...)\n", expr)
+		return
+	}
+
+	if sch.IsRegistered() == true {
+		fmt.Fprintf(w, "p = %s.encodeTo(p)\n", expr)
+		return
+	}
+
+	switch sch.Kind() {
+
+	case reflect.Bool:
+
+		fmt.Fprintf(w, "if %s {\np = append(p, 1)\n} else {\n"+
+			"p = append(p, 0)\n}\n", expr)
+
+	case reflect.Int8, reflect.Uint8:
+
+		fmt.Fprintf(w, "p = append(p, byte(%s))\n", expr)
+
+	case reflect.Int16, reflect.Uint16:
+
+		fmt.Fprintf(w, "p = cxoPutUint16(p, uint16(%s))\n", expr)
+
+	case reflect.Int32, reflect.Uint32:
+
+		fmt.Fprintf(w, "p = cxoPutUint32(p, uint32(%s))\n", expr)
+
+	case reflect.Int64, reflect.Uint64:
+
+		fmt.Fprintf(w, "p = cxoPutUint64(p, uint64(%s))\n", expr)
+
+	case reflect.Float32:
+
+		g.usesMath = true
+		fmt.Fprintf(w, "p = cxoPutUint32(p, math.Float32bits(float32(%s)))\n",
+			expr)
+
+	case reflect.Float64:
+
+		g.usesMath = true
+		fmt.Fprintf(w, "p = cxoPutUint64(p, math.Float64bits(float64(%s)))\n",
+			expr)
+
+	case reflect.String:
+
+		fmt.Fprintf(w, "p = cxoPutUint32(p, uint32(len(%[1]s)))\n"+
+			"p = append(p, string(%[1]s)...)\n", expr)
+
+	case reflect.Slice, reflect.Array:
+
+		if sch.Kind() == reflect.Slice {
+			fmt.Fprintf(w, "p = cxoPutUint32(p, uint32(len(%s)))\n", expr)
+		}
+
+		if isByte(sch.Elem()) == true {
+			fmt.Fprintf(w, "p = append(p, %s[:]...)\n", expr)
+			return
+		}
+
+		fmt.Fprintf(w, "for i%d := range %s {\n", depth, expr)
+
+		err = g.encode(w, sch.Elem(), fmt.Sprintf("%s[i%d]", expr, depth),
+			depth+1)
+
+		if err != nil {
+			return
+		}
+
+		w.WriteString("}\n")
+
+	case reflect.Struct:
+
+		for _, fl := range sch.Fields() {
+			err = g.encode(w, fl.Schema(), expr+"."+fl.Name(), depth)
+
+			if err != nil {
+				return
+			}
+		}
+
+	default:
+
+		return fmt.Errorf("invalid Kind <%s> of Schema %q", sch.Kind(), sch)
+
+	}
+
+	return
+}
+
+// write code that checks length of p
+func writeCheck(w *bytes.Buffer, need string) {
+	fmt.Fprintf(w, "if len(p)-n < %s {\n"+
+		"return n, registry.ErrInvalidSchemaOrData\n}\n", need)
+}
+
+// write code that decodes value (expr)
+// of given Schema from p[n:]
+func (g *generator) decode(
+	w *bytes.Buffer, //        : the code
+	sch registry.Schema, //    : schema of the value
+	expr string, //            : the value
+	depth int, //              : depth of loops
+) (
+	err error, //              : an error
+) {
+
+	if sch.IsReference() == true {
+
+		if sch.ReferenceType() == registry.ReferenceTypeDynamic {
+			writeCheck(w, "64")
+			fmt.Fprintf(w, "copy(%[1]s.Hash[:], p[n:])\nn += 32\n"+
+				"copy(%[1]s.Schema[:], p[n:])\nn += 32\n", expr)
+			return
+		}
+
+		writeCheck(w, "32")
+		fmt.Fprintf(w, "copy(%s.Hash[:], p[n:])\nn += 32\n", expr)
+		return
+	}
+
+	if sch.IsRegistered() == true {
+		fmt.Fprintf(w, "{\nvar m int\n"+
+			"if m, err = %s.decodeFrom(p[n:]); err != nil {\nreturn\n}\n"+
+			"n += m\n}\n", expr)
+		return
+	}
+
+	var typ string
+	if typ, err = g.goType(sch); err != nil {
+		return
+	}
+
+	switch sch.Kind() {
+
+	case reflect.Bool:
+
+		writeCheck(w, "1")
+		fmt.Fprintf(w, "%s = p[n] != 0\nn++\n", expr)
+
+	case reflect.Int8, reflect.Uint8:
+
+		writeCheck(w, "1")
+		fmt.Fprintf(w, "%s = %s(p[n])\nn++\n", expr, typ)
+
+	case reflect.Int16, reflect.Uint16:
+
+		writeCheck(w, "2")
+		fmt.Fprintf(w, "%s = %s(cxoUint16(p[n:]))\nn += 2\n", expr, typ)
+
+	case reflect.Int32, reflect.Uint32:
+
+		writeCheck(w, "4")
+		fmt.Fprintf(w, "%s = %s(cxoUint32(p[n:]))\nn += 4\n", expr, typ)
+
+	case reflect.Int64, reflect.Uint64:
+
+		writeCheck(w, "8")
+		fmt.Fprintf(w, "%s = %s(cxoUint64(p[n:]))\nn += 8\n", expr, typ)
+
+	case reflect.Float32:
+
+		g.usesMath = true
+		writeCheck(w, "4")
+		fmt.Fprintf(w, "%s = %s(math.Float32frombits(cxoUint32(p[n:])))\n"+
+			"n += 4\n", expr, typ)
+
+	case reflect.Float64:
+
+		g.usesMath = true
+		writeCheck(w, "8")
+		fmt.Fprintf(w, "%s = %s(math.Float64frombits(cxoUint64(p[n:])))\n"+
+			"n += 8\n", expr, typ)
+
+	case reflect.String:
+
+		w.WriteString("{\n")
+		writeLength(w)
+		writeCheck(w, "l")
+		fmt.Fprintf(w, "%s = %s(p[n : n+l])\nn += l\n}\n", expr, typ)
+
+	case reflect.Slice:
+
+		w.WriteString("{\n")
+		writeLength(w)
+
+		if isByte(sch.Elem()) == true {
+			writeCheck(w, "l")
+			fmt.Fprintf(w, "%[1]s = make(%[2]s, l)\ncopy(%[1]s, p[n:])\n"+
+				"n += l\n}\n", expr, typ)
+			return
+		}
+
+		// the check protects against huge allocations
+		if min := minSize(sch.Elem()); min > 0 {
+			fmt.Fprintf(w, "if (len(p)-n)/%d < l {\n"+
+				"return n, registry.ErrInvalidSchemaOrData\n}\n", min)
+		}
+
+		fmt.Fprintf(w, "%s = make(%s, l)\n", expr, typ)
+
+		if err = g.decodeElements(w, sch, expr, depth); err != nil {
+			return
+		}
+
+		w.WriteString("}\n")
+
+	case reflect.Array:
+
+		if isByte(sch.Elem()) == true {
+			writeCheck(w, fmt.Sprint(sch.Len()))
+			fmt.Fprintf(w, "copy(%s[:], p[n:])\nn += %d\n", expr, sch.Len())
+			return
+		}
+
+		return g.decodeElements(w, sch, expr, depth)
+
+	case reflect.Struct:
+
+		for _, fl := range sch.Fields() {
+			err = g.decode(w, fl.Schema(), expr+"."+fl.Name(), depth)
+
+			if err != nil {
+				return
+			}
+		}
+
+	default:
+
+		return fmt.Errorf("invalid Kind <%s> of Schema %q", sch.Kind(), sch)
+
+	}
+
+	return
+}
+
+// write code that reads length (l) of string or slice
+func writeLength(w *bytes.Buffer) {
+	writeCheck(w, "4")
+	w.WriteString("var l = int(cxoUint32(p[n:]))\nn += 4\n" +
+		"if l < 0 {\nreturn n, registry.ErrInvalidSchemaOrData\n}\n")
+}
+
+// write loop that decodes elements of array or slice
+func (g *generator) decodeElements(
+	w *bytes.Buffer, //        : the code
+	sch registry.Schema, //    : schema of the array or slice
+	expr string, //            : the array or slice
+	depth int, //              : depth of loops
+) (
+	err error, //              : an error
+) {
+
+	fmt.Fprintf(w, "for i%d := range %s {\n", depth, expr)
+
+	err = g.decode(w, sch.Elem(), fmt.Sprintf("%s[i%d]", expr, depth),
+		depth+1)
+
+	if err != nil {
+		return
+	}
+
+	w.WriteString("}\n")
+	return
+}
+
+// min size of encoded value of given Schema
+func minSize(sch registry.Schema) (n int) {
+
+	if sch.IsReference() == true {
+		if sch.ReferenceType() == registry.ReferenceTypeDynamic {
+			return 64
+		}
+		return 32
+	}
+
+	switch sch.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return 1
+	case reflect.Int16, reflect.Uint16:
+		return 2
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		return 4
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		return 8
+	case reflect.String, reflect.Slice:
+		return 4
+	case reflect.Array:
+		return sch.Len() * minSize(sch.Elem())
+	case reflect.Struct:
+		for _, fl := range sch.Fields() {
+			n += minSize(fl.Schema())
+		}
+	}
+
+	return
+}
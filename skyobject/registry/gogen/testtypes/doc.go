@@ -0,0 +1,9 @@
+// Package testtypes contains code generated by the
+// gogen for types of the gogen tests. The package is
+// compiled and used to compare the generated types
+// with the original ones. Use
+//
+//	go test -run TestGenerate_compile -update
+//
+// inside the gogen directory to regenerate the code
+package testtypes
@@ -0,0 +1,642 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// MaxDecodedBlobSize is max size of content of a Blob
+// the DecodeValue puts to an expanded Blob
+const MaxDecodedBlobSize int64 = 64 * 1024
+
+// DecodeValue decodes given encoded value using given
+// Schema to generic representation without Go types.
+// The representation can be encoded to JSON using
+// encoding/json package. The DecodeValue produces
+//
+//     bool                   for bool
+//     int64                  for int8, int16, int32 and int64
+//     uint64                 for uint8, uint16, uint32 and uint64
+//     float64                for float32 and float64
+//     string                 for NaN and infinities ("NaN",
+//                            "+Inf" and "-Inf")
+//     string                 for string
+//     []byte                 for []byte and arrays of bytes
+//     []interface{}          for other arrays and slices
+//     map[string]interface{} for structures (by field names)
+//
+// References are represented by hex-encoded hashes if
+// given depth is zero. Otherwise, they are expanded
+// decoding objects they point to with depth - 1. Use
+// negative depth to expand all references. An expanded
+// Ref is the object, an expanded Refs is list of its
+// elements and an expanded Blob is map with "Hash",
+// "Size" and "Bytes" keys, where the "Bytes" is content
+// of the Blob; the "Bytes" is omitted if the Blob is
+// larger than the MaxDecodedBlobSize. A
+// Dynamic is map with "Schema" and "Hash" keys, or
+// with "Schema" and "Value" keys if it's expanded.
+// The "Schema" is name of registered type or hex-encoded
+// SchemaRef if the type is not registered. Blank
+// references are nil, except Refs that is always
+// hash or list. The DecodeValue returns
+// ErrInvalidSchemaOrData if the val doesn't
+// fit the Schema exactly
+func DecodeValue(
+	pack Pack, //     : pack to get
+	sch Schema, //    : schema of the value
+	val []byte, //    : encoded value
+	depth int, //     : depth of references to expand
+) (
+	v interface{}, // : generic representation
+	err error, //     : an error
+) {
+
+	// lengths of slices are checked against
+	// the val before any decoding
+	var n int
+	if n, err = sch.Size(val); err != nil {
+		return
+	}
+
+	if n != len(val) {
+		return nil, ErrInvalidSchemaOrData
+	}
+
+	return decodeValue(pack, sch, val, depth)
+}
+
+// decodeValue is DecodeValue of a value
+// which size already checked
+func decodeValue(
+	pack Pack, //     : pack to get
+	sch Schema, //    : schema of the value
+	val []byte, //    : encoded value
+	depth int, //     : depth of references to expand
+) (
+	v interface{}, // : generic representation
+	err error, //     : an error
+) {
+
+	if sch.IsReference() == true {
+		return decodeReference(pack, sch, val, depth)
+	}
+
+	switch sch.Kind() {
+
+	case reflect.Bool:
+
+		var x bool
+		err = deserializeExact(val, &x)
+		v = x
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+
+		return decodeInt(sch, val)
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+
+		return decodeUint(sch, val)
+
+	case reflect.Float32:
+
+		var x float32
+		err = deserializeExact(val, &x)
+		v = decodeFloat(float64(x))
+
+	case reflect.Float64:
+
+		var x float64
+		err = deserializeExact(val, &x)
+		v = decodeFloat(x)
+
+	case reflect.String:
+
+		var x string
+		err = deserializeExact(val, &x)
+		v = x
+
+	case reflect.Array, reflect.Slice:
+
+		return decodeArraySlice(pack, sch, val, depth)
+
+	case reflect.Struct:
+
+		return decodeStruct(pack, sch, val, depth)
+
+	default:
+
+		err = fmt.Errorf("invalid Kind <%s> of Schema %q", sch.Kind(), sch)
+
+	}
+
+	if err != nil {
+		v = nil
+	}
+
+	return
+}
+
+// ValueJSON is DecodeValue that returns JSON
+func ValueJSON(
+	pack Pack, //  : pack to get
+	sch Schema, // : schema of the value
+	val []byte, // : encoded value
+	depth int, //  : depth of references to expand
+) (
+	js []byte, //  : the JSON
+	err error, //  : an error
+) {
+
+	var v interface{}
+	if v, err = DecodeValue(pack, sch, val, depth); err != nil {
+		return
+	}
+
+	return json.Marshal(v)
+}
+
+// DecodeValue decodes the Root to generic representation
+// (see DecodeValue function). The representation is map
+// with "Pub", "Nonce", "Seq", "Time", "Prev", "Hash",
+// "Reg", "Descriptor" and "Refs" keys. The Refs are
+// Dynamic references of the Root decoded with given
+// depth. Hashes and public key are hex-encoded
+func (r *Root) DecodeValue(
+	pack Pack, //                : pack to get
+	depth int, //                : depth of references to expand
+) (
+	v map[string]interface{}, // : generic representation
+	err error, //                : an error
+) {
+
+	var refs = make([]interface{}, 0, len(r.Refs))
+
+	for _, dr := range r.Refs {
+
+		var rv interface{}
+		if rv, err = decodeDynamic(pack, dr, depth); err != nil {
+			return
+		}
+
+		refs = append(refs, rv)
+
+	}
+
+	v = map[string]interface{}{
+		"Pub":        r.Pub.Hex(),
+		"Nonce":      r.Nonce,
+		"Seq":        r.Seq,
+		"Time":       r.Time,
+		"Prev":       r.Prev.Hex(),
+		"Hash":       r.Hash.Hex(),
+		"Reg":        r.Reg.String(),
+		"Descriptor": r.Descriptor,
+		"Refs":       refs,
+	}
+
+	return
+}
+
+// JSON is DecodeValue of the Root that returns JSON
+func (r *Root) JSON(pack Pack, depth int) (js []byte, err error) {
+
+	var v map[string]interface{}
+	if v, err = r.DecodeValue(pack, depth); err != nil {
+		return
+	}
+
+	return json.Marshal(v)
+}
+
+// JSON can't represent NaN and infinities,
+// thus they are strings
+func decodeFloat(x float64) (v interface{}) {
+
+	if math.IsNaN(x) == true || math.IsInf(x, 0) == true {
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+
+	return x
+}
+
+// expanded Blob, with content if the Blob
+// is not larger than the MaxDecodedBlobSize
+func decodeBlob(pack Pack, blob *Blob) (v interface{}, err error) {
+
+	var size int64
+	if size, err = blob.Size(pack); err != nil {
+		return
+	}
+
+	var m = map[string]interface{}{
+		"Hash": blob.Hash.Hex(),
+		"Size": size,
+	}
+
+	if size > MaxDecodedBlobSize {
+		return m, nil // too large
+	}
+
+	var br *BlobReader
+	if br, err = blob.Reader(pack); err != nil {
+		return
+	}
+
+	var p []byte
+	p, err = ioutil.ReadAll(io.LimitReader(br, MaxDecodedBlobSize+1))
+
+	if err != nil {
+		return
+	}
+
+	if int64(len(p)) != size {
+		return nil, ErrInvalidBlob
+	}
+
+	m["Bytes"] = p
+	return m, nil
+}
+
+func decodeInt(sch Schema, val []byte) (v interface{}, err error) {
+
+	var x int64
+
+	switch sch.Kind() {
+	case reflect.Int8:
+		var y int8
+		err = deserializeExact(val, &y)
+		x = int64(y)
+	case reflect.Int16:
+		var y int16
+		err = deserializeExact(val, &y)
+		x = int64(y)
+	case reflect.Int32:
+		var y int32
+		err = deserializeExact(val, &y)
+		x = int64(y)
+	case reflect.Int64:
+		err = deserializeExact(val, &x)
+	}
+
+	if err != nil {
+		return
+	}
+
+	return x, nil
+}
+
+func decodeUint(sch Schema, val []byte) (v interface{}, err error) {
+
+	var x uint64
+
+	switch sch.Kind() {
+	case reflect.Uint8:
+		var y uint8
+		err = deserializeExact(val, &y)
+		x = uint64(y)
+	case reflect.Uint16:
+		var y uint16
+		err = deserializeExact(val, &y)
+		x = uint64(y)
+	case reflect.Uint32:
+		var y uint32
+		err = deserializeExact(val, &y)
+		x = uint64(y)
+	case reflect.Uint64:
+		err = deserializeExact(val, &x)
+	}
+
+	if err != nil {
+		return
+	}
+
+	return x, nil
+}
+
+func decodeArraySlice(
+	pack Pack, //     : pack to get
+	sch Schema, //    : schema of the array or slice
+	val []byte, //    : encoded array or slice
+	depth int, //     : depth of references to expand
+) (
+	v interface{}, // : generic representation
+	err error, //     : an error
+) {
+
+	var el Schema
+
+	if el = sch.Elem(); el == nil {
+		return nil, fmt.Errorf("Schema of element of %q is nil", sch)
+	}
+
+	var ln, shift int
+
+	if sch.Kind() == reflect.Array {
+		ln = sch.Len()
+	} else {
+		if ln, err = getLength(val); err != nil {
+			return
+		}
+		shift = 4
+	}
+
+	// []byte and arrays of bytes
+	if el.Kind() == reflect.Uint8 && el.IsReference() == false {
+
+		if shift+ln != len(val) {
+			return nil, ErrInvalidSchemaOrData
+		}
+
+		return append([]byte{}, val[shift:]...), nil
+	}
+
+	// the ln is not trusted, thus it's not used to
+	// allocate more than the val can hold
+	var capacity = len(val) - shift
+
+	if ln < capacity {
+		capacity = ln
+	} else if capacity < 0 {
+		capacity = 0
+	}
+
+	var (
+		list = make([]interface{}, 0, capacity)
+		ev   interface{}
+		m    int
+	)
+
+	for k := 0; k < ln; k++ {
+
+		if shift > len(val) {
+			return nil, ErrInvalidSchemaOrData
+		}
+
+		if m, err = el.Size(val[shift:]); err != nil {
+			return
+		}
+
+		ev, err = decodeValue(pack, el, val[shift:shift+m], depth)
+
+		if err != nil {
+			return
+		}
+
+		list = append(list, ev)
+		shift += m
+
+	}
+
+	if shift != len(val) {
+		return nil, ErrInvalidSchemaOrData
+	}
+
+	return list, nil
+}
+
+func decodeStruct(
+	pack Pack, //     : pack to get
+	sch Schema, //    : schema of the struct
+	val []byte, //    : encoded struct
+	depth int, //     : depth of references to expand
+) (
+	v interface{}, // : generic representation
+	err error, //     : an error
+) {
+
+	var (
+		fields = make(map[string]interface{}, len(sch.Fields()))
+		fv     interface{}
+		shift  int
+		s      int
+	)
+
+	for _, fl := range sch.Fields() {
+
+		if shift > len(val) {
+			return nil, ErrInvalidSchemaOrData
+		}
+
+		if s, err = fl.Schema().Size(val[shift:]); err != nil {
+			return
+		}
+
+		fv, err = decodeValue(pack, fl.Schema(), val[shift:shift+s], depth)
+
+		if err != nil {
+			return
+		}
+
+		fields[fl.Name()] = fv
+		shift += s
+
+	}
+
+	if shift != len(val) {
+		return nil, ErrInvalidSchemaOrData
+	}
+
+	return fields, nil
+}
+
+func decodeReference(
+	pack Pack, //     : pack to get
+	sch Schema, //    : schema of the reference
+	val []byte, //    : encoded reference
+	depth int, //     : depth of references to expand
+) (
+	v interface{}, // : generic representation
+	err error, //     : an error
+) {
+
+	var rt = sch.ReferenceType()
+
+	switch rt {
+
+	case ReferenceTypeSingle:
+
+		var ref Ref
+		if err = deserializeExact(val, &ref); err != nil {
+			return
+		}
+
+		if ref.IsBlank() == true {
+			return nil, nil
+		}
+
+		if depth == 0 {
+			return ref.Hash.Hex(), nil
+		}
+
+		var el Schema
+		if el = sch.Elem(); el == nil {
+			return nil, ErrInvalidSchema
+		}
+
+		return decodeHash(pack, el, ref.Hash, depth-1)
+
+	case ReferenceTypeSlice:
+
+		var refs Refs
+		if err = deserializeExact(val, &refs); err != nil {
+			return
+		}
+
+		if depth == 0 {
+			return refs.Hash.Hex(), nil
+		}
+
+		var el Schema
+		if el = sch.Elem(); el == nil {
+			return nil, ErrInvalidSchema
+		}
+
+		return decodeRefs(pack, el, &refs, depth-1)
+
+	case ReferenceTypeDynamic:
+
+		var dr Dynamic
+		if err = deserializeExact(val, &dr); err != nil {
+			return
+		}
+
+		return decodeDynamic(pack, dr, depth)
+
+	case ReferenceTypeBlob:
+
+		var blob Blob
+		if err = deserializeExact(val, &blob); err != nil {
+			return
+		}
+
+		if blob.IsBlank() == true {
+			return nil, nil
+		}
+
+		if depth == 0 {
+			return blob.Hash.Hex(), nil
+		}
+
+		return decodeBlob(pack, &blob)
+
+	}
+
+	return nil, fmt.Errorf("invalid ReferenceType %d of %q", rt, sch)
+}
+
+// decode object by hash
+func decodeHash(
+	pack Pack, //          : pack to get
+	sch Schema, //         : schema of the object
+	hash cipher.SHA256, // : hash of the object
+	depth int, //          : depth of references to expand
+) (
+	v interface{}, //      : generic representation
+	err error, //          : an error
+) {
+
+	var val []byte
+	if val, err = pack.Get(hash); err != nil {
+		return
+	}
+
+	if IsSealed(val) == true {
+		return nil, ErrCantOpen // not a private Pack
+	}
+
+	return DecodeValue(pack, sch, val, depth)
+}
+
+func decodeRefs(
+	pack Pack, //     : pack to get
+	el Schema, //     : schema of elements
+	refs *Refs, //    : the Refs
+	depth int, //     : depth of references to expand
+) (
+	v interface{}, // : generic representation
+	err error, //     : an error
+) {
+
+	// length of the Refs is not trusted and is
+	// not used to allocate the list
+	var list = []interface{}{}
+
+	err = refs.Ascend(pack, func(_ int, hash cipher.SHA256) (err error) {
+
+		if hash == (cipher.SHA256{}) {
+			list = append(list, nil)
+			return
+		}
+
+		var ev interface{}
+		if ev, err = decodeHash(pack, el, hash, depth); err != nil {
+			return
+		}
+
+		list = append(list, ev)
+		return
+	})
+
+	if err != nil {
+		return
+	}
+
+	return list, nil
+}
+
+func decodeDynamic(
+	pack Pack, //     : pack to get
+	dr Dynamic, //    : the Dynamic
+	depth int, //     : depth of references to expand
+) (
+	v interface{}, // : generic representation
+	err error, //     : an error
+) {
+
+	if dr.IsValid() == false {
+		return nil, ErrInvalidDynamicReference
+	}
+
+	if dr.IsBlank() == true {
+		return nil, nil
+	}
+
+	var (
+		name = dr.Schema.String()
+		sch  Schema
+	)
+
+	if sch, err = pack.Registry().SchemaByReference(dr.Schema); err == nil {
+		if sch.Name() != "" {
+			name = sch.Name()
+		}
+	} else if depth != 0 {
+		return // can't expand
+	}
+
+	if dr.Hash == (cipher.SHA256{}) {
+		return map[string]interface{}{"Schema": name, "Hash": nil}, nil
+	}
+
+	if depth == 0 {
+		return map[string]interface{}{
+			"Schema": name,
+			"Hash":   dr.Hash.Hex(),
+		}, nil
+	}
+
+	var dv interface{}
+	if dv, err = decodeHash(pack, sch, dr.Hash, depth-1); err != nil {
+		return
+	}
+
+	return map[string]interface{}{"Schema": name, "Value": dv}, nil
+}
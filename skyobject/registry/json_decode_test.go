@@ -0,0 +1,403 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+func TestDecodeValue(t *testing.T) {
+	// DecodeValue(pack Pack, sch Schema, val []byte,
+	//     depth int) (v interface{}, err error)
+
+	var (
+		pack = getTestPack()
+		r    = getTestQueryRoot(t, pack)
+
+		sch Schema
+		val []byte
+		v   interface{}
+		err error
+	)
+
+	// depth 0, references are hashes
+
+	if sch, val, err = r.Get(pack, "Refs[0]"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err = DecodeValue(pack, sch, val, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var group, ok = v.(map[string]interface{})
+
+	if ok == false {
+		t.Fatalf("wrong type %T", v)
+	}
+
+	if group["Name"] != "the CXO" {
+		t.Error("wrong name:", group["Name"])
+	}
+
+	if _, ok = group["Members"].(string); ok == false {
+		t.Errorf("wrong type of Members %T", group["Members"])
+	}
+
+	if _, ok = group["Curator"].(string); ok == false {
+		t.Errorf("wrong type of Curator %T", group["Curator"])
+	}
+
+	if dev, ok := group["Developer"].(map[string]interface{}); ok == false {
+		t.Errorf("wrong type of Developer %T", group["Developer"])
+	} else if dev["Schema"] != "test.Man" {
+		t.Error("wrong schema of Developer:", dev["Schema"])
+	} else if _, ok = dev["Hash"].(string); ok == false {
+		t.Errorf("wrong type of Developer hash %T", dev["Hash"])
+	}
+
+	// expand all
+
+	if v, err = DecodeValue(pack, sch, val, -1); err != nil {
+		t.Fatal(err)
+	}
+
+	group = v.(map[string]interface{})
+
+	if members, ok := group["Members"].([]interface{}); ok == false {
+		t.Errorf("wrong type of Members %T", group["Members"])
+	} else if len(members) != 10 {
+		t.Error("wrong number of members:", len(members))
+	} else if usr := members[3].(map[string]interface{}); usr["Name"] != "Alice #18" {
+		t.Error("wrong member:", usr["Name"])
+	} else if usr["Age"] != uint64(3) {
+		t.Errorf("wrong age %v (%T)", usr["Age"], usr["Age"])
+	}
+
+	if cur, ok := group["Curator"].(map[string]interface{}); ok == false {
+		t.Errorf("wrong type of Curator %T", group["Curator"])
+	} else if cur["Name"] != "Bob" {
+		t.Error("wrong curator:", cur["Name"])
+	}
+
+	if dev, ok := group["Developer"].(map[string]interface{}); ok == false {
+		t.Errorf("wrong type of Developer %T", group["Developer"])
+	} else if man, ok := dev["Value"].(map[string]interface{}); ok == false {
+		t.Errorf("wrong type of Developer value %T", dev["Value"])
+	} else if man["GitHub"] != "logrusorgru" {
+		t.Error("wrong developer:", man["GitHub"])
+	}
+
+	// depth 1, members are expanded, but not their references
+
+	if v, err = DecodeValue(pack, sch, val, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	group = v.(map[string]interface{})
+
+	if members, ok := group["Members"].([]interface{}); ok == false {
+		t.Errorf("wrong type of Members %T", group["Members"])
+	} else if len(members) != 10 {
+		t.Error("wrong number of members:", len(members))
+	}
+
+	// arrays
+
+	if sch, val, err = r.Get(pack, "Refs[1]"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err = DecodeValue(pack, sch, val, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var arrays = v.(map[string]interface{})
+
+	if ts, ok := arrays["TwoStrings"].([]interface{}); ok == false {
+		t.Errorf("wrong type of TwoStrings %T", arrays["TwoStrings"])
+	} else if len(ts) != 2 || ts[0] != "one" || ts[1] != "two" {
+		t.Error("wrong TwoStrings:", ts)
+	}
+
+	if named, ok := arrays["Named"].([]interface{}); ok == false {
+		t.Errorf("wrong type of Named %T", arrays["Named"])
+	} else if len(named) != 5 || named[2] != int64(12) {
+		t.Error("wrong Named:", named)
+	}
+
+	// blank references
+
+	if sch, val, err = r.Get(pack, "Refs[2]"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err = DecodeValue(pack, sch, val, -1); err != nil {
+		t.Fatal(err)
+	}
+
+	group = v.(map[string]interface{})
+
+	if group["Curator"] != nil {
+		t.Error("blank Ref is not nil:", group["Curator"])
+	}
+
+	if group["Developer"] != nil {
+		t.Error("blank Dynamic is not nil:", group["Developer"])
+	}
+
+	if members, ok := group["Members"].([]interface{}); ok == false {
+		t.Errorf("wrong type of Members %T", group["Members"])
+	} else if len(members) != 0 {
+		t.Error("wrong number of members:", len(members))
+	}
+
+}
+
+func TestRoot_JSON(t *testing.T) {
+	// JSON(pack Pack, depth int) (js []byte, err error)
+
+	var (
+		pack = getTestPack()
+		r    = getTestQueryRoot(t, pack)
+
+		js  []byte
+		err error
+	)
+
+	if js, err = r.JSON(pack, -1); err != nil {
+		t.Fatal(err)
+	}
+
+	var root struct {
+		Nonce uint64
+		Refs  []struct {
+			Schema string
+			Value  json.RawMessage
+		}
+	}
+
+	if err = json.Unmarshal(js, &root); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(root.Refs) != 3 {
+		t.Fatal("wrong number of Refs:", len(root.Refs))
+	}
+
+	if root.Refs[1].Schema != "test.Arrays" {
+		t.Error("wrong schema:", root.Refs[1].Schema)
+	}
+
+	var group struct {
+		Name    string
+		Members []TestUser
+		Curator TestUser
+	}
+
+	if err = json.Unmarshal(root.Refs[0].Value, &group); err != nil {
+		t.Fatal(err)
+	}
+
+	if group.Name != "the CXO" || len(group.Members) != 10 {
+		t.Error("wrong group:", group.Name, len(group.Members))
+	} else if group.Members[9].Name != "Alice #24" {
+		t.Error("wrong member:", group.Members[9].Name)
+	}
+
+	if group.Curator.Name != "Bob" || group.Curator.Age != 21 {
+		t.Error("wrong curator:", group.Curator)
+	}
+
+}
+
+func TestDecodeValue_nanInf(t *testing.T) {
+
+	var reg = NewRegistry(func(r *Reg) {
+		r.Register("test.Floats", TestFloatsStruct{})
+	})
+
+	var (
+		pack = testPackReg(reg)
+		sch  Schema
+		err  error
+	)
+
+	if sch, err = reg.SchemaByName("test.Floats"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fs := range []TestFloatsStruct{
+		{float32(math.NaN()), math.NaN()},
+		{float32(math.Inf(1)), math.Inf(-1)},
+	} {
+
+		var js []byte
+		if js, err = ValueJSON(pack, sch, encoder.Serialize(&fs), 0); err != nil {
+			t.Fatal(err)
+		}
+
+		var val []byte
+		if val, err = EncodeJSON(pack, sch, js); err != nil {
+			t.Fatal(err)
+		}
+
+		if bytes.Compare(val, encoder.Serialize(&fs)) != 0 {
+			t.Errorf("wrong encoded value of %s", js)
+		}
+
+	}
+
+	if _, err = EncodeJSON(pack, sch, []byte(`{"Float64": "1.5"}`)); err == nil {
+		t.Error("missing error")
+	}
+
+}
+
+func TestDecodeValue_blob(t *testing.T) {
+
+	var reg = NewRegistry(func(r *Reg) {
+		r.Register("test.File", testFile{})
+	})
+
+	var (
+		pack = testPackReg(reg)
+		file = testFile{Name: "file.txt"}
+		data = testBlobData(9, int(MaxDecodedBlobSize)+1)
+
+		sch Schema
+		err error
+	)
+
+	if sch, err = reg.SchemaByName("test.File"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = file.Content.SetBytes(pack, data); err != nil {
+		t.Fatal(err)
+	}
+
+	file.Parts = make([]Blob, 1)
+
+	if err = file.Parts[0].SetBytes(pack, []byte("part")); err != nil {
+		t.Fatal(err)
+	}
+
+	var js []byte
+	if js, err = ValueJSON(pack, sch, encoder.Serialize(&file), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	type blob struct {
+		Hash  string
+		Size  int64
+		Bytes []byte
+	}
+
+	var dec struct {
+		Content blob
+		Parts   []blob
+	}
+
+	if err = json.Unmarshal(js, &dec); err != nil {
+		t.Fatal(err)
+	}
+
+	if dec.Content.Hash != file.Content.Hash.Hex() {
+		t.Error("wrong hash of large Blob")
+	} else if dec.Content.Size != int64(len(data)) {
+		t.Error("wrong size of large Blob:", dec.Content.Size)
+	} else if dec.Content.Bytes != nil {
+		t.Error("content of large Blob")
+	}
+
+	if len(dec.Parts) != 1 {
+		t.Fatal("wrong number of parts:", len(dec.Parts))
+	} else if dec.Parts[0].Size != 4 || string(dec.Parts[0].Bytes) != "part" {
+		t.Error("wrong part:", dec.Parts[0])
+	}
+
+	// back
+
+	var val []byte
+	if val, err = EncodeJSON(pack, sch, js); err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Compare(val, encoder.Serialize(&file)) != 0 {
+		t.Error("wrong encoded value")
+	}
+
+}
+
+func TestDecodeValue_length(t *testing.T) {
+
+	type testUsers struct {
+		Users []TestUser
+	}
+
+	var reg = NewRegistry(func(r *Reg) {
+		r.Register("test.User", TestUser{})
+		r.Register("test.Group", TestGroup{})
+		r.Register("test.Users", testUsers{})
+	})
+
+	var (
+		pack = testPackReg(reg)
+		sch  Schema
+		err  error
+	)
+
+	// slice with huge length
+	if sch, err = reg.SchemaByName("test.Users"); err != nil {
+		t.Fatal(err)
+	}
+
+	sch = sch.Fields()[0].Schema() // []test.User
+
+	var val = encoder.Serialize(uint32(math.MaxUint32))
+
+	if _, err = DecodeValue(pack, sch, val, 0); err == nil {
+		t.Error("missing error")
+	}
+
+	// Refs with huge length
+	if sch, err = reg.SchemaByName("test.Group"); err != nil {
+		t.Fatal(err)
+	}
+
+	var user cipher.SHA256
+	if user, err = pack.Add(encoder.Serialize(&TestUser{Name: "Alice"})); err != nil {
+		t.Fatal(err)
+	}
+
+	var group TestGroup
+
+	group.Members.Hash, err = pack.Add(encoder.Serialize(&encodedRefs{
+		Degree:   uint32(pack.Degree()),
+		Length:   math.MaxUint32,
+		Elements: []cipher.SHA256{user},
+	}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v interface{}
+	if v, err = DecodeValue(pack, sch, encoder.Serialize(&group), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var members = v.(map[string]interface{})["Members"]
+
+	if list, ok := members.([]interface{}); ok == false {
+		t.Errorf("wrong type of Members %T", members)
+	} else if len(list) != 1 {
+		t.Error("wrong number of Members:", len(list))
+	}
+
+}
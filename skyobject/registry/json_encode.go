@@ -0,0 +1,720 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// EncodeValue encodes given generic representation using
+// given Schema. The result is the same as result of the
+// encoder.Serialize of value of Go type of the Schema.
+// The EncodeValue is reverse of the DecodeValue and
+// accepts the same representation. Also, it accepts
+//
+//     json.Number, float64, int, int64 and uint64 for numbers
+//     base64-encoded string                       for []byte and
+//                                                 arrays of bytes
+//
+// A nil represents zero value of the Schema and missing
+// fields of a struct are zero. References can be given by
+// hex-encoded hashes or inline. Inline objects are saved
+// in given Pack. That is
+//
+//     Ref     nil, hash or the object
+//     Refs    nil, hash of the Refs or list of elements,
+//             every element is nil, hash or the object
+//     Dynamic nil or map with "Schema" key and "Hash" or
+//             "Value" key (see DecodeValue)
+//     Blob    nil, hash or map with "Bytes" key that is
+//             base64-encoded content of the Blob, or
+//             with "Hash" key (see DecodeValue)
+//
+// The "Schema" of a Dynamic is name of registered type or
+// hex-encoded SchemaRef
+func EncodeValue(
+	pack Pack, //       : pack to save inline objects
+	sch Schema, //      : schema of the value
+	v interface{}, //   : generic representation
+) (
+	val []byte, //      : encoded value
+	err error, //       : an error
+) {
+
+	if sch.IsReference() == true {
+		return encodeReference(pack, sch, v)
+	}
+
+	switch sch.Kind() {
+
+	case reflect.Bool:
+
+		var x bool
+
+		if v != nil {
+			var ok bool
+			if x, ok = v.(bool); ok == false {
+				return nil, encodeTypeError(sch, v)
+			}
+		}
+
+		return encoder.Serialize(x), nil
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+
+		return encodeInt(sch, v)
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+
+		return encodeUint(sch, v)
+
+	case reflect.Float32, reflect.Float64:
+
+		return encodeFloat(sch, v)
+
+	case reflect.String:
+
+		var x string
+
+		if v != nil {
+			var ok bool
+			if x, ok = v.(string); ok == false {
+				return nil, encodeTypeError(sch, v)
+			}
+		}
+
+		return encoder.Serialize(x), nil
+
+	case reflect.Array, reflect.Slice:
+
+		return encodeArraySlice(pack, sch, v)
+
+	case reflect.Struct:
+
+		return encodeStruct(pack, sch, v)
+
+	}
+
+	return nil, fmt.Errorf("invalid Kind <%s> of Schema %q", sch.Kind(), sch)
+}
+
+// EncodeJSON is EncodeValue that takes JSON
+func EncodeJSON(
+	pack Pack, //  : pack to save inline objects
+	sch Schema, // : schema of the value
+	js []byte, //  : the JSON
+) (
+	val []byte, // : encoded value
+	err error, //  : an error
+) {
+
+	var v interface{}
+	if v, err = unmarshalJSON(js); err != nil {
+		return
+	}
+
+	return EncodeValue(pack, sch, v)
+}
+
+// SetJSON sets Refs and Descriptor fields of the Root
+// from given JSON. The JSON is object like the JSON
+// method of the Root returns. Other fields of the
+// object are ignored. The Refs is list of Dynamic
+// references (see EncodeValue). Inline objects are
+// saved in given Pack
+func (r *Root) SetJSON(pack Pack, js []byte) (err error) {
+
+	var v interface{}
+	if v, err = unmarshalJSON(js); err != nil {
+		return
+	}
+
+	var root, ok = v.(map[string]interface{})
+
+	if ok == false {
+		return fmt.Errorf("expected JSON object for the Root, got %T", v)
+	}
+
+	var refs []Dynamic
+
+	if rv := root["Refs"]; rv != nil {
+
+		var list []interface{}
+
+		if list, ok = rv.([]interface{}); ok == false {
+			return fmt.Errorf("expected list for Refs of the Root, got %T", rv)
+		}
+
+		refs = make([]Dynamic, 0, len(list))
+
+		for i, el := range list {
+
+			var dr Dynamic
+			if dr, err = encodeDynamic(pack, el); err != nil {
+				return fmt.Errorf("Refs[%d]: %v", i, err)
+			}
+
+			refs = append(refs, dr)
+
+		}
+
+	}
+
+	var desc []byte
+
+	if dv := root["Descriptor"]; dv != nil {
+		if desc, err = encodeBytesValue(dv); err != nil {
+			return fmt.Errorf("Descriptor of the Root: %v", err)
+		}
+	}
+
+	r.Refs, r.Descriptor = refs, desc
+	return
+}
+
+// unmarshal JSON keeping numbers as is
+func unmarshalJSON(js []byte) (v interface{}, err error) {
+
+	var dec = json.NewDecoder(bytes.NewReader(js))
+
+	dec.UseNumber()
+
+	if err = dec.Decode(&v); err != nil {
+		return
+	}
+
+	if dec.More() == true {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+
+	return
+}
+
+func encodeTypeError(sch Schema, v interface{}) error {
+	return fmt.Errorf("unexpected value of type %T for %q", v, sch)
+}
+
+func encodeInt(sch Schema, v interface{}) (val []byte, err error) {
+
+	var x int64
+
+	switch y := v.(type) {
+	case nil:
+	case json.Number:
+		if x, err = y.Int64(); err != nil {
+			return
+		}
+	case float64:
+		if x = int64(y); float64(x) != y {
+			return nil, fmt.Errorf("%v is not an integer", y)
+		}
+	case int:
+		x = int64(y)
+	case int64:
+		x = y
+	case uint64:
+		if y > math.MaxInt64 {
+			return nil, fmt.Errorf("%d overflows %q", y, sch)
+		}
+		x = int64(y)
+	default:
+		return nil, encodeTypeError(sch, v)
+	}
+
+	switch sch.Kind() {
+	case reflect.Int8:
+		if x < math.MinInt8 || x > math.MaxInt8 {
+			return nil, fmt.Errorf("%d overflows %q", x, sch)
+		}
+		return encoder.Serialize(int8(x)), nil
+	case reflect.Int16:
+		if x < math.MinInt16 || x > math.MaxInt16 {
+			return nil, fmt.Errorf("%d overflows %q", x, sch)
+		}
+		return encoder.Serialize(int16(x)), nil
+	case reflect.Int32:
+		if x < math.MinInt32 || x > math.MaxInt32 {
+			return nil, fmt.Errorf("%d overflows %q", x, sch)
+		}
+		return encoder.Serialize(int32(x)), nil
+	}
+
+	return encoder.Serialize(x), nil
+}
+
+func encodeUint(sch Schema, v interface{}) (val []byte, err error) {
+
+	var x uint64
+
+	switch y := v.(type) {
+	case nil:
+	case json.Number:
+		if x, err = strconv.ParseUint(y.String(), 10, 64); err != nil {
+			return
+		}
+	case float64:
+		if x = uint64(y); y < 0 || float64(x) != y {
+			return nil, fmt.Errorf("%v is not an unsigned integer", y)
+		}
+	case int:
+		if y < 0 {
+			return nil, fmt.Errorf("%d overflows %q", y, sch)
+		}
+		x = uint64(y)
+	case int64:
+		if y < 0 {
+			return nil, fmt.Errorf("%d overflows %q", y, sch)
+		}
+		x = uint64(y)
+	case uint64:
+		x = y
+	default:
+		return nil, encodeTypeError(sch, v)
+	}
+
+	switch sch.Kind() {
+	case reflect.Uint8:
+		if x > math.MaxUint8 {
+			return nil, fmt.Errorf("%d overflows %q", x, sch)
+		}
+		return encoder.Serialize(uint8(x)), nil
+	case reflect.Uint16:
+		if x > math.MaxUint16 {
+			return nil, fmt.Errorf("%d overflows %q", x, sch)
+		}
+		return encoder.Serialize(uint16(x)), nil
+	case reflect.Uint32:
+		if x > math.MaxUint32 {
+			return nil, fmt.Errorf("%d overflows %q", x, sch)
+		}
+		return encoder.Serialize(uint32(x)), nil
+	}
+
+	return encoder.Serialize(x), nil
+}
+
+func encodeFloat(sch Schema, v interface{}) (val []byte, err error) {
+
+	var x float64
+
+	switch y := v.(type) {
+	case nil:
+	case json.Number:
+		if x, err = y.Float64(); err != nil {
+			return
+		}
+	case float64:
+		x = y
+	case int:
+		x = float64(y)
+	case int64:
+		x = float64(y)
+	case uint64:
+		x = float64(y)
+	case string:
+		// NaN and infinities (see DecodeValue)
+		if x, err = strconv.ParseFloat(y, 64); err != nil ||
+			(math.IsNaN(x) == false && math.IsInf(x, 0) == false) {
+			return nil, encodeTypeError(sch, v)
+		}
+	default:
+		return nil, encodeTypeError(sch, v)
+	}
+
+	if sch.Kind() == reflect.Float32 {
+		return encoder.Serialize(float32(x)), nil
+	}
+
+	return encoder.Serialize(x), nil
+}
+
+// base64-encoded string or []byte
+func encodeBytesValue(v interface{}) (p []byte, err error) {
+
+	switch y := v.(type) {
+	case nil:
+	case []byte:
+		p = y
+	case string:
+		p, err = base64.StdEncoding.DecodeString(y)
+	default:
+		err = fmt.Errorf("expected base64-encoded string, got %T", v)
+	}
+
+	return
+}
+
+func encodeArraySlice(
+	pack Pack, //     : pack to save inline objects
+	sch Schema, //    : schema of the array or slice
+	v interface{}, // : generic representation
+) (
+	val []byte, //    : encoded array or slice
+	err error, //     : an error
+) {
+
+	var el Schema
+
+	if el = sch.Elem(); el == nil {
+		return nil, fmt.Errorf("Schema of element of %q is nil", sch)
+	}
+
+	// []byte and arrays of bytes
+
+	if el.Kind() == reflect.Uint8 && el.IsReference() == false {
+
+		var p []byte
+		if p, err = encodeBytesValue(v); err != nil {
+			return nil, fmt.Errorf("%q: %v", sch, err)
+		}
+
+		if sch.Kind() == reflect.Slice {
+			return encoder.Serialize(p), nil
+		}
+
+		if v == nil {
+			p = make([]byte, sch.Len())
+		}
+
+		if len(p) != sch.Len() {
+			return nil, fmt.Errorf("wrong length %d of %q", len(p), sch)
+		}
+
+		return p, nil
+	}
+
+	var list []interface{}
+
+	if v != nil {
+		var ok bool
+		if list, ok = v.([]interface{}); ok == false {
+			return nil, encodeTypeError(sch, v)
+		}
+	}
+
+	if sch.Kind() == reflect.Array {
+
+		if v == nil {
+			list = make([]interface{}, sch.Len())
+		}
+
+		if len(list) != sch.Len() {
+			return nil, fmt.Errorf("wrong length %d of %q", len(list), sch)
+		}
+
+	} else {
+		val = encoder.Serialize(uint32(len(list)))
+	}
+
+	var ev []byte
+
+	for i, x := range list {
+
+		if ev, err = EncodeValue(pack, el, x); err != nil {
+			return nil, fmt.Errorf("[%d]: %v", i, err)
+		}
+
+		val = append(val, ev...)
+
+	}
+
+	if val == nil {
+		val = []byte{} // empty array
+	}
+
+	return
+}
+
+func encodeStruct(
+	pack Pack, //     : pack to save inline objects
+	sch Schema, //    : schema of the struct
+	v interface{}, // : generic representation
+) (
+	val []byte, //    : encoded struct
+	err error, //     : an error
+) {
+
+	var fields map[string]interface{}
+
+	if v != nil {
+		var ok bool
+		if fields, ok = v.(map[string]interface{}); ok == false {
+			return nil, encodeTypeError(sch, v)
+		}
+	}
+
+	var known = make(map[string]struct{}, len(sch.Fields()))
+
+	val = []byte{}
+
+	for _, fl := range sch.Fields() {
+
+		var fv []byte
+		fv, err = EncodeValue(pack, fl.Schema(), fields[fl.Name()])
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fl.Name(), err)
+		}
+
+		val = append(val, fv...)
+		known[fl.Name()] = struct{}{}
+
+	}
+
+	for name := range fields {
+		if _, ok := known[name]; ok == false {
+			return nil, fmt.Errorf("%s: %v of %q", name, ErrNoSuchField, sch)
+		}
+	}
+
+	return
+}
+
+func encodeReference(
+	pack Pack, //     : pack to save inline objects
+	sch Schema, //    : schema of the reference
+	v interface{}, // : generic representation
+) (
+	val []byte, //    : encoded reference
+	err error, //     : an error
+) {
+
+	var rt = sch.ReferenceType()
+
+	switch rt {
+
+	case ReferenceTypeSingle:
+
+		var el Schema
+		if el = sch.Elem(); el == nil {
+			return nil, ErrInvalidSchema
+		}
+
+		var ref Ref
+		if ref.Hash, err = encodeHash(pack, el, v); err != nil {
+			return
+		}
+
+		return encoder.Serialize(&ref), nil
+
+	case ReferenceTypeSlice:
+
+		var el Schema
+		if el = sch.Elem(); el == nil {
+			return nil, ErrInvalidSchema
+		}
+
+		var refs Refs
+		if err = encodeRefs(pack, el, &refs, v); err != nil {
+			return
+		}
+
+		return encoder.Serialize(&refs), nil
+
+	case ReferenceTypeDynamic:
+
+		var dr Dynamic
+		if dr, err = encodeDynamic(pack, v); err != nil {
+			return
+		}
+
+		return encoder.Serialize(&dr), nil
+
+	case ReferenceTypeBlob:
+
+		var blob Blob
+		if err = encodeBlob(pack, &blob, v); err != nil {
+			return
+		}
+
+		return encoder.Serialize(&blob), nil
+
+	}
+
+	return nil, fmt.Errorf("invalid ReferenceType %d of %q", rt, sch)
+}
+
+// hash or inline object, inline
+// object is saved in given pack
+func encodeHash(
+	pack Pack, //          : pack to save inline object
+	sch Schema, //         : schema of the object
+	v interface{}, //      : hash or the object
+) (
+	hash cipher.SHA256, // : hash of the object
+	err error, //          : an error
+) {
+
+	switch y := v.(type) {
+
+	case nil:
+
+		return
+
+	case string:
+
+		return cipher.SHA256FromHex(y)
+
+	}
+
+	var val []byte
+	if val, err = EncodeValue(pack, sch, v); err != nil {
+		return
+	}
+
+	return pack.Add(val)
+}
+
+func encodeRefs(
+	pack Pack, //     : pack to save inline objects
+	el Schema, //     : schema of elements
+	refs *Refs, //    : the Refs to set
+	v interface{}, // : hash or list of elements
+) (
+	err error, //     : an error
+) {
+
+	switch y := v.(type) {
+
+	case nil:
+
+		return
+
+	case string:
+
+		refs.Hash, err = cipher.SHA256FromHex(y)
+		return
+
+	case []interface{}:
+
+		var hashes = make([]cipher.SHA256, 0, len(y))
+
+		for i, x := range y {
+
+			var hash cipher.SHA256
+			if hash, err = encodeHash(pack, el, x); err != nil {
+				return fmt.Errorf("[%d]: %v", i, err)
+			}
+
+			hashes = append(hashes, hash)
+
+		}
+
+		return refs.AppendHashes(pack, hashes...)
+
+	}
+
+	return fmt.Errorf("unexpected value of type %T for Refs", v)
+}
+
+func encodeDynamic(
+	pack Pack, //     : pack to save inline object
+	v interface{}, // : nil or map
+) (
+	dr Dynamic, //    : the Dynamic
+	err error, //     : an error
+) {
+
+	if v == nil {
+		return // blank
+	}
+
+	var m, ok = v.(map[string]interface{})
+
+	if ok == false {
+		return dr, fmt.Errorf("unexpected value of type %T for Dynamic", v)
+	}
+
+	var name string
+
+	if name, ok = m["Schema"].(string); ok == false {
+		return dr, fmt.Errorf("missing Schema of Dynamic")
+	}
+
+	var reg = pack.Registry()
+
+	if reg == nil {
+		return dr, ErrMissingRegistry
+	}
+
+	var sch Schema
+
+	if sch, err = reg.SchemaByName(name); err != nil {
+
+		var hash cipher.SHA256
+		if hash, err = cipher.SHA256FromHex(name); err != nil {
+			return dr, fmt.Errorf("unknown Schema %q of Dynamic", name)
+		}
+
+		if sch, err = reg.SchemaByReference(SchemaRef(hash)); err != nil {
+			return
+		}
+
+	}
+
+	dr.Schema = sch.Reference()
+
+	if _, ok = m["Value"]; ok == true {
+		dr.Hash, err = encodeHash(pack, sch, m["Value"])
+		return
+	}
+
+	if hv := m["Hash"]; hv != nil {
+
+		var hash string
+
+		if hash, ok = hv.(string); ok == false {
+			return dr, fmt.Errorf("unexpected Hash of type %T of Dynamic", hv)
+		}
+
+		dr.Hash, err = cipher.SHA256FromHex(hash)
+	}
+
+	return
+}
+
+func encodeBlob(
+	pack Pack, //     : pack to save content
+	blob *Blob, //    : the Blob to set
+	v interface{}, // : hash or map
+) (
+	err error, //     : an error
+) {
+
+	switch y := v.(type) {
+
+	case nil:
+
+		return
+
+	case string:
+
+		blob.Hash, err = cipher.SHA256FromHex(y)
+		return
+
+	case map[string]interface{}:
+
+		// expanded Blob without content (see DecodeValue)
+		if hash, ok := y["Hash"].(string); ok == true && y["Bytes"] == nil {
+			blob.Hash, err = cipher.SHA256FromHex(hash)
+			return
+		}
+
+		var p []byte
+		if p, err = encodeBytesValue(y["Bytes"]); err != nil {
+			return fmt.Errorf("Bytes of Blob: %v", err)
+		}
+
+		return blob.SetBytes(pack, p)
+
+	}
+
+	return fmt.Errorf("unexpected value of type %T for Blob", v)
+}
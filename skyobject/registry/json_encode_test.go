@@ -0,0 +1,174 @@
+package registry
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+func TestEncodeJSON(t *testing.T) {
+	// EncodeJSON(pack Pack, sch Schema, js []byte) (val []byte, err error)
+
+	var (
+		pack = getTestPack()
+		reg  = pack.Registry()
+
+		sch Schema
+		val []byte
+		err error
+	)
+
+	if sch, err = reg.SchemaByName("test.Arrays"); err != nil {
+		t.Fatal(err)
+	}
+
+	var arrays = TestArraysStruct{
+		OneInt16:   [1]int16{-16},
+		TwoStrings: [2]string{"one", "two"},
+		Named:      TestNamedArray{10, 11, 12, 13, 14},
+	}
+
+	arrays.FourStringStruct[2].String = "three"
+
+	val, err = EncodeJSON(pack, sch, []byte(`{
+		"OneInt16": [-16],
+		"TwoStrings": ["one", "two"],
+		"FourStringStruct": [null, null, {"String": "three"}, null],
+		"Named": [10, 11, 12, 13, 14]
+	}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Compare(val, encoder.Serialize(&arrays)) != 0 {
+		t.Error("wrong encoded value")
+	}
+
+	// errors
+
+	for _, js := range []string{
+		`{"Named": [1, 2, 3]}`,
+		`{"OneInt16": [40000]}`,
+		`{"OneInt16": [1.5]}`,
+		`{"TwoStrings": [1, 2]}`,
+		`{"Other": 1}`,
+		`[]`,
+		`{} {}`,
+	} {
+		if _, err = EncodeJSON(pack, sch, []byte(js)); err == nil {
+			t.Errorf("%s: missing error", js)
+		}
+	}
+
+	// inline references
+
+	if sch, err = reg.SchemaByName("test.Group"); err != nil {
+		t.Fatal(err)
+	}
+
+	var group = TestGroup{Name: "the CXO"}
+
+	if err = group.Members.AppendValues(pack, getTestUsers(3)...); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = group.Curator.SetValue(pack, TestUser{Name: "Bob", Age: 21}); err != nil {
+		t.Fatal(err)
+	}
+
+	val, err = EncodeJSON(pack, sch, []byte(`{
+		"Name": "the CXO",
+		"Members": [
+			{"Name": "Alice #15", "Age": 0},
+			{"Name": "Alice #16", "Age": 1},
+			{"Name": "Alice #17", "Age": 2}
+		],
+		"Curator": {"Name": "Bob", "Age": 21}
+	}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Compare(val, encoder.Serialize(&group)) != 0 {
+		t.Error("wrong encoded value")
+	}
+
+	// by hash
+
+	val, err = EncodeJSON(pack, sch, []byte(`{
+		"Name": "the CXO",
+		"Members": "`+group.Members.Hash.Hex()+`",
+		"Curator": "`+group.Curator.Hash.Hex()+`"
+	}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Compare(val, encoder.Serialize(&group)) != 0 {
+		t.Error("wrong encoded value")
+	}
+
+}
+
+func TestRoot_SetJSON(t *testing.T) {
+	// SetJSON(pack Pack, js []byte) (err error)
+
+	var (
+		pack = getTestPack()
+		r    = getTestQueryRoot(t, pack)
+
+		js  []byte
+		err error
+	)
+
+	for _, depth := range []int{0, 1, -1} {
+
+		if js, err = r.JSON(pack, depth); err != nil {
+			t.Fatal(err)
+		}
+
+		var x Root
+
+		if err = x.SetJSON(pack, js); err != nil {
+			t.Fatalf("depth %d: %v", depth, err)
+		}
+
+		if len(x.Refs) != len(r.Refs) {
+			t.Fatalf("depth %d: wrong number of Refs %d", depth, len(x.Refs))
+		}
+
+		for i, dr := range x.Refs {
+			if dr != r.Refs[i] {
+				t.Errorf("depth %d: wrong Refs[%d]: %s, want %s", depth, i,
+					dr.Short(), r.Refs[i].Short())
+			}
+		}
+
+	}
+
+	// blank and errors
+
+	var x Root
+
+	if err = x.SetJSON(pack, []byte(`{"Refs": [null]}`)); err != nil {
+		t.Error(err)
+	} else if len(x.Refs) != 1 || x.Refs[0].IsBlank() == false {
+		t.Error("wrong Refs")
+	}
+
+	for _, js := range []string{
+		`[]`,
+		`{"Refs": {}}`,
+		`{"Refs": [{"Schema": "test.Unknown"}]}`,
+		`{"Refs": [{"Schema": "test.User", "Value": {"Age": -1}}]}`,
+	} {
+		if err = x.SetJSON(pack, []byte(js)); err == nil {
+			t.Errorf("%s: missing error", js)
+		}
+	}
+
+}
@@ -0,0 +1,245 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// MultisigPrefix is first byte of a feed that is
+// multisig feed (see Multisig). Compressed public
+// keys starts with 0x02 or 0x03, thus a multisig
+// feed can't be equal to public key
+const MultisigPrefix byte = 'M'
+
+// MaxMultisigKeys is limit of keys of a Multisig
+const MaxMultisigKeys int = 255
+
+// A Multisig represents M-of-N feed. A Root of such
+// feed should be signed by at least M keys of the N.
+// A Multisig feed is a cipher.PubKey that is not a
+// real public key, but prefix (see MultisigPrefix)
+// and hash of encoded Multisig. Thus, the feed can
+// be used everywhere. But to verify signatures of
+// a Root, the Multisig should be known. A Container
+// keeps added Multisig (see AddMultisigFeed method
+// of the skyobject.Container)
+type Multisig struct {
+	M    uint32          // required signatures
+	Keys []cipher.PubKey // all keys (N)
+}
+
+// NewMultisig creates Multisig and validates it.
+// The order of keys matters, because signatures
+// refer to keys by index
+func NewMultisig(m int, keys ...cipher.PubKey) (ms *Multisig, err error) {
+
+	if m < 0 {
+		return nil, errors.New("negative M")
+	}
+
+	ms = &Multisig{M: uint32(m), Keys: keys}
+
+	if err = ms.Validate(); err != nil {
+		ms = nil
+	}
+
+	return
+}
+
+// Validate the Multisig
+func (m *Multisig) Validate() (err error) {
+
+	if len(m.Keys) == 0 {
+		return errors.New("empty list of keys")
+	}
+
+	if len(m.Keys) > MaxMultisigKeys {
+		return fmt.Errorf("too many keys %d, max is %d", len(m.Keys),
+			MaxMultisigKeys)
+	}
+
+	if m.M == 0 || int(m.M) > len(m.Keys) {
+		return fmt.Errorf("invalid M %d of %d keys", m.M, len(m.Keys))
+	}
+
+	var seen = make(map[cipher.PubKey]struct{}, len(m.Keys))
+
+	for _, pk := range m.Keys {
+
+		if err = pk.Verify(); err != nil {
+			return fmt.Errorf("invalid key %s: %v", pk.Hex()[:7], err)
+		}
+
+		if _, ok := seen[pk]; ok == true {
+			return fmt.Errorf("duplicate key %s", pk.Hex()[:7])
+		}
+
+		seen[pk] = struct{}{}
+	}
+
+	return
+}
+
+// Encode the Multisig
+func (m *Multisig) Encode() []byte {
+	return encoder.Serialize(m)
+}
+
+// DecodeMultisig decodes and validates encoded Multisig
+func DecodeMultisig(p []byte) (m *Multisig, err error) {
+
+	m = new(Multisig)
+
+	if _, err = encoder.DeserializeRaw(p, m); err != nil {
+		return nil, err
+	}
+
+	if err = m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// Hash of the encoded Multisig
+func (m *Multisig) Hash() cipher.SHA256 {
+	return cipher.SumSHA256(m.Encode())
+}
+
+// Feed returns feed of the Multisig
+func (m *Multisig) Feed() (feed cipher.PubKey) {
+	var hash = m.Hash()
+	feed[0] = MultisigPrefix
+	copy(feed[1:], hash[:])
+	return
+}
+
+// IsMultisigFeed returns true if given
+// feed is a feed of a Multisig
+func IsMultisigFeed(feed cipher.PubKey) bool {
+	return feed[0] == MultisigPrefix
+}
+
+// MultisigHash returns hash of encoded Multisig
+// of given multisig feed
+func MultisigHash(feed cipher.PubKey) (hash cipher.SHA256, err error) {
+	if IsMultisigFeed(feed) == false {
+		return cipher.SHA256{}, ErrNotMultisigFeed
+	}
+	copy(hash[:], feed[1:])
+	return
+}
+
+// Index returns index of given key
+// in the Keys or -1 if not found
+func (m *Multisig) Index(pk cipher.PubKey) int {
+	for i, k := range m.Keys {
+		if k == pk {
+			return i
+		}
+	}
+	return -1
+}
+
+// Sign given hash of a Root by given secret key,
+// that should be secret key of one of the Keys
+func (m *Multisig) Sign(
+	hash cipher.SHA256,
+	sk cipher.SecKey,
+) (
+	s Signature,
+	err error,
+) {
+
+	var i = m.Index(cipher.PubKeyFromSecKey(sk))
+
+	if i < 0 {
+		return Signature{}, ErrNotMultisigKey
+	}
+
+	s.Key = uint32(i)
+	s.Sig, err = cipher.SignHash(hash, sk)
+	return
+}
+
+// VerifySignature verifies single signature of given hash
+func (m *Multisig) VerifySignature(hash cipher.SHA256, s Signature) error {
+
+	if int(s.Key) >= len(m.Keys) {
+		return ErrNotMultisigKey
+	}
+
+	return cipher.VerifyPubKeySignedHash(m.Keys[s.Key], s.Sig, hash)
+}
+
+// Verify given signatures of given hash. The
+// Verify returns error if there are less then
+// M valid signatures of different keys, or if
+// a signature is invalid
+func (m *Multisig) Verify(hash cipher.SHA256, sigs Signatures) (err error) {
+
+	var signed = make(map[uint32]struct{}, len(sigs))
+
+	for _, s := range sigs {
+
+		if err = m.VerifySignature(hash, s); err != nil {
+			return fmt.Errorf("invalid signature of key %d: %v", s.Key, err)
+		}
+
+		signed[s.Key] = struct{}{}
+	}
+
+	if len(signed) < int(m.M) {
+		return ErrNotEnoughSignatures
+	}
+
+	return
+}
+
+// A Signature is signature of a
+// key of a Multisig
+type Signature struct {
+	Key uint32     // index of the key in the Keys
+	Sig cipher.Sig // signature
+}
+
+// Signatures is signature container of Root
+// of a multisig feed (see Multisig)
+type Signatures []Signature
+
+// Add given signature replacing
+// existing signature of the key
+func (s Signatures) Add(sig Signature) Signatures {
+	for i := range s {
+		if s[i].Key == sig.Key {
+			s[i] = sig
+			return s
+		}
+	}
+	return append(s, sig)
+}
+
+// Encode the Signatures. It returns nil
+// if the Signatures is empty
+func (s Signatures) Encode() []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return encoder.Serialize(s)
+}
+
+// DecodeSignatures decodes encoded Signatures.
+// The DecodeSignatures returns nil, nil for
+// empty input
+func DecodeSignatures(p []byte) (s Signatures, err error) {
+	if len(p) == 0 {
+		return
+	}
+	if _, err = encoder.DeserializeRaw(p, &s); err != nil {
+		s = nil
+	}
+	return
+}
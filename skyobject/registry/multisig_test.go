@@ -0,0 +1,144 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func testMultisig(t *testing.T, m, n int) (ms *Multisig, sks []cipher.SecKey) {
+
+	var pks = make([]cipher.PubKey, n)
+	sks = make([]cipher.SecKey, n)
+
+	for i := range pks {
+		pks[i], sks[i] = cipher.GenerateKeyPair()
+	}
+
+	var err error
+	if ms, err = NewMultisig(m, pks...); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestNewMultisig(t *testing.T) {
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	for _, tt := range []struct {
+		m    int
+		keys []cipher.PubKey
+	}{
+		{1, nil},
+		{0, []cipher.PubKey{pk}},
+		{2, []cipher.PubKey{pk}},
+		{1, []cipher.PubKey{pk, pk}},
+		{1, []cipher.PubKey{{}}},
+	} {
+		if _, err := NewMultisig(tt.m, tt.keys...); err == nil {
+			t.Errorf("missing error: %d of %d", tt.m, len(tt.keys))
+		}
+	}
+
+}
+
+func TestMultisig_Feed(t *testing.T) {
+
+	var ms, _ = testMultisig(t, 2, 3)
+
+	var feed = ms.Feed()
+
+	if IsMultisigFeed(feed) == false {
+		t.Fatal("not a multisig feed")
+	}
+
+	if hash, err := MultisigHash(feed); err != nil {
+		t.Fatal(err)
+	} else if hash != ms.Hash() {
+		t.Error("wrong hash")
+	}
+
+	if IsMultisigFeed(ms.Keys[0]) == true {
+		t.Error("public key is multisig feed")
+	}
+
+	if dm, err := DecodeMultisig(ms.Encode()); err != nil {
+		t.Fatal(err)
+	} else if dm.Feed() != feed {
+		t.Error("wrong decoded Multisig")
+	}
+
+}
+
+func TestMultisig_Verify(t *testing.T) {
+
+	var (
+		ms, sks = testMultisig(t, 2, 3)
+		hash    = cipher.SumSHA256([]byte("root"))
+
+		sigs Signatures
+	)
+
+	for i, sk := range sks[:2] {
+
+		var sig, err = ms.Sign(hash, sk)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sig.Key != uint32(i) {
+			t.Error("wrong key index")
+		}
+
+		sigs = sigs.Add(sig)
+		sigs = sigs.Add(sig) // replace
+
+	}
+
+	if len(sigs) != 2 {
+		t.Fatal("wrong number of signatures", len(sigs))
+	}
+
+	if err := ms.Verify(hash, sigs[:1]); err != ErrNotEnoughSignatures {
+		t.Error("unexpected error:", err)
+	}
+
+	// the same signature twice
+	if err := ms.Verify(hash, Signatures{sigs[0], sigs[0]}); err == nil {
+		t.Error("missing error")
+	}
+
+	if err := ms.Verify(hash, sigs); err != nil {
+		t.Error(err)
+	}
+
+	// encode / decode
+
+	var ds, err = DecodeSignatures(sigs.Encode())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ms.Verify(hash, ds); err != nil {
+		t.Error(err)
+	}
+
+	// wrong hash
+
+	if err = ms.Verify(cipher.SumSHA256([]byte("x")), sigs); err == nil {
+		t.Error("missing error")
+	}
+
+	// not a key of the Multisig
+
+	var _, sk = cipher.GenerateKeyPair()
+
+	if _, err = ms.Sign(hash, sk); err != ErrNotMultisigKey {
+		t.Error("unexpected error:", err)
+	}
+
+}
@@ -0,0 +1,447 @@
+package registry
+
+import (
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A PathProof proves that an object is reachable from
+// a signed Root. The PathProof contains the Root with
+// its signatures, Registry of the Root and encoded
+// objects of the path from the Root to the object:
+// objects, Refs and nodes of the Refs. Thus, a light
+// client can trust an object without the Root tree
+// (see Verify method)
+//
+// The PathProof can't prove reachability through
+// encrypted objects of a private Root, and can't
+// prove that an object is chunk of a Blob
+//
+// Use Encode and DecodePathProof to send a PathProof
+// outside CXO
+type PathProof struct {
+	Root     []byte     // encoded Root
+	Sig      cipher.Sig // signature of the Root
+	Sigs     []byte     // signature container of the Root (see EncodeSigs)
+	Multisig []byte     // encoded Multisig of a multisig feed
+	Registry []byte     // encoded Registry of the Root
+	Path     [][]byte   // encoded objects from the Root to the object
+}
+
+// Encode the PathProof
+func (p *PathProof) Encode() []byte {
+	return encoder.Serialize(p)
+}
+
+// DecodePathProof decodes encoded PathProof.
+// It doesn't verify the proof
+func DecodePathProof(val []byte) (pp *PathProof, err error) {
+
+	pp = new(PathProof)
+
+	if err = deserializeExact(val, pp); err != nil {
+		return nil, ErrInvalidProof
+	}
+
+	return
+}
+
+// Verify the PathProof. The Verify checks signature
+// of the Root and every hash link from the Root to
+// object with given hash. It returns the Root if the
+// PathProof proves that the object is reachable from
+// the Root. The Verify returns signature error or
+// ErrInvalidProof
+//
+// Certificate of the Root (see Certificate) is verified
+// against local clock, but the Verify can't check
+// Revocations, since it requires history of the feed.
+// Multisig field of the PathProof required for Root of
+// a multisig feed
+func (p *PathProof) Verify(hash cipher.SHA256) (r *Root, err error) {
+
+	if len(p.Path) == 0 {
+		return nil, ErrInvalidProof
+	}
+
+	// the Root
+
+	if r, err = DecodeRoot(p.Root); err != nil {
+		return nil, ErrInvalidProof
+	}
+
+	r.Hash = cipher.SumSHA256(p.Root)
+	r.Sig = p.Sig
+
+	if err = r.DecodeSigs(p.Sigs); err != nil {
+		return nil, ErrInvalidProof
+	}
+
+	if err = p.verifyRootSignature(r); err != nil {
+		return nil, err
+	}
+
+	// the Registry
+
+	if cipher.SumSHA256(p.Registry) != cipher.SHA256(r.Reg) {
+		return nil, ErrInvalidProof
+	}
+
+	var reg *Registry
+	if reg, err = DecodeRegistry(p.Registry); err != nil {
+		return nil, ErrInvalidProof
+	}
+
+	// the path
+
+	var links []pathLink
+	if links, err = rootPathLinks(reg, r); err != nil {
+		return nil, ErrInvalidProof
+	}
+
+	var last cipher.SHA256
+
+	for _, val := range p.Path {
+
+		last = cipher.SumSHA256(val)
+
+		var (
+			link  pathLink
+			found bool
+		)
+
+		for _, link = range links {
+			if link.hash == last {
+				found = true
+				break
+			}
+		}
+
+		if found == false {
+			return nil, ErrInvalidProof
+		}
+
+		if links, err = link.links(reg, val); err != nil {
+			return nil, ErrInvalidProof
+		}
+
+	}
+
+	if last != hash {
+		return nil, ErrInvalidProof
+	}
+
+	return
+}
+
+func (p *PathProof) verifyRootSignature(r *Root) (err error) {
+
+	if IsMultisigFeed(r.Pub) == true {
+
+		var ms *Multisig
+		if ms, err = DecodeMultisig(p.Multisig); err != nil {
+			return ErrInvalidProof
+		}
+
+		if ms.Feed() != r.Pub {
+			return ErrInvalidProof
+		}
+
+		return ms.Verify(r.Hash, r.Sigs)
+	}
+
+	var signer = r.Pub
+
+	if r.Cert != nil {
+
+		if err = r.Cert.Allows(r, time.Now()); err != nil {
+			return
+		}
+
+		signer = r.Cert.Key
+	}
+
+	return cipher.VerifyPubKeySignedHash(signer, r.Sig, r.SigHash())
+}
+
+// NewPathProof creates PathProof of object with given
+// hash of given Root. The Root must be signed and the
+// Pack must have Registry of the Root. The Multisig
+// required for Root of a multisig feed and should be
+// nil otherwise. The NewPathProof walks the Root tree
+// to find the object and returns ErrNotFound if the
+// object is not reachable from the Root
+func NewPathProof(
+	pack Pack, //          : pack to get
+	r *Root, //            : the signed Root
+	ms *Multisig, //       : Multisig of a multisig feed or nil
+	hash cipher.SHA256, // : hash of the object
+) (
+	pp *PathProof, //      : the proof
+	err error, //          : an error
+) {
+
+	var reg = pack.Registry()
+
+	if reg == nil {
+		return nil, ErrMissingRegistry
+	}
+
+	var links []pathLink
+	if links, err = rootPathLinks(reg, r); err != nil {
+		return
+	}
+
+	var (
+		visited = make(map[cipher.SHA256]struct{})
+		path    [][]byte // reversed
+
+		find func(links []pathLink) (found bool, err error)
+	)
+
+	find = func(links []pathLink) (found bool, err error) {
+
+		for _, link := range links {
+
+			if _, ok := visited[link.hash]; ok == true {
+				continue
+			}
+
+			visited[link.hash] = struct{}{}
+
+			var val []byte
+			if val, err = pack.Get(link.hash); err != nil {
+				return
+			}
+
+			if link.hash != hash {
+
+				var next []pathLink
+				if next, err = link.links(reg, val); err != nil {
+					return
+				}
+
+				if found, err = find(next); err != nil {
+					return
+				}
+
+				if found == false {
+					continue
+				}
+
+			}
+
+			path = append(path, val)
+			return true, nil
+
+		}
+
+		return
+	}
+
+	var found bool
+	if found, err = find(links); err != nil {
+		return
+	}
+
+	if found == false {
+		return nil, ErrNotFound
+	}
+
+	pp = &PathProof{
+		Root:     r.Encode(),
+		Sig:      r.Sig,
+		Sigs:     r.EncodeSigs(),
+		Registry: reg.Encode(),
+	}
+
+	if ms != nil {
+		pp.Multisig = ms.Encode()
+	}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		pp.Path = append(pp.Path, path[i])
+	}
+
+	return
+}
+
+// kind of pathLink
+type pathLinkKind int
+
+// kinds of pathLink
+const (
+	pathLinkObject   pathLinkKind = iota // an object
+	pathLinkRefs                         // a Refs
+	pathLinkRefsNode                     // a node of a Refs
+)
+
+// a link of a PathProof
+type pathLink struct {
+	hash  cipher.SHA256 // hash of the object
+	kind  pathLinkKind  // kind of the object
+	sch   Schema        // schema of the object or elements of a Refs
+	depth int           // depth of a Refs node
+}
+
+// links of given Root
+func rootPathLinks(reg *Registry, r *Root) (links []pathLink, err error) {
+
+	for _, dr := range r.Refs {
+
+		var link pathLink
+		if link, err = dynamicPathLink(reg, dr); err != nil {
+			return
+		}
+
+		if link.hash != (cipher.SHA256{}) {
+			links = append(links, link)
+		}
+
+	}
+
+	return
+}
+
+func dynamicPathLink(reg *Registry, dr Dynamic) (link pathLink, err error) {
+
+	if dr.IsValid() == false {
+		return link, ErrInvalidDynamicReference
+	}
+
+	if dr.Hash == (cipher.SHA256{}) {
+		return // blank
+	}
+
+	link.hash = dr.Hash
+
+	if link.sch, err = reg.SchemaByReference(dr.Schema); err != nil {
+		return
+	}
+
+	return
+}
+
+// links of object of the link by its encoded value
+func (p *pathLink) links(reg *Registry, val []byte) (links []pathLink, err error) {
+
+	switch p.kind {
+
+	case pathLinkObject:
+
+		// can't go through a sealed object and
+		// through an object without references
+		if IsSealed(val) == true || p.sch.HasReferences() == false {
+			return
+		}
+
+		err = rangeReferences(p.sch, val, func(rs Schema, rv []byte) (err error) {
+
+			var link pathLink
+
+			switch rs.ReferenceType() {
+
+			case ReferenceTypeSingle:
+
+				var ref Ref
+				if err = deserializeExact(rv, &ref); err != nil {
+					return
+				}
+
+				link = pathLink{hash: ref.Hash, sch: rs.Elem()}
+
+			case ReferenceTypeSlice:
+
+				var refs Refs
+				if err = deserializeExact(rv, &refs); err != nil {
+					return
+				}
+
+				link = pathLink{hash: refs.Hash, kind: pathLinkRefs,
+					sch: rs.Elem()}
+
+			case ReferenceTypeDynamic:
+
+				var dr Dynamic
+				if err = deserializeExact(rv, &dr); err != nil {
+					return
+				}
+
+				if link, err = dynamicPathLink(reg, dr); err != nil {
+					return
+				}
+
+			default:
+
+				return // Blob
+
+			}
+
+			if link.sch == nil {
+				return ErrInvalidSchema
+			}
+
+			if link.hash != (cipher.SHA256{}) {
+				links = append(links, link)
+			}
+
+			return
+		})
+
+		return
+
+	case pathLinkRefs:
+
+		var er encodedRefs
+		if err = deserializeExact(val, &er); err != nil {
+			return
+		}
+
+		return p.elementLinks(er.Elements, int(er.Depth)), nil
+
+	case pathLinkRefsNode:
+
+		var ern encodedRefsNode
+		if err = deserializeExact(val, &ern); err != nil {
+			return
+		}
+
+		return p.elementLinks(ern.Elements, p.depth), nil
+
+	}
+
+	return nil, ErrInvalidProof
+}
+
+// links of elements of a Refs or a node of Refs
+func (p *pathLink) elementLinks(
+	elements []cipher.SHA256, // : elements of the node
+	depth int, //                : depth of the node
+) (
+	links []pathLink, //         : links
+) {
+
+	for _, hash := range elements {
+
+		if hash == (cipher.SHA256{}) {
+			continue
+		}
+
+		if depth == 0 {
+			links = append(links, pathLink{hash: hash, sch: p.sch})
+			continue
+		}
+
+		links = append(links, pathLink{
+			hash:  hash,
+			kind:  pathLinkRefsNode,
+			sch:   p.sch,
+			depth: depth - 1,
+		})
+
+	}
+
+	return
+}
@@ -0,0 +1,555 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/aes"
+	gocipher "crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// Private feeds
+//
+// Objects of a private Root are encrypted by a content key
+// (see ContentKey). The content key is distributed inside
+// Descriptor of the Root wrapped for every reader (see
+// SetPrivate and ContentKey method of the Root). The Refs
+// of encrypted objects (the Refs tree) are not encrypted,
+// since the Refs tree contains hashes only.
+//
+// An encrypted object (a sealed object) carries lists of
+// hashes it refers to (objects, Refs and Blobs). Thus, a node can walk and fill a
+// private Root without the content key, checking hashes
+// of received objects as usual. The list leaks structure
+// of the tree, but not content.
+//
+// The Ref, Refs and Dynamic seal objects if given Pack
+// implements the PrivatePack and has a content key. The
+// PrivatePack should open sealed objects returning them
+// decrypted (see Open method of the ContentKey)
+
+// private feeds related constants
+const (
+	ContentKeySize int = 32 // AES-256
+
+	sealedPrefix  = "\x00cxo:sealed\x00"
+	privatePrefix = "\x00cxo:private\x00"
+)
+
+// A ContentKey represents key used to encrypt
+// objects of a private Root
+type ContentKey [ContentKeySize]byte
+
+// NewContentKey generates random ContentKey
+func NewContentKey() (key ContentKey) {
+	if _, err := rand.Read(key[:]); err != nil {
+		panic(err) // crypto/rand is broken
+	}
+	return
+}
+
+// A PrivatePack is a Pack of a private Root. If the
+// ContentKey method returns true, then the Ref, Refs
+// and Dynamic seal objects they save
+type PrivatePack interface {
+	Pack
+	ContentKey() (key ContentKey, ok bool)
+}
+
+// a sealed object
+type sealed struct {
+	Objects []cipher.SHA256 // sealed objects the object refers to
+	Refs    []cipher.SHA256 // Refs (of sealed objects) the object contains
+	Blobs   []cipher.SHA256 // Blobs the object contains
+	Data    []byte          // nonce + encrypted object
+}
+
+// additional data of the sealed
+func (s *sealed) ad() []byte {
+	return encoder.Serialize(sealed{
+		Objects: s.Objects,
+		Refs:    s.Refs,
+		Blobs:   s.Blobs,
+	})
+}
+
+func (s *sealed) encode() []byte {
+	return append([]byte(sealedPrefix), encoder.Serialize(s)...)
+}
+
+func decodeSealed(val []byte) (s *sealed, err error) {
+
+	if bytes.HasPrefix(val, []byte(sealedPrefix)) == false {
+		return nil, ErrNotSealed
+	}
+
+	s = new(sealed)
+
+	if err = deserializeExact(val[len(sealedPrefix):], s); err != nil {
+		return nil, ErrNotSealed
+	}
+
+	return
+}
+
+// decode given value to given pointer, the
+// value must not have unused bytes
+func deserializeExact(val []byte, obj interface{}) (err error) {
+
+	var n, derr = encoder.DeserializeRaw(val, obj)
+
+	if derr != nil {
+		return derr
+	}
+
+	if int(n) != len(val) {
+		return ErrInvalidSchemaOrData
+	}
+
+	return
+}
+
+// IsSealed returns true if given value is a sealed
+// (encrypted) object of a private Root
+func IsSealed(val []byte) (ok bool) {
+	_, err := decodeSealed(val)
+	return err == nil
+}
+
+func newAEAD(key []byte) (aead gocipher.AEAD) {
+
+	var (
+		block gocipher.Block
+		err   error
+	)
+
+	if block, err = aes.NewCipher(key); err != nil {
+		panic(err) // never happens, the key is 32 bytes long
+	}
+
+	if aead, err = gocipher.NewGCM(block); err != nil {
+		panic(err) // never happens
+	}
+
+	return
+}
+
+// seal with random nonce, the result is nonce + encrypted
+func sealRandom(aead gocipher.AEAD, val, ad []byte) (sealed []byte) {
+
+	var nonce = make([]byte, aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err) // crypto/rand is broken
+	}
+
+	return aead.Seal(nonce, nonce, val, ad)
+}
+
+// open value sealed by the sealRandom
+func openSealed(aead gocipher.AEAD, sealed, ad []byte) (val []byte, err error) {
+
+	var ns = aead.NonceSize()
+
+	if len(sealed) < ns {
+		return nil, ErrCantOpen
+	}
+
+	if val, err = aead.Open(nil, sealed[:ns], sealed[ns:], ad); err != nil {
+		return nil, ErrCantOpen
+	}
+
+	return
+}
+
+// seal given encoded object with given references; the nonce
+// is derived from the key and the object, thus the same
+// object sealed by the same key has the same hash
+func (k *ContentKey) seal(
+	val []byte, //                         : encoded object
+	objs, refs, blobs []cipher.SHA256, // : references
+) []byte {
+
+	var (
+		aead = newAEAD(k[:])
+		mac  = hmac.New(sha256.New, k[:])
+		s    = sealed{Objects: objs, Refs: refs, Blobs: blobs}
+	)
+
+	mac.Write(val)
+
+	var nonce = mac.Sum(nil)[:aead.NonceSize()]
+
+	s.Data = aead.Seal(nonce, nonce, val, s.ad())
+	return s.encode()
+}
+
+// Open sealed object returning encoded object. The
+// Open returns ErrNotSealed if given value is not
+// sealed and ErrCantOpen if the value is sealed by
+// another key
+func (k *ContentKey) Open(val []byte) (obj []byte, err error) {
+
+	var s *sealed
+	if s, err = decodeSealed(val); err != nil {
+		return
+	}
+
+	return openSealed(newAEAD(k[:]), s.Data, s.ad())
+}
+
+// content key of given Pack if the Pack is
+// a PrivatePack with content key
+func contentKey(pack Pack) (key ContentKey, ok bool) {
+
+	var pp PrivatePack
+	if pp, ok = pack.(PrivatePack); ok == false {
+		return
+	}
+
+	return pp.ContentKey()
+}
+
+// add encoded object to given Pack sealing it if
+// the Pack is a PrivatePack with content key
+func add(pack Pack, obj interface{}) (hash cipher.SHA256, err error) {
+
+	var val = encoder.Serialize(obj)
+
+	var key, ok = contentKey(pack)
+	if ok == false {
+		return pack.Add(val)
+	}
+
+	var objs, refs, blobs []cipher.SHA256
+	if objs, refs, blobs, err = references(pack, obj, val); err != nil {
+		return
+	}
+
+	return pack.Add(key.seal(val, objs, refs, blobs))
+}
+
+// references of given object (not deep) using Schema of the
+// object; the objs are targets of Ref and Dynamic, the refs
+// are Refs and the blobs are Blobs
+func references(
+	pack Pack, //       : pack to load Refs
+	obj interface{}, // : the object
+	val []byte, //      : encoded object
+) (
+	objs []cipher.SHA256, // : Ref and Dynamic targets
+	refs []cipher.SHA256, //  : Refs
+	blobs []cipher.SHA256, // : Blobs
+	err error, //             : an error
+) {
+
+	var reg = pack.Registry()
+
+	if reg == nil {
+		return nil, nil, nil, ErrMissingRegistry
+	}
+
+	var name string
+	if name, err = reg.Types().SchemaName(obj); err != nil {
+		return
+	}
+
+	var sch Schema
+	if sch, err = reg.SchemaByName(name); err != nil {
+		return
+	}
+
+	if sch.HasReferences() == false {
+		return // leaf
+	}
+
+	err = rangeReferences(sch, val, func(rs Schema, rv []byte) (err error) {
+
+		var (
+			hash cipher.SHA256
+			list *[]cipher.SHA256
+		)
+
+		switch rt := rs.ReferenceType(); rt {
+		case ReferenceTypeSingle:
+			var ref Ref
+			_, err = encoder.DeserializeRaw(rv, &ref)
+			hash, list = ref.Hash, &objs
+		case ReferenceTypeDynamic:
+			var dr Dynamic
+			_, err = encoder.DeserializeRaw(rv, &dr)
+			hash, list = dr.Hash, &objs
+		case ReferenceTypeSlice:
+			var r Refs
+			_, err = encoder.DeserializeRaw(rv, &r)
+			hash, list = r.Hash, &refs
+		case ReferenceTypeBlob:
+			var b Blob
+			_, err = encoder.DeserializeRaw(rv, &b)
+			hash, list = b.Hash, &blobs
+		default:
+			return fmt.Errorf("invalid ReferenceType %d to seal", rt)
+		}
+
+		if err == nil && hash != (cipher.SHA256{}) {
+			*list = append(*list, hash)
+		}
+
+		return
+	})
+
+	return
+}
+
+// elements of Refs of sealed objects
+type sealedSchema struct {
+	schema
+}
+
+func (*sealedSchema) HasReferences() bool {
+	return true // unknown
+}
+
+// Schema of objects referenced by a sealed object
+var sealedObjectSchema Schema = &sealedSchema{
+	schema{kind: reflect.Invalid, name: []byte("<sealed>")},
+}
+
+// walk a sealed object
+func walkSealed(pack Pack, val []byte, walkFunc WalkFunc) (err error) {
+
+	var s *sealed
+	if s, err = decodeSealed(val); err != nil {
+		return
+	}
+
+	for _, hash := range s.Objects {
+		var ref = Ref{Hash: hash}
+		if err = ref.Walk(pack, sealedObjectSchema, walkFunc); err != nil {
+			return
+		}
+	}
+
+	for _, hash := range s.Refs {
+		var refs = Refs{Hash: hash}
+		if err = refs.Walk(pack, sealedObjectSchema, walkFunc); err != nil {
+			return
+		}
+	}
+
+	for _, hash := range s.Blobs {
+		var blob = Blob{Hash: hash}
+		if err = blob.Walk(pack, walkFunc); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// split a sealed object
+func splitSealed(s Splitter, val []byte) {
+
+	var sd, err = decodeSealed(val)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	for _, hash := range sd.Objects {
+		splitSchemaHashAsync(s, sealedObjectSchema, hash)
+	}
+
+	for _, hash := range sd.Refs {
+		var refs = Refs{Hash: hash}
+		refs.Split(s, sealedObjectSchema)
+	}
+
+	for _, hash := range sd.Blobs {
+		var blob = Blob{Hash: hash}
+		blob.Split(s)
+	}
+
+}
+
+// a content key wrapped for a reader
+type wrappedKey struct {
+	Reader cipher.PubKey
+	Key    []byte
+}
+
+// Descriptor of a private Root
+type privateDescriptor struct {
+	Ephemeral cipher.PubKey // ephemeral key
+	Readers   []wrappedKey  // content key for every reader
+	Data      []byte        // sealed Descriptor data
+}
+
+func (p *privateDescriptor) encode() []byte {
+	return append([]byte(privatePrefix), encoder.Serialize(p)...)
+}
+
+func decodePrivateDescriptor(
+	descriptor []byte,
+) (
+	p *privateDescriptor,
+	err error,
+) {
+
+	if bytes.HasPrefix(descriptor, []byte(privatePrefix)) == false {
+		return nil, ErrNotPrivate
+	}
+
+	p = new(privateDescriptor)
+
+	if err = deserializeExact(descriptor[len(privatePrefix):], p); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// key encryption key
+func wrappingAEAD(pub cipher.PubKey, sec cipher.SecKey) gocipher.AEAD {
+	return newAEAD(cipher.ECDH(pub, sec))
+}
+
+// SetPrivate makes the Root private. The SetPrivate replaces
+// Descriptor of the Root with given content key wrapped for
+// every given reader and with given data encrypted. The data
+// is optional and can be obtained by readers using the
+// PrivateData method. Add the feed to the readers, to read
+// the Root using the feed key. The SetPrivate should be
+// called before the Root is saved. Objects of the Root
+// should be sealed by the same content key. Keep the
+// content key between Root objects of a feed, since
+// sealed objects can be reused by the next Root objects
+func (r *Root) SetPrivate(
+	key ContentKey, //          : the content key
+	readers []cipher.PubKey, // : readers
+	data []byte, //             : Descriptor data
+) (
+	err error, //               : an error
+) {
+
+	for _, pk := range readers {
+		if err = pk.Verify(); err != nil {
+			return
+		}
+	}
+
+	var (
+		eph, esk = cipher.GenerateKeyPair()
+		p        = privateDescriptor{Ephemeral: eph}
+	)
+
+	p.Readers = make([]wrappedKey, 0, len(readers))
+
+	for _, pk := range readers {
+		p.Readers = append(p.Readers, wrappedKey{
+			Reader: pk,
+			Key:    sealRandom(wrappingAEAD(pk, esk), key[:], pk[:]),
+		})
+	}
+
+	if len(data) != 0 {
+		p.Data = sealRandom(newAEAD(key[:]), data, eph[:])
+	}
+
+	r.Descriptor = p.encode()
+	return
+}
+
+// IsPrivate returns true if the Root is private
+// (see SetPrivate)
+func (r *Root) IsPrivate() bool {
+	return bytes.HasPrefix(r.Descriptor, []byte(privatePrefix))
+}
+
+// Readers of the private Root. It returns
+// ErrNotPrivate if the Root is not private
+func (r *Root) Readers() (readers []cipher.PubKey, err error) {
+
+	var p *privateDescriptor
+	if p, err = decodePrivateDescriptor(r.Descriptor); err != nil {
+		return
+	}
+
+	readers = make([]cipher.PubKey, 0, len(p.Readers))
+
+	for _, wk := range p.Readers {
+		readers = append(readers, wk.Reader)
+	}
+
+	return
+}
+
+// ContentKey returns content key of the private Root
+// for reader with given secret key. It returns
+// ErrNotPrivate if the Root is not private and
+// ErrNotReader if the key is not a key of a reader
+func (r *Root) ContentKey(sk cipher.SecKey) (key ContentKey, err error) {
+
+	var p *privateDescriptor
+	if p, err = decodePrivateDescriptor(r.Descriptor); err != nil {
+		return
+	}
+
+	if err = sk.Verify(); err != nil {
+		return
+	}
+
+	if err = p.Ephemeral.Verify(); err != nil {
+		return
+	}
+
+	var pk = cipher.PubKeyFromSecKey(sk)
+
+	for _, wk := range p.Readers {
+
+		if wk.Reader != pk {
+			continue
+		}
+
+		var val []byte
+		val, err = openSealed(wrappingAEAD(p.Ephemeral, sk), wk.Key, pk[:])
+
+		if err != nil {
+			return
+		}
+
+		if len(val) != ContentKeySize {
+			err = ErrCantOpen
+			return
+		}
+
+		copy(key[:], val)
+		return
+	}
+
+	err = ErrNotReader
+	return
+}
+
+// PrivateData returns data of Descriptor of the private
+// Root (see SetPrivate). Use the ContentKey method to get
+// the key
+func (r *Root) PrivateData(key ContentKey) (data []byte, err error) {
+
+	var p *privateDescriptor
+	if p, err = decodePrivateDescriptor(r.Descriptor); err != nil {
+		return
+	}
+
+	if len(p.Data) == 0 {
+		return
+	}
+
+	return openSealed(newAEAD(key[:]), p.Data, p.Ephemeral[:])
+}
@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestRoot_SetPrivate(t *testing.T) {
+
+	var (
+		apk, ask = cipher.GenerateKeyPair()
+		bpk, bsk = cipher.GenerateKeyPair()
+		_, esk   = cipher.GenerateKeyPair()
+		key      = NewContentKey()
+		r        = new(Root)
+	)
+
+	if r.IsPrivate() == true {
+		t.Error("blank Root is private")
+	}
+
+	if _, err := r.ContentKey(ask); err != ErrNotPrivate {
+		t.Error("wrong error:", err)
+	}
+
+	if err := r.SetPrivate(key, []cipher.PubKey{{}}, nil); err == nil {
+		t.Error("missing error")
+	}
+
+	var err = r.SetPrivate(key, []cipher.PubKey{apk, bpk}, []byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.IsPrivate() == false {
+		t.Fatal("not private")
+	}
+
+	var readers []cipher.PubKey
+	if readers, err = r.Readers(); err != nil {
+		t.Fatal(err)
+	} else if len(readers) != 2 || readers[0] != apk || readers[1] != bpk {
+		t.Error("wrong readers")
+	}
+
+	for _, sk := range []cipher.SecKey{ask, bsk} {
+		if got, err := r.ContentKey(sk); err != nil {
+			t.Error(err)
+		} else if got != key {
+			t.Error("wrong content key")
+		}
+	}
+
+	if _, err = r.ContentKey(esk); err != ErrNotReader {
+		t.Error("wrong error:", err)
+	}
+
+	var data []byte
+	if data, err = r.PrivateData(key); err != nil {
+		t.Error(err)
+	} else if string(data) != "data" {
+		t.Error("wrong data")
+	}
+
+	if _, err = r.PrivateData(NewContentKey()); err != ErrCantOpen {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestContentKey_Open(t *testing.T) {
+
+	var (
+		key = NewContentKey()
+		val = []byte("secret")
+		obj = []cipher.SHA256{cipher.SumSHA256([]byte("obj"))}
+	)
+
+	var sv = key.seal(val, obj, nil, nil)
+
+	if IsSealed(sv) == false {
+		t.Fatal("not sealed")
+	}
+
+	if IsSealed(val) == true {
+		t.Error("plain value is sealed")
+	}
+
+	if string(key.seal(val, obj, nil, nil)) != string(sv) {
+		t.Error("not deterministic")
+	}
+
+	if got, err := key.Open(sv); err != nil {
+		t.Error(err)
+	} else if string(got) != string(val) {
+		t.Error("wrong value")
+	}
+
+	var wrong = NewContentKey()
+	if _, err := wrong.Open(sv); err != ErrCantOpen {
+		t.Error("wrong error:", err)
+	}
+
+	if _, err := key.Open(val); err != ErrNotSealed {
+		t.Error("wrong error:", err)
+	}
+
+	if s, err := decodeSealed(sv); err != nil {
+		t.Error(err)
+	} else if len(s.Objects) != 1 || s.Objects[0] != obj[0] {
+		t.Error("wrong references")
+	}
+
+}
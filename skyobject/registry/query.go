@@ -0,0 +1,459 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/DiSiqueira/GoTree"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// A PathError occurs when a path (see Get method
+// of the Root) can't be parsed or resolved. The
+// Step is element of the path that causes the error
+type PathError struct {
+	Path string // the path
+	Step string // failed step
+	Err  error  // reason
+}
+
+// Error implements error interface
+func (p *PathError) Error() string {
+	if p.Step == "" {
+		return fmt.Sprintf("path %q: %v", p.Path, p.Err)
+	}
+	return fmt.Sprintf("path %q, step %q: %v", p.Path, p.Step, p.Err)
+}
+
+// a step of a path
+type pathStep struct {
+	field string // name of a field
+	index int    // index, if the field is blank
+	raw   string // for errors
+}
+
+// parsePath parses path like "Refs[0].Posts[-1].Body"
+func parsePath(path string) (steps []pathStep, err error) {
+
+	var (
+		rest = path
+		step pathStep
+	)
+
+	for i := 0; rest != ""; i++ {
+
+		switch {
+
+		case rest[0] == '[':
+
+			var end = strings.IndexByte(rest, ']')
+
+			if end < 0 {
+				return nil, &PathError{path, rest, ErrInvalidPath}
+			}
+
+			step.raw = rest[:end+1]
+
+			if step.index, err = strconv.Atoi(rest[1:end]); err != nil {
+				return nil, &PathError{path, step.raw, ErrInvalidPath}
+			}
+
+			step.field = ""
+			rest = rest[end+1:]
+
+		case rest[0] == '.' && i > 0, i == 0:
+
+			if i > 0 {
+				rest = rest[1:]
+			}
+
+			var end = strings.IndexAny(rest, ".[")
+
+			if end < 0 {
+				end = len(rest)
+			}
+
+			if step.field = rest[:end]; isPathIdent(step.field) == false {
+				return nil, &PathError{path, step.field, ErrInvalidPath}
+			}
+
+			step.raw = step.field
+			rest = rest[end:]
+
+		default:
+
+			return nil, &PathError{path, rest, ErrInvalidPath}
+
+		}
+
+		steps = append(steps, step)
+
+	}
+
+	if len(steps) < 2 || steps[0].field != "Refs" || steps[1].field != "" {
+		return nil, &PathError{path, "", ErrInvalidPath}
+	}
+
+	return
+}
+
+func isPathIdent(s string) bool {
+
+	if s == "" {
+		return false
+	}
+
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// Get value by path. The path starts with element of
+// Refs field of the Root and continues with names of
+// fields and indices of arrays, slices and Refs. A
+// negative index means index from the end. For example
+//
+//     Refs[0].Members[3].Name
+//     Refs[1].Posts[-1]
+//
+// References (Ref and Dynamic) are resolved on the way
+// and at the end of the path. Thus, the Get never returns
+// Ref or Dynamic, but can return Refs or Blob. The Get
+// returns Schema and encoded value. Use ValueByPath to
+// decode the value or DataTree to print it. The Get
+// returns *PathError if the path is invalid or can't
+// be resolved
+func (r *Root) Get(
+	pack Pack, //   : pack to get
+	path string, // : the path
+) (
+	sch Schema, //  : schema of the value
+	val []byte, //  : encoded value
+	err error, //   : an error
+) {
+
+	var steps []pathStep
+	if steps, err = parsePath(path); err != nil {
+		return
+	}
+
+	var reg = pack.Registry()
+
+	if reg == nil {
+		return nil, nil, ErrMissingRegistry
+	}
+
+	// Refs of the Root
+
+	var i = steps[1].index
+
+	if i < 0 {
+		i += len(r.Refs)
+	}
+
+	if i < 0 || i >= len(r.Refs) {
+		return nil, nil, &PathError{path, steps[1].raw, ErrIndexOutOfRange}
+	}
+
+	if sch, val, err = dynamicPathValue(pack, r.Refs[i]); err != nil {
+		return nil, nil, &PathError{path, steps[1].raw, err}
+	}
+
+	for _, step := range steps[2:] {
+
+		if sch, val, err = pathValue(pack, sch, val, step); err != nil {
+			return nil, nil, &PathError{path, step.raw, err}
+		}
+
+	}
+
+	return
+}
+
+// ValueByPath is Get that decodes the value to given
+// object. The object must be a pointer to value of
+// type the path points to
+func (r *Root) ValueByPath(
+	pack Pack, //       : pack to get
+	path string, //     : the path
+	obj interface{}, // : pointer to decode to
+) (
+	err error, //       : an error
+) {
+
+	var val []byte
+	if _, val, err = r.Get(pack, path); err != nil {
+		return
+	}
+
+	return deserializeExact(val, obj)
+}
+
+// DataTree returns tree of given encoded value
+// like the Tree method of the Root does
+func DataTree(pack Pack, sch Schema, val []byte) (tree string) {
+	return gotree.StringTree(rootTreeData(pack, sch, val))
+}
+
+// resolve a step of a path
+func pathValue(
+	pack Pack, //     : pack to get
+	sch Schema, //    : schema of current value
+	val []byte, //    : current value
+	step pathStep, // : the step
+) (
+	vs Schema, //     : schema of the value
+	vv []byte, //     : the value
+	err error, //     : an error
+) {
+
+	if step.field != "" {
+		return pathField(pack, sch, val, step.field)
+	}
+
+	return pathIndex(pack, sch, val, step.index)
+}
+
+func pathField(
+	pack Pack, //   : pack to get
+	sch Schema, //  : schema of a struct
+	val []byte, //  : encoded struct
+	name string, // : name of field
+) (
+	vs Schema, //   : schema of the field
+	vv []byte, //   : value of the field
+	err error, //   : an error
+) {
+
+	if sch.Kind() != reflect.Struct || sch.IsReference() == true {
+		return nil, nil, fmt.Errorf("%s is not a struct", sch)
+	}
+
+	var shift, s int
+
+	for _, fl := range sch.Fields() {
+
+		if shift > len(val) {
+			return nil, nil, ErrInvalidSchemaOrData
+		}
+
+		if s, err = fl.Schema().Size(val[shift:]); err != nil {
+			return
+		}
+
+		if fl.Name() == name {
+			return derefPathValue(pack, fl.Schema(), val[shift:shift+s])
+		}
+
+		shift += s
+
+	}
+
+	return nil, nil, ErrNoSuchField
+}
+
+func pathIndex(
+	pack Pack, //  : pack to get
+	sch Schema, // : schema of array, slice or Refs
+	val []byte, // : encoded array, slice or Refs
+	i int, //      : index
+) (
+	vs Schema, //  : schema of the element
+	vv []byte, //  : value of the element
+	err error, //  : an error
+) {
+
+	if sch.IsReference() == true {
+
+		if sch.ReferenceType() != ReferenceTypeSlice {
+			return nil, nil, fmt.Errorf("can't index %s", sch)
+		}
+
+		return refsPathIndex(pack, sch, val, i)
+	}
+
+	var ln, shift int
+
+	switch sch.Kind() {
+	case reflect.Array:
+		ln = sch.Len()
+	case reflect.Slice:
+		if ln, err = getLength(val); err != nil {
+			return
+		}
+		shift = 4
+	default:
+		return nil, nil, fmt.Errorf("can't index %s", sch)
+	}
+
+	var el = sch.Elem()
+
+	if el == nil {
+		return nil, nil, ErrInvalidSchema
+	}
+
+	if i < 0 {
+		i += ln
+	}
+
+	if i < 0 || i >= ln {
+		return nil, nil, ErrIndexOutOfRange
+	}
+
+	var s int
+
+	for k := 0; ; k++ {
+
+		if shift > len(val) {
+			return nil, nil, ErrInvalidSchemaOrData
+		}
+
+		if s, err = el.Size(val[shift:]); err != nil {
+			return
+		}
+
+		if k == i {
+			return derefPathValue(pack, el, val[shift:shift+s])
+		}
+
+		shift += s
+
+	}
+
+}
+
+func refsPathIndex(
+	pack Pack, //  : pack to get
+	sch Schema, // : schema of the Refs
+	val []byte, // : encoded Refs
+	i int, //      : index
+) (
+	vs Schema, //  : schema of the element
+	vv []byte, //  : value of the element
+	err error, //  : an error
+) {
+
+	if vs = sch.Elem(); vs == nil {
+		return nil, nil, ErrInvalidSchema
+	}
+
+	var refs Refs
+	if err = deserializeExact(val, &refs); err != nil {
+		return
+	}
+
+	if i < 0 {
+
+		var ln int
+		if ln, err = refs.Len(pack); err != nil {
+			return
+		}
+
+		i += ln
+	}
+
+	var hash cipher.SHA256
+	if hash, err = refs.HashByIndex(pack, i); err != nil {
+		return
+	}
+
+	if hash == (cipher.SHA256{}) {
+		return nil, nil, ErrRefsElementIsNil
+	}
+
+	if vv, err = pack.Get(hash); err != nil {
+		return
+	}
+
+	return derefPathValue(pack, vs, vv)
+}
+
+// resolve Ref and Dynamic
+func derefPathValue(
+	pack Pack, //  : pack to get
+	sch Schema, // : schema of the value
+	val []byte, // : the value
+) (
+	vs Schema, //  : schema of the value or referenced value
+	vv []byte, //  : the value or referenced value
+	err error, //  : an error
+) {
+
+	if sch.IsReference() == false {
+		return sch, val, nil
+	}
+
+	switch sch.ReferenceType() {
+
+	case ReferenceTypeSingle:
+
+		var ref Ref
+		if err = deserializeExact(val, &ref); err != nil {
+			return
+		}
+
+		if ref.IsBlank() == true {
+			return nil, nil, ErrReferenceRepresentsNil
+		}
+
+		if vs = sch.Elem(); vs == nil {
+			return nil, nil, ErrInvalidSchema
+		}
+
+		if vv, err = pack.Get(ref.Hash); err != nil {
+			return
+		}
+
+		return derefPathValue(pack, vs, vv)
+
+	case ReferenceTypeDynamic:
+
+		var dr Dynamic
+		if err = deserializeExact(val, &dr); err != nil {
+			return
+		}
+
+		return dynamicPathValue(pack, dr)
+
+	}
+
+	return sch, val, nil // Refs or Blob
+}
+
+func dynamicPathValue(
+	pack Pack, //  : pack to get
+	dr Dynamic, // : the Dynamic
+) (
+	vs Schema, //  : schema of referenced value
+	vv []byte, //  : referenced value
+	err error, //  : an error
+) {
+
+	if dr.IsValid() == false {
+		return nil, nil, ErrInvalidDynamicReference
+	}
+
+	if dr.Hash == (cipher.SHA256{}) {
+		return nil, nil, ErrReferenceRepresentsNil
+	}
+
+	if vs, err = pack.Registry().SchemaByReference(dr.Schema); err != nil {
+		return
+	}
+
+	if vv, err = pack.Get(dr.Hash); err != nil {
+		return
+	}
+
+	return derefPathValue(pack, vs, vv)
+}
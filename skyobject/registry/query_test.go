@@ -0,0 +1,193 @@
+package registry
+
+import (
+	"testing"
+)
+
+func testQueryDynamic(
+	t *testing.T, //    : the testing
+	pack Pack, //       : pack to save
+	name string, //     : name of registered type
+	obj interface{}, // : the object
+) (
+	dr Dynamic, //      : dynamic reference to the object
+) {
+
+	var (
+		sch Schema
+		err error
+	)
+
+	if sch, err = pack.Registry().SchemaByName(name); err != nil {
+		t.Fatal(err)
+	}
+
+	dr.Schema = sch.Reference()
+
+	if err = dr.SetValue(pack, obj); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func getTestQueryRoot(t *testing.T, pack Pack) (r *Root) {
+
+	var (
+		group = TestGroup{Name: "the CXO"}
+		err   error
+	)
+
+	if err = group.Members.AppendValues(pack, getTestUsers(10)...); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = group.Curator.SetValue(pack, TestUser{Name: "Bob", Age: 21}); err != nil {
+		t.Fatal(err)
+	}
+
+	group.Developer = testQueryDynamic(t, pack, "test.Man",
+		TestMan{"kostyarin", "logrusorgru"})
+
+	r = new(Root)
+	r.Refs = []Dynamic{
+		testQueryDynamic(t, pack, "test.Group", group),
+		testQueryDynamic(t, pack, "test.Arrays", TestArraysStruct{
+			TwoStrings: [2]string{"one", "two"},
+			Named:      TestNamedArray{10, 11, 12, 13, 14},
+		}),
+		testQueryDynamic(t, pack, "test.Group", TestGroup{Name: "empty"}),
+	}
+
+	return
+}
+
+func TestRoot_Get(t *testing.T) {
+	// Get(pack Pack, path string) (sch Schema, val []byte, err error)
+
+	var (
+		pack = getTestPack()
+		r    = getTestQueryRoot(t, pack)
+	)
+
+	for _, tc := range []struct {
+		path string
+		sch  string
+	}{
+		{"Refs[0]", "test.Group"},
+		{"Refs[0].Name", "string"},
+		{"Refs[0].Members", "[]*test.User"},
+		{"Refs[0].Members[3]", "test.User"},
+		{"Refs[0].Curator", "test.User"},
+		{"Refs[0].Developer", "test.Man"},
+		{"Refs[1].Named[2]", "int32"},
+		{"Refs[-1]", "test.Group"},
+	} {
+
+		var sch, _, err = r.Get(pack, tc.path)
+
+		if err != nil {
+			t.Errorf("%s: %v", tc.path, err)
+			continue
+		}
+
+		if sch.String() != tc.sch && sch.Name() != tc.sch {
+			t.Errorf("%s: wrong schema %s, want %s", tc.path, sch, tc.sch)
+		}
+
+	}
+
+}
+
+func TestRoot_ValueByPath(t *testing.T) {
+	// ValueByPath(pack Pack, path string, obj interface{}) (err error)
+
+	var (
+		pack = getTestPack()
+		r    = getTestQueryRoot(t, pack)
+
+		str string
+		num uint32
+		usr TestUser
+		err error
+	)
+
+	for _, tc := range []struct {
+		path string
+		want string
+	}{
+		{"Refs[0].Name", "the CXO"},
+		{"Refs[0].Members[3].Name", "Alice #18"},
+		{"Refs[0].Members[-1].Name", "Alice #24"},
+		{"Refs[0].Curator.Name", "Bob"},
+		{"Refs[0].Developer.GitHub", "logrusorgru"},
+		{"Refs[1].TwoStrings[-1]", "two"},
+		{"Refs[-1].Name", "empty"},
+	} {
+
+		if err = r.ValueByPath(pack, tc.path, &str); err != nil {
+			t.Errorf("%s: %v", tc.path, err)
+		} else if str != tc.want {
+			t.Errorf("%s: wrong value %q, want %q", tc.path, str, tc.want)
+		}
+
+	}
+
+	if err = r.ValueByPath(pack, "Refs[0].Members[7].Age", &num); err != nil {
+		t.Error(err)
+	} else if num != 7 {
+		t.Error("wrong age:", num)
+	}
+
+	if err = r.ValueByPath(pack, "Refs[0].Members[7]", &usr); err != nil {
+		t.Error(err)
+	} else if usr.Name != "Alice #22" {
+		t.Error("wrong user:", usr.Name)
+	}
+
+	// errors
+
+	for _, tc := range []struct {
+		path string
+		err  error
+	}{
+		{"", ErrInvalidPath},
+		{"Refs", ErrInvalidPath},
+		{"Refs.Name", ErrInvalidPath},
+		{"Other[0]", ErrInvalidPath},
+		{"Refs[0]..Name", ErrInvalidPath},
+		{"Refs[0]Name", ErrInvalidPath},
+		{"Refs[x]", ErrInvalidPath},
+		{"Refs[0].Name[", ErrInvalidPath},
+		{"Refs[3]", ErrIndexOutOfRange},
+		{"Refs[-4]", ErrIndexOutOfRange},
+		{"Refs[0].Members[10]", ErrIndexOutOfRange},
+		{"Refs[1].TwoStrings[2]", ErrIndexOutOfRange},
+		{"Refs[0].Other", ErrNoSuchField},
+		{"Refs[2].Curator", ErrReferenceRepresentsNil},
+		{"Refs[2].Developer", ErrReferenceRepresentsNil},
+	} {
+
+		err = r.ValueByPath(pack, tc.path, &str)
+
+		if pe, ok := err.(*PathError); ok == false {
+			t.Errorf("%s: unexpected error: %v", tc.path, err)
+		} else if pe.Err != tc.err {
+			t.Errorf("%s: wrong error: %v, want %v", tc.path, pe.Err, tc.err)
+		}
+
+	}
+
+	// can't index or get field
+
+	for _, path := range []string{
+		"Refs[0].Name[0]",
+		"Refs[0].Name.Other",
+		"Refs[0].Curator[0]",
+	} {
+		if err = r.ValueByPath(pack, path, &str); err == nil {
+			t.Errorf("%s: missing error", path)
+		}
+	}
+
+}
@@ -64,7 +64,7 @@ func (r *Ref) SetValue(
 	}
 
 	var hash cipher.SHA256
-	if hash, err = pack.Add(encoder.Serialize(obj)); err != nil {
+	if hash, err = add(pack, obj); err != nil {
 		return
 	}
 
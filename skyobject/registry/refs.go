@@ -652,7 +652,7 @@ func (r *Refs) SetValueByIndex(
 	var hash cipher.SHA256
 
 	if isNil(obj) == false {
-		if hash, err = pack.Add(encoder.Serialize(obj)); err != nil {
+		if hash, err = add(pack, obj); err != nil {
 			return
 		}
 	}
@@ -1491,27 +1491,9 @@ func (r *Refs) AppendValues(
 		return // short curcit (nothing to append)
 	}
 
-	var (
-		hashes = make([]cipher.SHA256, 0, len(values)) //
-		hash   cipher.SHA256                           // current
-	)
-
-	for _, val := range values {
-
-		if isNil(val) == true {
-
-			hash = cipher.SHA256{}
-
-		} else {
-
-			if hash, err = pack.Add(encoder.Serialize(val)); err != nil {
-				return
-			}
-
-		}
-
-		hashes = append(hashes, hash)
-
+	var hashes []cipher.SHA256
+	if hashes, err = addValues(pack, values); err != nil {
+		return
 	}
 
 	return r.AppendHashes(pack, hashes...)
@@ -1600,6 +1582,16 @@ func (r *Refs) AppendHashes(
 
 	// ok, here we have enough place to fit all new elements
 
+	// the appending updates hashes of nodes it goes through
+	// clearing their contentMod flag; thus, unsaved changes
+	// (LazyUpdating) should be saved first, otherwise they
+	// will be lost for the walkUpdating
+	if r.mods&contentMod != 0 {
+		if err = r.walkUpdating(pack); err != nil {
+			return
+		}
+	}
+
 	var af, fini = r.appnedFunc(pack) // the func
 
 	for i, hash := range hashes {
@@ -0,0 +1,436 @@
+package registry
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+//
+// insert
+//
+
+// InsertByIndex inserts given hashes to the Refs before element
+// with given index shifting elements after. The index can be equal
+// to length of the Refs, in this case the hashes will be appended.
+// The hashes must point to objects of schema of the Refs. There are
+// no internal checks for the Schema.
+//
+// The InsertByIndex splits full nodes the hashes inserted to and
+// updates hashes of modified nodes only. If LazyUpdating flag is
+// set, then the hashes are not updated until Rebuild.
+//
+// The big O of the call is O(m * depth), where m is number of
+// hashes to insert
+func (r *Refs) InsertByIndex(
+	pack Pack, //               : pack to load and save
+	i int, //                   : index to insert before
+	hashes ...cipher.SHA256, // : hashes to insert
+) (
+	err error, //               : error if any
+) {
+
+	if err = r.initialize(pack); err != nil {
+		return
+	}
+
+	if i < 0 || i > r.length {
+		return ErrIndexOutOfRange
+	}
+
+	if len(hashes) == 0 {
+		return // short curcit (nothing to insert)
+	}
+
+	if i == r.length {
+		return r.AppendHashes(pack, hashes...)
+	}
+
+	if err = r.insertHashes(pack, i, hashes); err != nil {
+		return
+	}
+
+	r.rewindIterators() // for iterators
+	return
+}
+
+// InsertValues saves given values and inserts them to the Refs
+// before element with given index. The values must be of schema
+// of the Refs. There are no internal checks for the schema. Use
+// nil for blank hash. See InsertByIndex for details
+func (r *Refs) InsertValues(
+	pack Pack, //             : pack to load and save
+	i int, //                 : index to insert before
+	values ...interface{}, // : values to insert
+) (
+	err error, //             : error if any
+) {
+
+	var hashes []cipher.SHA256
+	if hashes, err = addValues(pack, values); err != nil {
+		return
+	}
+
+	return r.InsertByIndex(pack, i, hashes...)
+}
+
+// Splice replaces elements of the Refs from i (inclusive) to
+// j (exclusive) with given values. The i and j are like
+// golang [i:j]. The values must be of schema of the Refs. There
+// are no internal checks for the schema. Use nil for blank hash.
+// See SpliceHashes for details
+func (r *Refs) Splice(
+	pack Pack, //             : pack to load and save
+	i int, //                 : start of the range (inclusive)
+	j int, //                 : end of the range (exclusive)
+	values ...interface{}, // : values to insert
+) (
+	err error, //             : error if any
+) {
+
+	var hashes []cipher.SHA256
+	if hashes, err = addValues(pack, values); err != nil {
+		return
+	}
+
+	return r.SpliceHashes(pack, i, j, hashes...)
+}
+
+// SpliceHashes replaces elements of the Refs from i (inclusive)
+// to j (exclusive) with given hashes. The i and j are like
+// golang [i:j]. Thus, the SpliceHashes can insert (i == j),
+// delete (no hashes) and replace elements. For example
+//
+//     refs.SpliceHashes(pack, 2, 2, a, b) // insert a and b before 2
+//     refs.SpliceHashes(pack, 2, 4)       // delete elements 2 and 3
+//     refs.SpliceHashes(pack, 2, 3, a, b) // replace 2 with a and b
+//
+// The SpliceHashes replaces hashes of elements in place first,
+// and then deletes or inserts the rest. Thus, only affected
+// branches of the Refs are changed. If LazyUpdating flag is set,
+// then hashes of the branches are not updated until Rebuild.
+//
+// The big O of the call is O(m * depth), where m is
+// max(j - i, len(hashes))
+func (r *Refs) SpliceHashes(
+	pack Pack, //               : pack to load and save
+	i int, //                   : start of the range (inclusive)
+	j int, //                   : end of the range (exclusive)
+	hashes ...cipher.SHA256, // : hashes to insert
+) (
+	err error, //               : error if any
+) {
+
+	if err = r.initialize(pack); err != nil {
+		return
+	}
+
+	if err = validateSliceIndices(i, j, r.length); err != nil {
+		return
+	}
+
+	// (1) replace
+
+	var n = j - i
+
+	if n > len(hashes) {
+		n = len(hashes)
+	}
+
+	for k := 0; k < n; k++ {
+
+		var el *refsElement
+		el, err = r.elementByIndex(pack, r.refsNode, i+k, r.depth)
+		if err != nil {
+			return
+		}
+
+		if err = r.setElementHash(pack, el, hashes[k]); err != nil {
+			return
+		}
+
+	}
+
+	// (2) delete
+
+	if j-i > n {
+
+		for k := n; k < j-i; k++ {
+			err = r.deleteElementByIndex(pack, r.refsNode, i+n, r.depth)
+			if err != nil {
+				return
+			}
+		}
+
+		err = r.updateHashIfNeed(pack, r.flags&LazyUpdating == 0)
+		if err != nil {
+			return
+		}
+
+		r.rewindIterators() // for iterators
+		return
+
+	}
+
+	// (3) insert
+
+	if len(hashes) > n {
+		return r.InsertByIndex(pack, i+n, hashes[n:]...)
+	}
+
+	return
+}
+
+// addValues saves given values returning their hashes,
+// blank hash for nil
+func addValues(
+	pack Pack, //              : pack to save
+	values []interface{}, //   : values to save
+) (
+	hashes []cipher.SHA256, // : hashes of the values
+	err error, //              : an error
+) {
+
+	hashes = make([]cipher.SHA256, 0, len(values))
+
+	for _, val := range values {
+
+		var hash cipher.SHA256
+
+		if isNil(val) == false {
+			if hash, err = add(pack, val); err != nil {
+				return
+			}
+		}
+
+		hashes = append(hashes, hash)
+
+	}
+
+	return
+}
+
+// insertHashes inserts given hashes to the Refs before
+// element with given index; the index is less than length
+// of the Refs; the insertHashes marks all modified nodes
+// and updates them in the end if the Refs is not lazy
+func (r *Refs) insertHashes(
+	pack Pack, //             : pack to load and save
+	i int, //                 : index to insert before
+	hashes []cipher.SHA256, // : hashes to insert
+) (
+	err error, //             : error if any
+) {
+
+	for k, hash := range hashes {
+		if err = r.insertElement(pack, i+k, hash); err != nil {
+			return
+		}
+	}
+
+	if r.flags&LazyUpdating != 0 {
+		return // the nodes are marked as modified
+	}
+
+	return r.walkUpdating(pack)
+}
+
+// insertElement inserts element before element with
+// given index, the index is less than length of the
+// Refs; the insertElement doesn't update hashes, but
+// marks all modified nodes
+func (r *Refs) insertElement(
+	pack Pack, //          : pack to load
+	i int, //              : index to insert before
+	hash cipher.SHA256, // : hash to insert
+) (
+	err error, //          : error if any
+) {
+
+	// find node with leafs that contains the i-th element
+
+	var rn, depth = r.refsNode, r.depth
+
+	for ; depth > 0; depth-- {
+
+		var br *refsNode
+		for _, br = range rn.branches {
+
+			if err = r.loadNodeIfNeed(pack, br, depth-1); err != nil {
+				return
+			}
+
+			if i >= br.length {
+				i -= br.length // subtract length of the skipped branch
+				continue       // and skip the branch
+			}
+
+			break // the branch that contains the needle has been found
+		}
+
+		rn = br
+	}
+
+	if i >= len(rn.leafs) {
+		return ErrInvalidRefs // invalid state
+	}
+
+	var el = &refsElement{
+		Hash:  hash,
+		upper: rn,
+	}
+
+	if r.flags&HashTableIndex != 0 {
+		r.addElementToIndex(el)
+	}
+
+	rn.leafs = append(rn.leafs, nil)
+	copy(rn.leafs[i+1:], rn.leafs[i:])
+	rn.leafs[i] = el
+
+	// increase length of the node and all upper nodes
+	// (including the Refs) and mark them as modified
+
+	for up := rn; up != nil; up = up.upper {
+		up.length++
+		up.mods |= contentMod
+	}
+
+	// split full nodes going up
+
+	for up := rn; up != nil; up, depth = up.upper, depth+1 {
+
+		if up.fanout(depth) <= int(r.degree) {
+			break // the rest is not overflowed
+		}
+
+		if err = r.splitOverflowed(pack, up, depth); err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+// number of leafs or branches of the node
+func (r *refsNode) fanout(depth int) int {
+	if depth == 0 {
+		return len(r.leafs)
+	}
+	return len(r.branches)
+}
+
+// splitOverflowed splits overflowed node to two nodes; if
+// the node is the Refs (the root), then the splitOverflowed
+// increases depth of the Refs
+func (r *Refs) splitOverflowed(
+	pack Pack, //    : pack to load
+	rn *refsNode, // : the overflowed node
+	depth int, //    : depth of the node
+) (
+	err error, //    : error if any
+) {
+
+	var (
+		half = rn.fanout(depth) / 2
+		tail = &refsNode{mods: loadedMod | contentMod}
+	)
+
+	if depth == 0 {
+
+		tail.leafs = append([]*refsElement{}, rn.leafs[half:]...)
+		rn.leafs = rn.leafs[:half:half]
+
+		for _, el := range tail.leafs {
+			el.upper = tail
+		}
+
+	} else {
+
+		tail.branches = append([]*refsNode{}, rn.branches[half:]...)
+		rn.branches = rn.branches[:half:half]
+
+		for _, br := range tail.branches {
+			// the length is required
+			if err = r.loadNodeIfNeed(pack, br, depth-1); err != nil {
+				return
+			}
+			br.upper = tail
+		}
+
+	}
+
+	tail.length = tail.countLength(depth)
+
+	if rn.upper == nil {
+
+		// the rn is the Refs, move its first half
+		// to new node and increase depth
+
+		var head = &refsNode{
+			length:   rn.length - tail.length,
+			mods:     loadedMod | contentMod,
+			leafs:    rn.leafs,
+			branches: rn.branches,
+			upper:    rn,
+		}
+
+		for _, el := range head.leafs {
+			el.upper = head
+		}
+
+		for _, br := range head.branches {
+			br.upper = head
+		}
+
+		tail.upper = rn
+
+		rn.leafs = nil
+		rn.branches = []*refsNode{head, tail}
+		rn.mods |= contentMod
+
+		r.depth++
+		return
+
+	}
+
+	// insert the tail after the rn
+
+	var up = rn.upper
+
+	rn.length -= tail.length
+	rn.mods |= contentMod
+	tail.upper = up
+
+	var k int
+	for k = 0; k < len(up.branches); k++ {
+		if up.branches[k] == rn {
+			break
+		}
+	}
+
+	if k == len(up.branches) {
+		return ErrInvalidRefs // invalid state
+	}
+
+	up.branches = append(up.branches, nil)
+	copy(up.branches[k+2:], up.branches[k+1:])
+	up.branches[k+1] = tail
+	up.mods |= contentMod
+
+	return
+}
+
+// length of the node by its leafs or branches,
+// the branches must be loaded
+func (r *refsNode) countLength(depth int) (length int) {
+
+	if depth == 0 {
+		return len(r.leafs)
+	}
+
+	for _, br := range r.branches {
+		length += br.length
+	}
+
+	return
+}
@@ -0,0 +1,406 @@
+package registry
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// check elements of the Refs, its hash-table index and
+// saved state of the Refs
+func testRefsElements(
+	t *testing.T, //           : the testing
+	r *Refs, //                : the Refs
+	pack Pack, //              : the Pack
+	want []cipher.SHA256, //   : expected elements
+) {
+
+	var check = func(r *Refs) {
+
+		var (
+			ln  int
+			h   cipher.SHA256
+			err error
+		)
+
+		if ln, err = r.Len(pack); err != nil {
+			t.Error(err)
+			return
+		} else if ln != len(want) {
+			t.Errorf("wrong length %d, want %d", ln, len(want))
+			return
+		}
+
+		for i, hash := range want {
+			if h, err = r.HashByIndex(pack, i); err != nil {
+				t.Errorf("i %d: %s", i, err.Error())
+				return
+			} else if h != hash {
+				t.Errorf("wrong hash of %d: %s, want %s", i, h.Hex()[:7],
+					hash.Hex()[:7])
+				return
+			}
+		}
+
+		if r.flags&HashTableIndex != 0 && len(want) > 0 {
+			testRefsHashTableIndex(t, r, want)
+		}
+
+	}
+
+	check(r)
+
+	if t.Failed() == true {
+		logRefsTree(t, r, pack, false)
+		return
+	}
+
+	if r.flags&LazyUpdating == 0 {
+		// saved without Rebuild
+		check(&Refs{Hash: r.Hash})
+	}
+
+	if err := r.Rebuild(pack); err != nil {
+		t.Error(err)
+		return
+	}
+
+	check(&Refs{Hash: r.Hash})
+
+	if t.Failed() == true {
+		logRefsTree(t, r, pack, true)
+	}
+
+}
+
+// insert into a copy of a slice
+func testInsertHashes(
+	hashes []cipher.SHA256,
+	i int,
+	ins ...cipher.SHA256,
+) (
+	result []cipher.SHA256,
+) {
+
+	result = append(result, hashes[:i]...)
+	result = append(result, ins...)
+	return append(result, hashes[i:]...)
+}
+
+// generate n new hashes
+func testNewHashes(seed, n int) (hashes []cipher.SHA256) {
+	for k := 0; k < n; k++ {
+		hashes = append(hashes, hashByNumber(uint64(seed+k+1)<<32))
+	}
+	return
+}
+
+func TestRefs_InsertByIndex(t *testing.T) {
+	// InsertByIndex(pack Pack, i int, hashes ...cipher.SHA256) (err error)
+
+	var (
+		pack = getTestPack()
+
+		users []cipher.SHA256
+		want  []cipher.SHA256
+
+		r   Refs
+		err error
+	)
+
+	for _, flags := range testRefsFlags() {
+
+		pack.ClearFlags(^0)
+		pack.AddFlags(flags)
+
+		for _, degree := range testRefsDegrees(pack) {
+
+			for _, length := range testRefsLengths(degree) {
+
+				t.Run(fmt.Sprintf("%08b:%d:%d", flags, degree, length),
+					func(t *testing.T) {
+
+						users = getHashList(getTestUsers(length))
+
+						clearRefs(t, &r, pack, degree)
+
+						if err = r.AppendHashes(pack, users...); err != nil {
+							t.Fatal(err)
+						}
+
+						var hash = hashByNumber(1)
+
+						if err = r.InsertByIndex(pack, -1, hash); err != ErrIndexOutOfRange {
+							t.Error("wrong error:", err)
+						}
+
+						if err = r.InsertByIndex(pack, length+1, hash); err != ErrIndexOutOfRange {
+							t.Error("wrong error:", err)
+						}
+
+						// one by one to different positions
+
+						want = users
+
+						for i := 0; i <= len(want) && t.Failed() == false; i += 3 {
+
+							var ins = testNewHashes(i, 1)
+
+							if err = r.InsertByIndex(pack, i, ins...); err != nil {
+								t.Fatal(err)
+							}
+
+							want = testInsertHashes(want, i, ins...)
+							testRefsElements(t, &r, pack, want)
+
+						}
+
+						// many to the middle (overflow many nodes)
+
+						var (
+							i   = len(want) / 2
+							ins = testNewHashes(10*length, int(degree*degree)+1)
+						)
+
+						r.Reset() // unloaded
+
+						if err = r.InsertByIndex(pack, i, ins...); err != nil {
+							t.Fatal(err)
+						}
+
+						want = testInsertHashes(want, i, ins...)
+						testRefsElements(t, &r, pack, want)
+
+					})
+
+			}
+
+		}
+
+	}
+
+}
+
+// append after insert (the Refs has non-full nodes)
+func TestRefs_InsertByIndex_append(t *testing.T) {
+
+	var (
+		pack = getTestPack()
+
+		want []cipher.SHA256
+
+		r   Refs
+		err error
+	)
+
+	for _, flags := range testRefsFlags() {
+
+		pack.ClearFlags(^0)
+		pack.AddFlags(flags)
+
+		for _, degree := range testRefsDegrees(pack) {
+
+			for seed := int64(0); seed < 10; seed++ {
+
+				t.Run(fmt.Sprintf("%08b:%d:%d", flags, degree, seed),
+					func(t *testing.T) {
+
+						var rnd = rand.New(rand.NewSource(seed))
+
+						want = nil
+						clearRefs(t, &r, pack, degree)
+
+						for k := 0; k < 12; k++ {
+
+							var hashes = testNewHashes(100*k, 1+rnd.Intn(10))
+
+							if len(want) == 0 || rnd.Intn(2) == 0 {
+
+								if err = r.AppendHashes(pack, hashes...); err != nil {
+									t.Fatal(err)
+								}
+
+								want = append(want, hashes...)
+
+							} else {
+
+								var i = rnd.Intn(len(want) + 1)
+
+								if err = r.InsertByIndex(pack, i, hashes...); err != nil {
+									t.Fatal(err)
+								}
+
+								want = testInsertHashes(want, i, hashes...)
+
+							}
+
+							testRefsElements(t, &r, pack, want)
+
+							if t.Failed() == true {
+								t.Fatalf("step %d", k)
+							}
+
+						}
+
+					})
+
+			}
+
+		}
+
+	}
+
+}
+
+func TestRefs_InsertValues(t *testing.T) {
+	// InsertValues(pack Pack, i int, values ...interface{}) (err error)
+
+	var (
+		pack  = getTestPack()
+		users = getTestUsers(5)
+
+		r   Refs
+		err error
+	)
+
+	clearRefs(t, &r, pack, 2)
+
+	if err = r.AppendValues(pack, users[0], users[4]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = r.InsertValues(pack, 1, users[1], nil, users[3]); err != nil {
+		t.Fatal(err)
+	}
+
+	var want = getHashList(users)
+	want[2] = cipher.SHA256{}
+
+	testRefsElements(t, &r, pack, want)
+
+}
+
+func TestRefs_SpliceHashes(t *testing.T) {
+	// SpliceHashes(pack Pack, i, j int, hashes ...cipher.SHA256) (err error)
+
+	var (
+		pack = getTestPack()
+
+		users []cipher.SHA256
+		want  []cipher.SHA256
+
+		r   Refs
+		err error
+	)
+
+	for _, flags := range testRefsFlags() {
+
+		pack.ClearFlags(^0)
+		pack.AddFlags(flags)
+
+		for _, degree := range testRefsDegrees(pack) {
+
+			for _, length := range testRefsLengths(degree) {
+
+				t.Run(fmt.Sprintf("%08b:%d:%d", flags, degree, length),
+					func(t *testing.T) {
+
+						users = getHashList(getTestUsers(length))
+
+						clearRefs(t, &r, pack, degree)
+
+						if err = r.AppendHashes(pack, users...); err != nil {
+							t.Fatal(err)
+						}
+
+						if err = r.SpliceHashes(pack, 1, 0); err != ErrInvalidSliceIndex {
+							t.Error("wrong error:", err)
+						}
+
+						if err = r.SpliceHashes(pack, 0, length+1); err != ErrIndexOutOfRange {
+							t.Error("wrong error:", err)
+						}
+
+						want = users
+
+						for k, tc := range []struct {
+							i, j, n int
+						}{
+							{0, 0, 0},                // nothing
+							{0, 1, 1},                // replace
+							{1, 1, 2},                // insert
+							{0, 2, 3},                // replace and insert
+							{1, 4, 1},                // replace and delete
+							{2, 3, 0},                // delete
+							{0, 0, int(degree) + 1},  // insert many
+							{1, 1 + int(degree), 0},  // delete many
+							{0, -1, 2 * int(degree)}, // replace all
+						} {
+
+							if tc.j < 0 {
+								tc.j = len(want)
+							}
+
+							if tc.j > len(want) {
+								continue
+							}
+
+							var ins = testNewHashes(100*k, tc.n)
+
+							if err = r.SpliceHashes(pack, tc.i, tc.j, ins...); err != nil {
+								t.Fatal(err)
+							}
+
+							want = testInsertHashes(append(want[:tc.i:tc.i],
+								want[tc.j:]...), tc.i, ins...)
+							testRefsElements(t, &r, pack, want)
+
+							if t.Failed() == true {
+								t.Fatalf("case %d: %v", k, tc)
+							}
+
+						}
+
+						// delete all
+						if err = r.SpliceHashes(pack, 0, len(want)); err != nil {
+							t.Fatal(err)
+						}
+
+						testRefsElements(t, &r, pack, nil)
+
+					})
+
+			}
+
+		}
+
+	}
+
+}
+
+func TestRefs_Splice(t *testing.T) {
+	// Splice(pack Pack, i, j int, values ...interface{}) (err error)
+
+	var (
+		pack  = getTestPack()
+		users = getTestUsers(4)
+
+		r   Refs
+		err error
+	)
+
+	clearRefs(t, &r, pack, 2)
+
+	if err = r.AppendValues(pack, users[0], users[0], users[3]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = r.Splice(pack, 1, 2, users[1], users[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	testRefsElements(t, &r, pack, getHashList(users))
+
+}
@@ -206,8 +206,11 @@ func (r *Refs) loadNodeIfNeed(
 
 // encode a the refsNode as is
 func (r *refsNode) encode(depth int) []byte {
+	return encoder.Serialize(r.encodedNode(depth))
+}
 
-	var ern encodedRefsNode
+// encodedRefsNode of the refsNode
+func (r *refsNode) encodedNode(depth int) (ern encodedRefsNode) {
 
 	ern.Length = uint32(r.length)
 
@@ -227,7 +230,7 @@ func (r *refsNode) encode(depth int) []byte {
 
 	}
 
-	return encoder.Serialize(ern)
+	return
 }
 
 //
@@ -1109,16 +1112,14 @@ func (r *Refs) appendNodeGoUp(
 	// is > 0 and the ap.rn.upper contains branches
 	if len(ap.rn.branches) == int(r.degree) {
 
-		// not increased (idle pass)
-		if ap.increase == 0 {
-			return r.appendNodeGoUp(pack, ap, hash) // go up
-		}
-
 		ap.increase = 0 // reset
 
 		// TODO (kostyarin): LazyUpdating
 
-		// else -> increased
+		// even if the ap.increase is zero, the node can be
+		// changed, since its last branch can be updated by
+		// previous step; the updateHash doesn't save the node
+		// if its hash is the same
 		if err = ap.rn.updateHashIfNeed(pack, ap.depth, true); err != nil {
 			return // saving error
 		}
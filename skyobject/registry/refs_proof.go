@@ -0,0 +1,231 @@
+package registry
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A RefsProof is Merkle inclusion proof of an element
+// of a Refs. The proof contains nodes of the Refs from
+// the root to the element and can be verified without
+// the Refs, using hash of the Refs and hash of the
+// element only (see Verify method). A RefsProof proves
+// that the element has given index in the Refs
+//
+// Since nodes of a Refs can be not full (after deleting
+// for example), a RefsProof contains all nodes before
+// the path to the element. Thus, the proof is about
+// O(depth * degree * degree) hashes
+//
+// Use Encode and DecodeRefsProof to send a RefsProof
+// outside CXO
+type RefsProof struct {
+	Index  uint32 // index of the element
+	Depth  uint32 // depth of the Refs
+	Degree uint32 // degree of the Refs
+
+	// Levels of the proof from the Refs (the root)
+	// to node that contains the element, e.g. the
+	// proof has Depth + 1 levels. Length of the first
+	// level is length of the Refs
+	Levels []RefsProofLevel
+}
+
+// A RefsProofLevel is a level of RefsProof
+type RefsProofLevel struct {
+	Position uint32          // index of the path in the Node.Elements
+	Node     RefsProofNode   // node of the path
+	Before   []RefsProofNode // nodes of the Node.Elements before the Position
+}
+
+// A RefsProofNode is a node of a Refs as it saved
+type RefsProofNode struct {
+	Length   uint32          // length of the node
+	Elements []cipher.SHA256 // hashes of elements or branches
+}
+
+// hash of the node as it saved
+func (r *RefsProofNode) hash() cipher.SHA256 {
+	return cipher.SumSHA256(encoder.Serialize(encodedRefsNode(*r)))
+}
+
+// Encode the RefsProof
+func (r *RefsProof) Encode() []byte {
+	return encoder.Serialize(r)
+}
+
+// DecodeRefsProof decodes encoded RefsProof.
+// It doesn't verify the proof
+func DecodeRefsProof(val []byte) (rp *RefsProof, err error) {
+
+	rp = new(RefsProof)
+
+	if err = deserializeExact(val, rp); err != nil {
+		return nil, ErrInvalidProof
+	}
+
+	return
+}
+
+// Verify the RefsProof. The Verify returns
+// ErrInvalidProof if the RefsProof doesn't prove
+// that element with given hash is element of the
+// Refs with given hash with the Index
+func (r *RefsProof) Verify(
+	refs cipher.SHA256, // : hash of the Refs
+	hash cipher.SHA256, // : hash of the element
+) (
+	err error, //          : ErrInvalidProof or nil
+) {
+
+	if Degree(r.Degree).Validate() != nil ||
+		len(r.Levels) != int(r.Depth)+1 {
+
+		return ErrInvalidProof
+	}
+
+	// the root
+
+	var root = r.Levels[0].Node
+
+	if r.Index >= root.Length {
+		return ErrInvalidProof
+	}
+
+	var er = encodedRefs{
+		Depth:    r.Depth,
+		Degree:   r.Degree,
+		Length:   root.Length,
+		Elements: root.Elements,
+	}
+
+	if cipher.SumSHA256(encoder.Serialize(er)) != refs {
+		return ErrInvalidProof
+	}
+
+	// the path
+
+	var index uint32 // index of the element by the path
+
+	for k, lv := range r.Levels {
+
+		var depth = int(r.Depth) - k
+
+		if int(lv.Position) >= len(lv.Node.Elements) ||
+			len(lv.Node.Elements) > int(r.Degree) {
+
+			return ErrInvalidProof
+		}
+
+		if depth == 0 {
+
+			if len(lv.Before) != 0 || lv.Node.Elements[lv.Position] != hash {
+				return ErrInvalidProof
+			}
+
+			index += lv.Position
+			break
+		}
+
+		if len(lv.Before) != int(lv.Position) {
+			return ErrInvalidProof
+		}
+
+		for i, bn := range lv.Before {
+			if bn.hash() != lv.Node.Elements[i] {
+				return ErrInvalidProof
+			}
+			index += bn.Length
+		}
+
+		var next = r.Levels[k+1].Node
+		if next.hash() != lv.Node.Elements[lv.Position] {
+			return ErrInvalidProof
+		}
+
+	}
+
+	if index != r.Index {
+		return ErrInvalidProof
+	}
+
+	return
+}
+
+// Proof returns Merkle inclusion proof of element
+// with given index. If the Refs has unsaved changes
+// (see LazyUpdating flag), then they will be saved
+// (like the Rebuild does). See RefsProof for details
+func (r *Refs) Proof(
+	pack Pack, //        : pack to load
+	i int, //            : index of the element
+) (
+	rp *RefsProof, //    : the proof
+	err error, //        : error if any
+) {
+
+	if err = r.initialize(pack); err != nil {
+		return
+	}
+
+	if err = validateIndex(i, r.length); err != nil {
+		return
+	}
+
+	if r.mods&contentMod != 0 {
+		if err = r.walkUpdating(pack); err != nil {
+			return
+		}
+	}
+
+	rp = &RefsProof{
+		Index:  uint32(i),
+		Depth:  uint32(r.depth),
+		Degree: uint32(r.degree),
+	}
+
+	var rn, depth = r.refsNode, r.depth
+
+	for {
+
+		var lv = RefsProofLevel{Node: proofNode(rn, depth)}
+
+		if depth == 0 {
+			lv.Position = uint32(i)
+			rp.Levels = append(rp.Levels, lv)
+			break
+		}
+
+		var (
+			br *refsNode
+			j  int
+		)
+
+		for j, br = range rn.branches {
+
+			if err = r.loadNodeIfNeed(pack, br, depth-1); err != nil {
+				return nil, err
+			}
+
+			if i >= br.length {
+				lv.Before = append(lv.Before, proofNode(br, depth-1))
+				i -= br.length // subtract length of the skipped branch
+				continue       // and skip the branch
+			}
+
+			break // the branch that contains the needle has been found
+		}
+
+		lv.Position = uint32(j)
+		rp.Levels = append(rp.Levels, lv)
+
+		rn, depth = br, depth-1
+	}
+
+	return
+}
+
+// node of the Refs as RefsProofNode
+func proofNode(rn *refsNode, depth int) RefsProofNode {
+	return RefsProofNode(rn.encodedNode(depth))
+}
@@ -0,0 +1,151 @@
+package registry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func testRefsProof(
+	t *testing.T, //           : the testing
+	r *Refs, //                : the Refs
+	pack Pack, //              : the Pack
+	hashes []cipher.SHA256, // : elements of the Refs
+) {
+
+	var (
+		rp  *RefsProof
+		err error
+	)
+
+	for i, hash := range hashes {
+
+		if rp, err = r.Proof(pack, i); err != nil {
+			t.Fatal(err)
+		}
+
+		if rp.Index != uint32(i) {
+			t.Error("wrong index")
+		}
+
+		if err = rp.Verify(r.Hash, hash); err != nil {
+			t.Errorf("can't verify %d: %v", i, err)
+			continue
+		}
+
+		// encoding
+
+		if rp, err = DecodeRefsProof(rp.Encode()); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = rp.Verify(r.Hash, hash); err != nil {
+			t.Errorf("can't verify decoded %d: %v", i, err)
+		}
+
+		// invalid
+
+		if err = rp.Verify(r.Hash, hashByNumber(1<<60)); err != ErrInvalidProof {
+			t.Error("wrong error:", err)
+		}
+
+		if err = rp.Verify(cipher.SHA256{}, hash); err != ErrInvalidProof {
+			t.Error("wrong error:", err)
+		}
+
+		// other index
+
+		rp.Index = uint32(len(hashes) - 1 - i)
+
+		if rp.Index != uint32(i) {
+			if err = rp.Verify(r.Hash, hash); err != ErrInvalidProof {
+				t.Error("wrong error:", err)
+			}
+		}
+
+	}
+
+	if _, err = r.Proof(pack, len(hashes)); err != ErrIndexOutOfRange {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestRefs_Proof(t *testing.T) {
+	// Proof(pack Pack, i int) (rp *RefsProof, err error)
+
+	var (
+		pack = getTestPack()
+
+		users []cipher.SHA256
+
+		r   Refs
+		err error
+	)
+
+	for _, flags := range testRefsFlags() {
+
+		pack.ClearFlags(^0)
+		pack.AddFlags(flags)
+
+		for _, degree := range testRefsDegrees(pack) {
+
+			for _, length := range testRefsLengths(degree) {
+
+				t.Run(fmt.Sprintf("%08b:%d:%d", flags, degree, length),
+					func(t *testing.T) {
+
+						users = getHashList(getTestUsers(length))
+
+						clearRefs(t, &r, pack, degree)
+
+						if err = r.AppendHashes(pack, users...); err != nil {
+							t.Fatal(err)
+						}
+
+						testRefsProof(t, &r, pack, users)
+
+						// not full nodes and changed elements (unsaved
+						// changes if LazyUpdating flag is set)
+
+						if length < 3 {
+							return
+						}
+
+						if err = r.DeleteByIndex(pack, 1); err != nil {
+							t.Fatal(err)
+						}
+
+						users = append(users[:1:1], users[2:]...)
+						users[0] = hashByNumber(1 << 40)
+
+						if err = r.SetHashByIndex(pack, 0, users[0]); err != nil {
+							t.Fatal(err)
+						}
+
+						testRefsProof(t, &r, pack, users)
+
+						// unloaded
+
+						r.Reset()
+						testRefsProof(t, &r, pack, users)
+
+					})
+
+			}
+
+		}
+
+	}
+
+}
+
+func TestDecodeRefsProof(t *testing.T) {
+	// DecodeRefsProof(val []byte) (rp *RefsProof, err error)
+
+	if _, err := DecodeRefsProof([]byte("invalid")); err != ErrInvalidProof {
+		t.Error("wrong error:", err)
+	}
+
+}
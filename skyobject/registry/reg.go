@@ -25,7 +25,7 @@ func (r *Reg) Register(name string, val interface{}) {
 	}
 	typ := typeOf(val)
 	switch typ {
-	case typeOfRef, typeOfRefs, typeOfDynamic:
+	case typeOfRef, typeOfRefs, typeOfDynamic, typeOfBlob:
 		panic("can't register reference type")
 	default:
 	}
@@ -63,6 +63,10 @@ func (r *Reg) getSchema(typ reflect.Type) Schema {
 		}
 	}
 
+	if typ == typeOfBlob { // blob
+		return blobSchema()
+	}
+
 	if typ == typeOfRef || typ == typeOfRefs {
 		panic("Ref or Refs are not allowed in arrays and slices")
 	}
@@ -181,6 +185,9 @@ func (r *Reg) getField(sf reflect.StructField) Field {
 			typ: ReferenceTypeDynamic,
 		}
 		return f
+	case typeOfBlob: // blob
+		f.schema = blobSchema()
+		return f
 	default:
 	}
 
@@ -193,3 +200,13 @@ func (r *Reg) getField(sf reflect.StructField) Field {
 	return f
 
 }
+
+func blobSchema() Schema {
+	return &referenceSchema{
+		schema: schema{
+			ref:  SchemaRef{},
+			kind: reflect.Ptr, // Blob is pointer to a tree
+		},
+		typ: ReferenceTypeBlob,
+	}
+}
@@ -51,7 +51,9 @@ func DecodeRegistry(b []byte) (r *Registry, err error) {
 	r = newRegistry()
 
 	for _, re := range res {
-		s, err = decodeSchema(re.Schema)
+		if s, err = decodeSchema(re.Schema); err != nil {
+			return nil, err
+		}
 		r.reg[re.Name] = s
 		r.srf[s.Reference()] = s
 	}
@@ -119,6 +121,19 @@ func (r *Registry) SchemaByName(name string) (Schema, error) {
 	return r.schemaByName(name)
 }
 
+// Names returns sorted names of registered types
+func (r *Registry) Names() (names []string) {
+
+	names = make([]string, 0, len(r.reg))
+
+	for name := range r.reg {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return
+}
+
 // Types returns Types of the Registry. If this registry creaded using
 // DecodeRegistry (received from network) then result will not
 // be valid (empty maps). The Types used to pack/unpack CX objects
@@ -165,7 +180,7 @@ func (r *Registry) fillSchema(s Schema, filled map[Schema]struct{}) {
 				panic(err)
 			}
 			r.fillSchema(x.elem, filled)
-		case ReferenceTypeDynamic:
+		case ReferenceTypeDynamic, ReferenceTypeBlob:
 			// do nothing
 		default:
 			panic("invalid reference: " + s.String())
@@ -294,12 +309,13 @@ func decodeSchema(b []byte) (s Schema, err error) {
 	}
 	// is reference
 	switch ReferenceType(x.ReferenceType) {
-	case ReferenceTypeSingle, ReferenceTypeSlice, ReferenceTypeDynamic:
+	case ReferenceTypeSingle, ReferenceTypeSlice, ReferenceTypeDynamic,
+		ReferenceTypeBlob:
 		// kind, typ, elem
 		rs := referenceSchema{}
 		rs.kind = reflect.Kind(x.Kind)
 		rs.typ = ReferenceType(x.ReferenceType)
-		if rs.typ != ReferenceTypeDynamic {
+		if rs.typ == ReferenceTypeSingle || rs.typ == ReferenceTypeSlice {
 			if rs.elem, err = decodeSchema(x.Elem); err != nil {
 				return
 			}
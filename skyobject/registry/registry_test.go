@@ -308,6 +308,38 @@ func TestRegistry_SchemaByName(t *testing.T) {
 	}
 }
 
+func TestRegistry_Names(t *testing.T) {
+
+	var reg = NewRegistry(func(r *Reg) {
+		r.Register("test.User", TestUser{})
+		r.Register("test.Group", TestGroup{})
+		r.Register("test.Man", TestMan{})
+	})
+
+	var names = reg.Names()
+
+	if len(names) != 3 {
+		t.Fatal("wrong number of names:", len(names))
+	}
+
+	for i, want := range []string{"test.Group", "test.Man", "test.User"} {
+		if names[i] != want {
+			t.Errorf("wrong name #%d: %q, want %q", i, names[i], want)
+		}
+	}
+
+	var dec, err = DecodeRegistry(reg.Encode())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dec.Names()) != 3 {
+		t.Error("wrong number of names of decoded Registry")
+	}
+
+}
+
 func TestRegistry_SchemaByReference(t *testing.T) {
 
 	var reg = testRegistry()
@@ -28,6 +28,19 @@ type Root struct {
 	Sig  cipher.Sig    `enc:"-"` // signature
 	Hash cipher.SHA256 `enc:"-"` // hash of this encoded Root
 
+	// Sigs is signature container of a Root of
+	// a multisig feed (see Multisig), the Sig is
+	// blank for such Root
+	Sigs Signatures `enc:"-"`
+
+	// Cert is certificate of a key that signed
+	// the Root instead of the feed (see Certificate)
+	Cert *Certificate `enc:"-"`
+
+	// Revs is list of revocations carried
+	// by the Root (see Revocation)
+	Revs []Revocation `enc:"-"`
+
 	// Prev is hash of previous Root, the Prev can
 	// be blank is Seq of the Root is zero, that
 	// means the Root is first in chain
@@ -108,6 +108,11 @@ func rootTreeHash(
 		return
 	}
 
+	if IsSealed(val) == true {
+		it.Name = "(sealed) " + hash.Hex()[:7]
+		return
+	}
+
 	return rootTreeData(pack, sch, val)
 }
 
@@ -198,6 +203,10 @@ func rootTreeReferences(
 
 		return rootTreeDynamic(&dr, pack)
 
+	case ReferenceTypeBlob:
+
+		return rootTreeBlob(pack, val)
+
 	default:
 
 		it = new(gotree.GTStructure)
@@ -440,7 +449,7 @@ func rootTreeSlice(pack Pack, sch Schema, val []byte) (it *gotree.GTStructure) {
 
 	var m, s, k int
 
-	if s = fixedSize(el.Kind()); s < 0 {
+	if s = fixedSize(el.Kind()); s > 0 {
 
 		for k = 0; k < ln; k++ {
 
@@ -641,3 +650,54 @@ func rootTreeRefs(pack Pack, sch Schema, val []byte) (it *gotree.GTStructure) {
 
 	return
 }
+
+func rootTreeBlob(pack Pack, val []byte) (it *gotree.GTStructure) {
+
+	var (
+		blob Blob
+		err  error
+	)
+
+	it = new(gotree.GTStructure)
+
+	if _, err = encoder.DeserializeRaw(val, &blob); err != nil {
+		it.Name = "*(blob) err: " + err.Error()
+		return
+	}
+
+	if blob.IsBlank() == true {
+		it.Name = "*(blob) nil"
+		return
+	}
+
+	if val, err = pack.Get(blob.Hash); err != nil {
+		it.Name = fmt.Sprintf("*(blob) %s err: %s", blob.Short(), err.Error())
+		return
+	}
+
+	if IsSealed(val) == true {
+		it.Name = "*(blob) (sealed) " + blob.Short()
+		return
+	}
+
+	var leafs []blobItem
+	if leafs, err = blobLeafs(pack, blob.Hash); err != nil {
+		it.Name = fmt.Sprintf("*(blob) %s err: %s", blob.Short(), err.Error())
+		return
+	}
+
+	var size uint64
+	it.Items = make([]*gotree.GTStructure, 0, len(leafs))
+
+	for _, leaf := range leafs {
+		size += leaf.Size
+		it.Items = append(it.Items, &gotree.GTStructure{
+			Name: fmt.Sprintf("%s (%d bytes)", leaf.Hash.Hex()[:7], leaf.Size),
+		})
+	}
+
+	it.Name = fmt.Sprintf("*(blob) %s size: %d, chunks: %d", blob.Short(),
+		size, len(leafs))
+
+	return
+}
@@ -12,6 +12,7 @@ var (
 	typeOfRef     = typeOf(Ref{})
 	typeOfRefs    = typeOf(Refs{})
 	typeOfDynamic = typeOf(Dynamic{})
+	typeOfBlob    = typeOf(Blob{})
 )
 
 // A ReferenceType represents type of a reference
@@ -23,6 +24,7 @@ const (
 	ReferenceTypeSingle                // Ref (cipher.SHA256)
 	ReferenceTypeSlice                 // Refs (a'la []Ref)
 	ReferenceTypeDynamic               // Dynamic (struct{Object, Schema Ref.})
+	ReferenceTypeBlob                  // Blob (Merkle-tree of chunks)
 )
 
 // A Schema represents schema of a CX object
@@ -176,7 +178,7 @@ func (r *referenceSchema) HasReferences() bool {
 }
 
 func (r *referenceSchema) IsRegistered() bool {
-	return false // Ref, Refs and Dynamic are not regsitered
+	return false // Ref, Refs, Dynamic and Blob are not regsitered
 }
 
 func (r *referenceSchema) IsReference() bool {
@@ -206,6 +208,8 @@ func (r *referenceSchema) Size(p []byte) (n int, err error) {
 		n = refsSize
 	case ReferenceTypeDynamic:
 		n = dynamicSize
+	case ReferenceTypeBlob:
+		n = blobSize
 	default:
 		err = fmt.Errorf("[ERR] reference with invalid ReferenceType: %d", rt)
 		return
@@ -220,7 +224,7 @@ func (r *referenceSchema) encodedSchema() (x encodedSchema) {
 	x.Kind = uint32(r.kind)
 	x.ReferenceType = uint32(r.typ)
 	// the schema of the Elem is registered allways
-	if r.typ != ReferenceTypeDynamic {
+	if r.typ == ReferenceTypeSingle || r.typ == ReferenceTypeSlice {
 		x.Elem = (&schema{
 			SchemaRef{},
 			r.elem.Kind(),
@@ -246,6 +250,8 @@ func (r *referenceSchema) String() string {
 		return fmt.Sprintf("[]*%s", r.Elem().String())
 	case ReferenceTypeDynamic:
 		return "*(dynamic)"
+	case ReferenceTypeBlob:
+		return "*(blob)"
 	}
 	return "<invalid>"
 }
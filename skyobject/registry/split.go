@@ -91,6 +91,15 @@ func splitSchemaHash(
 		return
 	}
 
+	// the object of a private Root (see SetPrivate)
+	if IsSealed(val) == true {
+		splitSealed(s, val)
+		return
+	} else if sch == sealedObjectSchema {
+		s.Fail(ErrNotSealed)
+		return
+	}
+
 	// go deepper
 
 	splitSchemaData(s, sch, val)
@@ -115,7 +124,7 @@ func splitSchemaData(
 		return // no references, no walking
 	}
 
-	// the object represents Ref, Refs or Dynamic
+	// the object represents Ref, Refs, Dynamic or Blob
 	if sch.IsReference() == true {
 		splitSchemaReference(s, sch, val)
 		return
@@ -186,6 +195,16 @@ func splitSchemaReference(
 
 		dr.Split(s)
 
+	case ReferenceTypeBlob: // Blob
+
+		var blob Blob
+		if _, err = encoder.DeserializeRaw(val, &blob); err != nil {
+			s.Fail(err)
+			return
+		}
+
+		blob.Split(s)
+
 	default:
 
 		s.Fail(fmt.Errorf("invalid ReferenceType %d to walk through", rt))
@@ -201,7 +220,7 @@ func splitArray(
 ) {
 
 	var el Schema // Schema of the element
-	if el = sch.Elem(); el != nil {
+	if el = sch.Elem(); el == nil {
 		s.Fail(fmt.Errorf("Schema of element of array %q is nil", sch))
 		return
 	}
@@ -227,7 +246,7 @@ func splitSlice(
 	}
 
 	var el Schema // Schema of the element
-	if el = sch.Elem(); el != nil {
+	if el = sch.Elem(); el == nil {
 		s.Fail(fmt.Errorf("Schema of element of slice %q is nil", sch))
 		return
 	}
@@ -0,0 +1,82 @@
+package registry
+
+import (
+	"fmt"
+)
+
+// Limits of a Root tree a Splitter walks through.
+// A hostile feed can publish a Root with huge tree
+// to exhaust resources of nodes that fill it. The
+// Limits used to stop the filling early. Zero value
+// of a field means no limit
+type Limits struct {
+	Objects        uint64 // max number of objects (including the Root)
+	Volume         uint64 // max total size of the objects in bytes
+	RefsDepth      int    // max depth of a Refs (see Depth method of Refs)
+	RefsDegree     Degree // max degree of a Refs (see Degree method of Refs)
+	DynamicNesting int    // max number of nested Dynamic references
+}
+
+// A LimitError occurs when a Root tree exceeds Limits.
+// Since a valid Root can't exceed the Limits, the error
+// means that the feed (or peer that sends the Root)
+// is hostile
+type LimitError struct {
+	Name  string // "objects", "volume", "refs depth", "refs degree" or "dynamic nesting"
+	Limit uint64 // the limit
+}
+
+// Error implements error interface
+func (l *LimitError) Error() string {
+	return fmt.Sprintf("%s limit %d of a Root tree exceeded", l.Name, l.Limit)
+}
+
+// A Limiter is a Splitter that limits the Root tree
+// it walks through. The Splitter fails with *LimitError
+// if the tree exceeds the Limits. The Limiter is
+// optional, a Splitter that doesn't implement it
+// has no limits
+type Limiter interface {
+	Limits() (l Limits)
+}
+
+// Limits of given Splitter, if the Splitter
+// doesn't implement the Limiter, then there
+// are no limits
+func splitterLimits(s Splitter) (l Limits) {
+	if lr, ok := s.(Limiter); ok == true {
+		l = lr.Limits()
+	}
+	return
+}
+
+// a Splitter that keeps nesting of
+// Dynamic references it walks through
+type nestedSplitter struct {
+	Splitter
+	nesting int
+}
+
+// Limits of the underlying Splitter
+func (n *nestedSplitter) Limits() (l Limits) {
+	return splitterLimits(n.Splitter)
+}
+
+// splitDynamic returns Splitter to split object of a
+// Dynamic reference, or nil if the Dynamic exceeds
+// nesting limit (the splitting fails in this case)
+func splitDynamic(s Splitter) (ds Splitter) {
+
+	var ns = nestedSplitter{s, 1}
+
+	if ps, ok := s.(*nestedSplitter); ok == true {
+		ns.Splitter, ns.nesting = ps.Splitter, ps.nesting+1
+	}
+
+	if limit := splitterLimits(s).DynamicNesting; limit > 0 && ns.nesting > limit {
+		s.Fail(&LimitError{"dynamic nesting", uint64(limit)})
+		return
+	}
+
+	return &ns
+}
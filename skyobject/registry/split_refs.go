@@ -77,6 +77,16 @@ func (r *Refs) Split(s Splitter, el Schema) {
 		return
 	}
 
+	if limit := splitterLimits(s).RefsDepth; limit > 0 && r.depth+1 > limit {
+		s.Fail(&LimitError{"refs depth", uint64(limit)})
+		return
+	}
+
+	if limit := splitterLimits(s).RefsDegree; limit > 0 && r.degree > limit {
+		s.Fail(&LimitError{"refs degree", uint64(limit)})
+		return
+	}
+
 	r.splitNode(&fp, el, r.refsNode, r.depth)
 
 }
@@ -39,6 +39,13 @@ func walkSchemaHash(
 		return
 	}
 
+	// the object of a private Root (see SetPrivate)
+	if IsSealed(val) == true {
+		return walkSealed(pack, val, walkFunc)
+	} else if sch == sealedObjectSchema {
+		return ErrNotSealed
+	}
+
 	return walkSchemaData(pack, sch, val, walkFunc)
 }
 
@@ -53,18 +60,36 @@ func walkSchemaData(
 	err error, //         : an error
 ) {
 
-	// the object represents Ref, Refs or Dynamic
+	return rangeReferences(sch, val, func(rs Schema, rv []byte) error {
+		return walkSchemaReference(pack, rs, rv, walkFunc)
+	})
+
+}
+
+// rangeReferences calls given function for every
+// reference (Ref, Refs, Dynamic or Blob) of given
+// encoded object with Schema and encoded value of
+// the reference
+func rangeReferences(
+	sch Schema, //                          : schema of the object
+	val []byte, //                          : encoded object
+	fn func(rs Schema, rv []byte) error, // : the function
+) (
+	err error, //                           : an error
+) {
+
+	// the object represents Ref, Refs, Dynamic or Blob
 	if sch.IsReference() == true {
-		return walkSchemaReference(pack, sch, val, walkFunc)
+		return fn(sch, val)
 	}
 
 	switch sch.Kind() {
 	case reflect.Array:
-		return walkArray(pack, sch, val, walkFunc)
+		return rangeArray(sch, val, fn)
 	case reflect.Slice:
-		return walkSlice(pack, sch, val, walkFunc)
+		return rangeSlice(sch, val, fn)
 	case reflect.Struct:
-		return walkStruct(pack, sch, val, walkFunc)
+		return rangeStruct(sch, val, fn)
 	}
 
 	return fmt.Errorf("invalid Schema to walk through: %s", sch)
@@ -118,6 +143,14 @@ func walkSchemaReference(
 		}
 		return dr.Walk(pack, walkFunc)
 
+	case ReferenceTypeBlob: // Blob
+
+		var blob Blob
+		if _, err = encoder.DeserializeRaw(val, &blob); err != nil {
+			return
+		}
+		return blob.Walk(pack, walkFunc)
+
 	default:
 
 		return fmt.Errorf("invalid ReferenceType %d to walk through", rt)
@@ -126,17 +159,16 @@ func walkSchemaReference(
 
 }
 
-func walkArray(
-	pack Pack, //         : pack to get
-	sch Schema, //        : schema of the array
-	val []byte, //        : encoded array
-	walkFunc WalkFunc, // : the function
+func rangeArray(
+	sch Schema, //                     : schema of the array
+	val []byte, //                     : encoded array
+	fn func(Schema, []byte) error, // : the function
 ) (
-	err error, //         : an error
+	err error, //                      : an error
 ) {
 
 	var el Schema // Schema of the element
-	if el = sch.Elem(); el != nil {
+	if el = sch.Elem(); el == nil {
 		// just avoid panic if the Scehma is invlaid;
 		// any invalid Schema shuld not break CXO, since
 		// we are not trusting remote nodes, even if they
@@ -146,17 +178,16 @@ func walkArray(
 		return fmt.Errorf("Schema of element of array %q is nil", sch)
 	}
 
-	return walkArraySlice(pack, el, sch.Len(), val, walkFunc)
+	return rangeArraySlice(el, sch.Len(), val, fn)
 
 }
 
-func walkSlice(
-	pack Pack, //         : pack to get
-	sch Schema, //        : schema of the slice
-	val []byte, //        : encoded slice
-	walkFunc WalkFunc, // : the function
+func rangeSlice(
+	sch Schema, //                     : schema of the slice
+	val []byte, //                     : encoded slice
+	fn func(Schema, []byte) error, // : the function
 ) (
-	err error, //         : an error
+	err error, //                      : an error
 ) {
 
 	var ln int // length of the slice
@@ -165,22 +196,21 @@ func walkSlice(
 	}
 
 	var el Schema // Schema of the element
-	if el = sch.Elem(); el != nil {
+	if el = sch.Elem(); el == nil {
 		return fmt.Errorf("Schema of element of slice %q is nil", sch)
 	}
 
-	return walkArraySlice(pack, el, ln, val[4:], walkFunc)
+	return rangeArraySlice(el, ln, val[4:], fn)
 
 }
 
-func walkArraySlice(
-	pack Pack, //         : pack to get
-	el Schema, //         : shcema of an element
-	ln int, //            : length of the array or slice (> 0)
-	val []byte, //        : encoded array or slice starting from first element
-	walkFunc WalkFunc, // : the function
+func rangeArraySlice(
+	el Schema, //                      : shcema of an element
+	ln int, //                         : length of the array or slice (> 0)
+	val []byte, //                     : encoded array or slice from first element
+	fn func(Schema, []byte) error, // : the function
 ) (
-	err error, //         : an error
+	err error, //                      : an error
 ) {
 
 	// doesn't need to walk through the zero-length
@@ -208,7 +238,7 @@ func walkArraySlice(
 		// and we don't need to call el.HasReferences(),
 		// we just walk the element
 
-		err = walkSchemaData(pack, el, val[shift:shift+m], walkFunc)
+		err = rangeReferences(el, val[shift:shift+m], fn)
 
 		if err != nil {
 			return
@@ -222,13 +252,12 @@ func walkArraySlice(
 
 }
 
-func walkStruct(
-	pack Pack, //         : pack to get
-	sch Schema, //        : schema of the struct
-	val []byte, //        : encoded struct
-	walkFunc WalkFunc, // : the function
+func rangeStruct(
+	sch Schema, //                     : schema of the struct
+	val []byte, //                     : encoded struct
+	fn func(Schema, []byte) error, // : the function
 ) (
-	err error, //         : an error
+	err error, //                      : an error
 ) {
 
 	var shift, s int
@@ -255,7 +284,7 @@ func walkStruct(
 			continue
 		}
 
-		err = walkSchemaData(pack, fl.Schema(), val[shift:shift+s], walkFunc)
+		err = rangeReferences(fl.Schema(), val[shift:shift+s], fn)
 
 		if err != nil {
 			return
@@ -1,3 +1,146 @@
 package registry
 
-// TODO (kostyarin): implement walk tests
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+type testWalkItem struct {
+	User Ref `skyobject:"schema=test.User"`
+}
+
+type testWalkList struct {
+	Array [2]testWalkItem
+	Slice []testWalkItem
+}
+
+// sequential Splitter
+type testSplitter struct {
+	pack *dummyPack
+	keys map[cipher.SHA256]struct{}
+	err  error
+}
+
+func (s *testSplitter) Registry() *Registry {
+	return s.pack.reg
+}
+
+func (s *testSplitter) Pre(cipher.SHA256) (rc int, err error) {
+	return
+}
+
+func (s *testSplitter) Get(key cipher.SHA256) (val []byte, rc int, err error) {
+	s.keys[key] = struct{}{}
+	val, err = s.pack.Get(key)
+	return
+}
+
+func (s *testSplitter) Fail(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *testSplitter) Go(fn func()) {
+	fn()
+}
+
+// list of users in array and slice
+func testWalkListRef(
+	t *testing.T,
+) (
+	pack *dummyPack,
+	sch Schema,
+	ref Ref,
+	users []cipher.SHA256,
+) {
+
+	var reg = NewRegistry(func(r *Reg) {
+		r.Register("test.User", TestUser{})
+		r.Register("test.Item", testWalkItem{})
+		r.Register("test.List", testWalkList{})
+	})
+
+	pack = testPackReg(reg)
+
+	var err error
+	if sch, err = reg.SchemaByName("test.List"); err != nil {
+		t.Fatal(err)
+	}
+
+	var list testWalkList
+
+	list.Slice = make([]testWalkItem, 2)
+
+	for i, item := range []*testWalkItem{
+		&list.Array[0],
+		&list.Array[1],
+		&list.Slice[0],
+		&list.Slice[1],
+	} {
+		var usr = TestUser{Name: "Alice", Age: uint32(i)}
+		if err = item.User.SetValue(pack, &usr); err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, item.User.Hash)
+	}
+
+	if err = ref.SetValue(pack, &list); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+// elements of arrays and slices with references
+func TestRef_Walk_arraySlice(t *testing.T) {
+
+	var (
+		pack, sch, ref, users = testWalkListRef(t)
+
+		hs = make(map[cipher.SHA256]struct{})
+	)
+
+	var err = ref.Walk(pack, sch, func(hash cipher.SHA256, _ int) (bool, error) {
+		hs[hash] = struct{}{}
+		return true, nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, user := range users {
+		if _, ok := hs[user]; ok == false {
+			t.Error("missing user", i)
+		}
+	}
+
+}
+
+// elements of arrays and slices with references
+func TestRef_Split_arraySlice(t *testing.T) {
+
+	var (
+		pack, sch, ref, users = testWalkListRef(t)
+
+		s = &testSplitter{
+			pack: pack,
+			keys: make(map[cipher.SHA256]struct{}),
+		}
+	)
+
+	ref.Split(s, sch)
+
+	if s.err != nil {
+		t.Fatal(s.err)
+	}
+
+	for i, user := range users {
+		if _, ok := s.keys[user]; ok == false {
+			t.Error("missing user", i)
+		}
+	}
+
+}
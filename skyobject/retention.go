@@ -0,0 +1,320 @@
+package skyobject
+
+import (
+	"encoding/binary"
+	"log"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// A Retention represents rules to remove old Root
+// objects of a feed or a head. A Root is kept if at
+// least one of the rules keeps it. Last Root of a
+// head and pinned Root objects (see Pin) are never
+// removed. Zero value of a field disables the rule.
+// Blank Retention keeps all Root objects
+//
+//     // keep last 10 Root objects, all Root objects of last
+//     // hour and one Root per day (the last of the day)
+//
+//     err = c.SetRetention(feed, 0, skyobject.Retention{
+//         KeepLast:  10,
+//         KeepNewer: time.Hour,
+//         KeepPer:   24 * time.Hour,
+//     })
+//
+type Retention struct {
+	KeepLast  uint32        // keep last N Root objects
+	KeepNewer time.Duration // keep Root objects newer than the duration
+	KeepPer   time.Duration // keep one (last) Root per the duration
+}
+
+// retention of a feed is stored as meta information
+// of the feed (see data.Feeds), the key is "t" for
+// the feed and "t" + nonce for a head
+func retentionKey(nonce uint64) (key []byte) {
+
+	key = []byte("t")
+
+	if nonce != 0 {
+		var bn [8]byte
+		binary.BigEndian.PutUint64(bn[:], nonce)
+		key = append(key, bn[:]...)
+	}
+
+	return
+}
+
+// SetRetention sets Retention of given head of given feed.
+// Use zero nonce to set Retention of all heads of the feed.
+// Retention of a head overrides Retention of the feed. Use
+// blank Retention to remove it. The Retention is kept in
+// DB and used by the Prune method. It returns
+// data.ErrNoSuchFeed if the Container doesn't have
+// the feed
+func (c *Container) SetRetention(
+	feed cipher.PubKey,
+	nonce uint64,
+	rt Retention,
+) (
+	err error,
+) {
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	var val []byte
+
+	if rt != (Retention{}) {
+		val = encoder.Serialize(&rt)
+	}
+
+	return c.db.IdxDB().Tx(func(fs data.Feeds) error {
+		return fs.SetMeta(feed, retentionKey(nonce), val)
+	})
+}
+
+// Retention returns Retention of given head of given feed.
+// Use zero nonce to get Retention of all heads of the feed.
+// It returns blank Retention if the Retention is not set.
+// And it returns data.ErrNoSuchFeed if the Container
+// doesn't have the feed
+func (c *Container) Retention(
+	feed cipher.PubKey,
+	nonce uint64,
+) (
+	rt Retention,
+	err error,
+) {
+
+	c.Index.mx.Lock()
+	defer c.Index.mx.Unlock()
+
+	err = c.db.IdxDB().Tx(func(fs data.Feeds) (err error) {
+		rt, err = getRetention(fs, feed, nonce)
+		return
+	})
+
+	return
+}
+
+func getRetention(
+	fs data.Feeds,
+	feed cipher.PubKey,
+	nonce uint64,
+) (
+	rt Retention,
+	err error,
+) {
+
+	var val []byte
+
+	switch val, err = fs.GetMeta(feed, retentionKey(nonce)); err {
+	case nil:
+	case data.ErrNotFound:
+		return rt, nil // blank
+	default:
+		return
+	}
+
+	_, err = encoder.DeserializeRaw(val, &rt)
+	return
+}
+
+// Prune removes old Root objects of all feeds and heads
+// using their Retentions (see SetRetention), and then
+// removes objects that are not used anymore (objects
+// with zero rc). It returns number of removed Root
+// objects and number of removed objects. If the
+// RetentionInterval of the Config is not zero, then
+// the Container calls the Prune periodically
+func (c *Container) Prune() (roots, objects int, err error) {
+
+	var now = time.Now().UnixNano()
+
+	for _, pk := range c.Feeds() {
+
+		var heads []uint64
+		if heads, err = c.Heads(pk); err != nil {
+			if err == data.ErrNoSuchFeed {
+				err = nil // removed
+				continue
+			}
+			return
+		}
+
+		for _, nonce := range heads {
+
+			var seqs []uint64
+			if seqs, err = c.Index.pruneSeqs(pk, nonce, now); err != nil {
+				if err == data.ErrNoSuchFeed || err == data.ErrNoSuchHead {
+					err = nil // removed
+					continue
+				}
+				return
+			}
+
+			for _, seq := range seqs {
+				if err = c.DelRoot(pk, nonce, seq); err != nil {
+					if err == data.ErrNotFound || err == ErrPinnedRoot {
+						err = nil // already removed or pinned
+						continue
+					}
+					return
+				}
+				roots++
+			}
+
+		}
+
+	}
+
+	if roots == 0 {
+		return // nothing to collect
+	}
+
+	objects, err = c.removeObjects()
+	return
+}
+
+// seq numbers of Root objects of given head
+// that should be removed by Retention rules
+func (i *Index) pruneSeqs(
+	pk cipher.PubKey, // : feed
+	nonce uint64, //     : head
+	now int64, //        : time point
+) (
+	seqs []uint64, //    : Root objects to remove
+	err error, //        : an error
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	err = i.c.db.IdxDB().Tx(func(fs data.Feeds) (err error) {
+
+		var rt Retention
+		if rt, err = getRetention(fs, pk, nonce); err != nil {
+			return
+		}
+
+		if rt == (Retention{}) {
+			if rt, err = getRetention(fs, pk, 0); err != nil {
+				return
+			}
+		}
+
+		if rt == (Retention{}) {
+			return // keep all
+		}
+
+		var hs data.Heads
+		if hs, err = fs.Heads(pk); err != nil {
+			return
+		}
+
+		var rs data.Roots
+		if rs, err = hs.Roots(nonce); err != nil {
+			return
+		}
+
+		var (
+			n      uint32 // number of Root objects from the end
+			period int64  // period of last kept Root (KeepPer)
+			first  = true // first of the KeepPer
+		)
+
+		return rs.Descend(func(dr *data.Root) (_ error) {
+
+			var keep bool
+
+			switch {
+			case n == 0: // last Root of the head
+				keep = true
+			case n < rt.KeepLast:
+				keep = true
+			case rt.KeepNewer > 0 && now-dr.Time < int64(rt.KeepNewer):
+				keep = true
+			}
+
+			if rt.KeepPer > 0 {
+				var p = dr.Time / int64(rt.KeepPer)
+				if first == true || p != period {
+					keep, first, period = true, false, p
+				}
+			}
+
+			if keep == false {
+				seqs = append(seqs, dr.Seq)
+			}
+
+			n++
+			return
+		})
+
+	})
+
+	return
+}
+
+// remove objects with zero rc from CXDS
+func (c *Container) removeObjects() (removed int, err error) {
+
+	err = c.db.CXDS().IterateDel(
+		func(key cipher.SHA256, rc uint32, _ []byte) (del bool, _ error) {
+			if del = (rc == 0) && (c.IsCached(key) == false); del == true {
+				removed++
+			}
+			return
+		})
+
+	return
+}
+
+// start pruning loop if the RetentionInterval is not zero
+func (c *Container) startPruning() {
+
+	c.quit = make(chan struct{})
+
+	if c.conf.RetentionInterval <= 0 {
+		return
+	}
+
+	c.await.Add(1)
+	go c.pruneLoop(c.conf.RetentionInterval)
+}
+
+func (c *Container) pruneLoop(interval time.Duration) {
+	defer c.await.Done()
+
+	var (
+		tk = time.NewTicker(interval)
+		tc = tk.C
+	)
+
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tc:
+			if _, _, err := c.Prune(); err != nil {
+				log.Print(Prefix, "pruning error: ", err)
+			}
+		case <-c.quit:
+			return
+		}
+	}
+
+}
+
+// stop the pruning loop
+func (c *Container) stopPruning() {
+	c.closeo.Do(func() {
+		close(c.quit)
+		c.await.Wait()
+	})
+}
@@ -0,0 +1,126 @@
+package skyobject
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_Retention(t *testing.T) {
+
+	var (
+		c     = getTestContainer()
+		pk, _ = cipher.GenerateKeyPair()
+		rt    Retention
+		err   error
+		feed  = Retention{KeepLast: 10}
+		head  = Retention{KeepNewer: time.Hour}
+	)
+
+	defer c.Close()
+
+	assertTrue(t, c.SetRetention(pk, 0, feed) == data.ErrNoSuchFeed,
+		"wrong error")
+
+	assertNil(t, c.AddFeed(pk))
+
+	rt, err = c.Retention(pk, 0)
+	assertNil(t, err)
+	assertTrue(t, rt == Retention{}, "not blank")
+
+	assertNil(t, c.SetRetention(pk, 0, feed))
+	assertNil(t, c.SetRetention(pk, 1, head))
+
+	rt, err = c.Retention(pk, 0)
+	assertNil(t, err)
+	assertTrue(t, rt == feed, "wrong Retention of feed")
+
+	rt, err = c.Retention(pk, 1)
+	assertNil(t, err)
+	assertTrue(t, rt == head, "wrong Retention of head")
+
+	assertNil(t, c.SetRetention(pk, 1, Retention{}))
+
+	rt, err = c.Retention(pk, 1)
+	assertNil(t, err)
+	assertTrue(t, rt == Retention{}, "not removed")
+
+}
+
+func TestContainer_Prune(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+
+	for i := 0; i < 5; i++ {
+		r.Refs = []registry.Dynamic{
+			createDynamic(up, testRegistry, "test.User", &User{
+				Name: fmt.Sprintf("User #%d", i),
+				Age:  uint32(i),
+			}),
+		}
+		assertNil(t, c.Save(up, r))
+	}
+
+	var prune = func(roots, objects int) {
+		t.Helper()
+		var pr, po, err = c.Prune()
+		assertNil(t, err)
+		assertTrue(t, pr == roots && po == objects,
+			fmt.Sprint("wrong number of removed: ", pr, po))
+	}
+
+	var has = func(seq uint64) bool {
+		var _, err = c.Root(pk, r.Nonce, seq)
+		return err == nil
+	}
+
+	// no Retention
+	prune(0, 0)
+
+	// Retention of the head overrides Retention of the feed
+	assertNil(t, c.SetRetention(pk, 0, Retention{KeepLast: 1}))
+	assertNil(t, c.SetRetention(pk, r.Nonce, Retention{KeepNewer: time.Hour}))
+	prune(0, 0)
+
+	// keep last 3 (Root and User of every removed one)
+	assertNil(t, c.SetRetention(pk, r.Nonce, Retention{KeepLast: 3}))
+	prune(2, 4)
+
+	assertTrue(t, has(0) == false && has(1) == false, "not removed")
+	assertTrue(t, has(2) && has(3) && has(4), "removed")
+
+	// one per long period (last)
+	assertNil(t, c.SetRetention(pk, r.Nonce, Retention{
+		KeepPer: 100 * 365 * 24 * time.Hour,
+	}))
+	prune(2, 4)
+
+	assertTrue(t, has(4), "last Root removed")
+
+	// the last one is never removed
+	assertNil(t, c.SetRetention(pk, r.Nonce, Retention{}))
+	prune(0, 0)
+
+	assertTrue(t, has(4), "last Root removed")
+
+}
@@ -0,0 +1,197 @@
+package skyobject
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// ErrNotDriveDB occurs if a DB is not on drive
+var ErrNotDriveDB = errors.New("the DB is not on drive (see DBPath and DataDir)")
+
+// RotateKey changes key of encrypted DB. Given Config
+// describes DB with current key (see KeyFile and
+// Passphrase). If the Config doesn't have a key, then
+// the RotateKey encrypts not encrypted DB. The new key
+// is given by path to key file (see crypt.SaveKeyFile)
+// or by a passphrase, but not both. For a passphrase
+// new random salt is generated and kept in the new DB
+// (see crypt.NewDBPassphrase). The DB must be on drive
+// (see DBPath and DataDir) and must not be used by a
+// Container. The RotateKey copies the DB to temporary
+// files with ".rotate" extension and replaces the DB
+// with them. Thus, the RotateKey requires free space
+// for the copy. Before the replacing, the RotateKey
+// creates marker file with ".rotated" extension near
+// the CXDS. If the replacing is interrupted, then the
+// NewContainer (or the RotateKey) finishes it. Use new
+// key file or passphrase after the RotateKey
+//
+//     var conf = skyobject.NewConfig()
+//     conf.DBPath = "/path/to/db"
+//     conf.KeyFile = "/path/to/old.key"
+//
+//     crypt.SaveKeyFile("/path/to/new.key", crypt.NewKey())
+//
+//     err = skyobject.RotateKey(conf, "/path/to/new.key", "")
+//     if err != nil {
+//         // [...]
+//     }
+//
+//     conf.KeyFile = "/path/to/new.key"
+//
+//     c, err = skyobject.NewContainer(conf)
+//
+func RotateKey(conf *Config, keyFile, passphrase string) (err error) {
+
+	var nc = *conf // new key
+
+	nc.KeyFile, nc.Passphrase = keyFile, passphrase
+
+	if keyFile != "" && passphrase != "" {
+		return errors.New("both key file and passphrase provided")
+	}
+
+	if nc.encrypted() == false {
+		return errors.New("missing new key file or passphrase")
+	}
+
+	if conf.DB != nil || conf.InMemoryDB == true {
+		return ErrNotDriveDB
+	}
+
+	var cxPath, idxPath = conf.dbPaths()
+
+	if err = finishRotate(cxPath, idxPath); err != nil {
+		return
+	}
+
+	for _, path := range []string{cxPath, idxPath} {
+		if _, err = os.Stat(path); err != nil {
+			return
+		}
+	}
+
+	var cxTemp, idxTemp = cxPath + ".rotate", idxPath + ".rotate"
+
+	for _, path := range []string{cxTemp, idxTemp} {
+		if _, err = os.Stat(path); err == nil {
+			return fmt.Errorf("file %q already exists", path)
+		} else if os.IsNotExist(err) == false {
+			return
+		}
+	}
+
+	var (
+		src, dst   *data.DB
+		esrc, edst *data.DB // encrypted
+		marked     bool     // the marker is created
+	)
+
+	if src, err = openDriveDB(cxPath, idxPath, -1); err != nil {
+		return
+	}
+	defer src.Close()
+
+	if esrc, err = conf.encrypt(src); err != nil {
+		return
+	}
+
+	if dst, err = openDriveDB(cxTemp, idxTemp, -1); err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil && marked == false {
+			os.Remove(cxTemp) // clean up
+			os.Remove(idxTemp)
+		}
+	}()
+
+	if edst, err = nc.encrypt(dst); err != nil {
+		dst.Close()
+		return
+	}
+
+	if err = data.Copy(edst, esrc); err != nil {
+		dst.Close()
+		return
+	}
+
+	if err = dst.Close(); err != nil {
+		return
+	}
+
+	if err = src.Close(); err != nil {
+		return
+	}
+
+	if err = createMarker(rotateMarker(cxPath)); err != nil {
+		return
+	}
+
+	marked = true // the copy is complete
+
+	return finishRotate(cxPath, idxPath)
+}
+
+// path to marker file of the RotateKey, the marker
+// file exists if the DB should be replaced with
+// complete copy
+func rotateMarker(cxPath string) string {
+	return cxPath + ".rotated"
+}
+
+// create empty file with given name and sync it
+func createMarker(name string) (err error) {
+
+	var fl *os.File
+	if fl, err = os.Create(name); err != nil {
+		return
+	}
+
+	if err = fl.Sync(); err != nil {
+		fl.Close()
+		os.Remove(name)
+		return
+	}
+
+	return fl.Close()
+}
+
+// finishRotate replaces DB with its copy made by
+// the RotateKey if the marker file exists, and
+// removes the marker; the finishRotate does
+// nothing if the marker doesn't exist
+func finishRotate(cxPath, idxPath string) (err error) {
+
+	var marker = rotateMarker(cxPath)
+
+	if _, err = os.Stat(marker); err != nil {
+		if os.IsNotExist(err) == true {
+			return nil // nothing to finish
+		}
+		return
+	}
+
+	for _, path := range []string{cxPath, idxPath} {
+
+		var temp = path + ".rotate"
+
+		if _, err = os.Stat(temp); err != nil {
+			if os.IsNotExist(err) == true {
+				continue // already replaced
+			}
+			return
+		}
+
+		if err = os.Rename(temp, path); err != nil {
+			return
+		}
+
+	}
+
+	return os.Remove(marker)
+}
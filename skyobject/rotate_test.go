@@ -0,0 +1,171 @@
+package skyobject
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data/crypt"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestRotateKey(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-rotate")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var conf = getTestConfig()
+	conf.InMemoryDB = false
+	conf.DBPath = filepath.Join(dir, "db")
+	conf.Passphrase = "secret"
+
+	var c *Container
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{
+			Name: "Alice Cooper",
+			Age:  21,
+		}),
+	}
+
+	assertNil(t, c.Save(up, r))
+	assertNil(t, c.Close())
+
+	// encrypted
+
+	var cx []byte
+	cx, err = ioutil.ReadFile(conf.DBPath + ".cxds")
+	assertNil(t, err)
+	assertTrue(t, bytes.Contains(cx, []byte("Alice Cooper")) == false,
+		"not encrypted")
+
+	// rotate
+
+	var (
+		key     = crypt.NewKey()
+		keyFile = filepath.Join(dir, "cxo.key")
+	)
+
+	assertNil(t, crypt.SaveKeyFile(keyFile, key))
+	assertNil(t, RotateKey(conf, keyFile, ""))
+
+	// old key
+
+	c, err = NewContainer(conf)
+	assertTrue(t, err == crypt.ErrWrongKey, "wrong error")
+
+	// new key
+
+	conf.Passphrase, conf.KeyFile = "", keyFile
+
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+
+	var rr *registry.Root
+	rr, err = c.LastRoot(pk, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, rr.Hash == r.Hash, "wrong Root")
+
+	var pack *Pack
+	pack, err = c.Pack(rr, nil)
+	assertNil(t, err)
+
+	var usr User
+	assertNil(t, rr.Refs[0].Value(pack, &usr))
+	assertTrue(t, usr.Name == "Alice Cooper", "wrong object")
+
+	assertNil(t, c.Close())
+
+	// new passphrase
+
+	assertTrue(t, RotateKey(conf, keyFile, "another") != nil, "missing error")
+	assertTrue(t, RotateKey(conf, "", "") != nil, "missing error")
+
+	assertNil(t, RotateKey(conf, "", "another"))
+
+	c, err = NewContainer(conf)
+	assertTrue(t, err == crypt.ErrWrongKey, "wrong error")
+
+	conf.Passphrase, conf.KeyFile = "another", ""
+
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	rr, err = c.LastRoot(pk, r.Nonce)
+	assertNil(t, err)
+
+	pack, err = c.Pack(rr, nil)
+	assertNil(t, err)
+
+	usr = User{}
+	assertNil(t, rr.Refs[0].Value(pack, &usr))
+	assertTrue(t, usr.Name == "Alice Cooper", "wrong object")
+
+}
+
+func TestRotateKey_interrupted(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-rotate")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		cxPath  = filepath.Join(dir, "db.cxds")
+		idxPath = filepath.Join(dir, "db.idx")
+	)
+
+	for _, path := range []string{cxPath, idxPath} {
+		assertNil(t, ioutil.WriteFile(path, []byte("old"), 0644))
+		assertNil(t, ioutil.WriteFile(path+".rotate", []byte("new"), 0644))
+	}
+
+	// no marker, the copy is not complete
+
+	assertNil(t, finishRotate(cxPath, idxPath))
+
+	for _, path := range []string{cxPath, idxPath} {
+		var got, err = ioutil.ReadFile(path)
+		assertNil(t, err)
+		assertTrue(t, string(got) == "old", "replaced")
+	}
+
+	// the CXDS is replaced, but the IdxDB is not
+
+	assertNil(t, createMarker(rotateMarker(cxPath)))
+	assertNil(t, os.Rename(cxPath+".rotate", cxPath))
+
+	assertNil(t, finishRotate(cxPath, idxPath))
+
+	for _, path := range []string{cxPath, idxPath} {
+		var got, err = ioutil.ReadFile(path)
+		assertNil(t, err)
+		assertTrue(t, string(got) == "new", "not replaced")
+
+		_, err = os.Stat(path + ".rotate")
+		assertTrue(t, os.IsNotExist(err), "temporary file is not removed")
+	}
+
+	_, err = os.Stat(rotateMarker(cxPath))
+	assertTrue(t, os.IsNotExist(err), "marker is not removed")
+
+}
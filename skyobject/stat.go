@@ -54,6 +54,13 @@ type ReadWriteStat struct {
 type FeedStat struct {
 	// Hads contains statistic of heads
 	Heads map[uint64]HeadStat
+
+	// Usage of the feed (see Usage method
+	// of the Container)
+	Usage Usage
+	// Quota of the feed, blank if
+	// the feed has no limits
+	Quota Quota
 }
 
 // A HeadStat represents statistic of
@@ -108,6 +115,12 @@ func (c *Container) Stat() (s *Stat) {
 
 	s.Feeds = c.Index.feedsStat()
 
+	for pk, fs := range s.Feeds {
+		fs.Usage, _ = c.Usage(pk) // ignore error
+		fs.Quota, _ = c.Quota(pk) // ignore error
+		s.Feeds[pk] = fs
+	}
+
 	return
 }
 
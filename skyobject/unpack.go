@@ -98,7 +98,18 @@ func (c *Container) Unpack(
 // timestamp of the Root. The Root should have correct
 // Pub, and Nonce fields. The Seq field will be set
 // to next inside the Save. The Save also set Hash and
-// Prev fields of the Root, and signs the Root
+// Prev fields of the Root, and signs the Root.
+//
+// A Root of multisig feed (see registry.Multisig)
+// should be prepared and signed before (see
+// PrepareRoot). The Save doesn't change and doesn't
+// sign such Root, but verifies its signatures.
+//
+// To sign a Root by a key authorized by feed (see
+// registry.Certificate) set Cert field of the Root
+// and use secret key of the authorized key for the
+// Unpack. Revocations carried by the Root (the Revs
+// field) are saved by this Container too
 func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
 
 	// save the Root recursive
@@ -127,6 +138,20 @@ func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
 
 	}
 
+	// check out signatures of a Root of multisig
+	// feed, the Root must not be changed after
+	// preparation (including the Reg field)
+
+	if registry.IsMultisigFeed(r.Pub) == true {
+		if err = c.verifyMultisigRoot(r); err != nil {
+			return
+		}
+	} else if r.Cert != nil {
+		if r.Cert.Key != cipher.PubKeyFromSecKey(up.sk) {
+			return ErrCertificateKey
+		}
+	}
+
 	// walk the Root first
 
 	defer func() {
@@ -281,31 +306,69 @@ func (i *Index) saveRoot(
 			return
 		}
 
-		if lastHash != (cipher.SHA256{}) {
-			r.Seq = lastSeq + 1
-			r.Prev = lastHash
+		if err = storeRootRevocations(fs, r); err != nil {
+			return
 		}
 
-		// else -> 0 and blank
+		if registry.IsMultisigFeed(r.Pub) == true {
+
+			// the Root has been prepared, signed and
+			// verified, but another Root can be saved
+			// or received after the preparation
+
+			var seq, prev = uint64(0), cipher.SHA256{}
+
+			if lastHash != (cipher.SHA256{}) {
+				seq, prev = lastSeq+1, lastHash
+			}
+
+			if r.Seq != seq || r.Prev != prev {
+				return ErrOutdatedRoot
+			}
+
+			val = r.Encode()
+			r.IsFull = true
+
+		} else {
+
+			if lastHash != (cipher.SHA256{}) {
+				r.Seq = lastSeq + 1
+				r.Prev = lastHash
+			}
 
-		r.Time = time.Now().UnixNano()
+			// else -> 0 and blank
 
-		// hash of the Root
+			var now = time.Now()
 
-		val = r.Encode()
-		r.Hash = cipher.SumSHA256(val)
-		r.IsFull = true
+			r.Time = now.UnixNano()
 
-		// sign
+			// check out Certificate of authorized key
+
+			if r.Cert != nil {
+				if err = verifyRootCertificate(fs, r, now); err != nil {
+					return
+				}
+			}
+
+			// hash of the Root
+
+			val = r.Encode()
+			r.Hash = cipher.SumSHA256(val)
+			r.IsFull = true
+
+			// sign
+
+			if r.Sig, err = cipher.SignHash(r.SigHash(), up.sk); err != nil {
+				return err
+			}
 
-		if r.Sig, err = cipher.SignHash(r.Hash, up.sk); err != nil {
-			return err
 		}
 
 		dr.Seq = r.Seq
 		dr.Prev = r.Prev
 		dr.Hash = r.Hash
 		dr.Sig = r.Sig
+		dr.Sigs = r.EncodeSigs()
 		dr.Time = r.Time
 
 		return roots.Set(dr) // save